@@ -20,7 +20,6 @@ import (
 	"github.com/knadh/koanf"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
 	"google.golang.org/grpc"
 
 	"github.com/umee-network/peggo/cmd/peggo/client"
@@ -70,27 +69,23 @@ func deployGravityCmd() *cobra.Command {
 				return err
 			}
 
-			logger, err := getLogger(cmd)
+			logger, err := getLogger(konfig)
 			if err != nil {
 				return err
 			}
 
-			tmRPCEndpoint, err := parseURL(logger, konfig, flagTendermintRPC)
-			if err != nil {
-				return err
-			}
 			cosmosGRPC, err := parseURL(logger, konfig, flagCosmosGRPC)
 			if err != nil {
 				return err
 			}
 
-			tmRPC, err := rpchttp.New(tmRPCEndpoint, "/websocket")
+			tmRPC, err := newTendermintRPC(logger, konfig)
 			if err != nil {
-				return fmt.Errorf("failed to create Tendermint RPC client: %w", err)
+				return err
+			}
+			if tmRPC != nil {
+				clientCtx = clientCtx.WithClient(tmRPC).WithNodeURI(konfig.String(flagTendermintRPC))
 			}
-
-			fmt.Fprintf(os.Stderr, "Connected to Tendermint RPC: %s\n", tmRPCEndpoint)
-			clientCtx = clientCtx.WithClient(tmRPC).WithNodeURI(tmRPCEndpoint)
 
 			daemonClient, err := client.NewCosmosClient(clientCtx, logger, cosmosGRPC)
 			if err != nil {
@@ -222,27 +217,23 @@ func deployERC20Cmd() *cobra.Command {
 				return err
 			}
 
-			logger, err := getLogger(cmd)
+			logger, err := getLogger(konfig)
 			if err != nil {
 				return err
 			}
 
-			tmRPCEndpoint, err := parseURL(logger, konfig, flagTendermintRPC)
-			if err != nil {
-				return err
-			}
 			cosmosGRPC, err := parseURL(logger, konfig, flagCosmosGRPC)
 			if err != nil {
 				return err
 			}
 
-			tmRPC, err := rpchttp.New(tmRPCEndpoint, "/websocket")
+			tmRPC, err := newTendermintRPC(logger, konfig)
 			if err != nil {
-				return fmt.Errorf("failed to create Tendermint RPC client: %w", err)
+				return err
+			}
+			if tmRPC != nil {
+				clientCtx = clientCtx.WithClient(tmRPC).WithNodeURI(konfig.String(flagTendermintRPC))
 			}
-
-			fmt.Fprintf(os.Stderr, "Connected to Tendermint RPC: %s\n", tmRPCEndpoint)
-			clientCtx = clientCtx.WithClient(tmRPC).WithNodeURI(tmRPCEndpoint)
 
 			daemonClient, err := client.NewCosmosClient(clientCtx, logger, cosmosGRPC)
 			if err != nil {
@@ -2,8 +2,10 @@ package client
 
 import (
 	"context"
-	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -12,10 +14,13 @@ import (
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	grpcstatus "google.golang.org/grpc/status"
 )
 
 type CosmosClient interface {
@@ -24,7 +29,8 @@ type CosmosClient interface {
 	QueryClient() *grpc.ClientConn
 	SyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error)
 	AsyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error)
-	QueueBroadcastMsg(msgs ...sdk.Msg) error
+	QueueBroadcastMsg(msgs ...sdk.Msg) (*BroadcastHandle, error)
+	GenerateUnsignedTx(msgs ...sdk.Msg) ([]byte, error)
 	ClientContext() client.Context
 	Close()
 }
@@ -55,11 +61,14 @@ func NewCosmosClient(
 		}
 	}
 
-	txFactory := NewTxFactory(ctx)
+	txFactory := NewTxFactory(ctx).WithGasAdjustment(opts.GasAdjustment)
 	if len(opts.GasPrices) > 0 {
 		txFactory = txFactory.WithGasPrices(opts.GasPrices)
 	}
 
+	// Queries, like the ones of the account retriever, go through gRPC, so Tendermint RPC isn't required.
+	ctx = ctx.WithGRPCClient(conn)
+
 	cc := &cosmosClient{
 		ctx:  ctx,
 		opts: opts,
@@ -70,7 +79,7 @@ func NewCosmosClient(
 		txFactory: txFactory,
 		canSign:   ctx.Keyring != nil,
 		syncMux:   new(sync.Mutex),
-		msgC:      make(chan sdk.Msg, msgCommitBatchSizeLimit),
+		msgC:      make(chan queuedMsg, msgCommitBatchSizeLimit),
 		doneC:     make(chan bool, 1),
 	}
 
@@ -83,6 +92,8 @@ func NewCosmosClient(
 			return nil, err
 		}
 
+		cc.syncGasPrices()
+
 		go cc.runBatchBroadcast()
 	}
 
@@ -91,10 +102,27 @@ func NewCosmosClient(
 
 type cosmosClientOptions struct {
 	GasPrices string
+
+	// GasAdjustment is the factor the simulated gas of the txs is multiplied by.
+	GasAdjustment float64
+	// MaxGasPrices caps the gas prices raised after insufficient fee errors.
+	MaxGasPrices sdk.DecCoins
+	// GasPriceBump is the percentage the gas prices (or the gas adjustment) are raised by when a tx fails because of
+	// insufficient fees (or because it ran out of gas).
+	GasPriceBump uint64
+	// FeeRetries is the number of times a tx failing because of its fees or gas is retried.
+	FeeRetries uint
+	// QueueRetries is the number of times a queued batch failing because of a transient error is retried.
+	QueueRetries uint
 }
 
 func defaultCosmosClientOptions() *cosmosClientOptions {
-	return &cosmosClientOptions{}
+	return &cosmosClientOptions{
+		GasAdjustment: 1.5,
+		GasPriceBump:  20,
+		FeeRetries:    3,
+		QueueRetries:  3,
+	}
 }
 
 type CosmosClientOption func(opts *cosmosClientOptions) error
@@ -112,6 +140,55 @@ func OptionGasPrices(gasPrices string) CosmosClientOption {
 	}
 }
 
+// OptionGasAdjustment sets the factor the simulated gas of the txs is multiplied by.
+func OptionGasAdjustment(gasAdjustment float64) CosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		if gasAdjustment < 1 {
+			return errors.Errorf("gas adjustment must be at least 1, got %f", gasAdjustment)
+		}
+
+		opts.GasAdjustment = gasAdjustment
+		return nil
+	}
+}
+
+// OptionMaxGasPrices caps the gas prices raised after insufficient fee errors. Empty means no cap.
+func OptionMaxGasPrices(maxGasPrices string) CosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		decCoins, err := sdk.ParseDecCoins(maxGasPrices)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to ParseDecCoins %s", maxGasPrices)
+			return err
+		}
+
+		opts.MaxGasPrices = decCoins
+		return nil
+	}
+}
+
+// OptionFeeRetries sets the number of times a tx failing because of insufficient fees or gas is retried, and the
+// percentage the gas prices or the gas adjustment are raised by on each retry.
+func OptionFeeRetries(retries uint, bumpPercent uint64) CosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		if retries > 0 && bumpPercent == 0 {
+			return errors.New("the gas price bump must be greater than zero to retry txs")
+		}
+
+		opts.FeeRetries = retries
+		opts.GasPriceBump = bumpPercent
+		return nil
+	}
+}
+
+// OptionQueueRetries sets the number of times a batch of queued msgs failing because of a transient error, like an
+// unavailable node or a full mempool, is retried before its handles resolve with the error.
+func OptionQueueRetries(retries uint) CosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		opts.QueueRetries = retries
+		return nil
+	}
+}
+
 func (c *cosmosClient) syncNonce() {
 	num, seq, err := c.txFactory.AccountRetriever().GetAccountNumberSequence(c.ctx, c.ctx.GetFromAddress())
 	if err != nil {
@@ -135,12 +212,15 @@ type cosmosClient struct {
 	txFactory tx.Factory
 
 	doneC   chan bool
-	msgC    chan sdk.Msg
+	msgC    chan queuedMsg
 	syncMux *sync.Mutex
 
 	accNum uint64
 	accSeq uint64
 
+	// gasPricesBumped is set when the gas prices of the tx factory were raised after insufficient fee errors.
+	gasPricesBumped bool
+
 	closed  int64
 	canSign bool
 }
@@ -171,31 +251,19 @@ var (
 	ErrReadOnly       = errors.New("client is in read-only mode")
 )
 
-// SyncBroadcastMsg sends Tx to chain and waits until Tx is included in block.
+// SyncBroadcastMsg sends Tx to chain and waits until Tx is included in block. If the tx is rejected or fails in the
+// block, the error is a *TxError holding the tx response.
 func (c *cosmosClient) SyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
 	c.syncMux.Lock()
 	defer c.syncMux.Unlock()
 
-	c.txFactory = c.txFactory.WithSequence(c.accSeq)
-	c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-	res, err := c.broadcastTx(c.ctx, c.txFactory, true, msgs...)
+	res, err := c.broadcastMsgs(true, true, msgs...)
 	if err != nil {
-		if strings.Contains(err.Error(), "account sequence mismatch") {
-			c.syncNonce()
-			c.txFactory = c.txFactory.WithSequence(c.accSeq)
-			c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-			c.logger.Debug().Uint64("nonce", c.accSeq).Msg("retrying broadcastTx with nonce")
-			res, err = c.broadcastTx(c.ctx, c.txFactory, true, msgs...)
-		}
-		if err != nil {
-			resJSON, _ := json.MarshalIndent(res, "", "\t")
-			c.logger.Err(err).Int("size", len(msgs)).RawJSON("tx_response", resJSON).Msg("failed to (sync) broadcast tx")
-			return nil, err
-		}
+		resJSON, _ := json.MarshalIndent(res, "", "\t")
+		c.logger.Err(err).Int("size", len(msgs)).RawJSON("tx_response", resJSON).Msg("failed to (sync) broadcast tx")
+		return res, err
 	}
 
-	c.accSeq++
-
 	return res, nil
 }
 
@@ -206,27 +274,40 @@ func (c *cosmosClient) AsyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, erro
 	c.syncMux.Lock()
 	defer c.syncMux.Unlock()
 
+	res, err := c.broadcastMsgs(false, true, msgs...)
+	if err != nil {
+		resJSON, _ := json.MarshalIndent(res, "", "\t")
+		c.logger.Err(err).Int("size", len(msgs)).RawJSON("tx_response", resJSON).Msg("failed to (async) broadcast tx")
+		return res, err
+	}
+
+	return res, nil
+}
+
+// broadcastMsgs broadcasts the msgs with the account sequence of the client. With resync, the sequence is synced and
+// the tx retried once if it's out of date. The sequence is incremented when the tx uses it. Must be called with the
+// sync lock held.
+func (c *cosmosClient) broadcastMsgs(await bool, resync bool, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
 	c.txFactory = c.txFactory.WithSequence(c.accSeq)
 	c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-	res, err := c.broadcastTx(c.ctx, c.txFactory, false, msgs...)
-	if err != nil {
-		if strings.Contains(err.Error(), "account sequence mismatch") {
-			c.syncNonce()
-			c.txFactory = c.txFactory.WithSequence(c.accSeq)
-			c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-			c.logger.Debug().Uint64("nonce", c.accSeq).Msg("retrying broadcastTx with nonce")
-			res, err = c.broadcastTx(c.ctx, c.txFactory, false, msgs...)
-		}
-		if err != nil {
-			resJSON, _ := json.MarshalIndent(res, "", "\t")
-			c.logger.Err(err).Int("size", len(msgs)).RawJSON("tx_response", resJSON).Msg("failed to (async) broadcast tx")
-			return nil, err
-		}
+	c.logger.Debug().Uint64("nonce", c.accSeq).Msg("broadcastTx with nonce")
+
+	res, err := c.broadcastTxWithFeeRetries(await, msgs...)
+	if resync && err != nil && strings.Contains(err.Error(), "account sequence mismatch") {
+		c.syncNonce()
+		c.txFactory = c.txFactory.WithSequence(c.accSeq)
+		c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
+		c.logger.Debug().Uint64("nonce", c.accSeq).Msg("retrying broadcastTx with nonce")
+		res, err = c.broadcastTxWithFeeRetries(await, msgs...)
 	}
 
-	c.accSeq++
+	// A tx that failed in a block still used its sequence.
+	if err == nil || (res != nil && res.Height > 0) {
+		c.accSeq++
+		c.logger.Debug().Uint64("nonce", c.accSeq).Msg("nonce incremented")
+	}
 
-	return res, nil
+	return res, err
 }
 
 const (
@@ -234,6 +315,8 @@ const (
 	defaultBroadcastTimeout    = 60 * time.Second
 )
 
+// broadcastTx simulates, signs and broadcasts the msgs through the gRPC tx service. If await is set, it waits until
+// the tx is included in a block.
 func (c *cosmosClient) broadcastTx(
 	clientCtx client.Context,
 	txf tx.Factory,
@@ -241,29 +324,11 @@ func (c *cosmosClient) broadcastTx(
 	msgs ...sdk.Msg,
 ) (*sdk.TxResponse, error) {
 
-	txf, err := c.prepareFactory(clientCtx, txf)
-	if err != nil {
-		err = errors.Wrap(err, "failed to prepareFactory")
-		return nil, err
-	}
-
-	if txf.SimulateAndExecute() || clientCtx.Simulate {
-		_, adjusted, err := tx.CalculateGas(clientCtx, txf, msgs...)
-		if err != nil {
-			err = errors.Wrap(err, "failed to CalculateGas")
-			return nil, err
-		}
-
-		txf = txf.WithGas(adjusted)
-	}
-
-	txn, err := txf.BuildUnsignedTx(msgs...)
+	txf, txn, err := c.buildTx(clientCtx, txf, msgs...)
 	if err != nil {
-		err = errors.Wrap(err, "failed to BuildUnsignedTx")
 		return nil, err
 	}
 
-	txn.SetFeeGranter(clientCtx.GetFeeGranterAddress())
 	err = tx.Sign(txf, clientCtx.GetFromName(), txn, true)
 	if err != nil {
 		err = errors.Wrap(err, "failed to Sign Tx")
@@ -276,43 +341,58 @@ func (c *cosmosClient) broadcastTx(
 		return nil, err
 	}
 
-	res, err := clientCtx.BroadcastTxSync(txBytes)
-	if !await || err != nil {
-		return res, err
-	}
-
 	awaitCtx, cancelFn := context.WithTimeout(context.Background(), defaultBroadcastTimeout)
 	defer cancelFn()
 
-	txHash, _ := hex.DecodeString(res.TxHash)
+	txService := txtypes.NewServiceClient(c.conn)
+
+	broadcastRes, err := txService.BroadcastTx(awaitCtx, &txtypes.BroadcastTxRequest{
+		TxBytes: txBytes,
+		Mode:    txtypes.BroadcastMode_BROADCAST_MODE_SYNC,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "failed to BroadcastTx")
+		return nil, err
+	}
+
+	res := broadcastRes.TxResponse
+	if res.Code != 0 {
+		return res, &TxError{Response: res}
+	}
+
+	if !await {
+		return res, nil
+	}
+
 	t := time.NewTimer(defaultBroadcastStatusPoll)
 
 	for {
 		select {
 		case <-awaitCtx.Done():
+			// The tx may still be included, so the response of the broadcast is returned along with its hash.
 			err := errors.Wrapf(ErrTimedOut, "%s", res.TxHash)
 			t.Stop()
-			return nil, err
+			return res, err
 		case <-t.C:
-			resultTx, err := clientCtx.Client.Tx(awaitCtx, txHash, false)
+			txRes, err := txService.GetTx(awaitCtx, &txtypes.GetTxRequest{Hash: res.TxHash})
 			if err != nil {
-				if errRes := client.CheckTendermintError(err, txBytes); errRes != nil {
-					c.logger.Error().Str("tendermint errRes", errRes.RawLog)
-					return errRes, err
+				// The tx isn't found until it's included in a block.
+				if grpcstatus.Code(err) != codes.NotFound {
+					c.logger.Error().Err(err).
+						Str("tx_hash", res.TxHash).
+						Msg("failed to get tx on broadcastTx")
 				}
 
-				c.logger.Error().Err(err).
-					Str("Tx Hash", res.TxHash).
-					Str("Raw Log", res.RawLog).
-					Msg("Tx error on broadcastTx")
-
 				t.Reset(defaultBroadcastStatusPoll)
 				continue
+			}
 
-			} else if resultTx.Height > 0 {
-				res = sdk.NewResponseResultTx(resultTx, res.Tx, res.Timestamp)
+			if txRes.TxResponse != nil && txRes.TxResponse.Height > 0 {
 				t.Stop()
-				return res, err
+				if txRes.TxResponse.Code != 0 {
+					return txRes.TxResponse, &TxError{Response: txRes.TxResponse}
+				}
+				return txRes.TxResponse, nil
 			}
 
 			t.Reset(defaultBroadcastStatusPoll)
@@ -322,6 +402,108 @@ func (c *cosmosClient) broadcastTx(
 
 var ErrTimedOut = errors.New("tx timed out")
 
+// msgIndexRegex matches the index of the failing msg in the log of a failed tx.
+var msgIndexRegex = regexp.MustCompile(`message index: (\d+)`)
+
+// buildTx simulates the gas of the msgs and builds the unsigned tx holding them. It returns the factory the tx was
+// built with, for signing.
+func (c *cosmosClient) buildTx(
+	clientCtx client.Context,
+	txf tx.Factory,
+	msgs ...sdk.Msg,
+) (tx.Factory, client.TxBuilder, error) {
+	txf, err := c.prepareFactory(clientCtx, txf)
+	if err != nil {
+		err = errors.Wrap(err, "failed to prepareFactory")
+		return txf, nil, err
+	}
+
+	if txf.SimulateAndExecute() || clientCtx.Simulate {
+		_, adjusted, err := tx.CalculateGas(c.conn, txf, msgs...)
+		if err != nil {
+			err = errors.Wrap(err, "failed to CalculateGas")
+			return txf, nil, err
+		}
+
+		txf = txf.WithGas(adjusted)
+	}
+
+	txn, err := txf.BuildUnsignedTx(msgs...)
+	if err != nil {
+		err = errors.Wrap(err, "failed to BuildUnsignedTx")
+		return txf, nil, err
+	}
+
+	txn.SetFeeGranter(clientCtx.GetFeeGranterAddress())
+
+	return txf, txn, nil
+}
+
+// GenerateUnsignedTx simulates the msgs and returns the unsigned tx holding them, encoded in JSON, with the gas and
+// the fees it would be broadcast with. Only the from address of the client context is needed, not its keyring, so
+// the tx can be signed offline.
+func (c *cosmosClient) GenerateUnsignedTx(msgs ...sdk.Msg) ([]byte, error) {
+	if c.ctx.GetFromAddress().Empty() {
+		return nil, errors.New("the from address is required to generate a tx")
+	}
+
+	c.syncMux.Lock()
+	defer c.syncMux.Unlock()
+
+	// Without a keyring the gas prices weren't synced with the node when the client was created.
+	if !c.canSign {
+		c.syncGasPrices()
+	}
+
+	_, txn, err := c.buildTx(c.ctx, c.txFactory, msgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	txJSON, err := c.ctx.TxConfig.TxJSONEncoder()(txn.GetTx())
+	if err != nil {
+		err = errors.Wrap(err, "failed to encode the tx to JSON")
+		return nil, err
+	}
+
+	return txJSON, nil
+}
+
+// TxError is returned when a tx is rejected by the node or fails in a block.
+type TxError struct {
+	Response *sdk.TxResponse
+}
+
+func (e *TxError) Error() string {
+	return fmt.Sprintf(
+		"tx %s failed with error %d (%s): %s",
+		e.Response.TxHash,
+		e.Response.Code,
+		e.Response.Codespace,
+		e.Response.RawLog,
+	)
+}
+
+// MsgIndex returns the index of the msg that made the tx fail, if the log of the tx has it.
+func (e *TxError) MsgIndex() (int, bool) {
+	return parseMsgIndex(e.Response.RawLog)
+}
+
+// parseMsgIndex returns the index of the failing msg in the log of a failed tx.
+func parseMsgIndex(log string) (int, bool) {
+	matches := msgIndexRegex.FindStringSubmatch(log)
+	if matches == nil {
+		return 0, false
+	}
+
+	index, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, false
+	}
+
+	return index, true
+}
+
 // prepareFactory ensures the account defined by ctx.GetFromAddress() exists and
 // if the account number and/or the account sequence number are zero (not set),
 // they will be queried for and set on the provided Factory. A new Factory with
@@ -354,24 +536,27 @@ func (c *cosmosClient) prepareFactory(clientCtx client.Context, txf tx.Factory)
 
 // QueueBroadcastMsg enqueues a list of messages. Messages will added to the queue
 // and grouped into Txns in chunks. Use this method to mass broadcast Txns with efficiency.
-func (c *cosmosClient) QueueBroadcastMsg(msgs ...sdk.Msg) error {
+// The returned handle resolves once the messages are included in a block, or when one of them fails.
+func (c *cosmosClient) QueueBroadcastMsg(msgs ...sdk.Msg) (*BroadcastHandle, error) {
 	if !c.canSign {
-		return ErrReadOnly
+		return nil, ErrReadOnly
 	} else if atomic.LoadInt64(&c.closed) == 1 {
-		return ErrQueueClosed
+		return nil, ErrQueueClosed
 	}
 
+	handle := newBroadcastHandle(len(msgs))
+
 	t := time.NewTimer(10 * time.Second)
 	for _, msg := range msgs {
 		select {
 		case <-t.C:
-			return ErrEnqueueTimeout
-		case c.msgC <- msg:
+			return nil, ErrEnqueueTimeout
+		case c.msgC <- queuedMsg{msg: msg, handle: handle}:
 		}
 	}
 	t.Stop()
 
-	return nil
+	return handle, nil
 }
 
 func (c *cosmosClient) Close() {
@@ -395,46 +580,28 @@ const (
 	msgCommitBatchTimeLimit = 500 * time.Millisecond
 )
 
+// queuedMsg is a msg of the queue, along with the handle it resolves.
+type queuedMsg struct {
+	msg    sdk.Msg
+	handle *BroadcastHandle
+}
+
 func (c *cosmosClient) runBatchBroadcast() {
 	expirationTimer := time.NewTimer(msgCommitBatchTimeLimit)
-	msgBatch := make([]sdk.Msg, 0, msgCommitBatchSizeLimit)
+	msgBatch := make([]queuedMsg, 0, msgCommitBatchSizeLimit)
 
-	submitBatch := func(toSubmit []sdk.Msg) {
-		c.syncMux.Lock()
-		defer c.syncMux.Unlock()
-
-		c.txFactory = c.txFactory.WithSequence(c.accSeq)
-		c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-		c.logger.Debug().Uint64("nonce", c.accSeq).Msg("broadcastTx with nonce")
-		res, err := c.broadcastTx(c.ctx, c.txFactory, true, toSubmit...)
+	submitBatch := func(toSubmit []queuedMsg) {
+		res, err := c.broadcastQueuedMsgs(toSubmit)
 		if err != nil {
-			if strings.Contains(err.Error(), "account sequence mismatch") {
-				c.syncNonce()
-				c.txFactory = c.txFactory.WithSequence(c.accSeq)
-				c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-				c.logger.Debug().Uint64("nonce", c.accSeq).Msg("retrying broadcastTx with nonce")
-				res, err = c.broadcastTx(c.ctx, c.txFactory, true, toSubmit...)
-			}
-			if err != nil {
-				resJSON, _ := json.MarshalIndent(res, "", "\t")
-				c.logger.Err(err).
-					Int("size", len(toSubmit)).
-					RawJSON("tx_response", resJSON).
-					Msg("failed to (sync) broadcast batch tx")
-				return
-			}
-		}
-
-		if res.Code != 0 {
-			err = errors.Errorf("error %d (%s): %s", res.Code, res.Codespace, res.RawLog)
-			c.logger.Err(err).Str("tx_hash", res.TxHash).
-				Msg("failed to (sync) broadcast tx batch error code != 0")
-		} else {
-			c.logger.Debug().Str("tx_hash", res.TxHash).Msg("batch tx committed successfully")
+			resJSON, _ := json.MarshalIndent(res, "", "\t")
+			c.logger.Err(err).
+				Int("size", len(toSubmit)).
+				RawJSON("tx_response", resJSON).
+				Msg("failed to (sync) broadcast batch tx")
+			return
 		}
 
-		c.accSeq++
-		c.logger.Debug().Uint64("nonce", c.accSeq).Msg("nonce incremented")
+		c.logger.Debug().Str("tx_hash", res.TxHash).Msg("batch tx committed successfully")
 	}
 
 	for {
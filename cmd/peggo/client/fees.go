@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/node"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/pkg/errors"
+)
+
+// nodeConfigTimeout is the timeout of the queries of the node's minimum gas prices.
+const nodeConfigTimeout = 10 * time.Second
+
+// broadcastTxWithFeeRetries broadcasts the msgs with the tx factory of the client. When the fees are too low, it
+// retries with higher gas prices, which are kept for the next txs until one succeeds; when the tx runs out of gas, it
+// retries with a higher gas adjustment. It retries up to the fee retries of the options. Must be called with the sync
+// lock held.
+func (c *cosmosClient) broadcastTxWithFeeRetries(await bool, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+	txf := c.txFactory
+
+	for retry := uint(0); ; retry++ {
+		res, err := c.broadcastTx(c.ctx, txf, await, msgs...)
+		if err == nil {
+			c.resetGasPrices()
+		}
+
+		if retry >= c.opts.FeeRetries {
+			return res, err
+		}
+
+		switch {
+		case isTxError(res, err, sdkerrors.ErrInsufficientFee):
+			gasPrices, ok := c.bumpGasPrices()
+			if !ok {
+				c.logger.Warn().
+					Str("gas_prices", c.txFactory.GasPrices().String()).
+					Msg("insufficient fees, but the gas prices are already at the max gas prices")
+				return res, err
+			}
+
+			c.logger.Warn().
+				Str("gas_prices", gasPrices.String()).
+				Uint("retry", retry+1).
+				Msg("insufficient fees, retrying with higher gas prices")
+
+			c.txFactory = c.txFactory.WithGasPrices(gasPrices.String())
+			c.gasPricesBumped = true
+			txf = txf.WithGasPrices(gasPrices.String())
+
+		case isTxError(res, err, sdkerrors.ErrOutOfGas):
+			// A tx that ran out of gas in a block still used its sequence.
+			if res != nil && res.Height > 0 {
+				c.accSeq++
+				txf = txf.WithSequence(c.accSeq)
+			}
+
+			gasAdjustment := txf.GasAdjustment() * (1 + float64(c.opts.GasPriceBump)/100)
+
+			c.logger.Warn().
+				Float64("gas_adjustment", gasAdjustment).
+				Uint("retry", retry+1).
+				Msg("out of gas, retrying with a higher gas adjustment")
+
+			txf = txf.WithGasAdjustment(gasAdjustment)
+
+		default:
+			return res, err
+		}
+	}
+}
+
+// bumpGasPrices returns the gas prices of the client bumped by the gas price bump, at least the node's minimum gas
+// prices and at most the max gas prices. It returns false if the gas prices can't be raised.
+func (c *cosmosClient) bumpGasPrices() (sdk.DecCoins, bool) {
+	current := c.txFactory.GasPrices()
+	bumped := bumpGasPrices(current, c.opts.GasPriceBump)
+
+	minGasPrices, err := c.queryMinGasPrices()
+	if err != nil {
+		c.logger.Debug().Err(err).Msg("failed to query the minimum gas prices of the node")
+	} else {
+		bumped = raiseGasPrices(bumped, minGasPrices)
+	}
+
+	bumped = capGasPrices(bumped, c.opts.MaxGasPrices)
+
+	return bumped, gasPricesRaised(current, bumped)
+}
+
+// syncGasPrices raises the gas prices of the client to the node's minimum gas prices, if they're lower.
+func (c *cosmosClient) syncGasPrices() {
+	minGasPrices, err := c.queryMinGasPrices()
+	if err != nil {
+		c.logger.Debug().Err(err).Msg("failed to query the minimum gas prices of the node")
+		return
+	}
+
+	current := c.txFactory.GasPrices()
+	gasPrices := capGasPrices(raiseGasPrices(current, minGasPrices), c.opts.MaxGasPrices)
+
+	if gasPricesRaised(current, gasPrices) {
+		c.logger.Info().
+			Str("gas_prices", gasPrices.String()).
+			Str("node_min_gas_prices", minGasPrices.String()).
+			Msg("raised gas prices to the minimum gas prices of the node")
+
+		c.txFactory = c.txFactory.WithGasPrices(gasPrices.String())
+	}
+}
+
+// resetGasPrices sets the gas prices of the client, raised after insufficient fee errors, back to the configured gas
+// prices, raised to the node's minimum gas prices. Otherwise the gas prices would only ever go up. Must be called with
+// the sync lock held.
+func (c *cosmosClient) resetGasPrices() {
+	if !c.gasPricesBumped {
+		return
+	}
+
+	c.gasPricesBumped = false
+	bumped := c.txFactory.GasPrices()
+
+	c.txFactory = c.txFactory.WithGasPrices(c.opts.GasPrices)
+	c.syncGasPrices()
+
+	c.logger.Info().
+		Str("bumped_gas_prices", bumped.String()).
+		Str("gas_prices", c.txFactory.GasPrices().String()).
+		Msg("reset gas prices after a successful broadcast")
+}
+
+// queryMinGasPrices returns the minimum gas prices set in the config of the node.
+func (c *cosmosClient) queryMinGasPrices() (sdk.DecCoins, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), nodeConfigTimeout)
+	defer cancel()
+
+	res, err := node.NewServiceClient(c.conn).Config(ctx, &node.ConfigRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query node config")
+	}
+
+	minGasPrices, err := sdk.ParseDecCoins(res.MinimumGasPrice)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse minimum gas prices %s", res.MinimumGasPrice)
+	}
+
+	return minGasPrices, nil
+}
+
+// isTxError returns true if the tx failed with the SDK error, either in its response or in the broadcast error.
+func isTxError(res *sdk.TxResponse, err error, sdkErr *sdkerrors.Error) bool {
+	if res != nil && res.Code != 0 {
+		return res.Codespace == sdkErr.Codespace() && res.Code == sdkErr.ABCICode()
+	}
+
+	return err != nil && (sdkErr.Is(err) || strings.Contains(err.Error(), sdkErr.Error()))
+}
+
+// bumpGasPrices returns the gas prices increased by the percentage.
+func bumpGasPrices(gasPrices sdk.DecCoins, percent uint64) sdk.DecCoins {
+	factor := sdk.NewDec(int64(100 + percent)).QuoInt64(100)
+
+	bumped := make(sdk.DecCoins, 0, len(gasPrices))
+	for _, gasPrice := range gasPrices {
+		bumped = append(bumped, sdk.NewDecCoinFromDec(gasPrice.Denom, gasPrice.Amount.Mul(factor)))
+	}
+
+	return bumped.Sort()
+}
+
+// raiseGasPrices returns the gas prices raised to the minimum gas prices of their denoms. If there are no gas
+// prices, the minimum gas prices are returned.
+func raiseGasPrices(gasPrices, minGasPrices sdk.DecCoins) sdk.DecCoins {
+	if gasPrices.Empty() {
+		return minGasPrices
+	}
+
+	raised := make(sdk.DecCoins, 0, len(gasPrices))
+	for _, gasPrice := range gasPrices {
+		amount := sdk.MaxDec(gasPrice.Amount, minGasPrices.AmountOf(gasPrice.Denom))
+		raised = append(raised, sdk.NewDecCoinFromDec(gasPrice.Denom, amount))
+	}
+
+	return raised.Sort()
+}
+
+// capGasPrices returns the gas prices lowered to the max gas prices of their denoms, if any.
+func capGasPrices(gasPrices, maxGasPrices sdk.DecCoins) sdk.DecCoins {
+	capped := make(sdk.DecCoins, 0, len(gasPrices))
+	for _, gasPrice := range gasPrices {
+		amount := gasPrice.Amount
+
+		if maxGasPrice := maxGasPrices.AmountOf(gasPrice.Denom); maxGasPrice.IsPositive() {
+			amount = sdk.MinDec(amount, maxGasPrice)
+		}
+
+		capped = append(capped, sdk.NewDecCoinFromDec(gasPrice.Denom, amount))
+	}
+
+	return capped.Sort()
+}
+
+// gasPricesRaised returns true if any of the gas prices is higher than before.
+func gasPricesRaised(before, after sdk.DecCoins) bool {
+	for _, gasPrice := range after {
+		if gasPrice.Amount.GT(before.AmountOf(gasPrice.Denom)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func mustParseDecCoins(t *testing.T, coins string) sdk.DecCoins {
+	decCoins, err := sdk.ParseDecCoins(coins)
+	assert.Nil(t, err)
+
+	return decCoins
+}
+
+func TestBumpGasPrices(t *testing.T) {
+	testCases := []struct {
+		name      string
+		gasPrices string
+		percent   uint64
+		expected  string
+	}{
+		{"single denom", "0.1uumee", 20, "0.12uumee"},
+		{"multiple denoms", "0.1uumee,2uatom", 10, "2.2uatom,0.11uumee"},
+		{"no gas prices", "", 20, ""},
+		{"zero gas price", "0uumee", 20, "0uumee"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			bumped := bumpGasPrices(mustParseDecCoins(t, tc.gasPrices), tc.percent)
+			assert.Equal(t, mustParseDecCoins(t, tc.expected).String(), bumped.String())
+		})
+	}
+}
+
+func TestRaiseGasPrices(t *testing.T) {
+	testCases := []struct {
+		name         string
+		gasPrices    string
+		minGasPrices string
+		expected     string
+	}{
+		{"below the minimum", "0.01uumee", "0.05uumee", "0.05uumee"},
+		{"above the minimum", "0.1uumee", "0.05uumee", "0.1uumee"},
+		{"no minimum for the denom", "0.1uumee", "0.05uatom", "0.1uumee"},
+		{"some denoms below the minimum", "0.01uumee,3uatom", "0.05uumee,1uatom", "3uatom,0.05uumee"},
+		{"no gas prices", "", "0.05uumee", "0.05uumee"},
+		{"no minimum", "0.1uumee", "", "0.1uumee"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			raised := raiseGasPrices(mustParseDecCoins(t, tc.gasPrices), mustParseDecCoins(t, tc.minGasPrices))
+			assert.Equal(t, mustParseDecCoins(t, tc.expected).String(), raised.String())
+		})
+	}
+}
+
+func TestCapGasPrices(t *testing.T) {
+	testCases := []struct {
+		name         string
+		gasPrices    string
+		maxGasPrices string
+		expected     string
+	}{
+		{"above the max", "0.2uumee", "0.1uumee", "0.1uumee"},
+		{"below the max", "0.05uumee", "0.1uumee", "0.05uumee"},
+		{"no max for the denom", "0.2uumee", "1uatom", "0.2uumee"},
+		{"no max", "0.2uumee", "", "0.2uumee"},
+		{"some denoms above the max", "0.2uumee,3uatom", "0.1uumee,5uatom", "3uatom,0.1uumee"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			capped := capGasPrices(mustParseDecCoins(t, tc.gasPrices), mustParseDecCoins(t, tc.maxGasPrices))
+			assert.Equal(t, mustParseDecCoins(t, tc.expected).String(), capped.String())
+		})
+	}
+}
+
+func TestGasPricesRaised(t *testing.T) {
+	testCases := []struct {
+		name   string
+		before string
+		after  string
+		raised bool
+	}{
+		{"raised", "0.1uumee", "0.12uumee", true},
+		{"same", "0.1uumee", "0.1uumee", false},
+		{"lowered", "0.1uumee", "0.05uumee", false},
+		{"new denom", "0.1uumee", "0.1uumee,1uatom", true},
+		{"one denom raised", "0.1uumee,2uatom", "0.12uumee,1uatom", true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			raised := gasPricesRaised(mustParseDecCoins(t, tc.before), mustParseDecCoins(t, tc.after))
+			assert.Equal(t, tc.raised, raised)
+		})
+	}
+}
+
+func TestIsTxError(t *testing.T) {
+	insufficientFee := &sdk.TxResponse{
+		Codespace: sdkerrors.ErrInsufficientFee.Codespace(),
+		Code:      sdkerrors.ErrInsufficientFee.ABCICode(),
+		RawLog:    "insufficient fees; got: 100uumee required: 200uumee: insufficient fee",
+	}
+
+	testCases := []struct {
+		name     string
+		res      *sdk.TxResponse
+		err      error
+		expected bool
+	}{
+		{
+			name:     "response code",
+			res:      insufficientFee,
+			err:      &TxError{Response: insufficientFee},
+			expected: true,
+		},
+		{
+			name: "other response code",
+			res: &sdk.TxResponse{
+				Codespace: sdkerrors.ErrOutOfGas.Codespace(),
+				Code:      sdkerrors.ErrOutOfGas.ABCICode(),
+				RawLog:    "out of gas: insufficient fee",
+			},
+			err: errors.New("out of gas"),
+		},
+		{
+			name: "same code in another codespace",
+			res: &sdk.TxResponse{
+				Codespace: "gravity",
+				Code:      sdkerrors.ErrInsufficientFee.ABCICode(),
+			},
+			err: errors.New("gravity error"),
+		},
+		{
+			name:     "wrapped error",
+			err:      errors.Wrap(sdkerrors.Wrap(sdkerrors.ErrInsufficientFee, "got 100uumee"), "failed to broadcast"),
+			expected: true,
+		},
+		{
+			name:     "simulation error message",
+			err:      errors.New("failed to CalculateGas: rpc error: code = Unknown desc = got 100uumee: insufficient fee"),
+			expected: true,
+		},
+		{
+			name: "other error",
+			err:  errors.New("connection refused"),
+		},
+		{
+			name:     "successful response",
+			res:      &sdk.TxResponse{Height: 10},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isTxError(tc.res, tc.err, sdkerrors.ErrInsufficientFee))
+		})
+	}
+}
+
+func TestResetGasPrices(t *testing.T) {
+	// Nothing listens on the port, so the node's minimum gas prices can't be queried.
+	conn, err := grpc.Dial("localhost:1", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	newClient := func(bumped bool) *cosmosClient {
+		return &cosmosClient{
+			logger:          zerolog.Nop(),
+			opts:            &cosmosClientOptions{GasPrices: "0.05uumee"},
+			conn:            conn,
+			txFactory:       tx.Factory{}.WithGasPrices("0.1uumee"),
+			gasPricesBumped: bumped,
+		}
+	}
+
+	t.Run("bumped", func(t *testing.T) {
+		c := newClient(true)
+		c.resetGasPrices()
+
+		assert.Equal(t, mustParseDecCoins(t, "0.05uumee"), c.txFactory.GasPrices())
+		assert.False(t, c.gasPricesBumped)
+	})
+
+	t.Run("not bumped", func(t *testing.T) {
+		c := newClient(false)
+		c.resetGasPrices()
+
+		assert.Equal(t, mustParseDecCoins(t, "0.1uumee"), c.txFactory.GasPrices())
+	})
+}
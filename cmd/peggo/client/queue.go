@@ -0,0 +1,339 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+const (
+	// queueRetryBackoff is the delay before retrying a queued batch, multiplied by the number of the retry.
+	queueRetryBackoff = time.Second
+
+	// txQueryTimeout is the timeout of the queries of timed out txs.
+	txQueryTimeout = 10 * time.Second
+)
+
+// BroadcastHandle is returned by QueueBroadcastMsg to follow the queued msgs. It resolves when all of them have been
+// included in a block, or when any of them fails, after the queue gave up retrying it.
+type BroadcastHandle struct {
+	mtx     sync.Mutex
+	pending int
+	done    chan struct{}
+
+	res *sdk.TxResponse
+	err error
+}
+
+func newBroadcastHandle(msgs int) *BroadcastHandle {
+	return &BroadcastHandle{
+		pending: msgs,
+		done:    make(chan struct{}),
+	}
+}
+
+// Done returns a channel closed when the handle resolves.
+func (h *BroadcastHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Result returns the response of the last tx the msgs were included in, or the error of the first msg that failed.
+// It must be called after the handle resolved.
+func (h *BroadcastHandle) Result() (*sdk.TxResponse, error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	return h.res, h.err
+}
+
+// Wait blocks until the handle resolves or the context is done, and returns its result.
+func (h *BroadcastHandle) Wait(ctx context.Context) (*sdk.TxResponse, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-h.done:
+		return h.Result()
+	}
+}
+
+// resolveMsg sets the result of one of the msgs of the handle. The handle resolves with the first error, or once all
+// the msgs succeeded.
+func (h *BroadcastHandle) resolveMsg(res *sdk.TxResponse, err error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if h.pending == 0 {
+		return
+	}
+
+	h.res = res
+	h.err = err
+	h.pending--
+
+	if err != nil {
+		h.pending = 0
+	}
+
+	if h.pending == 0 {
+		close(h.done)
+	}
+}
+
+// queueBroadcaster broadcasts the batches of queued msgs. It's implemented by the cosmos client.
+type queueBroadcaster interface {
+	// broadcastBatch broadcasts the msgs of the batch in a single tx and waits until it's included in a block. If the
+	// tx times out, the response holds its hash. With resync, a tx rejected because of an out of date sequence is
+	// retried once with the sequence synced with the chain.
+	broadcastBatch(batch []queuedMsg, resync bool) (*sdk.TxResponse, error)
+
+	// includedTx returns the response of a tx included in a block, or nil if it wasn't included.
+	includedTx(txHash string) (*sdk.TxResponse, error)
+
+	// sequence returns the account sequence the next tx is sent with.
+	sequence() uint64
+
+	// syncSequence syncs the account sequence with the chain.
+	syncSequence()
+}
+
+// msgQueue broadcasts the batches of queued msgs and resolves their handles.
+type msgQueue struct {
+	logger       zerolog.Logger
+	broadcaster  queueBroadcaster
+	retries      uint
+	retryBackoff time.Duration
+}
+
+// broadcastQueuedMsgs broadcasts a batch of queued msgs in a single tx and resolves their handles.
+func (c *cosmosClient) broadcastQueuedMsgs(batch []queuedMsg) (*sdk.TxResponse, error) {
+	q := &msgQueue{
+		logger:       c.logger,
+		broadcaster:  c,
+		retries:      c.opts.QueueRetries,
+		retryBackoff: queueRetryBackoff,
+	}
+
+	return q.broadcast(batch)
+}
+
+// broadcast broadcasts a batch of queued msgs in a single tx and resolves their handles. A msg making the tx fail is
+// resolved with the error and the other msgs are resubmitted without it; a batch failing because of a transient error
+// is retried up to the queue retries.
+//
+// A tx that timed out may still be in the mempool and be included later, so before retrying it the account sequence
+// is synced and the timed out txs are looked up: if one of them was included, the batch resolves with it. Otherwise
+// the msgs are sent again with the sequence of the timed out tx, so at most one of the txs can be included. If the
+// sequence was used but no timed out tx is found, or they can't be looked up after retrying transient lookup errors
+// up to the queue retries, the batch resolves with an error rather than risking to submit the msgs twice.
+func (q *msgQueue) broadcast(batch []queuedMsg) (*sdk.TxResponse, error) {
+	var (
+		timedOutTxs      []string
+		timedOutSequence uint64
+	)
+
+	for retry := uint(0); ; {
+		sequence := q.broadcaster.sequence()
+
+		res, err := q.broadcaster.broadcastBatch(batch, len(timedOutTxs) == 0)
+		if errors.Is(err, ErrTimedOut) && res != nil {
+			if len(timedOutTxs) == 0 {
+				timedOutSequence = sequence
+			}
+
+			timedOutTxs = append(timedOutTxs, res.TxHash)
+		}
+
+		if err != nil && len(timedOutTxs) > 0 {
+			included, lookupErr := q.findIncludedTxWithRetries(timedOutTxs, timedOutSequence)
+			if lookupErr != nil {
+				q.resolveBatch(batch, res, lookupErr)
+				return res, lookupErr
+			}
+
+			if included != nil {
+				timedOutTxs = nil
+
+				res, err = included, nil
+				if included.Code != 0 {
+					err = &TxError{Response: included}
+				}
+			}
+		}
+
+		if err == nil {
+			q.resolveBatch(batch, res, nil)
+			return res, nil
+		}
+
+		if index, ok := FailedMsgIndex(err); ok && index < len(batch) && len(batch) > 1 {
+			q.logger.Warn().
+				Err(err).
+				Int("msg_index", index).
+				Str("msg_type", sdk.MsgTypeURL(batch[index].msg)).
+				Msg("queued msg failed; resubmitting the other msgs of the batch")
+
+			batch[index].handle.resolveMsg(res, err)
+			batch = append(batch[:index:index], batch[index+1:]...)
+			continue
+		}
+
+		if retry < q.retries && isTransientBroadcastError(res, err) {
+			retry++
+
+			q.logger.Warn().
+				Err(err).
+				Int("size", len(batch)).
+				Uint("retry", retry).
+				Msg("queued msgs failed with a transient error; retrying")
+
+			time.Sleep(time.Duration(retry) * q.retryBackoff)
+			continue
+		}
+
+		q.resolveBatch(batch, res, err)
+		return res, err
+	}
+}
+
+// findIncludedTxWithRetries looks up the timed out txs like findIncludedTx, retrying transient lookup errors, like an
+// unavailable node, up to the queue retries.
+func (q *msgQueue) findIncludedTxWithRetries(timedOutTxs []string, timedOutSequence uint64) (*sdk.TxResponse, error) {
+	for retry := uint(0); ; {
+		res, err := q.findIncludedTx(timedOutTxs, timedOutSequence)
+		if err == nil || retry >= q.retries || !isTransientBroadcastError(nil, err) {
+			return res, err
+		}
+
+		retry++
+
+		q.logger.Warn().
+			Err(err).
+			Strs("tx_hashes", timedOutTxs).
+			Uint("retry", retry).
+			Msg("failed to look up timed out txs with a transient error; retrying")
+
+		time.Sleep(time.Duration(retry) * q.retryBackoff)
+	}
+}
+
+// findIncludedTx syncs the account sequence and returns the timed out tx included in a block, if any. It fails if
+// the sequence of the timed out txs was used by a tx that can't be found.
+func (q *msgQueue) findIncludedTx(timedOutTxs []string, timedOutSequence uint64) (*sdk.TxResponse, error) {
+	q.broadcaster.syncSequence()
+
+	for _, txHash := range timedOutTxs {
+		res, err := q.broadcaster.includedTx(txHash)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to look up timed out tx %s", txHash)
+		}
+
+		if res != nil {
+			return res, nil
+		}
+	}
+
+	if q.broadcaster.sequence() > timedOutSequence {
+		return nil, errors.Errorf(
+			"sequence %d of timed out txs %s was used, but none of them was found",
+			timedOutSequence,
+			strings.Join(timedOutTxs, ", "),
+		)
+	}
+
+	return nil, nil
+}
+
+// resolveBatch resolves the handles of the msgs of the batch.
+func (q *msgQueue) resolveBatch(batch []queuedMsg, res *sdk.TxResponse, err error) {
+	for _, queued := range batch {
+		queued.handle.resolveMsg(res, err)
+	}
+}
+
+// broadcastBatch broadcasts the msgs of the batch in a single tx and waits until it's included in a block.
+func (c *cosmosClient) broadcastBatch(batch []queuedMsg, resync bool) (*sdk.TxResponse, error) {
+	msgs := make([]sdk.Msg, 0, len(batch))
+	for _, queued := range batch {
+		msgs = append(msgs, queued.msg)
+	}
+
+	c.syncMux.Lock()
+	defer c.syncMux.Unlock()
+
+	return c.broadcastMsgs(true, resync, msgs...)
+}
+
+// includedTx returns the response of a tx included in a block, or nil if the node doesn't know about it.
+func (c *cosmosClient) includedTx(txHash string) (*sdk.TxResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), txQueryTimeout)
+	defer cancel()
+
+	res, err := txtypes.NewServiceClient(c.conn).GetTx(ctx, &txtypes.GetTxRequest{Hash: txHash})
+	if grpcstatus.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to GetTx")
+	}
+
+	if res.TxResponse == nil || res.TxResponse.Height == 0 {
+		return nil, nil
+	}
+
+	return res.TxResponse, nil
+}
+
+func (c *cosmosClient) sequence() uint64 {
+	c.syncMux.Lock()
+	defer c.syncMux.Unlock()
+
+	return c.accSeq
+}
+
+func (c *cosmosClient) syncSequence() {
+	c.syncMux.Lock()
+	defer c.syncMux.Unlock()
+
+	c.syncNonce()
+}
+
+// FailedMsgIndex returns the index of the msg that made a tx fail, either in a block or during its simulation.
+func FailedMsgIndex(err error) (int, bool) {
+	var txErr *TxError
+	if errors.As(err, &txErr) {
+		return txErr.MsgIndex()
+	}
+
+	return parseMsgIndex(err.Error())
+}
+
+// isTransientBroadcastError returns true if the tx failed for a reason unrelated to its msgs, like an unavailable
+// node, a full mempool, an out of date sequence or a timed out tx still in the mempool, so broadcasting it again may
+// succeed.
+func isTransientBroadcastError(res *sdk.TxResponse, err error) bool {
+	switch {
+	case errors.Is(err, ErrTimedOut),
+		isTxError(res, err, sdkerrors.ErrMempoolIsFull),
+		isTxError(res, err, sdkerrors.ErrWrongSequence),
+		isTxError(res, err, sdkerrors.ErrTxInMempoolCache):
+		return true
+	}
+
+	if status, ok := grpcstatus.FromError(errors.Cause(err)); ok {
+		switch status.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,498 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// broadcastResult is the result of a broadcast of the fake broadcaster.
+type broadcastResult struct {
+	res *sdk.TxResponse
+	err error
+}
+
+// fakeBroadcaster returns the scripted results of the broadcasts and records the msgs of each one.
+type fakeBroadcaster struct {
+	results []broadcastResult
+
+	// seq is the sequence of the next tx, synced to chainSequence, the account sequence on the chain.
+	seq           uint64
+	chainSequence uint64
+
+	includedTxs map[string]*sdk.TxResponse
+	// lookupErrs are returned by the first lookups of included txs, and lookupErr by all the other ones.
+	lookupErrs []error
+	lookupErr  error
+	lookups    int
+
+	broadcasts [][]sdk.Msg
+	resyncs    []bool
+	syncs      int
+}
+
+func (b *fakeBroadcaster) broadcastBatch(batch []queuedMsg, resync bool) (*sdk.TxResponse, error) {
+	msgs := make([]sdk.Msg, 0, len(batch))
+	for _, queued := range batch {
+		msgs = append(msgs, queued.msg)
+	}
+
+	b.broadcasts = append(b.broadcasts, msgs)
+	b.resyncs = append(b.resyncs, resync)
+
+	result := b.results[0]
+	b.results = b.results[1:]
+
+	if result.err == nil {
+		b.seq++
+	}
+
+	return result.res, result.err
+}
+
+func (b *fakeBroadcaster) includedTx(txHash string) (*sdk.TxResponse, error) {
+	b.lookups++
+
+	if len(b.lookupErrs) > 0 {
+		err := b.lookupErrs[0]
+		b.lookupErrs = b.lookupErrs[1:]
+		return nil, err
+	}
+
+	if b.lookupErr != nil {
+		return nil, b.lookupErr
+	}
+
+	return b.includedTxs[txHash], nil
+}
+
+func (b *fakeBroadcaster) sequence() uint64 {
+	return b.seq
+}
+
+func (b *fakeBroadcaster) syncSequence() {
+	b.syncs++
+	b.seq = b.chainSequence
+}
+
+func newTestQueue(broadcaster *fakeBroadcaster) *msgQueue {
+	return &msgQueue{logger: zerolog.Nop(), broadcaster: broadcaster, retries: 2}
+}
+
+// newTestBatch returns a batch of msgs resolving the same handle.
+func newTestBatch(size int) ([]queuedMsg, *BroadcastHandle) {
+	handle := newBroadcastHandle(size)
+
+	batch := make([]queuedMsg, 0, size)
+	for i := 0; i < size; i++ {
+		batch = append(batch, queuedMsg{
+			msg:    &banktypes.MsgSend{FromAddress: string(rune('a' + i))},
+			handle: handle,
+		})
+	}
+
+	return batch, handle
+}
+
+func msgsOf(batch []queuedMsg) []sdk.Msg {
+	msgs := make([]sdk.Msg, 0, len(batch))
+	for _, queued := range batch {
+		msgs = append(msgs, queued.msg)
+	}
+
+	return msgs
+}
+
+// txFailed returns the result of a tx rejected by the node or failed in a block.
+func txFailed(res *sdk.TxResponse) broadcastResult {
+	return broadcastResult{res: res, err: &TxError{Response: res}}
+}
+
+func timedOut(txHash string) broadcastResult {
+	return broadcastResult{
+		res: &sdk.TxResponse{TxHash: txHash},
+		err: errors.Wrapf(ErrTimedOut, "%s", txHash),
+	}
+}
+
+func TestBroadcastHandle(t *testing.T) {
+	res := &sdk.TxResponse{TxHash: "AA", Height: 10}
+	txErr := &TxError{Response: &sdk.TxResponse{TxHash: "BB", Code: 1}}
+
+	t.Run("resolves once all msgs succeed", func(t *testing.T) {
+		handle := newBroadcastHandle(2)
+
+		handle.resolveMsg(res, nil)
+		select {
+		case <-handle.Done():
+			t.Fatal("handle resolved before all msgs succeeded")
+		default:
+		}
+
+		handle.resolveMsg(res, nil)
+		<-handle.Done()
+
+		gotRes, err := handle.Result()
+		assert.Nil(t, err)
+		assert.Equal(t, res, gotRes)
+	})
+
+	t.Run("resolves with the first error", func(t *testing.T) {
+		handle := newBroadcastHandle(3)
+
+		handle.resolveMsg(nil, txErr)
+		handle.resolveMsg(res, nil)
+
+		gotRes, err := handle.Wait(context.Background())
+		assert.Equal(t, txErr, err)
+		assert.Nil(t, gotRes)
+	})
+
+	t.Run("wait is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := newBroadcastHandle(1).Wait(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestMsgQueueBroadcast(t *testing.T) {
+	res := &sdk.TxResponse{TxHash: "AA", Height: 10}
+
+	t.Run("success", func(t *testing.T) {
+		broadcaster := &fakeBroadcaster{results: []broadcastResult{{res: res}}}
+		batch, handle := newTestBatch(2)
+
+		gotRes, err := newTestQueue(broadcaster).broadcast(batch)
+		assert.Nil(t, err)
+		assert.Equal(t, res, gotRes)
+		assert.Equal(t, [][]sdk.Msg{msgsOf(batch)}, broadcaster.broadcasts)
+
+		gotRes, err = handle.Wait(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, res, gotRes)
+	})
+
+	t.Run("failing msg is removed", func(t *testing.T) {
+		failed := txFailed(&sdk.TxResponse{
+			TxHash: "BB",
+			Code:   sdkerrors.ErrInvalidRequest.ABCICode(),
+			RawLog: "failed to execute message; message index: 1: invalid request",
+		})
+		broadcaster := &fakeBroadcaster{results: []broadcastResult{failed, {res: res}}}
+		batch, _ := newTestBatch(3)
+
+		failedHandle := newBroadcastHandle(1)
+		batch[1].handle = failedHandle
+		msgs := msgsOf(batch)
+
+		gotRes, err := newTestQueue(broadcaster).broadcast(batch)
+		assert.Nil(t, err)
+		assert.Equal(t, res, gotRes)
+		assert.Equal(t, [][]sdk.Msg{msgs, {msgs[0], msgs[2]}}, broadcaster.broadcasts)
+
+		_, err = failedHandle.Wait(context.Background())
+		assert.Equal(t, failed.err, err)
+	})
+
+	t.Run("transient error is retried", func(t *testing.T) {
+		mempoolFull := txFailed(&sdk.TxResponse{
+			Codespace: sdkerrors.ErrMempoolIsFull.Codespace(),
+			Code:      sdkerrors.ErrMempoolIsFull.ABCICode(),
+		})
+		unavailable := grpcstatus.Error(codes.Unavailable, "connection refused")
+		broadcaster := &fakeBroadcaster{results: []broadcastResult{mempoolFull, {err: unavailable}, {res: res}}}
+		batch, handle := newTestBatch(1)
+
+		_, err := newTestQueue(broadcaster).broadcast(batch)
+		assert.Nil(t, err)
+		assert.Len(t, broadcaster.broadcasts, 3)
+
+		_, err = handle.Wait(context.Background())
+		assert.Nil(t, err)
+	})
+
+	t.Run("retries are exhausted", func(t *testing.T) {
+		unavailable := grpcstatus.Error(codes.Unavailable, "connection refused")
+		broadcaster := &fakeBroadcaster{
+			results: []broadcastResult{{err: unavailable}, {err: unavailable}, {err: unavailable}},
+		}
+		batch, handle := newTestBatch(2)
+
+		_, err := newTestQueue(broadcaster).broadcast(batch)
+		assert.Equal(t, unavailable, err)
+		assert.Len(t, broadcaster.broadcasts, 3)
+
+		_, err = handle.Wait(context.Background())
+		assert.Equal(t, unavailable, err)
+	})
+
+	t.Run("non transient error isn't retried", func(t *testing.T) {
+		unauthorized := txFailed(&sdk.TxResponse{
+			Codespace: sdkerrors.ErrUnauthorized.Codespace(),
+			Code:      sdkerrors.ErrUnauthorized.ABCICode(),
+		})
+		broadcaster := &fakeBroadcaster{results: []broadcastResult{unauthorized}}
+		batch, _ := newTestBatch(2)
+
+		_, err := newTestQueue(broadcaster).broadcast(batch)
+		assert.Equal(t, unauthorized.err, err)
+		assert.Len(t, broadcaster.broadcasts, 1)
+	})
+
+	t.Run("timed out tx was included", func(t *testing.T) {
+		included := &sdk.TxResponse{TxHash: "CC", Height: 11}
+		broadcaster := &fakeBroadcaster{
+			results:       []broadcastResult{timedOut("CC")},
+			seq:           5,
+			chainSequence: 6,
+			includedTxs:   map[string]*sdk.TxResponse{"CC": included},
+		}
+		batch, handle := newTestBatch(2)
+
+		gotRes, err := newTestQueue(broadcaster).broadcast(batch)
+		assert.Nil(t, err)
+		assert.Equal(t, included, gotRes)
+		assert.Len(t, broadcaster.broadcasts, 1)
+		assert.Equal(t, 1, broadcaster.syncs)
+
+		gotRes, err = handle.Wait(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, included, gotRes)
+	})
+
+	t.Run("timed out tx failed in a block", func(t *testing.T) {
+		included := &sdk.TxResponse{
+			TxHash: "CC",
+			Height: 11,
+			Code:   sdkerrors.ErrInvalidRequest.ABCICode(),
+			RawLog: "failed to execute message; message index: 0: invalid request",
+		}
+		broadcaster := &fakeBroadcaster{
+			results:       []broadcastResult{timedOut("CC"), {res: res}},
+			seq:           5,
+			chainSequence: 6,
+			includedTxs:   map[string]*sdk.TxResponse{"CC": included},
+		}
+		batch, _ := newTestBatch(2)
+		msgs := msgsOf(batch)
+
+		// The other msgs are resubmitted with the next sequence.
+		_, err := newTestQueue(broadcaster).broadcast(batch)
+		assert.Nil(t, err)
+		assert.Equal(t, [][]sdk.Msg{msgs, {msgs[1]}}, broadcaster.broadcasts)
+		assert.Equal(t, []bool{true, true}, broadcaster.resyncs)
+	})
+
+	t.Run("timed out tx is resent with the same sequence", func(t *testing.T) {
+		wrongSequence := txFailed(&sdk.TxResponse{
+			Codespace: sdkerrors.ErrWrongSequence.Codespace(),
+			Code:      sdkerrors.ErrWrongSequence.ABCICode(),
+			RawLog:    "account sequence mismatch, expected 6, got 5: incorrect account sequence",
+		})
+		broadcaster := &fakeBroadcaster{
+			results:       []broadcastResult{timedOut("CC"), wrongSequence, {res: res}},
+			seq:           5,
+			chainSequence: 5,
+		}
+		batch, handle := newTestBatch(2)
+
+		gotRes, err := newTestQueue(broadcaster).broadcast(batch)
+		assert.Nil(t, err)
+		assert.Equal(t, res, gotRes)
+		assert.Len(t, broadcaster.broadcasts, 3)
+
+		// The sequence isn't synced past the timed out tx while it may still be in the mempool.
+		assert.Equal(t, []bool{true, false, false}, broadcaster.resyncs)
+		assert.Equal(t, 2, broadcaster.syncs)
+
+		_, err = handle.Wait(context.Background())
+		assert.Nil(t, err)
+	})
+
+	t.Run("timed out tx is included after a retry", func(t *testing.T) {
+		included := &sdk.TxResponse{TxHash: "CC", Height: 12}
+		wrongSequence := txFailed(&sdk.TxResponse{
+			Codespace: sdkerrors.ErrWrongSequence.Codespace(),
+			Code:      sdkerrors.ErrWrongSequence.ABCICode(),
+		})
+		broadcaster := &fakeBroadcaster{
+			results:       []broadcastResult{timedOut("CC"), wrongSequence},
+			seq:           5,
+			chainSequence: 5,
+			includedTxs:   map[string]*sdk.TxResponse{},
+		}
+
+		batch, handle := newTestBatch(1)
+
+		// The tx is included between the first lookup and the retry.
+		q := newTestQueue(broadcaster)
+		q.broadcaster = &includeOnSync{fakeBroadcaster: broadcaster, syncsBeforeInclusion: 1, included: included}
+
+		gotRes, err := q.broadcast(batch)
+		assert.Nil(t, err)
+		assert.Equal(t, included, gotRes)
+		assert.Len(t, broadcaster.broadcasts, 2)
+
+		gotRes, err = handle.Wait(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, included, gotRes)
+	})
+
+	t.Run("timed out sequence used by an unknown tx", func(t *testing.T) {
+		broadcaster := &fakeBroadcaster{
+			results:       []broadcastResult{timedOut("CC")},
+			seq:           5,
+			chainSequence: 6,
+		}
+		batch, handle := newTestBatch(2)
+
+		_, err := newTestQueue(broadcaster).broadcast(batch)
+		assert.ErrorContains(t, err, "sequence 5 of timed out txs CC was used")
+		assert.Len(t, broadcaster.broadcasts, 1)
+
+		_, err = handle.Wait(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("timed out tx can't be looked up", func(t *testing.T) {
+		broadcaster := &fakeBroadcaster{
+			results:       []broadcastResult{timedOut("CC")},
+			seq:           5,
+			chainSequence: 5,
+			lookupErr:     grpcstatus.Error(codes.Unavailable, "connection refused"),
+		}
+		batch, _ := newTestBatch(2)
+
+		_, err := newTestQueue(broadcaster).broadcast(batch)
+		assert.ErrorContains(t, err, "failed to look up timed out tx CC")
+		assert.Len(t, broadcaster.broadcasts, 1)
+
+		// The transient lookup error is retried up to the queue retries.
+		assert.Equal(t, 3, broadcaster.lookups)
+	})
+
+	t.Run("timed out tx lookup is retried", func(t *testing.T) {
+		included := &sdk.TxResponse{TxHash: "CC", Height: 12}
+		broadcaster := &fakeBroadcaster{
+			results:       []broadcastResult{timedOut("CC")},
+			seq:           5,
+			chainSequence: 6,
+			includedTxs:   map[string]*sdk.TxResponse{"CC": included},
+			lookupErrs:    []error{grpcstatus.Error(codes.Unavailable, "connection refused")},
+		}
+		batch, handle := newTestBatch(2)
+
+		gotRes, err := newTestQueue(broadcaster).broadcast(batch)
+		assert.Nil(t, err)
+		assert.Equal(t, included, gotRes)
+		assert.Len(t, broadcaster.broadcasts, 1)
+		assert.Equal(t, 2, broadcaster.lookups)
+
+		gotRes, err = handle.Wait(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, included, gotRes)
+	})
+
+	t.Run("timed out tx lookup fails with a non transient error", func(t *testing.T) {
+		broadcaster := &fakeBroadcaster{
+			results:       []broadcastResult{timedOut("CC")},
+			seq:           5,
+			chainSequence: 5,
+			lookupErr:     grpcstatus.Error(codes.InvalidArgument, "invalid hash"),
+		}
+		batch, handle := newTestBatch(1)
+
+		_, err := newTestQueue(broadcaster).broadcast(batch)
+		assert.ErrorContains(t, err, "failed to look up timed out tx CC")
+		assert.Equal(t, 1, broadcaster.lookups)
+
+		_, err = handle.Wait(context.Background())
+		assert.Error(t, err)
+	})
+}
+
+// includeOnSync includes a tx in a block, and uses its sequence, once the sequence was synced a number of times.
+type includeOnSync struct {
+	*fakeBroadcaster
+	syncsBeforeInclusion int
+	included             *sdk.TxResponse
+}
+
+func (b *includeOnSync) syncSequence() {
+	if b.syncs == b.syncsBeforeInclusion {
+		b.includedTxs[b.included.TxHash] = b.included
+		b.chainSequence++
+	}
+
+	b.fakeBroadcaster.syncSequence()
+}
+
+func TestIsTransientBroadcastError(t *testing.T) {
+	testCases := []struct {
+		name      string
+		res       *sdk.TxResponse
+		err       error
+		transient bool
+	}{
+		{
+			name:      "timed out",
+			res:       &sdk.TxResponse{TxHash: "AA"},
+			err:       errors.Wrap(ErrTimedOut, "AA"),
+			transient: true,
+		},
+		{
+			name: "mempool is full",
+			res: &sdk.TxResponse{
+				Codespace: sdkerrors.ErrMempoolIsFull.Codespace(),
+				Code:      sdkerrors.ErrMempoolIsFull.ABCICode(),
+			},
+			err:       errors.New("mempool is full"),
+			transient: true,
+		},
+		{
+			name: "tx in mempool cache",
+			res: &sdk.TxResponse{
+				Codespace: sdkerrors.ErrTxInMempoolCache.Codespace(),
+				Code:      sdkerrors.ErrTxInMempoolCache.ABCICode(),
+			},
+			err:       errors.New("tx already in mempool"),
+			transient: true,
+		},
+		{
+			name:      "wrong sequence",
+			err:       sdkerrors.Wrap(sdkerrors.ErrWrongSequence, "account sequence mismatch"),
+			transient: true,
+		},
+		{
+			name:      "node unavailable",
+			err:       errors.Wrap(grpcstatus.Error(codes.Unavailable, "connection refused"), "failed to BroadcastTx"),
+			transient: true,
+		},
+		{
+			name: "msg error",
+			res: &sdk.TxResponse{
+				Codespace: sdkerrors.ErrInvalidRequest.Codespace(),
+				Code:      sdkerrors.ErrInvalidRequest.ABCICode(),
+			},
+			err: errors.New("invalid request"),
+		},
+		{
+			name: "simulation error",
+			err:  errors.Wrap(grpcstatus.Error(codes.Unknown, "invalid request"), "failed to CalculateGas"),
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.transient, isTransientBroadcastError(tc.res, tc.err), tc.name)
+	}
+}
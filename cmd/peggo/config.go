@@ -0,0 +1,273 @@
+package peggo
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/parsers/toml"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const redactedValue = "<redacted>"
+
+// secretFlags are the flags whose values are never printed.
+var secretFlags = []string{
+	flagCosmosPK,
+	flagCosmosFromPassphrase,
+	flagEthPK,
+	flagEthPassphrase,
+	flagEthBundleSignerPK,
+	flagEmergencyWebhook,
+}
+
+// envOnlyKeys are the string keys that have no flag, e.g. PEGGO_ETH_PK.
+var envOnlyKeys = []string{
+	flagEthPK,
+}
+
+// urlFlags are the flags whose values are endpoint URLs.
+var urlFlags = []string{
+	flagCosmosGRPC,
+	flagTendermintRPC,
+	flagEthRPC,
+	flagEthAlchemyWS,
+	flagEthPendingTxEndpoint,
+	flagEthBundleRelay,
+	flagCoinGeckoAPI,
+	flagEmergencyWebhook,
+}
+
+func getConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Configuration subcommands",
+	}
+
+	cmd.AddCommand(getConfigValidateCmd())
+
+	return cmd
+}
+
+func getConfigValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Args:  cobra.NoArgs,
+		Short: "Validate the configuration and print the effective config with secrets redacted",
+		Long: `Validate the configuration merged from the config file, the PEGGO_ environment
+variables and the flags. Every key must be the name of a flag of a Peggo command, its value
+must be of the type of the flag, and endpoint URLs must be valid. The effective config is
+printed with secrets redacted, as TOML in the text output.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(konfig)
+			if err != nil {
+				return err
+			}
+
+			if err := validateConfig(logger, konfig, configFlags(NewRootCmd())); err != nil {
+				return err
+			}
+
+			return printConfig(cmd.OutOrStdout(), konfig)
+		},
+	}
+
+	cmd.Flags().StringP(flagOutput, "o", outputText, "Output format (text|json|yaml)")
+
+	return cmd
+}
+
+// getConfigPath returns the path of the config file set with --config or
+// PEGGO_CONFIG, if any.
+func getConfigPath(cmd *cobra.Command) string {
+	if configPath, err := cmd.Flags().GetString(flagConfig); err == nil && len(configPath) > 0 {
+		return configPath
+	}
+
+	return os.Getenv("PEGGO_CONFIG")
+}
+
+// configFileParser returns the parser of a config file, by its extension.
+func configFileParser(configPath string) (koanf.Parser, error) {
+	switch ext := strings.ToLower(filepath.Ext(configPath)); ext {
+	case ".toml":
+		return toml.Parser(), nil
+
+	case ".yaml", ".yml":
+		return yaml.Parser(), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q; expected .toml, .yaml or .yml", ext)
+	}
+}
+
+// configFlags returns the flags of every command of the tree by name, plus
+// string flags for the keys without flags, which are the keys a config can set.
+func configFlags(root *cobra.Command) map[string]*pflag.Flag {
+	flags := map[string]*pflag.Flag{}
+
+	envOnlyFlags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	for _, key := range envOnlyKeys {
+		envOnlyFlags.String(key, "", "")
+		flags[key] = envOnlyFlags.Lookup(key)
+	}
+
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		cmd.InitDefaultHelpFlag()
+
+		for _, fs := range []*pflag.FlagSet{cmd.PersistentFlags(), cmd.Flags()} {
+			fs.VisitAll(func(f *pflag.Flag) {
+				if _, ok := flags[f.Name]; !ok {
+					flags[f.Name] = f
+				}
+			})
+		}
+
+		for _, subCmd := range cmd.Commands() {
+			walk(subCmd)
+		}
+	}
+	walk(root)
+
+	return flags
+}
+
+// validateConfig checks that every key of the config is a flag and that its
+// value can be parsed as the type of the flag, and parses the endpoint URLs.
+// The flags are used to parse the values, so they shouldn't be the ones of
+// the running command.
+func validateConfig(logger zerolog.Logger, konfig *koanf.Koanf, flags map[string]*pflag.Flag) error {
+	var problems []string
+
+	for _, key := range konfig.Keys() {
+		f, ok := flags[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: unknown key", key))
+			continue
+		}
+
+		if err := checkConfigValue(f, konfig.Get(key)); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", key, err))
+		}
+	}
+
+	for _, flag := range urlFlags {
+		if _, err := parseURL(logger, konfig, flag); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: invalid URL: %s", flag, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	return nil
+}
+
+// checkConfigValue sets the value on the flag, which fails if it can't be
+// parsed as the type of the flag. Lists are only accepted for string slices,
+// which can't be set with a single string in a config file or environment
+// variable.
+func checkConfigValue(f *pflag.Flag, value interface{}) error {
+	isSlice := f.Value.Type() == "stringSlice"
+
+	items, isList := value.([]interface{})
+	switch {
+	case isList && !isSlice:
+		return fmt.Errorf("expected a %s, got a list", f.Value.Type())
+
+	case !isList && isSlice:
+		return fmt.Errorf("expected a list, got %v", value)
+
+	case isList:
+		strs := make([]string, len(items))
+		for i, item := range items {
+			strs[i] = fmt.Sprint(item)
+		}
+
+		return f.Value.Set(strings.Join(strs, ","))
+
+	default:
+		return f.Value.Set(fmt.Sprint(value))
+	}
+}
+
+// redactConfig returns the flattened config, without the values of the secret
+// flags and the credentials of the endpoint URLs.
+func redactConfig(konfig *koanf.Koanf) map[string]interface{} {
+	values := konfig.All()
+	delete(values, "help")
+
+	for _, flag := range urlFlags {
+		if endpoint := konfig.String(flag); len(endpoint) > 0 {
+			values[flag] = redactURL(endpoint)
+		}
+	}
+
+	for _, flag := range secretFlags {
+		if len(konfig.String(flag)) > 0 {
+			values[flag] = redactedValue
+		}
+	}
+
+	return values
+}
+
+// redactURL returns the scheme and host of an endpoint URL. API keys, e.g. the
+// ones of Infura and Alchemy, are part of the userinfo, the path or the query,
+// so those are redacted. URLs that can't be parsed are redacted entirely.
+func redactURL(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return redactedValue
+	}
+
+	if u.User == nil && strings.Trim(u.Path, "/") == "" && u.RawQuery == "" && u.Fragment == "" && u.Opaque == "" {
+		return endpoint
+	}
+
+	redacted := url.URL{Scheme: u.Scheme, Host: u.Host}
+	return redacted.String() + "/" + redactedValue
+}
+
+// printConfig prints the redacted config as TOML in the text output, or in the
+// JSON or YAML output.
+func printConfig(w io.Writer, konfig *koanf.Koanf) error {
+	var parser koanf.Parser
+
+	switch output := konfig.String(flagOutput); output {
+	case outputText:
+		parser = toml.Parser()
+
+	case outputJSON:
+		parser = json.Parser()
+
+	case outputYAML:
+		parser = yaml.Parser()
+
+	default:
+		return fmt.Errorf("invalid output format: %s", output)
+	}
+
+	bz, err := parser.Marshal(redactConfig(konfig))
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w, strings.TrimSpace(string(bz)))
+	return err
+}
@@ -4,26 +4,46 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	"github.com/knadh/koanf"
 	"github.com/rs/zerolog"
 	"github.com/spf13/pflag"
 
+	umeepfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
 	umeeparams "github.com/umee-network/umee/v3/app/params"
+
+	"github.com/umee-network/peggo/orchestrator/emergency"
+	"github.com/umee-network/peggo/orchestrator/relayer"
+	"github.com/umee-network/peggo/orchestrator/wallet"
 )
 
 const (
 	logLevelJSON = "json"
 	logLevelText = "text"
 
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+
+	flagConfig                  = "config"
 	flagLogLevel                = "log-level"
 	flagLogFormat               = "log-format"
 	flagSvcWaitTimeout          = "svc-wait-timeout"
+	flagOutput                  = "output"
+	flagDryRun                  = "dry-run"
+	flagGenerateOnly            = "generate-only"
+	flagBridgeFee               = "bridge-fee"
 	flagCosmosChainID           = "cosmos-chain-id"
 	flagCosmosGRPC              = "cosmos-grpc"
 	flagTendermintRPC           = "tendermint-rpc"
 	flagCosmosGasPrices         = "cosmos-gas-prices"
+	flagCosmosGasAdjustment     = "cosmos-gas-adjustment"
+	flagCosmosMaxGasPrices      = "cosmos-max-gas-prices"
+	flagCosmosGasPriceBump      = "cosmos-gas-price-bump"
+	flagCosmosFeeRetries        = "cosmos-fee-retries"
+	flagCosmosQueueRetries      = "cosmos-queue-retries"
 	flagCosmosKeyring           = "cosmos-keyring"
 	flagCosmosKeyringDir        = "cosmos-keyring-dir"
 	flagCosmosKeyringApp        = "cosmos-keyring-app"
@@ -32,6 +52,7 @@ const (
 	flagCosmosPK                = "cosmos-pk"
 	flagCosmosUseLedger         = "cosmos-use-ledger"
 	flagCosmosFeeGranter        = "cosmos-fee-granter"
+	flagCosmosAuthzGranter      = "cosmos-authz-granter"
 	flagCosmosMsgsPerTx         = "cosmos-msgs-per-tx"
 	flagEthKeystoreDir          = "eth-keystore-dir"
 	flagEthFrom                 = "eth-from"
@@ -42,17 +63,52 @@ const (
 	flagEthGasAdjustment        = "eth-gas-price-adjustment"
 	flagEthGasLimitAdjustment   = "eth-gas-limit-adjustment"
 	flagEthAlchemyWS            = "eth-alchemy-ws"
+	flagEthPendingTxSource      = "eth-pending-tx-source"
+	flagEthPendingTxEndpoint    = "eth-pending-tx-endpoint"
+	flagEthLegacyTx             = "eth-legacy-tx"
+	flagEthFeeHistoryBlocks     = "eth-fee-history-blocks"
+	flagEthFeeHistoryPercentile = "eth-fee-history-percentile"
 	flagValsetRelayMode         = "valset-relay-mode"
+	flagValsetRelayOutdated     = "valset-relay-outdated"
 	flagRelayBatches            = "relay-batches"
 	flagCoinGeckoAPI            = "coingecko-api"
 	flagOracleProviders         = "oracle-providers"
+	flagDexPools                = "dex-pools"
+	flagPriceSources            = "price-sources"
+	flagOracleMaxPriceAge       = "oracle-max-price-age"
+	flagSymbolOverrides         = "symbol-overrides"
+	flagSymbolCache             = "symbol-cache"
+	flagSymbolCoinGecko         = "symbol-coingecko-fallback"
+	flagTokenDefaultDecimals    = "token-default-decimals"
+	flagUnreadableTokenTTL      = "unreadable-token-ttl"
+	flagDexMinLiquidity         = "dex-min-liquidity"
 	flagEthGasPrice             = "eth-gas-price"
 	flagEthGasLimit             = "eth-gas-limit"
 	flagAutoApprove             = "auto-approve"
 	flagEthBlocksPerLoop        = "eth-blocks-per-loop"
 	flagEthPendingTXWait        = "eth-pending-tx-wait"
+	flagEthStuckTXBlocks        = "eth-stuck-tx-blocks"
+	flagEthGasPriceBump         = "eth-gas-price-bump"
+	flagEthMaxGasPrice          = "eth-max-gas-price"
+	flagEthBundleRelay          = "eth-bundle-relay"
+	flagEthBundleFallbackBlocks = "eth-bundle-fallback-blocks"
+	flagEthBundleSignerPK       = "eth-bundle-signer-pk"
+	flagEthBalanceWarn          = "eth-balance-warn"
+	flagEthBalanceCritical      = "eth-balance-critical"
+	flagCosmosBalanceWarn       = "cosmos-balance-warn"
+	flagCosmosBalanceCritical   = "cosmos-balance-critical"
+	flagBalanceCheckInterval    = "balance-check-interval"
+	flagEthSpendBudget          = "eth-spend-budget"
+	flagEthSpendBudgetPeriod    = "eth-spend-budget-period"
+	flagEthSpendLedger          = "eth-spend-ledger"
 	flagProfitMultiplier        = "profit-multiplier"
 	flagRelayerLoopMultiplier   = "relayer-loop-multiplier"
+	flagRelayerGasBudget        = "relayer-gas-budget"
+	flagRelayStatsFile          = "relay-stats-file"
+	flagMetricsListenAddr       = "metrics-listen-addr"
+	flagEmergencyAction         = "emergency-action"
+	flagEmergencyWebhook        = "emergency-webhook"
+	flagEmergencyEvidenceDir    = "emergency-evidence-dir"
 	flagRequesterLoopMultiplier = "requester-loop-multiplier"
 	flagBridgeStartHeight       = "bridge-start-height"
 	flagEthMergePause           = "eth-merge-pause" // TODO: remove this after merge is completed
@@ -66,13 +122,19 @@ func cosmosFlagSet() *pflag.FlagSet {
 
 	fs.String(flagCosmosChainID, "", "The chain ID of the cosmos network")
 	fs.String(flagCosmosGRPC, "tcp://localhost:9090", "The gRPC endpoint of a cosmos node")
-	fs.String(flagTendermintRPC, "http://localhost:26657", "The Tendermint RPC endpoint of a Cosmos node")
+	fs.String(flagTendermintRPC, "", "The (optional) Tendermint RPC endpoint of a Cosmos node; transactions are broadcast through gRPC") //nolint: lll
 	fs.String(
 		flagCosmosGasPrices,
 		fmt.Sprintf("0.05%s", umeeparams.BondDenom),
-		"The gas prices to use for Cosmos transaction fees",
+		"The gas prices to use for Cosmos transaction fees (raised to the minimum gas prices of the node)",
 	)
 
+	fs.Float64(flagCosmosGasAdjustment, 1.5, "The factor the simulated gas of Cosmos transactions is multiplied by")
+	fs.String(flagCosmosMaxGasPrices, "", "The max gas prices Cosmos transactions are retried with after insufficient fee errors; empty means no cap")   //nolint: lll
+	fs.Uint64(flagCosmosGasPriceBump, 20, "Percentage by which Cosmos gas prices are raised on insufficient fees, and the gas adjustment on out of gas") //nolint: lll
+	fs.Uint(flagCosmosFeeRetries, 3, "Number of times a Cosmos transaction failing because of insufficient fees or out of gas is retried")               //nolint: lll
+	fs.Uint(flagCosmosQueueRetries, 3, "Number of times queued Cosmos messages failing because of a transient error are retried")                        //nolint: lll
+
 	return fs
 }
 
@@ -109,6 +171,80 @@ func ethereumOptsFlagSet() *pflag.FlagSet {
 	fs.String(flagEthRPC, "http://localhost:8545", "Specify the RPC address of an Ethereum node")
 	fs.Float64(flagEthGasAdjustment, float64(1.3), "Specify a gas price adjustment for Ethereum transactions")
 	fs.Float64(flagEthGasLimitAdjustment, float64(1.2), "Specify a gas limit adjustment for Ethereum transactions")
+	fs.Bool(flagEthLegacyTx, false, "Send legacy Ethereum transactions instead of EIP-1559 dynamic-fee transactions")
+	fs.Uint64(flagEthFeeHistoryBlocks, 20, "Specify the number of blocks used to estimate the priority fee of EIP-1559 transactions")       //nolint: lll
+	fs.Float64(flagEthFeeHistoryPercentile, 50, "Specify the reward percentile used to estimate the priority fee of EIP-1559 transactions") //nolint: lll
+
+	return fs
+}
+
+// relayerFlagSet returns the flags of the relayer, used by both the orchestrator and the standalone relayer.
+func relayerFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.String(flagValsetRelayMode, relayer.ValsetRelayModeNone.String(), "Set an (optional) relaying mode for valset updates to Ethereum. Possible values: none, minimum, all, profitable") //nolint: lll
+	fs.Bool(flagValsetRelayOutdated, true, "Relay unprofitable valset updates when the valset on Ethereum is outdated (used with the profitable valset relay mode)")                        //nolint: lll
+	fs.Bool(flagRelayBatches, false, "Relay transaction batches to Ethereum")
+	fs.String(flagCoinGeckoAPI, "https://api.coingecko.com/api/v3", "Specify the coingecko API endpoint")
+	fs.Duration(flagEthPendingTXWait, 20*time.Minute, "Time for a pending tx to be considered stale")
+	fs.Uint64(flagEthStuckTXBlocks, 10, "Number of Ethereum blocks after which a relayed tx that was not mined is replaced; zero disables replacements") //nolint: lll
+	fs.Uint64(flagEthGasPriceBump, 20, "Percentage by which the fees of a stuck tx are increased when replacing it (at least 10)")                       //nolint: lll
+	fs.Int64(flagEthMaxGasPrice, 0, "Maximum gas price (in wei) of a replacement tx; zero means no cap")
+	fs.String(flagEthBundleRelay, "", "Specify a private relay endpoint to send relayed txs as bundles (eth_sendBundle) instead of the public mempool")  //nolint: lll
+	fs.Uint64(flagEthBundleFallbackBlocks, 5, "Number of Ethereum blocks a bundle targets before its tx is sent to the public mempool")                  //nolint: lll
+	fs.String(flagEthBundleSignerPK, "", "Specify the private key used to sign bundle requests (it doesn't need funds); if empty, a random one is used") //nolint: lll
+	fs.String(flagEthAlchemyWS, "", "Specify the Alchemy websocket endpoint (deprecated: use --eth-pending-tx-source=alchemy)")                          //nolint: lll
+	fs.String(flagEthPendingTxSource, "", "Specify the source of pending txs used to avoid relaying duplicates (alchemy|subscribe|txpool)")
+	fs.String(flagEthPendingTxEndpoint, "", "Specify the endpoint of the pending tx source (websocket for alchemy and subscribe)")
+	fs.String(flagEthBalanceWarn, "0.1", "ETH balance of the relayer address below which a warning is logged; empty disables the check") //nolint: lll
+	fs.String(flagEthBalanceCritical, "0.02", "ETH balance of the relayer address below which an error is logged")                       //nolint: lll
+	fs.Duration(flagBalanceCheckInterval, 5*time.Minute, "Time between balance checks")
+	fs.String(flagEthSpendBudget, "", "Maximum amount of ETH spent on relays per budget period; empty means no limit")              //nolint: lll
+	fs.String(flagEthSpendBudgetPeriod, wallet.BudgetPeriodDay, "Rolling period of the ETH spend budget (day|week)")                //nolint: lll
+	fs.String(flagEthSpendLedger, "", "Specify a file to store the ledger of ETH spent on relays, so the budget survives restarts") //nolint: lll
+	fs.Float64(flagProfitMultiplier, 1.0, "Multiplier to apply to relayer profit")
+	fs.Float64(flagRelayerLoopMultiplier, 3.0, "Multiplier for the relayer loop duration (in ETH blocks)")
+	fs.Uint64(flagRelayerGasBudget, 0, "Maximum gas of the batch relays sent per relayer loop, in descending profit order; zero means no limit") //nolint: lll
+
+	fs.String(flagRelayStatsFile, "", "Specify a file to store the relay competition stats (who relayed each batch and valset, and our wasted attempts)") //nolint: lll
+	fs.String(flagMetricsListenAddr, "", "Specify an address to serve Prometheus metrics on (e.g. localhost:9100); empty disables the metrics")           //nolint: lll
+
+	fs.String(flagEmergencyAction, emergency.ActionAlert, "Action taken when the relayer finds a valset on Ethereum that doesn't match Cosmos (log|alert|halt); halt stops relaying and signing, which gets the validator slashed, until the halt file in --emergency-evidence-dir is deleted") //nolint: lll
+	fs.String(flagEmergencyWebhook, "", "Specify a webhook URL the valset hijack alerts are posted to")
+	fs.String(flagEmergencyEvidenceDir, ".", "Specify the directory the valset hijack evidence is dumped to")
+
+	defaultProviders := []string{
+		umeepfprovider.ProviderOsmosis.String(),
+		umeepfprovider.ProviderHuobi.String(),
+		umeepfprovider.ProviderOkx.String(),
+		umeepfprovider.ProviderCoinbase.String(),
+		umeepfprovider.ProviderBitget.String(),
+		umeepfprovider.ProviderMexc.String(),
+		umeepfprovider.ProviderCrypto.String(),
+	}
+
+	allProviders := append([]string{
+		umeepfprovider.ProviderKraken.String(),
+		umeepfprovider.ProviderGate.String(),
+		umeepfprovider.ProviderMock.String(),
+		umeepfprovider.ProviderBinance.String(),
+	}, defaultProviders...)
+
+	fs.StringSlice(flagOracleProviders, defaultProviders,
+		fmt.Sprintf("Specify the providers to use in the oracle, options \"%s\"", strings.Join(allProviders, ",")))
+	fs.String(flagDexPools, "", "Specify a JSON file of Uniswap pools used to price the tokens the oracle providers can't price")     //nolint: lll
+	fs.String(flagDexMinLiquidity, "10000", "Minimum value in USD of the quote token balance of a Uniswap pool used to price tokens") //nolint: lll
+
+	fs.String(flagPriceSources, "", "Specify a JSON file of price sources aggregated by weighted median, replacing the oracle providers") //nolint: lll
+
+	fs.Duration(flagOracleMaxPriceAge, 5*time.Minute, "Max age of the oracle prices used to decide if relays and batch requests are profitable; zero means no limit") //nolint: lll
+
+	fs.String(flagSymbolOverrides, "", "Specify a JSON file mapping token addresses to the symbols they're priced with, overriding their on-chain symbol") //nolint: lll
+	fs.String(flagSymbolCache, "", "Specify a file the token symbols are cached in across restarts")
+	fs.Bool(flagSymbolCoinGecko, true, "Resolve the symbols of tokens with CoinGecko when they can't be read on-chain")
+
+	fs.Uint8(flagTokenDefaultDecimals, 18, "Decimals of the tokens that don't implement decimals()")
+	fs.Duration(flagUnreadableTokenTTL, 10*time.Minute, "Time a token whose symbol or decimals can't be read is skipped before being read again") //nolint: lll
 
 	return fs
 }
@@ -133,7 +269,7 @@ func parseURL(logger zerolog.Logger, konfig *koanf.Koanf, flag string) (string,
 		return "", err
 	}
 	if strings.EqualFold(u.Scheme, "http") && !strings.Contains(u.Host, "localhost") {
-		logger.Warn().Str(flag, endpoint).Msg("flag is unsafe; unencrypted non-local url used")
+		logger.Warn().Str(flag, redactURL(endpoint)).Msg("flag is unsafe; unencrypted non-local url used")
 	}
 	return endpoint, nil
 }
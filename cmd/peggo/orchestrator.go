@@ -11,13 +11,16 @@ import (
 
 	"cloud.google.com/go/logging"
 	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdkclient "github.com/cosmos/cosmos-sdk/client"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
 	ethcmn "github.com/ethereum/go-ethereum/common"
-	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/knadh/koanf"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
-	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 
@@ -25,14 +28,12 @@ import (
 
 	"github.com/umee-network/peggo/cmd/peggo/client"
 	"github.com/umee-network/peggo/orchestrator"
-	"github.com/umee-network/peggo/orchestrator/coingecko"
+	"github.com/umee-network/peggo/orchestrator/analytics"
 	"github.com/umee-network/peggo/orchestrator/cosmos"
-	"github.com/umee-network/peggo/orchestrator/ethereum/committer"
 	gravity "github.com/umee-network/peggo/orchestrator/ethereum/gravity"
 	"github.com/umee-network/peggo/orchestrator/ethereum/provider"
-	"github.com/umee-network/peggo/orchestrator/oracle"
 	"github.com/umee-network/peggo/orchestrator/relayer"
-	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+	"github.com/umee-network/peggo/orchestrator/wallet"
 )
 
 func getOrchestratorCmd() *cobra.Command {
@@ -46,7 +47,7 @@ func getOrchestratorCmd() *cobra.Command {
 				return err
 			}
 
-			logger, err := getLogger(cmd)
+			logger, err := getLogger(konfig)
 			if err != nil {
 				return err
 			}
@@ -67,24 +68,11 @@ func getOrchestratorCmd() *cobra.Command {
 				return err
 			}
 
-			tmRPCEndpoint, err := parseURL(logger, konfig, flagTendermintRPC)
-			if err != nil {
-				return err
-			}
 			cosmosGRPC, err := parseURL(logger, konfig, flagCosmosGRPC)
 			if err != nil {
 				return err
 			}
 
-			cosmosGasPrices := konfig.String(flagCosmosGasPrices)
-
-			tmRPC, err := rpchttp.New(tmRPCEndpoint, "/websocket")
-			if err != nil {
-				return fmt.Errorf("failed to create Tendermint RPC client: %w", err)
-			}
-
-			fmt.Fprintf(os.Stderr, "Connected to Tendermint RPC: %s\n", tmRPCEndpoint)
-
 			var feeGranter sdk.AccAddress
 			if v := konfig.String(flagCosmosFeeGranter); len(v) > 0 {
 				feeGranter, err = sdk.AccAddressFromBech32(v)
@@ -93,9 +81,27 @@ func getOrchestratorCmd() *cobra.Command {
 				}
 			}
 
-			clientCtx = clientCtx.WithClient(tmRPC).WithNodeURI(tmRPCEndpoint).WithFeeGranterAddress(feeGranter)
+			clientCtx = clientCtx.WithFeeGranterAddress(feeGranter)
+
+			// With an authz granter, the key of the keyring is a grantee signing the msgs of the granter, which is the
+			// orchestrator address registered with the delegate keys.
+			var authzGranter sdk.AccAddress
+			if v := konfig.String(flagCosmosAuthzGranter); len(v) > 0 {
+				authzGranter, err = sdk.AccAddressFromBech32(v)
+				if err != nil {
+					return fmt.Errorf("failed to parse authz granter address: %w", err)
+				}
+			}
+
+			tmRPC, err := newTendermintRPC(logger, konfig)
+			if err != nil {
+				return err
+			}
+			if tmRPC != nil {
+				clientCtx = clientCtx.WithClient(tmRPC).WithNodeURI(konfig.String(flagTendermintRPC))
+			}
 
-			daemonClient, err := client.NewCosmosClient(clientCtx, logger, cosmosGRPC, client.OptionGasPrices(cosmosGasPrices))
+			daemonClient, err := client.NewCosmosClient(clientCtx, logger, cosmosGRPC, newCosmosClientOptions(konfig)...)
 			if err != nil {
 				return err
 			}
@@ -116,6 +122,15 @@ func getOrchestratorCmd() *cobra.Command {
 
 			gravityQuerier := gravitytypes.NewQueryClient(gRPCConn)
 
+			if err := checkGrants(ctx, logger, clientCtx, gRPCConn, authzGranter, feeGranter); err != nil {
+				return err
+			}
+
+			var gravityBroadcasterOptions []func(cosmos.GravityBroadcastClient)
+			if !authzGranter.Empty() {
+				gravityBroadcasterOptions = append(gravityBroadcasterOptions, cosmos.SetAuthzGranter(authzGranter))
+			}
+
 			gravityParams, err := getGravityParams(gRPCConn)
 			if err != nil {
 				return fmt.Errorf("failed to query for Gravity params: %w", err)
@@ -127,27 +142,13 @@ func getOrchestratorCmd() *cobra.Command {
 				return fmt.Errorf("failed to initialize Ethereum account: %w", err)
 			}
 
-			ethRPCEndpoint := konfig.String(flagEthRPC)
-			ethRPC, err := ethrpc.Dial(ethRPCEndpoint)
-			if err != nil {
-				return fmt.Errorf("failed to dial Ethereum RPC node: %w", err)
-			}
-
-			fmt.Fprintf(os.Stderr, "Connected to Ethereum RPC: %s\n", ethRPCEndpoint)
-			ethProvider := provider.NewEVMProvider(ethRPC)
+			ctx, cancel = context.WithCancel(context.Background())
+			// listen for and trap any OS signal to gracefully shutdown and exit
+			trapSignal(cancel)
 
-			ethGasPriceAdjustment := konfig.Float64(flagEthGasAdjustment)
-			ethGasLimitAdjustment := konfig.Float64(flagEthGasLimitAdjustment)
-			ethCommitter, err := committer.NewEthCommitter(
-				logger,
-				ethKeyFromAddress,
-				ethGasPriceAdjustment,
-				ethGasLimitAdjustment,
-				signerFn,
-				ethProvider,
-			)
-			if err != nil && err != grpc.ErrServerStopped {
-				return fmt.Errorf("failed to create Ethereum committer: %w", err)
+			gravityContract, err := newGravityContract(ctx, logger, konfig, args[0], ethKeyFromAddress, signerFn)
+			if err != nil {
+				return err
 			}
 
 			gravityBroadcaster := cosmos.NewGravityBroadcastClient(
@@ -157,73 +158,56 @@ func getOrchestratorCmd() *cobra.Command {
 				signerFn,
 				personalSignFn,
 				konfig.Int(flagCosmosMsgsPerTx),
+				gravityBroadcasterOptions...,
 			)
 
-			if !ethcmn.IsHexAddress(args[0]) {
-				return fmt.Errorf("invalid gravity address: %s", args[0])
-			}
-			gravityAddr := ethcmn.HexToAddress(args[0])
-
-			ethGravity, err := wrappers.NewGravity(gravityAddr, ethCommitter.Provider())
-			if err != nil {
-				return fmt.Errorf("failed to create a new instance of Gravity: %w", err)
-			}
-
-			gravityContract, err := gravity.NewGravityContract(logger, ethCommitter, gravityAddr, ethGravity)
-			if err != nil {
-				return fmt.Errorf("failed to create Ethereum committer: %w", err)
-			}
-
-			symbolRetriever := coingecko.NewCoingecko(logger, &coingecko.Config{
-				BaseURL: konfig.String(flagCoinGeckoAPI),
-			})
-
 			// gravityParams.AverageBlockTime and gravityParams.AverageEthereumBlockTime are in milliseconds.
 			averageCosmosBlockTime := time.Duration(gravityParams.AverageBlockTime) * time.Millisecond
 			averageEthBlockTime := time.Duration(gravityParams.AverageEthereumBlockTime) * time.Millisecond
 
-			// We multiply the relayer loop multiplier by the ETH block time.
-			// gravityParams.AverageEthereumBlockTime is in milliseconds.
-			ethBlockTimeF64 := float64(averageEthBlockTime.Milliseconds())
-			relayerLoopMultiplier := konfig.Float64(flagRelayerLoopMultiplier)
-
-			// Here we cast the float64 to a Duration (int64); as we are dealing with ms, we'll lose as much as 1ms.
-			relayerLoopDuration := time.Duration(ethBlockTimeF64*relayerLoopMultiplier) * time.Millisecond
+			symbolRetriever, err := newSymbolRetriever(logger, konfig, gravityContract)
+			if err != nil {
+				return fmt.Errorf("failed to create symbol retriever: %w", err)
+			}
 
-			valsetRelayMode, err := validateRelayValsetsMode(konfig.String(flagValsetRelayMode))
+			o, err := newPriceSources(ctx, logger, konfig, gravityContract.Provider(), gRPCConn)
 			if err != nil {
 				return err
 			}
 
-			ctx, cancel = context.WithCancel(context.Background())
-			// listen for and trap any OS signal to gracefully shutdown and exit
-			trapSignal(cancel)
+			metricsRegistry := newMetricsRegistry(konfig)
 
-			providers := konfig.Strings(flagOracleProviders)
-			o, err := oracle.New(ctx, logger.With().Str("module", "oracle").Logger(), stringsToProviderName(providers))
-			if err != nil {
-				return err
+			var relayAnalytics analytics.RelayAnalytics
+			if isRelaying(konfig) {
+				relayAnalytics, err = newRelayAnalytics(logger, konfig, gravityContract, ethKeyFromAddress, metricsRegistry)
+				if err != nil {
+					return fmt.Errorf("failed to create relay analytics: %w", err)
+				}
 			}
 
-			if err := o.SubscribeSymbols(oracle.SymbolETH); err != nil {
-				return err
+			// The relayer detects valset hijacks, halting both the relayer and the signer.
+			em, err := newEmergency(logger, konfig)
+			if err != nil {
+				return fmt.Errorf("failed to create emergency response: %w", err)
 			}
 
-			relayer := relayer.NewGravityRelayer(
+			gravityRelayer, err := newGravityRelayer(
 				logger,
+				konfig,
 				gravityQuerier,
 				gravityContract,
-				valsetRelayMode,
-				konfig.Bool(flagRelayBatches),
-				relayerLoopDuration,
-				konfig.Duration(flagEthPendingTXWait),
-				konfig.Float64(flagProfitMultiplier),
-				relayer.SetSymbolRetriever(symbolRetriever),
-				relayer.SetOracle(o),
+				averageEthBlockTime,
+				symbolRetriever,
+				o,
+				relayAnalytics,
+				em,
 			)
+			if err != nil {
+				return err
+			}
 
 			logger = logger.With().
-				Str("relayer_orchestrator_addr", orchAddress.String()).
+				Str("relayer_orchestrator_addr", gravityBroadcaster.AccFromAddress().String()).
 				Str("relayer_ethereum_addr", ethKeyFromAddress.String()).
 				Logger()
 
@@ -249,7 +233,7 @@ func getOrchestratorCmd() *cobra.Command {
 				ethKeyFromAddress,
 				signerFn,
 				personalSignFn,
-				relayer,
+				gravityRelayer,
 				averageCosmosBlockTime,
 				averageEthBlockTime,
 				batchRequesterLoopDuration,
@@ -258,19 +242,56 @@ func getOrchestratorCmd() *cobra.Command {
 				symbolRetriever,
 				o,
 				konfig.Bool(flagEthMergePause),
+				orchestrator.SetEmergency(em),
 			)
 
+			balanceMonitor, err := newBalanceMonitor(
+				logger,
+				konfig,
+				gravityContract.Provider(),
+				ethKeyFromAddress,
+				isRelaying(konfig),
+				banktypes.NewQueryClient(gRPCConn),
+				orchAddress,
+				feeGranter,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create balance monitor: %w", err)
+			}
+
 			g, errCtx := errgroup.WithContext(ctx)
 
 			g.Go(func() error {
 				return startOrchestrator(errCtx, logger, orch)
 			})
 
-			// If we have the alchemy WS endpoint, start listening for txs against the Gravity Bridge contract.
-			alchemyWS := konfig.String(flagEthAlchemyWS)
-			if alchemyWS != "" {
+			if balanceMonitor != nil {
+				g.Go(func() error {
+					return balanceMonitor.Start(errCtx)
+				})
+			}
+
+			if relayAnalytics != nil {
+				g.Go(func() error {
+					return relayAnalytics.Start(errCtx)
+				})
+			}
+
+			if metricsRegistry != nil {
 				g.Go(func() error {
-					return gravityContract.SubscribeToPendingTxs(errCtx, alchemyWS)
+					return serveMetrics(errCtx, logger, konfig.String(flagMetricsListenAddr), metricsRegistry)
+				})
+			}
+
+			// If we have a pending tx source, start listening for txs against the Gravity Bridge contract.
+			pendingTxWatcher, err := newPendingTxWatcher(logger, konfig, averageEthBlockTime)
+			if err != nil {
+				return err
+			}
+
+			if pendingTxWatcher != nil {
+				g.Go(func() error {
+					return gravityContract.WatchPendingTxs(errCtx, pendingTxWatcher)
 				})
 			}
 
@@ -284,43 +305,20 @@ func getOrchestratorCmd() *cobra.Command {
 	cmd.Flags().String(flagGcpLogLevel, zerolog.InfoLevel.String(), "Specify the log level to send to Google Cloud")
 
 	// Orch flags
-	cmd.Flags().String(flagValsetRelayMode, relayer.ValsetRelayModeNone.String(), "Set an (optional) relaying mode for valset updates to Ethereum. Possible values: none, minimum, all") //nolint: lll
-	cmd.Flags().Bool(flagRelayBatches, false, "Relay transaction batches to Ethereum")
 	cmd.Flags().Int64(flagEthBlocksPerLoop, 2000, "Number of Ethereum blocks to process per orchestrator loop")
-	cmd.Flags().String(flagCoinGeckoAPI, "https://api.coingecko.com/api/v3", "Specify the coingecko API endpoint")
-	cmd.Flags().Bool(flagEthMergePause, false, "Pause some messages related to the adaptation of the Gravity Bridge to the merge") //nolint: lll
-
-	defaultProviders := []string{
-		umeepfprovider.ProviderOsmosis.String(),
-		umeepfprovider.ProviderHuobi.String(),
-		umeepfprovider.ProviderOkx.String(),
-		umeepfprovider.ProviderCoinbase.String(),
-		umeepfprovider.ProviderBitget.String(),
-		umeepfprovider.ProviderMexc.String(),
-		umeepfprovider.ProviderCrypto.String(),
-	}
-
-	allProviders := append([]string{
-		umeepfprovider.ProviderKraken.String(),
-		umeepfprovider.ProviderGate.String(),
-		umeepfprovider.ProviderMock.String(),
-		umeepfprovider.ProviderBinance.String(),
-	}, defaultProviders...)
-
-	cmd.Flags().StringSlice(flagOracleProviders, defaultProviders,
-		fmt.Sprintf("Specify the providers to use in the oracle, options \"%s\"", strings.Join(allProviders, ",")))
-	cmd.Flags().Duration(flagEthPendingTXWait, 20*time.Minute, "Time for a pending tx to be considered stale")
-	cmd.Flags().String(flagEthAlchemyWS, "", "Specify the Alchemy websocket endpoint")
-	cmd.Flags().Float64(flagProfitMultiplier, 1.0, "Multiplier to apply to relayer profit")
-	cmd.Flags().Float64(flagRelayerLoopMultiplier, 3.0, "Multiplier for the relayer loop duration (in ETH blocks)")
-	cmd.Flags().Float64(flagRequesterLoopMultiplier, 60.0, "Multiplier for the batch requester loop duration (in Cosmos blocks)")             //nolint: lll
-	cmd.Flags().String(flagCosmosFeeGranter, "", "Set an (optional) fee granter address that will pay for Cosmos fees (feegrant must exist)") //nolint: lll
+	cmd.Flags().Bool(flagEthMergePause, false, "Pause some messages related to the adaptation of the Gravity Bridge to the merge")                                     //nolint: lll
+	cmd.Flags().String(flagCosmosBalanceWarn, "", "Balance of the orchestrator account below which a warning is logged (e.g. 1000000uumee); empty disables the check") //nolint: lll
+	cmd.Flags().String(flagCosmosBalanceCritical, "", "Balance of the orchestrator account below which an error is logged (e.g. 100000uumee)")                         //nolint: lll
+	cmd.Flags().Float64(flagRequesterLoopMultiplier, 60.0, "Multiplier for the batch requester loop duration (in Cosmos blocks)")                                      //nolint: lll
+	cmd.Flags().String(flagCosmosFeeGranter, "", "Set an (optional) fee granter address that will pay for Cosmos fees (feegrant must exist)")                          //nolint: lll
+	cmd.Flags().String(flagCosmosAuthzGranter, "", "Set an (optional) orchestrator address whose msgs the Cosmos key executes through authz (grants must exist)")      //nolint: lll
 	cmd.Flags().Int64(flagBridgeStartHeight, 0, "Set an (optional) height to wait for the bridge to be available")
 	cmd.Flags().Int(flagCosmosMsgsPerTx, 10, "Set a maximum number of messages to send per transaction (used for claims)")
 	cmd.Flags().AddFlagSet(cosmosFlagSet())
 	cmd.Flags().AddFlagSet(cosmosKeyringFlagSet())
 	cmd.Flags().AddFlagSet(ethereumKeyOptsFlagSet())
 	cmd.Flags().AddFlagSet(ethereumOptsFlagSet())
+	cmd.Flags().AddFlagSet(relayerFlagSet())
 
 	return cmd
 }
@@ -426,6 +424,8 @@ func validateRelayValsetsMode(mode string) (relayer.ValsetRelayMode, error) {
 		return relayer.ValsetRelayModeMinimum, nil
 	case relayer.ValsetRelayModeAll.String():
 		return relayer.ValsetRelayModeAll, nil
+	case relayer.ValsetRelayModeProfitable.String():
+		return relayer.ValsetRelayModeProfitable, nil
 	default:
 		return relayer.ValsetRelayModeNone, fmt.Errorf("invalid relay valsets mode: %s", mode)
 	}
@@ -439,3 +439,191 @@ func stringsToProviderName(providersName []string) []umeepfprovider.Name {
 
 	return names
 }
+
+// newBundleProvider wraps the Ethereum provider so relayed txs are sent to a private relay as bundles.
+func newBundleProvider(
+	ctx context.Context,
+	logger zerolog.Logger,
+	konfig *koanf.Koanf,
+	ethProvider provider.EVMProviderWithRet,
+) (provider.EVMProviderWithRet, error) {
+	relayURL, err := parseURL(logger, konfig, flagEthBundleRelay)
+	if err != nil {
+		return nil, err
+	}
+
+	// The tracker would replace a tx still waiting for its bundle, so the replacement would race the fallback.
+	fallbackBlocks := konfig.Int64(flagEthBundleFallbackBlocks)
+	if stuckTXBlocks := konfig.Int64(flagEthStuckTXBlocks); stuckTXBlocks > 0 && fallbackBlocks >= stuckTXBlocks {
+		return nil, fmt.Errorf(
+			"--%s (%d) must be less than --%s (%d)",
+			flagEthBundleFallbackBlocks, fallbackBlocks, flagEthStuckTXBlocks, stuckTXBlocks,
+		)
+	}
+
+	bundleOpts := []provider.BundleOption{
+		provider.OptionBundleFallbackBlocks(uint64(fallbackBlocks)),
+	}
+
+	if signerPK := konfig.String(flagEthBundleSignerPK); signerPK != "" {
+		signerKey, err := ethcrypto.HexToECDSA(strings.TrimPrefix(signerPK, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse bundle signer private key: %w", err)
+		}
+
+		bundleOpts = append(bundleOpts, provider.OptionBundleSigner(signerKey))
+	}
+
+	return provider.NewBundleProvider(ctx, logger, ethProvider, relayURL, bundleOpts...)
+}
+
+// newPendingTxWatcher returns the configured pending tx watcher, or nil if there's none. The deprecated Alchemy
+// websocket flag is still accepted.
+func newPendingTxWatcher(
+	logger zerolog.Logger,
+	konfig *koanf.Koanf,
+	ethBlockTime time.Duration,
+) (gravity.PendingTxWatcher, error) {
+	source := konfig.String(flagEthPendingTxSource)
+	endpoint := konfig.String(flagEthPendingTxEndpoint)
+
+	if alchemyWS := konfig.String(flagEthAlchemyWS); alchemyWS != "" {
+		if source != "" && source != gravity.PendingTxSourceAlchemy {
+			return nil, fmt.Errorf("--%s can't be used with --%s=%s", flagEthAlchemyWS, flagEthPendingTxSource, source)
+		}
+
+		source = gravity.PendingTxSourceAlchemy
+		endpoint = alchemyWS
+	}
+
+	if source == "" {
+		return nil, nil
+	}
+
+	if endpoint == "" {
+		return nil, fmt.Errorf("--%s is required for the %s pending tx source", flagEthPendingTxEndpoint, source)
+	}
+
+	return gravity.NewPendingTxWatcher(logger, source, endpoint, ethBlockTime)
+}
+
+// newSpendBudget returns the ETH spend budget of the relayer.
+func newSpendBudget(logger zerolog.Logger, konfig *koanf.Koanf) (wallet.SpendBudget, error) {
+	limit, err := parseEthAmount(konfig.String(flagEthSpendBudget))
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := wallet.ParseBudgetPeriod(konfig.String(flagEthSpendBudgetPeriod))
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet.NewSpendBudget(logger, limit, period, konfig.String(flagEthSpendLedger))
+}
+
+// newBalanceMonitor returns the monitor of the relayer and orchestrator balances, and of the fee granter paying the
+// orchestrator fees, if any, or nil if there's nothing to monitor.
+func newBalanceMonitor(
+	logger zerolog.Logger,
+	konfig *koanf.Koanf,
+	ethProvider provider.EVMProvider,
+	ethAddress ethcmn.Address,
+	relaying bool,
+	bankQueryClient banktypes.QueryClient,
+	orchAddress sdk.AccAddress,
+	feeGranter sdk.AccAddress,
+) (wallet.BalanceMonitor, error) {
+	monitorOpts := []wallet.MonitorOption{
+		wallet.OptionCheckInterval(konfig.Duration(flagBalanceCheckInterval)),
+	}
+
+	if ethWarn := konfig.String(flagEthBalanceWarn); relaying && ethWarn != "" {
+		warnThreshold, err := parseEthAmount(ethWarn)
+		if err != nil {
+			return nil, err
+		}
+
+		criticalThreshold, err := parseEthAmount(konfig.String(flagEthBalanceCritical))
+		if err != nil {
+			return nil, err
+		}
+
+		monitorOpts = append(
+			monitorOpts,
+			wallet.OptionEthAccount(ethProvider, ethAddress, warnThreshold, criticalThreshold),
+		)
+	}
+
+	if cosmosWarn := konfig.String(flagCosmosBalanceWarn); cosmosWarn != "" {
+		warnThreshold, err := sdk.ParseCoinNormalized(cosmosWarn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w", flagCosmosBalanceWarn, err)
+		}
+
+		criticalThreshold := sdk.NewCoin(warnThreshold.Denom, sdk.ZeroInt())
+		if cosmosCritical := konfig.String(flagCosmosBalanceCritical); cosmosCritical != "" {
+			criticalThreshold, err = sdk.ParseCoinNormalized(cosmosCritical)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --%s: %w", flagCosmosBalanceCritical, err)
+			}
+		}
+
+		monitorOpts = append(
+			monitorOpts,
+			wallet.OptionCosmosAccount(bankQueryClient, orchAddress, warnThreshold, criticalThreshold),
+		)
+
+		if !feeGranter.Empty() {
+			monitorOpts = append(monitorOpts, wallet.OptionCosmosFeeGranter(feeGranter))
+		}
+	}
+
+	if len(monitorOpts) == 1 {
+		return nil, nil
+	}
+
+	return wallet.NewBalanceMonitor(logger, monitorOpts...)
+}
+
+// checkGrants checks that the key of the keyring is granted all the Gravity msgs by the authz granter, if any, and
+// that the fee granter, if any, allows it to pay the fees of its txs, which only hold authz.MsgExec msgs with authz.
+func checkGrants(
+	ctx context.Context,
+	logger zerolog.Logger,
+	clientCtx sdkclient.Context,
+	conn *grpc.ClientConn,
+	authzGranter, feeGranter sdk.AccAddress,
+) error {
+	signer := clientCtx.GetFromAddress()
+	msgTypes := cosmos.GravityMsgTypes
+
+	if !authzGranter.Empty() {
+		if err := cosmos.CheckAuthzGrants(ctx, logger, authz.NewQueryClient(conn), authzGranter, signer); err != nil {
+			return fmt.Errorf("failed to check authz grants: %w", err)
+		}
+
+		logger.Info().
+			Str("granter", authzGranter.String()).
+			Str("grantee", signer.String()).
+			Msg("broadcasting Gravity msgs through authz")
+
+		msgTypes = []string{sdk.MsgTypeURL(&authz.MsgExec{})}
+	}
+
+	if !feeGranter.Empty() {
+		err := cosmos.CheckFeeAllowance(
+			ctx,
+			feegrant.NewQueryClient(conn),
+			clientCtx.InterfaceRegistry,
+			feeGranter,
+			signer,
+			msgTypes,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to check fee allowance: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/knadh/koanf"
 	"github.com/knadh/koanf/providers/env"
+	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/providers/posflag"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
@@ -21,42 +22,37 @@ func NewRootCmd() *cobra.Command {
 
 Inputs in the CLI commands can be provided via flags or environment variables. If
 using the later, prefix the environment variable with PEGGO_ and the named of the
-flag (e.g. PEGGO_COSMOS_PK).`,
+flag (e.g. PEGGO_COSMOS_PK). They can also be set in a TOML or YAML config file
+passed with --config, using the names of the flags as keys. Flags take precedence
+over environment variables, which take precedence over the config file.`,
 	}
 
+	cmd.PersistentFlags().String(flagConfig, "", "Path to a TOML or YAML config file; keys are the names of the flags")
 	cmd.PersistentFlags().String(flagLogLevel, zerolog.InfoLevel.String(), "logging level")
 	cmd.PersistentFlags().String(flagLogFormat, logLevelText, "logging format (text|json)")
 	cmd.PersistentFlags().String(flagSvcWaitTimeout, "1m", "Standard wait timeout for external services (e.g. Cosmos daemon gRPC connection)") //nolint: lll
 
 	cmd.AddCommand(
 		getOrchestratorCmd(),
+		getRelayerCmd(),
 		getBridgeCommand(),
 		getQueryCmd(),
 		getTxCmd(),
+		getConfigCmd(),
 		getVersionCmd(),
 	)
 
 	return cmd
 }
 
-func getLogger(cmd *cobra.Command) (zerolog.Logger, error) {
-	logLevelStr, err := cmd.Flags().GetString(flagLogLevel)
-	if err != nil {
-		return zerolog.Logger{}, err
-	}
-
-	logLvl, err := zerolog.ParseLevel(logLevelStr)
-	if err != nil {
-		return zerolog.Logger{}, err
-	}
-
-	logFormat, err := cmd.Flags().GetString(flagLogFormat)
+func getLogger(konfig *koanf.Koanf) (zerolog.Logger, error) {
+	logLvl, err := zerolog.ParseLevel(konfig.String(flagLogLevel))
 	if err != nil {
 		return zerolog.Logger{}, err
 	}
 
 	var logWriter io.Writer
-	switch logFormat {
+	switch logFormat := konfig.String(flagLogFormat); logFormat {
 	case logLevelJSON:
 		logWriter = os.Stderr
 
@@ -75,17 +71,21 @@ func getLogger(cmd *cobra.Command) (zerolog.Logger, error) {
 //
 // - flags
 // - environment variables
-// - configuration file (TOML) (TODO)
+// - configuration file (TOML or YAML)
 func parseServerConfig(cmd *cobra.Command) (*koanf.Koanf, error) {
 	konfig := koanf.New(".")
 
 	// load from file first (if provided)
-	// TODO: Support config files if/when needed.
-	// if configPath := ctx.String(config.ConfigPath); len(configPath) != 0 {
-	// 	if err := konfig.Load(file.Provider(configPath), toml.Parser()); err != nil {
-	// 		return nil, err
-	// 	}
-	// }
+	if configPath := getConfigPath(cmd); len(configPath) != 0 {
+		parser, err := configFileParser(configPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := konfig.Load(file.Provider(configPath), parser); err != nil {
+			return nil, fmt.Errorf("failed to load config file %s: %w", configPath, err)
+		}
+	}
 
 	// load from environment variables
 	if err := konfig.Load(env.Provider("PEGGO_", ".", func(s string) string {
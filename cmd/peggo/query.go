@@ -1,7 +1,25 @@
 package peggo
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/gogo/protobuf/proto"
+	"github.com/knadh/koanf"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"github.com/umee-network/peggo/cmd/peggo/client"
+	"github.com/umee-network/peggo/orchestrator/analytics"
+	"github.com/umee-network/peggo/orchestrator/emergency"
 )
 
 func getQueryCmd() *cobra.Command {
@@ -11,8 +29,410 @@ func getQueryCmd() *cobra.Command {
 		Short:   "Query commands that can get state info from Gravity",
 	}
 
-	// TODO: Add commands. Injective's Peggo doesn't have any at the moment.
-	cmd.AddCommand()
+	cmd.AddCommand(
+		getRelayStatsCmd(),
+		getValsetHijackReportCmd(),
+		getQueryParamsCmd(),
+		getQueryCurrentValsetCmd(),
+		getQueryValsetCmd(),
+		getQueryValsetsCmd(),
+		getQueryPendingValsetsCmd(),
+		getQueryValsetConfirmsCmd(),
+		getQueryBatchesCmd(),
+		getQueryBatchCmd(),
+		getQueryBatchConfirmsCmd(),
+		getQueryPendingBatchesCmd(),
+		getQueryBatchFeesCmd(),
+		getQueryPendingSendToEthCmd(),
+		getQueryLastEventNonceCmd(),
+		getQueryAttestationsCmd(),
+		getQueryDelegateKeysCmd(),
+		getQueryDenomToERC20Cmd(),
+		getQueryERC20ToDenomCmd(),
+	)
 
 	return cmd
 }
+
+func getRelayStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "relay-stats [stats-file]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show the relay competition stats stored by a relayer",
+		Long: `Show the relay competition stats stored by a relayer started with --relay-stats-file:
+how many batches and valset updates were relayed by us and by other relayers, and
+how many of our relay attempts were wasted because another relayer won the race
+or the transaction reverted.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stats, err := analytics.LoadStats(args[0])
+			if err != nil {
+				return err
+			}
+
+			return analytics.WriteReport(cmd.OutOrStdout(), stats)
+		},
+	}
+}
+
+func getValsetHijackReportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "valset-hijack-report [evidence-file]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show a report of the evidence of a valset hijack, for a governance proposal",
+		Long: `Show a report of the evidence dumped to --emergency-evidence-dir when the relayer
+finds a valset on Ethereum that doesn't match the valset with the same nonce on Cosmos:
+both valsets, the ValsetUpdated event that set it, and the validators of the previous
+valset who signed the update.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			evidence, err := emergency.LoadEvidence(args[0])
+			if err != nil {
+				return err
+			}
+
+			return emergency.WriteReport(cmd.OutOrStdout(), evidence)
+		},
+	}
+}
+
+func getQueryParamsCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "params",
+		Args:  cobra.NoArgs,
+		Short: "Show the parameters of the Gravity module",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, _ []string) (proto.Message, error) {
+		return qc.Params(ctx, &gravitytypes.QueryParamsRequest{})
+	})
+}
+
+func getQueryCurrentValsetCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "current-valset",
+		Args:  cobra.NoArgs,
+		Short: "Show the valset the current Cosmos validators would form",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, _ []string) (proto.Message, error) {
+		return qc.CurrentValset(ctx, &gravitytypes.QueryCurrentValsetRequest{})
+	})
+}
+
+func getQueryValsetCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "valset [nonce]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show the valset with a nonce",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, args []string) (proto.Message, error) {
+		nonce, err := parseNonce(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return qc.ValsetRequest(ctx, &gravitytypes.QueryValsetRequestRequest{Nonce: nonce})
+	})
+}
+
+func getQueryValsetsCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "valsets",
+		Args:  cobra.NoArgs,
+		Short: "Show the latest valsets",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, _ []string) (proto.Message, error) {
+		return qc.LastValsetRequests(ctx, &gravitytypes.QueryLastValsetRequestsRequest{})
+	})
+}
+
+func getQueryPendingValsetsCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "pending-valsets [orchestrator-address]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show the valsets an orchestrator hasn't signed yet",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, args []string) (proto.Message, error) {
+		return qc.LastPendingValsetRequestByAddr(ctx, &gravitytypes.QueryLastPendingValsetRequestByAddrRequest{
+			Address: args[0],
+		})
+	})
+}
+
+func getQueryValsetConfirmsCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "valset-confirms [nonce]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show the confirms of the valset with a nonce",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, args []string) (proto.Message, error) {
+		nonce, err := parseNonce(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return qc.ValsetConfirmsByNonce(ctx, &gravitytypes.QueryValsetConfirmsByNonceRequest{Nonce: nonce})
+	})
+}
+
+func getQueryBatchesCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "batches",
+		Args:  cobra.NoArgs,
+		Short: "Show the outgoing batches waiting to be relayed to Ethereum",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, _ []string) (proto.Message, error) {
+		return qc.OutgoingTxBatches(ctx, &gravitytypes.QueryOutgoingTxBatchesRequest{})
+	})
+}
+
+func getQueryBatchCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "batch [token-contract] [nonce]",
+		Args:  cobra.ExactArgs(2),
+		Short: "Show the outgoing batch of a token with a nonce",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, args []string) (proto.Message, error) {
+		tokenContract, nonce, err := parseBatchArgs(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return qc.BatchRequestByNonce(ctx, &gravitytypes.QueryBatchRequestByNonceRequest{
+			Nonce:           nonce,
+			ContractAddress: tokenContract,
+		})
+	})
+}
+
+func getQueryBatchConfirmsCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "batch-confirms [token-contract] [nonce]",
+		Args:  cobra.ExactArgs(2),
+		Short: "Show the confirms of the outgoing batch of a token with a nonce",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, args []string) (proto.Message, error) {
+		tokenContract, nonce, err := parseBatchArgs(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return qc.BatchConfirms(ctx, &gravitytypes.QueryBatchConfirmsRequest{
+			Nonce:           nonce,
+			ContractAddress: tokenContract,
+		})
+	})
+}
+
+func getQueryPendingBatchesCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "pending-batches [orchestrator-address]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show the outgoing batches an orchestrator hasn't signed yet",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, args []string) (proto.Message, error) {
+		return qc.LastPendingBatchRequestByAddr(ctx, &gravitytypes.QueryLastPendingBatchRequestByAddrRequest{
+			Address: args[0],
+		})
+	})
+}
+
+func getQueryBatchFeesCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "batch-fees",
+		Args:  cobra.NoArgs,
+		Short: "Show the fees of the transactions waiting to be batched, by token",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, _ []string) (proto.Message, error) {
+		return qc.BatchFees(ctx, &gravitytypes.QueryBatchFeeRequest{})
+	})
+}
+
+func getQueryPendingSendToEthCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "pending-send-to-eth [sender-address]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show the transfers to Ethereum of a sender that weren't relayed yet",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, args []string) (proto.Message, error) {
+		return qc.GetPendingSendToEth(ctx, &gravitytypes.QueryPendingSendToEth{SenderAddress: args[0]})
+	})
+}
+
+func getQueryLastEventNonceCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "last-event-nonce [orchestrator-address]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show the nonce of the last Ethereum event claimed by an orchestrator",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, args []string) (proto.Message, error) {
+		return qc.LastEventNonceByAddr(ctx, &gravitytypes.QueryLastEventNonceByAddrRequest{Address: args[0]})
+	})
+}
+
+func getQueryAttestationsCmd() *cobra.Command {
+	var req gravitytypes.QueryAttestationsRequest
+
+	cmd := newGravityQueryCmd(&cobra.Command{
+		Use:   "attestations",
+		Args:  cobra.NoArgs,
+		Short: "Show the attestations of the Ethereum events claimed by the orchestrators",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, _ []string) (proto.Message, error) {
+		return qc.GetAttestations(ctx, &req)
+	})
+
+	cmd.Flags().Uint64Var(&req.Limit, "limit", 0, "Maximum number of attestations to show; zero means the chain default")
+	cmd.Flags().StringVar(&req.OrderBy, "order-by", "", "Order of the attestations by event nonce (asc|desc)")
+	cmd.Flags().StringVar(&req.ClaimType, "claim-type", "", "Only show the attestations of a claim type (e.g. CLAIM_TYPE_SEND_TO_COSMOS)") //nolint: lll
+	cmd.Flags().Uint64Var(&req.Nonce, "nonce", 0, "Only show the attestations of an event nonce")
+	cmd.Flags().Uint64Var(&req.Height, "height", 0, "Only show the attestations of an Ethereum block height")
+
+	return cmd
+}
+
+func getQueryDelegateKeysCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "delegate-keys [validator-address|orchestrator-address|eth-address]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show the delegate keys of a validator, given any of its addresses",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, args []string) (proto.Message, error) {
+		addr := args[0]
+
+		switch {
+		case ethcmn.IsHexAddress(addr):
+			return qc.GetDelegateKeyByEth(ctx, &gravitytypes.QueryDelegateKeysByEthAddress{EthAddress: addr})
+
+		case strings.HasPrefix(addr, sdk.GetConfig().GetBech32ValidatorAddrPrefix()):
+			return qc.GetDelegateKeyByValidator(ctx, &gravitytypes.QueryDelegateKeysByValidatorAddress{
+				ValidatorAddress: addr,
+			})
+
+		default:
+			return qc.GetDelegateKeyByOrchestrator(ctx, &gravitytypes.QueryDelegateKeysByOrchestratorAddress{
+				OrchestratorAddress: addr,
+			})
+		}
+	})
+}
+
+func getQueryDenomToERC20Cmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "denom-to-erc20 [denom]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show the ERC20 token of a Cosmos denom",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, args []string) (proto.Message, error) {
+		return qc.DenomToERC20(ctx, &gravitytypes.QueryDenomToERC20Request{Denom: args[0]})
+	})
+}
+
+func getQueryERC20ToDenomCmd() *cobra.Command {
+	return newGravityQueryCmd(&cobra.Command{
+		Use:   "erc20-to-denom [erc20]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show the Cosmos denom of an ERC20 token",
+	}, func(ctx context.Context, qc gravitytypes.QueryClient, args []string) (proto.Message, error) {
+		return qc.ERC20ToDenom(ctx, &gravitytypes.QueryERC20ToDenomRequest{Erc20: args[0]})
+	})
+}
+
+// gravityQueryFn queries the Gravity module with the args of a command.
+type gravityQueryFn func(ctx context.Context, qc gravitytypes.QueryClient, args []string) (proto.Message, error)
+
+// newGravityQueryCmd sets the command to run the query against the Cosmos gRPC endpoint and print its response.
+func newGravityQueryCmd(cmd *cobra.Command, query gravityQueryFn) *cobra.Command {
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		konfig, err := parseServerConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		logger, err := getLogger(konfig)
+		if err != nil {
+			return err
+		}
+
+		cosmosGRPC, err := parseURL(logger, konfig, flagCosmosGRPC)
+		if err != nil {
+			return err
+		}
+
+		// Queries don't need a keyring.
+		clientCtx, err := client.NewClientContext("", "", nil)
+		if err != nil {
+			return err
+		}
+
+		daemonClient, err := client.NewCosmosClient(clientCtx, logger, cosmosGRPC)
+		if err != nil {
+			return err
+		}
+		defer daemonClient.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), konfig.Duration(flagSvcWaitTimeout))
+		defer cancel()
+
+		res, err := query(ctx, gravitytypes.NewQueryClient(daemonClient.QueryClient()), args)
+		if err != nil {
+			return err
+		}
+
+		return printProto(cmd.OutOrStdout(), clientCtx.Codec, konfig, res)
+	}
+
+	cmd.Flags().AddFlagSet(queryFlagSet())
+
+	return cmd
+}
+
+func queryFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.String(flagCosmosGRPC, "tcp://localhost:9090", "The gRPC endpoint of a cosmos node")
+	fs.StringP(flagOutput, "o", outputText, "Output format (text|json|yaml)")
+
+	return fs
+}
+
+// printProto writes the message in the output format of the config: JSON and YAML use the JSON mapping of protobuf,
+// text the protobuf text format.
+func printProto(w io.Writer, cdc codec.JSONCodec, konfig *koanf.Koanf, msg proto.Message) error {
+	var (
+		out []byte
+		err error
+	)
+
+	switch format := konfig.String(flagOutput); format {
+	case outputText:
+		out = []byte(proto.MarshalTextString(msg))
+
+	case outputJSON:
+		out, err = cdc.MarshalJSON(msg)
+		if err == nil {
+			out = append(out, '\n')
+		}
+
+	case outputYAML:
+		out, err = cdc.MarshalJSON(msg)
+		if err == nil {
+			out, err = yaml.JSONToYAML(out)
+		}
+
+	default:
+		return fmt.Errorf("invalid output format: %s", format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to format the response: %w", err)
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+// parseNonce parses the nonce of a valset or batch.
+func parseNonce(arg string) (uint64, error) {
+	nonce, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid nonce %s: %w", arg, err)
+	}
+
+	return nonce, nil
+}
+
+// parseBatchArgs parses the token contract and the nonce identifying a batch.
+func parseBatchArgs(args []string) (string, uint64, error) {
+	if !ethcmn.IsHexAddress(args[0]) {
+		return "", 0, fmt.Errorf("invalid token contract address: %s", args[0])
+	}
+
+	nonce, err := parseNonce(args[1])
+	if err != nil {
+		return "", 0, err
+	}
+
+	return ethcmn.HexToAddress(args[0]).Hex(), nonce, nil
+}
@@ -0,0 +1,539 @@
+package peggo
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/knadh/koanf"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+
+	"github.com/umee-network/peggo/cmd/peggo/client"
+	"github.com/umee-network/peggo/orchestrator/analytics"
+	"github.com/umee-network/peggo/orchestrator/coingecko"
+	"github.com/umee-network/peggo/orchestrator/emergency"
+	"github.com/umee-network/peggo/orchestrator/ethereum/committer"
+	gravity "github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	"github.com/umee-network/peggo/orchestrator/ethereum/provider"
+	"github.com/umee-network/peggo/orchestrator/ethereum/tracker"
+	"github.com/umee-network/peggo/orchestrator/oracle"
+	"github.com/umee-network/peggo/orchestrator/relayer"
+	"github.com/umee-network/peggo/orchestrator/symbols"
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+func getRelayerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "relayer [gravity-addr]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Starts a standalone relayer",
+		Long: `Starts a standalone relayer that relays valset updates and transaction batches
+to Ethereum. Unlike the orchestrator, it doesn't require a validator key: it only
+needs read-only access to the Cosmos gRPC endpoint and an Ethereum key to pay
+for the relays.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(konfig)
+			if err != nil {
+				return err
+			}
+
+			if konfig.Bool(flagEthUseLedger) {
+				return fmt.Errorf("cannot use Ledger for relayer")
+			}
+
+			if !isRelaying(konfig) {
+				return fmt.Errorf("nothing to relay; set --%s and/or --%s", flagRelayBatches, flagValsetRelayMode)
+			}
+
+			cosmosGRPC, err := parseURL(logger, konfig, flagCosmosGRPC)
+			if err != nil {
+				return err
+			}
+
+			// The relayer only queries the chain, so it doesn't need a keyring nor a Tendermint RPC endpoint.
+			clientCtx, err := client.NewClientContext(konfig.String(flagCosmosChainID), "", nil)
+			if err != nil {
+				return err
+			}
+
+			daemonClient, err := client.NewCosmosClient(clientCtx, logger, cosmosGRPC)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(os.Stderr, "Waiting for cosmos gRPC service...")
+			time.Sleep(time.Second)
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			gRPCConn := daemonClient.QueryClient()
+			waitForService(ctx, gRPCConn)
+
+			gravityQuerier := gravitytypes.NewQueryClient(gRPCConn)
+
+			gravityParams, err := getGravityParams(gRPCConn)
+			if err != nil {
+				return fmt.Errorf("failed to query for Gravity params: %w", err)
+			}
+
+			ethKeyFromAddress, signerFn, _, err := initEthereumAccountsManager(logger, gravityParams.BridgeChainId, konfig)
+			if err != nil {
+				return fmt.Errorf("failed to initialize Ethereum account: %w", err)
+			}
+
+			ctx, cancel = context.WithCancel(context.Background())
+			// listen for and trap any OS signal to gracefully shutdown and exit
+			trapSignal(cancel)
+
+			gravityContract, err := newGravityContract(ctx, logger, konfig, args[0], ethKeyFromAddress, signerFn)
+			if err != nil {
+				return err
+			}
+
+			// gravityParams.AverageEthereumBlockTime is in milliseconds.
+			averageEthBlockTime := time.Duration(gravityParams.AverageEthereumBlockTime) * time.Millisecond
+
+			symbolRetriever, err := newSymbolRetriever(logger, konfig, gravityContract)
+			if err != nil {
+				return fmt.Errorf("failed to create symbol retriever: %w", err)
+			}
+
+			o, err := newPriceSources(ctx, logger, konfig, gravityContract.Provider(), gRPCConn)
+			if err != nil {
+				return err
+			}
+
+			logger = logger.With().Str("relayer_ethereum_addr", ethKeyFromAddress.String()).Logger()
+
+			metricsRegistry := newMetricsRegistry(konfig)
+
+			relayAnalytics, err := newRelayAnalytics(logger, konfig, gravityContract, ethKeyFromAddress, metricsRegistry)
+			if err != nil {
+				return fmt.Errorf("failed to create relay analytics: %w", err)
+			}
+
+			em, err := newEmergency(logger, konfig)
+			if err != nil {
+				return fmt.Errorf("failed to create emergency response: %w", err)
+			}
+
+			gravityRelayer, err := newGravityRelayer(
+				logger,
+				konfig,
+				gravityQuerier,
+				gravityContract,
+				averageEthBlockTime,
+				symbolRetriever,
+				o,
+				relayAnalytics,
+				em,
+			)
+			if err != nil {
+				return err
+			}
+
+			balanceMonitor, err := newBalanceMonitor(
+				logger,
+				konfig,
+				gravityContract.Provider(),
+				ethKeyFromAddress,
+				true,
+				nil,
+				nil,
+				nil,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to create balance monitor: %w", err)
+			}
+
+			g, errCtx := errgroup.WithContext(ctx)
+
+			g.Go(func() error {
+				logger.Info().Msg("starting relayer...")
+				return gravityRelayer.Start(errCtx)
+			})
+
+			if balanceMonitor != nil {
+				g.Go(func() error {
+					return balanceMonitor.Start(errCtx)
+				})
+			}
+
+			if relayAnalytics != nil {
+				g.Go(func() error {
+					return relayAnalytics.Start(errCtx)
+				})
+			}
+
+			if metricsRegistry != nil {
+				g.Go(func() error {
+					return serveMetrics(errCtx, logger, konfig.String(flagMetricsListenAddr), metricsRegistry)
+				})
+			}
+
+			pendingTxWatcher, err := newPendingTxWatcher(logger, konfig, averageEthBlockTime)
+			if err != nil {
+				return err
+			}
+
+			if pendingTxWatcher != nil {
+				g.Go(func() error {
+					return gravityContract.WatchPendingTxs(errCtx, pendingTxWatcher)
+				})
+			}
+
+			return g.Wait()
+		},
+	}
+
+	cmd.Flags().String(flagCosmosChainID, "", "The chain ID of the cosmos network")
+	cmd.Flags().String(flagCosmosGRPC, "tcp://localhost:9090", "The gRPC endpoint of a cosmos node")
+	cmd.Flags().AddFlagSet(ethereumKeyOptsFlagSet())
+	cmd.Flags().AddFlagSet(ethereumOptsFlagSet())
+	cmd.Flags().AddFlagSet(relayerFlagSet())
+
+	return cmd
+}
+
+// isRelaying returns true if batches or valset updates are relayed to Ethereum.
+func isRelaying(konfig *koanf.Koanf) bool {
+	return konfig.Bool(flagRelayBatches) || konfig.String(flagValsetRelayMode) != relayer.ValsetRelayModeNone.String()
+}
+
+// newGravityContract connects to the Ethereum node and returns the Gravity contract, sending transactions with the
+// given Ethereum key. The context stops the work the Ethereum provider does in the background.
+func newGravityContract(
+	ctx context.Context,
+	logger zerolog.Logger,
+	konfig *koanf.Koanf,
+	gravityAddrArg string,
+	ethKeyFromAddress ethcmn.Address,
+	signerFn bind.SignerFn,
+) (gravity.Contract, error) {
+	ethRPCEndpoint := konfig.String(flagEthRPC)
+	ethRPC, err := ethrpc.Dial(ethRPCEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial Ethereum RPC node: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Connected to Ethereum RPC: %s\n", redactURL(ethRPCEndpoint))
+	ethProvider := provider.NewEVMProvider(ethRPC)
+
+	if konfig.String(flagEthBundleRelay) != "" {
+		ethProvider, err = newBundleProvider(ctx, logger, konfig, ethProvider)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bundle provider: %w", err)
+		}
+	}
+
+	ethGasPriceAdjustment := konfig.Float64(flagEthGasAdjustment)
+	ethGasLimitAdjustment := konfig.Float64(flagEthGasLimitAdjustment)
+	ethCommitter, err := committer.NewEthCommitter(
+		logger,
+		ethKeyFromAddress,
+		ethGasPriceAdjustment,
+		ethGasLimitAdjustment,
+		signerFn,
+		ethProvider,
+		committer.OptionLegacyTx(konfig.Bool(flagEthLegacyTx)),
+		committer.OptionFeeHistory(
+			uint64(konfig.Int64(flagEthFeeHistoryBlocks)),
+			konfig.Float64(flagEthFeeHistoryPercentile),
+		),
+	)
+	if err != nil && err != grpc.ErrServerStopped {
+		return nil, fmt.Errorf("failed to create Ethereum committer: %w", err)
+	}
+
+	if !ethcmn.IsHexAddress(gravityAddrArg) {
+		return nil, fmt.Errorf("invalid gravity address: %s", gravityAddrArg)
+	}
+	gravityAddr := ethcmn.HexToAddress(gravityAddrArg)
+
+	ethGravity, err := wrappers.NewGravity(gravityAddr, ethCommitter.Provider())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a new instance of Gravity: %w", err)
+	}
+
+	gravityContract, err := gravity.NewGravityContract(
+		logger,
+		ethCommitter,
+		gravityAddr,
+		ethGravity,
+		gravity.OptionDefaultDecimals(uint8(konfig.Int(flagTokenDefaultDecimals))),
+		gravity.OptionUnreadableTokenTTL(konfig.Duration(flagUnreadableTokenTTL)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Ethereum committer: %w", err)
+	}
+
+	return gravityContract, nil
+}
+
+// newSymbolRetriever returns the retriever of the symbols the tokens are priced with. Symbols are read on-chain,
+// unless overridden, falling back to CoinGecko if enabled.
+func newSymbolRetriever(
+	logger zerolog.Logger,
+	konfig *koanf.Koanf,
+	reader symbols.SymbolReader,
+) (*symbols.Retriever, error) {
+	opts := []symbols.Option{
+		symbols.OptionOverridesFile(konfig.String(flagSymbolOverrides)),
+		symbols.OptionCacheFile(konfig.String(flagSymbolCache)),
+	}
+
+	if konfig.Bool(flagSymbolCoinGecko) {
+		opts = append(opts, symbols.OptionFallback(coingecko.NewCoingecko(logger, &coingecko.Config{
+			BaseURL: konfig.String(flagCoinGeckoAPI),
+		})))
+	}
+
+	return symbols.NewRetriever(logger, reader, opts...)
+}
+
+// newPriceSources returns the oracle used to price relays. The oracle aggregates the price sources of the price
+// sources file if it's set, or the price-feeder providers otherwise. If DEX pools are configured, the oracle falls
+// back to them for the tokens it can't price.
+func newPriceSources(
+	ctx context.Context,
+	logger zerolog.Logger,
+	konfig *koanf.Koanf,
+	ethCaller bind.ContractCaller,
+	cosmosConn *grpc.ClientConn,
+) (relayer.Oracle, error) {
+	providers := stringsToProviderName(konfig.Strings(flagOracleProviders))
+
+	var o relayer.Oracle
+	if priceSourcesPath := konfig.String(flagPriceSources); priceSourcesPath != "" {
+		sourcesConfig, err := oracle.LoadSourcesConfig(priceSourcesPath)
+		if err != nil {
+			return nil, err
+		}
+
+		o, err = oracle.NewRegistry().NewOracle(ctx, oracle.SourceDeps{
+			Logger:               logger,
+			CosmosConn:           cosmosConn,
+			PriceFeederProviders: providers,
+			MaxPriceAge:          konfig.Duration(flagOracleMaxPriceAge),
+		}, sourcesConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create price sources: %w", err)
+		}
+	} else {
+		var err error
+		o, err = oracle.New(ctx, logger.With().Str("module", "oracle").Logger(), providers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := o.SubscribeSymbols(oracle.SymbolETH); err != nil {
+		return nil, err
+	}
+
+	dexPoolsPath := konfig.String(flagDexPools)
+	if dexPoolsPath == "" {
+		return o, nil
+	}
+
+	dexConfig, err := oracle.LoadDexConfig(dexPoolsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	minLiquidity, err := sdk.NewDecFromStr(konfig.String(flagDexMinLiquidity))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DEX min liquidity: %w", err)
+	}
+
+	dexOracle, err := oracle.NewDexOracle(logger, ethCaller, dexConfig, minLiquidity, o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DEX oracle: %w", err)
+	}
+
+	return relayer.NewFallbackOracle(o, dexOracle), nil
+}
+
+// newGravityRelayer returns the relayer configured by the relayer flags.
+func newGravityRelayer(
+	logger zerolog.Logger,
+	konfig *koanf.Koanf,
+	gravityQuerier gravitytypes.QueryClient,
+	gravityContract gravity.Contract,
+	averageEthBlockTime time.Duration,
+	symbolRetriever relayer.SymbolRetriever,
+	o relayer.Oracle,
+	relayRecorder analytics.Recorder,
+	em emergency.Emergency,
+) (relayer.GravityRelayer, error) {
+	// We multiply the relayer loop multiplier by the ETH block time.
+	ethBlockTimeF64 := float64(averageEthBlockTime.Milliseconds())
+	relayerLoopMultiplier := konfig.Float64(flagRelayerLoopMultiplier)
+
+	// Here we cast the float64 to a Duration (int64); as we are dealing with ms, we'll lose as much as 1ms.
+	relayerLoopDuration := time.Duration(ethBlockTimeF64*relayerLoopMultiplier) * time.Millisecond
+
+	valsetRelayMode, err := validateRelayValsetsMode(konfig.String(flagValsetRelayMode))
+	if err != nil {
+		return nil, err
+	}
+
+	relayerOpts := []func(relayer.GravityRelayer){
+		relayer.SetSymbolRetriever(symbolRetriever),
+		relayer.SetOracle(o),
+		relayer.SetMaxPriceAge(konfig.Duration(flagOracleMaxPriceAge)),
+		relayer.SetRelayOutdatedValsets(konfig.Bool(flagValsetRelayOutdated)),
+		relayer.SetBatchGasBudget(uint64(konfig.Int64(flagRelayerGasBudget))),
+		relayer.SetEmergency(em),
+	}
+
+	trackerOpts := []tracker.Option{
+		tracker.OptionFeeBump(uint64(konfig.Int64(flagEthGasPriceBump))),
+		tracker.OptionMaxGasPrice(big.NewInt(konfig.Int64(flagEthMaxGasPrice))),
+		tracker.OptionRevertDecoder(gravity.DecodeRevertReason),
+	}
+
+	// Relayed txs are always tracked until they get a receipt, but only replaced if enabled.
+	if stuckTXBlocks := uint64(konfig.Int64(flagEthStuckTXBlocks)); stuckTXBlocks > 0 {
+		// A tx waiting for its bundle, or for the public mempool fallback, is unknown to the node. It must not be
+		// replaced or given up on before it can land, or its nonce would be reused.
+		if konfig.String(flagEthBundleRelay) != "" {
+			window := provider.BundleFallbackWindow(uint64(konfig.Int64(flagEthBundleFallbackBlocks)))
+			if stuckTXBlocks < window {
+				logger.Info().
+					Uint64("stuck_tx_blocks", stuckTXBlocks).
+					Uint64("bundle_fallback_window", window).
+					Msg("raising the stuck tx blocks to the bundle fallback window")
+
+				stuckTXBlocks = window
+			}
+		}
+
+		trackerOpts = append(trackerOpts, tracker.OptionStuckBlocks(stuckTXBlocks))
+	} else {
+		trackerOpts = append(trackerOpts, tracker.OptionReplace(false))
+	}
+
+	txTracker, err := tracker.NewTracker(logger, gravityContract, trackerOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Ethereum tx tracker: %w", err)
+	}
+
+	relayerOpts = append(relayerOpts, relayer.SetTxTracker(txTracker))
+
+	if konfig.String(flagEthSpendBudget) != "" {
+		spendBudget, err := newSpendBudget(logger, konfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ETH spend budget: %w", err)
+		}
+
+		relayerOpts = append(relayerOpts, relayer.SetSpendBudget(spendBudget))
+	}
+
+	if relayRecorder != nil {
+		relayerOpts = append(relayerOpts, relayer.SetRelayRecorder(relayRecorder))
+	}
+
+	return relayer.NewGravityRelayer(
+		logger,
+		gravityQuerier,
+		gravityContract,
+		valsetRelayMode,
+		konfig.Bool(flagRelayBatches),
+		relayerLoopDuration,
+		konfig.Duration(flagEthPendingTXWait),
+		konfig.Float64(flagProfitMultiplier),
+		relayerOpts...,
+	), nil
+}
+
+// newEmergency returns the emergency response to a valset hijack configured by the emergency flags.
+func newEmergency(logger zerolog.Logger, konfig *koanf.Koanf) (emergency.Emergency, error) {
+	return emergency.NewEmergency(
+		logger,
+		emergency.OptionAction(konfig.String(flagEmergencyAction)),
+		emergency.OptionWebhook(konfig.String(flagEmergencyWebhook)),
+		emergency.OptionEvidenceDir(konfig.String(flagEmergencyEvidenceDir)),
+	)
+}
+
+// newMetricsRegistry returns the registry of the metrics served on the metrics address, or nil if it's not set.
+func newMetricsRegistry(konfig *koanf.Koanf) *prometheus.Registry {
+	if konfig.String(flagMetricsListenAddr) == "" {
+		return nil
+	}
+
+	return prometheus.NewRegistry()
+}
+
+// serveMetrics serves the metrics of the registry on the given address until the context is done.
+func serveMetrics(ctx context.Context, logger zerolog.Logger, listenAddr string, registry *prometheus.Registry) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	server := &http.Server{
+		Addr:              listenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	logger.Info().Str("listen_addr", listenAddr).Msg("serving metrics")
+
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("failed to serve metrics: %w", err)
+	}
+
+	return nil
+}
+
+// newRelayAnalytics returns the analytics of the relays made to the Gravity contract if a stats file or a metrics
+// address is set, or nil otherwise.
+func newRelayAnalytics(
+	logger zerolog.Logger,
+	konfig *koanf.Koanf,
+	gravityContract gravity.Contract,
+	ethKeyFromAddress ethcmn.Address,
+	metricsRegistry *prometheus.Registry,
+) (analytics.RelayAnalytics, error) {
+	statsFile := konfig.String(flagRelayStatsFile)
+	if statsFile == "" && metricsRegistry == nil {
+		return nil, nil
+	}
+
+	analyticsOpts := []analytics.Option{analytics.OptionStatsFile(statsFile)}
+	if metricsRegistry != nil {
+		analyticsOpts = append(analyticsOpts, analytics.OptionMetrics(metricsRegistry))
+	}
+
+	return analytics.NewRelayAnalytics(
+		logger,
+		gravityContract.Provider(),
+		gravityContract.Address(),
+		ethKeyFromAddress,
+		analyticsOpts...,
+	)
+}
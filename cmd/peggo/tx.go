@@ -1,14 +1,366 @@
 package peggo
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdkclient "github.com/cosmos/cosmos-sdk/client"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/gogo/protobuf/proto"
+	"github.com/knadh/koanf"
+	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
+
+	"github.com/umee-network/peggo/cmd/peggo/client"
 )
 
 func getTxCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "tx",
 		Short: "Transactions for Gravity Bridge governance and maintenance on the Cosmos chain",
+		Long: `Transactions for Gravity Bridge governance and maintenance on the Cosmos chain.
+
+The transactions are signed with the key set by the Cosmos keyring flags. With
+--dry-run, the transaction is simulated and printed unsigned instead of being
+broadcast. With --generate-only, the keyring isn't needed: --cosmos-from may be
+an address, and the unsigned transaction is printed to be signed offline.`,
+	}
+
+	cmd.PersistentFlags().Bool(flagDryRun, false, "Simulate the transaction and print it unsigned, without broadcasting it")
+	cmd.PersistentFlags().Bool(flagGenerateOnly, false, "Print the unsigned transaction of the --cosmos-from address without using the keyring")        //nolint: lll
+	cmd.PersistentFlags().String(flagCosmosFeeGranter, "", "Set an (optional) fee granter address that will pay for Cosmos fees (feegrant must exist)") //nolint: lll
+	cmd.PersistentFlags().StringP(flagOutput, "o", outputText, "Output format of the transaction response (text|json|yaml)")
+	cmd.PersistentFlags().AddFlagSet(cosmosFlagSet())
+	cmd.PersistentFlags().AddFlagSet(cosmosKeyringFlagSet())
+
+	cmd.AddCommand(
+		getSetOrchestratorAddressCmd(),
+		getSendToEthCmd(),
+		getCancelSendToEthCmd(),
+		getRequestBatchCmd(),
+		getSubmitBadSignatureEvidenceCmd(),
+	)
+
+	return cmd
+}
+
+func getSetOrchestratorAddressCmd() *cobra.Command {
+	return newGravityTxCmd(&cobra.Command{
+		Use:   "set-orchestrator-address [orchestrator-address] [eth-address]",
+		Args:  cobra.ExactArgs(2),
+		Short: "Set the delegate orchestrator and Ethereum addresses of the validator signing the transaction",
+	}, func(_ context.Context, txCtx gravityTxContext, args []string) (sdk.Msg, error) {
+		orchAddress, err := sdk.AccAddressFromBech32(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid orchestrator address: %w", err)
+		}
+
+		if !ethcmn.IsHexAddress(args[1]) {
+			return nil, fmt.Errorf("invalid Ethereum address: %s", args[1])
+		}
+
+		return &gravitytypes.MsgSetOrchestratorAddress{
+			Validator:    sdk.ValAddress(txCtx.clientCtx.GetFromAddress()).String(),
+			Orchestrator: orchAddress.String(),
+			EthAddress:   ethcmn.HexToAddress(args[1]).Hex(),
+		}, nil
+	})
+}
+
+func getSendToEthCmd() *cobra.Command {
+	cmd := newGravityTxCmd(&cobra.Command{
+		Use:   "send-to-eth [eth-dest] [amount]",
+		Args:  cobra.ExactArgs(2),
+		Short: "Send coins to an Ethereum address",
+		Long: `Send coins to an Ethereum address. Without --bridge-fee, the bridge fee is the
+average fee of the transfers of the same coin waiting to be batched, so the
+transfer is as attractive to batch as the others.`,
+	}, func(ctx context.Context, txCtx gravityTxContext, args []string) (sdk.Msg, error) {
+		if !ethcmn.IsHexAddress(args[0]) {
+			return nil, fmt.Errorf("invalid Ethereum address: %s", args[0])
+		}
+
+		amount, err := sdk.ParseCoinNormalized(args[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount: %w", err)
+		}
+
+		recommendedFee, ok, err := recommendBridgeFee(ctx, txCtx.queryClient, amount.Denom)
+		if err != nil {
+			return nil, err
+		}
+
+		var bridgeFee sdk.Coin
+
+		switch v := txCtx.konfig.String(flagBridgeFee); {
+		case v != "":
+			bridgeFee, err = sdk.ParseCoinNormalized(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid bridge fee: %w", err)
+			}
+
+			if bridgeFee.Denom != amount.Denom {
+				return nil, fmt.Errorf("the bridge fee must be paid in %s", amount.Denom)
+			}
+
+			if ok && bridgeFee.IsLT(recommendedFee) {
+				txCtx.logger.Warn().
+					Str("bridge_fee", bridgeFee.String()).
+					Str("recommended_bridge_fee", recommendedFee.String()).
+					Msg("the bridge fee is lower than the recommended fee; the transfer may wait longer to be batched")
+			}
+
+		case ok:
+			bridgeFee = recommendedFee
+			txCtx.logger.Info().Str("bridge_fee", bridgeFee.String()).Msg("using the recommended bridge fee")
+
+		default:
+			return nil, fmt.Errorf(
+				"no transfers of %s are waiting to be batched to recommend a bridge fee; set --%s",
+				amount.Denom,
+				flagBridgeFee,
+			)
+		}
+
+		return &gravitytypes.MsgSendToEth{
+			Sender:    txCtx.clientCtx.GetFromAddress().String(),
+			EthDest:   ethcmn.HexToAddress(args[0]).Hex(),
+			Amount:    amount,
+			BridgeFee: bridgeFee,
+		}, nil
+	})
+
+	cmd.Flags().String(flagBridgeFee, "", "The fee paid to the relayer of the batch of the transfer (e.g. 1000uumee); empty means the recommended fee") //nolint: lll
+
+	return cmd
+}
+
+func getCancelSendToEthCmd() *cobra.Command {
+	return newGravityTxCmd(&cobra.Command{
+		Use:   "cancel-send-to-eth [transaction-id]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Cancel a transfer to Ethereum that wasn't batched yet, refunding its amount and bridge fee",
+	}, func(_ context.Context, txCtx gravityTxContext, args []string) (sdk.Msg, error) {
+		id, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction ID %s: %w", args[0], err)
+		}
+
+		return &gravitytypes.MsgCancelSendToEth{
+			TransactionId: id,
+			Sender:        txCtx.clientCtx.GetFromAddress().String(),
+		}, nil
+	})
+}
+
+func getRequestBatchCmd() *cobra.Command {
+	return newGravityTxCmd(&cobra.Command{
+		Use:   "request-batch [denom]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Request a batch of the transfers of a denom waiting to be batched",
+	}, func(_ context.Context, txCtx gravityTxContext, args []string) (sdk.Msg, error) {
+		return &gravitytypes.MsgRequestBatch{
+			Sender: txCtx.clientCtx.GetFromAddress().String(),
+			Denom:  args[0],
+		}, nil
+	})
+}
+
+func getSubmitBadSignatureEvidenceCmd() *cobra.Command {
+	return newGravityTxCmd(&cobra.Command{
+		Use:   "submit-bad-signature-evidence [subject-file] [signature]",
+		Args:  cobra.ExactArgs(2),
+		Short: "Submit the evidence of a validator signing a valset, batch or logic call that was never on the chain",
+		Long: `Submit the evidence of a validator signing a valset, batch or logic call that was
+never on the chain. The subject file holds the signed valset, batch or logic call in
+JSON, with its type (e.g. {"@type": "/gravity.v1.Valset", ...}), and the signature
+is hex encoded.`,
+	}, func(_ context.Context, txCtx gravityTxContext, args []string) (sdk.Msg, error) {
+		subjectJSON, err := os.ReadFile(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the subject file: %w", err)
+		}
+
+		var subject gravitytypes.EthereumSigned
+		if err := txCtx.clientCtx.Codec.UnmarshalInterfaceJSON(subjectJSON, &subject); err != nil {
+			return nil, fmt.Errorf("failed to decode the subject: %w", err)
+		}
+
+		subjectMsg, ok := subject.(proto.Message)
+		if !ok {
+			return nil, fmt.Errorf("invalid subject type %T", subject)
+		}
+
+		subjectAny, err := codectypes.NewAnyWithValue(subjectMsg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack the subject: %w", err)
+		}
+
+		if _, err := hexToBytes(args[1]); err != nil {
+			return nil, fmt.Errorf("invalid signature: %w", err)
+		}
+
+		return &gravitytypes.MsgSubmitBadSignatureEvidence{
+			Subject:   subjectAny,
+			Signature: args[1],
+			Sender:    txCtx.clientCtx.GetFromAddress().String(),
+		}, nil
+	})
+}
+
+// gravityTxContext holds what the msgs of the tx commands are built with.
+type gravityTxContext struct {
+	konfig      *koanf.Koanf
+	logger      zerolog.Logger
+	clientCtx   sdkclient.Context
+	queryClient gravitytypes.QueryClient
+}
+
+// gravityMsgFn builds the msg of a tx command from its args.
+type gravityMsgFn func(ctx context.Context, txCtx gravityTxContext, args []string) (sdk.Msg, error)
+
+// newGravityTxCmd sets the command to broadcast the msg built from its args, or to print the unsigned tx with
+// --dry-run or --generate-only.
+func newGravityTxCmd(cmd *cobra.Command, buildMsg gravityMsgFn) *cobra.Command {
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		konfig, err := parseServerConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		logger, err := getLogger(konfig)
+		if err != nil {
+			return err
+		}
+
+		dryRun, generateOnly := konfig.Bool(flagDryRun), konfig.Bool(flagGenerateOnly)
+		if dryRun && generateOnly {
+			return fmt.Errorf("--%s and --%s can't be used together", flagDryRun, flagGenerateOnly)
+		}
+
+		clientCtx, err := newTxClientContext(konfig, generateOnly)
+		if err != nil {
+			return err
+		}
+
+		cosmosGRPC, err := parseURL(logger, konfig, flagCosmosGRPC)
+		if err != nil {
+			return err
+		}
+
+		daemonClient, err := client.NewCosmosClient(clientCtx, logger, cosmosGRPC, newCosmosClientOptions(konfig)...)
+		if err != nil {
+			return err
+		}
+		defer daemonClient.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), konfig.Duration(flagSvcWaitTimeout))
+		defer cancel()
+
+		msg, err := buildMsg(ctx, gravityTxContext{
+			konfig:      konfig,
+			logger:      logger,
+			clientCtx:   clientCtx,
+			queryClient: gravitytypes.NewQueryClient(daemonClient.QueryClient()),
+		}, args)
+		if err != nil {
+			return err
+		}
+
+		if err := msg.ValidateBasic(); err != nil {
+			return fmt.Errorf("invalid %s: %w", sdk.MsgTypeURL(msg), err)
+		}
+
+		if dryRun || generateOnly {
+			txJSON, err := daemonClient.GenerateUnsignedTx(msg)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(txJSON))
+			return err
+		}
+
+		res, err := daemonClient.SyncBroadcastMsg(msg)
+		if err != nil {
+			return err
+		}
+
+		return printProto(cmd.OutOrStdout(), clientCtx.Codec, konfig, res)
 	}
 
 	return cmd
 }
+
+// newTxClientContext returns the client context of the tx commands. With generate only, the keyring isn't used when
+// --cosmos-from is an address, and the key isn't loaded otherwise: only the from address is set.
+func newTxClientContext(konfig *koanf.Koanf, generateOnly bool) (sdkclient.Context, error) {
+	var (
+		fromAddress sdk.AccAddress
+		kb          keyring.Keyring
+		err         error
+	)
+
+	if addr, addrErr := sdk.AccAddressFromBech32(konfig.String(flagCosmosFrom)); generateOnly && addrErr == nil {
+		fromAddress = addr
+	} else {
+		fromAddress, kb, err = initCosmosKeyring(konfig)
+		if err != nil {
+			return sdkclient.Context{}, fmt.Errorf("failed to initialize Cosmos keyring: %w", err)
+		}
+	}
+
+	if generateOnly {
+		kb = nil
+	}
+
+	clientCtx, err := client.NewClientContext(konfig.String(flagCosmosChainID), fromAddress.String(), kb)
+	if err != nil {
+		return sdkclient.Context{}, err
+	}
+
+	clientCtx = clientCtx.WithFromAddress(fromAddress)
+
+	if v := konfig.String(flagCosmosFeeGranter); len(v) > 0 {
+		feeGranter, err := sdk.AccAddressFromBech32(v)
+		if err != nil {
+			return sdkclient.Context{}, fmt.Errorf("failed to parse fee granter address: %w", err)
+		}
+
+		clientCtx = clientCtx.WithFeeGranterAddress(feeGranter)
+	}
+
+	return clientCtx, nil
+}
+
+// recommendBridgeFee returns the average bridge fee of the transfers of the denom waiting to be batched, if any.
+func recommendBridgeFee(
+	ctx context.Context,
+	queryClient gravitytypes.QueryClient,
+	denom string,
+) (sdk.Coin, bool, error) {
+	erc20Res, err := queryClient.DenomToERC20(ctx, &gravitytypes.QueryDenomToERC20Request{Denom: denom})
+	if err != nil {
+		return sdk.Coin{}, false, fmt.Errorf("failed to query the ERC20 token of %s: %w", denom, err)
+	}
+
+	feesRes, err := queryClient.BatchFees(ctx, &gravitytypes.QueryBatchFeeRequest{})
+	if err != nil {
+		return sdk.Coin{}, false, fmt.Errorf("failed to query batch fees: %w", err)
+	}
+
+	token := ethcmn.HexToAddress(erc20Res.Erc20)
+	for _, fees := range feesRes.BatchFees {
+		if ethcmn.HexToAddress(fees.Token) == token && fees.TxCount > 0 {
+			return sdk.NewCoin(denom, fees.TotalFees.QuoRaw(int64(fees.TxCount))), true, nil
+		}
+	}
+
+	return sdk.Coin{}, false, nil
+}
@@ -4,12 +4,19 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"math/big"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/knadh/koanf"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
+
+	"github.com/umee-network/peggo/cmd/peggo/client"
 )
 
 func hexToBytes(str string) ([]byte, error) {
@@ -44,3 +51,51 @@ func waitForService(ctx context.Context, clientconn *grpc.ClientConn) {
 		}
 	}
 }
+
+// parseEthAmount parses an amount of ETH (e.g. "0.5") into wei.
+func parseEthAmount(amount string) (*big.Int, error) {
+	amountDec, err := decimal.NewFromString(amount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ETH amount %q: %w", amount, err)
+	}
+
+	if amountDec.IsNegative() {
+		return nil, fmt.Errorf("ETH amount can't be negative: %s", amount)
+	}
+
+	// Ethereum decimals are 18 and that's a constant.
+	return amountDec.Shift(18).BigInt(), nil
+}
+
+// newTendermintRPC returns a client of the Tendermint RPC endpoint, or nil if the endpoint isn't set. Transactions are
+// broadcast and queried through gRPC, so the endpoint is optional.
+func newTendermintRPC(logger zerolog.Logger, konfig *koanf.Koanf) (*rpchttp.HTTP, error) {
+	if konfig.String(flagTendermintRPC) == "" {
+		return nil, nil
+	}
+
+	tmRPCEndpoint, err := parseURL(logger, konfig, flagTendermintRPC)
+	if err != nil {
+		return nil, err
+	}
+
+	tmRPC, err := rpchttp.New(tmRPCEndpoint, "/websocket")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Tendermint RPC client: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Connected to Tendermint RPC: %s\n", redactURL(tmRPCEndpoint))
+
+	return tmRPC, nil
+}
+
+// newCosmosClientOptions returns the options of the Cosmos client for the gas and fee flags.
+func newCosmosClientOptions(konfig *koanf.Koanf) []client.CosmosClientOption {
+	return []client.CosmosClientOption{
+		client.OptionGasPrices(konfig.String(flagCosmosGasPrices)),
+		client.OptionGasAdjustment(konfig.Float64(flagCosmosGasAdjustment)),
+		client.OptionMaxGasPrices(konfig.String(flagCosmosMaxGasPrices)),
+		client.OptionFeeRetries(uint(konfig.Int64(flagCosmosFeeRetries)), uint64(konfig.Int64(flagCosmosGasPriceBump))),
+		client.OptionQueueRetries(uint(konfig.Int64(flagCosmosQueueRetries))),
+	}
+}
@@ -10,6 +10,7 @@ import (
 	client "github.com/cosmos/cosmos-sdk/client"
 	types "github.com/cosmos/cosmos-sdk/types"
 	gomock "github.com/golang/mock/gomock"
+	client0 "github.com/umee-network/peggo/cmd/peggo/client"
 	grpc "google.golang.org/grpc"
 )
 
@@ -109,6 +110,25 @@ func (mr *MockCosmosClientMockRecorder) FromAddress() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FromAddress", reflect.TypeOf((*MockCosmosClient)(nil).FromAddress))
 }
 
+// GenerateUnsignedTx mocks base method.
+func (m *MockCosmosClient) GenerateUnsignedTx(arg0 ...types.Msg) ([]byte, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range arg0 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GenerateUnsignedTx", varargs...)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateUnsignedTx indicates an expected call of GenerateUnsignedTx.
+func (mr *MockCosmosClientMockRecorder) GenerateUnsignedTx(arg0 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateUnsignedTx", reflect.TypeOf((*MockCosmosClient)(nil).GenerateUnsignedTx), arg0...)
+}
+
 // QueryClient mocks base method.
 func (m *MockCosmosClient) QueryClient() *grpc.ClientConn {
 	m.ctrl.T.Helper()
@@ -124,15 +144,16 @@ func (mr *MockCosmosClientMockRecorder) QueryClient() *gomock.Call {
 }
 
 // QueueBroadcastMsg mocks base method.
-func (m *MockCosmosClient) QueueBroadcastMsg(arg0 ...types.Msg) error {
+func (m *MockCosmosClient) QueueBroadcastMsg(arg0 ...types.Msg) (*client0.BroadcastHandle, error) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{}
 	for _, a := range arg0 {
 		varargs = append(varargs, a)
 	}
 	ret := m.ctrl.Call(m, "QueueBroadcastMsg", varargs...)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*client0.BroadcastHandle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // QueueBroadcastMsg indicates an expected call of QueueBroadcastMsg.
@@ -13,6 +13,7 @@ import (
 	types "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	common "github.com/ethereum/go-ethereum/common"
 	gomock "github.com/golang/mock/gomock"
+	committer "github.com/umee-network/peggo/orchestrator/ethereum/committer"
 	gravity "github.com/umee-network/peggo/orchestrator/ethereum/gravity"
 	provider "github.com/umee-network/peggo/orchestrator/ethereum/provider"
 )
@@ -85,11 +86,11 @@ func (mr *MockContractMockRecorder) EncodeValsetUpdate(arg0, arg1, arg2, arg3 in
 }
 
 // EstimateGas mocks base method.
-func (m *MockContract) EstimateGas(arg0 context.Context, arg1 common.Address, arg2 []byte) (uint64, *big.Int, error) {
+func (m *MockContract) EstimateGas(arg0 context.Context, arg1 common.Address, arg2 []byte) (uint64, committer.GasFees, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "EstimateGas", arg0, arg1, arg2)
 	ret0, _ := ret[0].(uint64)
-	ret1, _ := ret[1].(*big.Int)
+	ret1, _ := ret[1].(committer.GasFees)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
@@ -188,6 +189,21 @@ func (mr *MockContractMockRecorder) GetTxBatchNonce(arg0, arg1, arg2 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTxBatchNonce", reflect.TypeOf((*MockContract)(nil).GetTxBatchNonce), arg0, arg1, arg2)
 }
 
+// GetValsetCheckpoint mocks base method.
+func (m *MockContract) GetValsetCheckpoint(arg0 context.Context, arg1 common.Address) (common.Hash, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetValsetCheckpoint", arg0, arg1)
+	ret0, _ := ret[0].(common.Hash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetValsetCheckpoint indicates an expected call of GetValsetCheckpoint.
+func (mr *MockContractMockRecorder) GetValsetCheckpoint(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValsetCheckpoint", reflect.TypeOf((*MockContract)(nil).GetValsetCheckpoint), arg0, arg1)
+}
+
 // GetValsetNonce mocks base method.
 func (m *MockContract) GetValsetNonce(arg0 context.Context, arg1 common.Address) (*big.Int, error) {
 	m.ctrl.T.Helper()
@@ -232,7 +248,7 @@ func (mr *MockContractMockRecorder) Provider() *gomock.Call {
 }
 
 // SendTx mocks base method.
-func (m *MockContract) SendTx(arg0 context.Context, arg1 common.Address, arg2 []byte, arg3 uint64, arg4 *big.Int) (common.Hash, error) {
+func (m *MockContract) SendTx(arg0 context.Context, arg1 common.Address, arg2 []byte, arg3 uint64, arg4 committer.GasFees) (common.Hash, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SendTx", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].(common.Hash)
@@ -246,16 +262,31 @@ func (mr *MockContractMockRecorder) SendTx(arg0, arg1, arg2, arg3, arg4 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTx", reflect.TypeOf((*MockContract)(nil).SendTx), arg0, arg1, arg2, arg3, arg4)
 }
 
-// SubscribeToPendingTxs mocks base method.
-func (m *MockContract) SubscribeToPendingTxs(arg0 context.Context, arg1 string) error {
+// SendTxWithNonce mocks base method.
+func (m *MockContract) SendTxWithNonce(arg0 context.Context, arg1 uint64, arg2 common.Address, arg3 []byte, arg4 uint64, arg5 committer.GasFees) (common.Hash, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendTxWithNonce", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(common.Hash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendTxWithNonce indicates an expected call of SendTxWithNonce.
+func (mr *MockContractMockRecorder) SendTxWithNonce(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTxWithNonce", reflect.TypeOf((*MockContract)(nil).SendTxWithNonce), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// WatchPendingTxs mocks base method.
+func (m *MockContract) WatchPendingTxs(arg0 context.Context, arg1 gravity.PendingTxWatcher) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SubscribeToPendingTxs", arg0, arg1)
+	ret := m.ctrl.Call(m, "WatchPendingTxs", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SubscribeToPendingTxs indicates an expected call of SubscribeToPendingTxs.
-func (mr *MockContractMockRecorder) SubscribeToPendingTxs(arg0, arg1 interface{}) *gomock.Call {
+// WatchPendingTxs indicates an expected call of WatchPendingTxs.
+func (mr *MockContractMockRecorder) WatchPendingTxs(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeToPendingTxs", reflect.TypeOf((*MockContract)(nil).SubscribeToPendingTxs), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchPendingTxs", reflect.TypeOf((*MockContract)(nil).WatchPendingTxs), arg0, arg1)
 }
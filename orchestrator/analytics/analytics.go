@@ -0,0 +1,607 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/provider"
+	"github.com/umee-network/peggo/orchestrator/ethereum/tracker"
+	"github.com/umee-network/peggo/orchestrator/loops"
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+// Relay kinds
+const (
+	KindBatch  = "batch"
+	KindValset = "valset"
+)
+
+const (
+	// scanConfirmations is the number of blocks a relay event must be buried under before it's recorded, so reorgs
+	// don't count a relay twice.
+	scanConfirmations = uint64(6)
+	// maxBlocksPerScan is the maximum number of blocks whose events are fetched at once.
+	maxBlocksPerScan = uint64(2000)
+	// initialLookbackBlocks is the number of blocks scanned the first time, about a day of Ethereum blocks.
+	initialLookbackBlocks = uint64(7200)
+	// maxRecentRecords is the number of relays and attempts kept in the stats.
+	maxRecentRecords = 500
+)
+
+// Relay is a batch or a valset update relayed to Ethereum, by us or by another relayer.
+type Relay struct {
+	Kind          string         `json:"kind"`
+	Nonce         uint64         `json:"nonce"`
+	TokenContract ethcmn.Address `json:"token_contract"`
+	TxHash        ethcmn.Hash    `json:"tx_hash"`
+	Relayer       ethcmn.Address `json:"relayer"`
+	Ours          bool           `json:"ours"`
+	BlockNumber   uint64         `json:"block_number"`
+	Time          time.Time      `json:"time"`
+	GasPrice      *big.Int       `json:"gas_price"`
+	GasUsed       uint64         `json:"gas_used"`
+}
+
+// Attempt is a relay transaction sent by us. An attempt is wasted if another relayer relayed the same item or a newer
+// one of the same token first, or if the transaction reverted. Once settled, its gas price is the effective gas price
+// of the mined transaction, which may be a replacement with bumped fees.
+type Attempt struct {
+	Kind          string          `json:"kind"`
+	Nonce         uint64          `json:"nonce"`
+	TokenContract ethcmn.Address  `json:"token_contract"`
+	TxHash        ethcmn.Hash     `json:"tx_hash"`
+	Time          time.Time       `json:"time"`
+	GasPrice      *big.Int        `json:"gas_price"`
+	Outcome       string          `json:"outcome,omitempty"`
+	GasUsed       uint64          `json:"gas_used"`
+	Wasted        bool            `json:"wasted"`
+	LostTo        *ethcmn.Address `json:"lost_to,omitempty"`
+}
+
+// KindStats holds the relay counters of a relay kind.
+type KindStats struct {
+	Relayed        uint64 `json:"relayed"`
+	Won            uint64 `json:"won"`
+	Attempts       uint64 `json:"attempts"`
+	WastedAttempts uint64 `json:"wasted_attempts"`
+	// WastedWei is the ETH spent on wasted attempts.
+	WastedWei *big.Int `json:"wasted_wei"`
+}
+
+// CompetitorStats holds the relays made by another relayer.
+type CompetitorStats struct {
+	Relays    map[string]uint64 `json:"relays"` // kind => count
+	LastRelay time.Time         `json:"last_relay"`
+}
+
+// Stats holds the relay competition analytics. They're stored as JSON in the stats file.
+type Stats struct {
+	RelayerAddress   ethcmn.Address                      `json:"relayer_address"`
+	LastScannedBlock uint64                              `json:"last_scanned_block"`
+	Kinds            map[string]*KindStats               `json:"kinds"`
+	Competitors      map[ethcmn.Address]*CompetitorStats `json:"competitors"`
+	RecentRelays     []Relay                             `json:"recent_relays"`
+	RecentAttempts   []*Attempt                          `json:"recent_attempts"`
+}
+
+func newStats(relayerAddress ethcmn.Address) *Stats {
+	return &Stats{
+		RelayerAddress: relayerAddress,
+		Kinds:          map[string]*KindStats{},
+		Competitors:    map[ethcmn.Address]*CompetitorStats{},
+	}
+}
+
+// kind returns the counters of a relay kind, creating them if needed.
+func (s *Stats) kind(kind string) *KindStats {
+	ks, ok := s.Kinds[kind]
+	if !ok {
+		ks = &KindStats{WastedWei: big.NewInt(0)}
+		s.Kinds[kind] = ks
+	}
+
+	return ks
+}
+
+// LoadStats reads the stats stored in a file.
+func LoadStats(path string) (*Stats, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read relay stats file")
+	}
+
+	stats := &Stats{}
+	if err := json.Unmarshal(bz, stats); err != nil {
+		return nil, errors.Wrap(err, "failed to decode relay stats file")
+	}
+
+	if stats.Kinds == nil {
+		stats.Kinds = map[string]*KindStats{}
+	}
+	if stats.Competitors == nil {
+		stats.Competitors = map[ethcmn.Address]*CompetitorStats{}
+	}
+	for _, ks := range stats.Kinds {
+		if ks.WastedWei == nil {
+			ks.WastedWei = big.NewInt(0)
+		}
+	}
+
+	return stats, nil
+}
+
+// Recorder receives the relay transactions sent by the relayer.
+type Recorder interface {
+	// RecordAttempt adds a relay transaction sent by us.
+	RecordAttempt(kind string, tokenContract ethcmn.Address, nonce uint64, txHash ethcmn.Hash, gasPrice *big.Int)
+
+	// SettleAttempt records the final outcome of a relay transaction sent by us.
+	SettleAttempt(txHash ethcmn.Hash, result tracker.Result)
+}
+
+// RelayAnalytics keeps track of who relays each batch and valset update, by scanning the events of the Gravity
+// contract, and of how many of our relay attempts are wasted.
+type RelayAnalytics interface {
+	Recorder
+
+	// Scan records the relays made since the last scanned block.
+	Scan(ctx context.Context) error
+
+	// Start scans the relays periodically until the context is done.
+	Start(ctx context.Context) error
+
+	// Stats returns a copy of the current stats.
+	Stats() Stats
+}
+
+type relayAnalytics struct {
+	logger         zerolog.Logger
+	ethProvider    provider.EVMProvider
+	gravityAddress ethcmn.Address
+	relayerAddress ethcmn.Address
+	opts           *options
+	metrics        *metrics
+	now            func() time.Time
+
+	mtx   sync.Mutex
+	stats *Stats
+}
+
+// NewRelayAnalytics returns the RelayAnalytics of the relays made to the Gravity contract, where ours are the ones
+// sent by relayerAddress. If a stats file is set and exists, the stats are resumed from it.
+func NewRelayAnalytics(
+	logger zerolog.Logger,
+	ethProvider provider.EVMProvider,
+	gravityAddress ethcmn.Address,
+	relayerAddress ethcmn.Address,
+	analyticsOpts ...Option,
+) (RelayAnalytics, error) {
+	a := &relayAnalytics{
+		logger:         logger.With().Str("module", "relay_analytics").Logger(),
+		ethProvider:    ethProvider,
+		gravityAddress: gravityAddress,
+		relayerAddress: relayerAddress,
+		opts:           defaultOptions(),
+		now:            time.Now,
+		stats:          newStats(relayerAddress),
+	}
+
+	if err := applyOptions(a.opts, analyticsOpts...); err != nil {
+		return nil, err
+	}
+
+	if a.opts.StatsFile != "" {
+		stats, err := LoadStats(a.opts.StatsFile)
+		switch {
+		case err == nil && stats.RelayerAddress == relayerAddress:
+			a.stats = stats
+		case err == nil:
+			a.logger.Warn().
+				Str("stats_relayer_address", stats.RelayerAddress.Hex()).
+				Msg("relay stats file belongs to another relayer; starting from scratch")
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+	}
+
+	if a.opts.Registerer != nil {
+		m, err := newMetrics(a.opts.Registerer)
+		if err != nil {
+			return nil, err
+		}
+		a.metrics = m
+	}
+
+	return a, nil
+}
+
+func (a *relayAnalytics) Start(ctx context.Context) error {
+	return loops.RunLoop(ctx, a.logger, a.opts.ScanInterval, func() error {
+		if err := a.Scan(ctx); err != nil {
+			a.logger.Err(err).Msg("failed to scan relays")
+		}
+
+		return nil
+	})
+}
+
+func (a *relayAnalytics) RecordAttempt(
+	kind string,
+	tokenContract ethcmn.Address,
+	nonce uint64,
+	txHash ethcmn.Hash,
+	gasPrice *big.Int,
+) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.stats.kind(kind).Attempts++
+	a.stats.RecentAttempts = append(a.stats.RecentAttempts, &Attempt{
+		Kind:          kind,
+		Nonce:         nonce,
+		TokenContract: tokenContract,
+		TxHash:        txHash,
+		Time:          a.now(),
+		GasPrice:      gasPrice,
+	})
+
+	if len(a.stats.RecentAttempts) > maxRecentRecords {
+		a.stats.RecentAttempts = a.stats.RecentAttempts[len(a.stats.RecentAttempts)-maxRecentRecords:]
+	}
+
+	a.metrics.incAttempts(kind)
+	a.persist()
+}
+
+func (a *relayAnalytics) SettleAttempt(txHash ethcmn.Hash, result tracker.Result) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	attempt := a.findAttempt(txHash)
+	if attempt == nil {
+		return
+	}
+
+	attempt.Outcome = result.Outcome.String()
+	attempt.GasUsed = result.GasUsed
+	if result.GasPrice != nil {
+		// The mined tx may be a replacement or a cancellation with bumped fees.
+		attempt.GasPrice = result.GasPrice
+	}
+
+	switch {
+	case attempt.Wasted:
+		// The attempt was lost before we knew how much gas it used.
+		a.addWastedCost(attempt)
+	case result.Outcome == tracker.OutcomeReverted || result.Outcome == tracker.OutcomeSuperseded:
+		a.markWasted(attempt, nil)
+	}
+
+	a.persist()
+}
+
+func (a *relayAnalytics) Stats() Stats {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	// A JSON round trip is the simplest deep copy of the stats.
+	bz, err := json.Marshal(a.stats)
+	if err != nil {
+		return Stats{}
+	}
+
+	var stats Stats
+	_ = json.Unmarshal(bz, &stats)
+	return stats
+}
+
+func (a *relayAnalytics) Scan(ctx context.Context) error {
+	latestHeader, err := a.ethProvider.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get latest header")
+	}
+
+	if latestHeader.Number.Uint64() < scanConfirmations {
+		return nil
+	}
+	endBlock := latestHeader.Number.Uint64() - scanConfirmations
+
+	a.mtx.Lock()
+	startBlock := a.stats.LastScannedBlock + 1
+	a.mtx.Unlock()
+
+	if startBlock == 1 && endBlock > initialLookbackBlocks {
+		startBlock = endBlock - initialLookbackBlocks
+	}
+
+	gravityFilterer, err := wrappers.NewGravityFilterer(a.gravityAddress, a.ethProvider)
+	if err != nil {
+		return errors.Wrap(err, "failed to init Gravity events filterer")
+	}
+
+	for startBlock <= endBlock {
+		toBlock := endBlock
+		if toBlock-startBlock >= maxBlocksPerScan {
+			toBlock = startBlock + maxBlocksPerScan - 1
+		}
+
+		relays, err := a.scanRange(ctx, gravityFilterer, startBlock, toBlock)
+		if err != nil {
+			return err
+		}
+
+		a.mtx.Lock()
+		for _, relay := range relays {
+			a.recordRelay(relay)
+		}
+		a.stats.LastScannedBlock = toBlock
+		a.persist()
+		a.mtx.Unlock()
+
+		startBlock = toBlock + 1
+	}
+
+	return nil
+}
+
+// scanRange returns the relays made between two blocks (inclusive), in order.
+func (a *relayAnalytics) scanRange(
+	ctx context.Context,
+	gravityFilterer *wrappers.GravityFilterer,
+	fromBlock uint64,
+	toBlock uint64,
+) ([]Relay, error) {
+	opts := &bind.FilterOpts{Start: fromBlock, End: &toBlock, Context: ctx}
+
+	var logs []relayLog
+
+	batchIter, err := gravityFilterer.FilterTransactionBatchExecutedEvent(opts, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to scan past TransactionBatchExecuted events from Ethereum")
+	}
+
+	for batchIter.Next() {
+		logs = append(logs, relayLog{
+			kind:          KindBatch,
+			nonce:         batchIter.Event.BatchNonce.Uint64(),
+			tokenContract: batchIter.Event.Token,
+			log:           batchIter.Event.Raw,
+		})
+	}
+	batchIter.Close()
+
+	valsetIter, err := gravityFilterer.FilterValsetUpdatedEvent(opts, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to scan past ValsetUpdated events from Ethereum")
+	}
+
+	for valsetIter.Next() {
+		// The first valset is set by the constructor of the contract, it's not relayed.
+		if valsetIter.Event.NewValsetNonce.Sign() == 0 {
+			continue
+		}
+
+		logs = append(logs, relayLog{
+			kind:  KindValset,
+			nonce: valsetIter.Event.NewValsetNonce.Uint64(),
+			log:   valsetIter.Event.Raw,
+		})
+	}
+	valsetIter.Close()
+
+	headers := map[uint64]*types.Header{}
+	relays := make([]Relay, 0, len(logs))
+
+	for _, l := range logs {
+		relay, err := a.relayFromLog(ctx, l, headers)
+		if err != nil {
+			return nil, err
+		}
+
+		relays = append(relays, relay)
+	}
+
+	return relays, nil
+}
+
+type relayLog struct {
+	kind          string
+	nonce         uint64
+	tokenContract ethcmn.Address
+	log           types.Log
+}
+
+// relayFromLog gets the sender, time and gas price of the transaction that emitted a relay event.
+func (a *relayAnalytics) relayFromLog(ctx context.Context, l relayLog, headers map[uint64]*types.Header) (Relay, error) {
+	tx, _, err := a.ethProvider.TransactionByHash(ctx, l.log.TxHash)
+	if err != nil {
+		return Relay{}, errors.Wrapf(err, "failed to get relay tx %s", l.log.TxHash.Hex())
+	}
+
+	sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return Relay{}, errors.Wrapf(err, "failed to get sender of relay tx %s", l.log.TxHash.Hex())
+	}
+
+	receipt, err := a.ethProvider.TransactionReceipt(ctx, l.log.TxHash)
+	if err != nil {
+		return Relay{}, errors.Wrapf(err, "failed to get receipt of relay tx %s", l.log.TxHash.Hex())
+	}
+
+	header, ok := headers[l.log.BlockNumber]
+	if !ok {
+		header, err = a.ethProvider.HeaderByNumber(ctx, new(big.Int).SetUint64(l.log.BlockNumber))
+		if err != nil {
+			return Relay{}, errors.Wrapf(err, "failed to get header of block %d", l.log.BlockNumber)
+		}
+		headers[l.log.BlockNumber] = header
+	}
+
+	// The price paid by a dynamic fee tx depends on the base fee of its block.
+	gasPrice := tx.GasPrice()
+	if header.BaseFee != nil {
+		gasPrice = new(big.Int).Add(header.BaseFee, tx.EffectiveGasTipValue(header.BaseFee))
+	}
+
+	return Relay{
+		Kind:          l.kind,
+		Nonce:         l.nonce,
+		TokenContract: l.tokenContract,
+		TxHash:        l.log.TxHash,
+		Relayer:       sender,
+		Ours:          sender == a.relayerAddress,
+		BlockNumber:   l.log.BlockNumber,
+		Time:          time.Unix(int64(header.Time), 0).UTC(),
+		GasPrice:      gasPrice,
+		GasUsed:       receipt.GasUsed,
+	}, nil
+}
+
+// recordRelay updates the stats with a relay, marking our attempts to relay the same item, or an older one it
+// supersedes, as wasted if someone else won. Must be called with the lock held.
+func (a *relayAnalytics) recordRelay(relay Relay) {
+	ks := a.stats.kind(relay.Kind)
+	ks.Relayed++
+
+	if relay.Ours {
+		ks.Won++
+	} else {
+		competitor, ok := a.stats.Competitors[relay.Relayer]
+		if !ok {
+			competitor = &CompetitorStats{Relays: map[string]uint64{}}
+			a.stats.Competitors[relay.Relayer] = competitor
+		}
+
+		competitor.Relays[relay.Kind]++
+		competitor.LastRelay = relay.Time
+
+		for _, attempt := range a.stats.RecentAttempts {
+			if a.supersedes(relay, attempt) {
+				a.markWasted(attempt, &relay.Relayer)
+			}
+		}
+
+		a.logger.Info().
+			Str("kind", relay.Kind).
+			Uint64("nonce", relay.Nonce).
+			Str("relayer", relay.Relayer.Hex()).
+			Str("tx_hash", relay.TxHash.Hex()).
+			Str("gas_price", relay.GasPrice.String()).
+			Msg("relayed by another relayer")
+	}
+
+	a.stats.RecentRelays = append(a.stats.RecentRelays, relay)
+	if len(a.stats.RecentRelays) > maxRecentRecords {
+		a.stats.RecentRelays = a.stats.RecentRelays[len(a.stats.RecentRelays)-maxRecentRecords:]
+	}
+
+	a.metrics.observeRelay(relay)
+}
+
+// supersedes returns true if the relay makes the attempt useless: it relays the same item, or a newer batch of the
+// same token or a newer valset, which invalidates the older ones in the contract. An attempt that relayed its item
+// before is not superseded. Must be called with the lock held.
+func (a *relayAnalytics) supersedes(relay Relay, attempt *Attempt) bool {
+	if attempt.Kind != relay.Kind || attempt.TokenContract != relay.TokenContract || attempt.Nonce > relay.Nonce {
+		return false
+	}
+
+	if attempt.Nonce == relay.Nonce {
+		return true
+	}
+
+	return !a.relayedByAttempt(attempt)
+}
+
+// relayedByAttempt returns true if the attempt relayed its item. Must be called with the lock held.
+func (a *relayAnalytics) relayedByAttempt(attempt *Attempt) bool {
+	if attempt.Outcome == tracker.OutcomeConfirmed.String() {
+		return true
+	}
+
+	for _, relay := range a.stats.RecentRelays {
+		if relay.Ours && relay.Kind == attempt.Kind && relay.TokenContract == attempt.TokenContract &&
+			relay.Nonce == attempt.Nonce {
+			return true
+		}
+	}
+
+	return false
+}
+
+// markWasted marks an attempt as wasted, once. Must be called with the lock held.
+func (a *relayAnalytics) markWasted(attempt *Attempt, lostTo *ethcmn.Address) {
+	if lostTo != nil && attempt.LostTo == nil {
+		attempt.LostTo = lostTo
+	}
+
+	if attempt.Wasted {
+		return
+	}
+
+	attempt.Wasted = true
+	a.stats.kind(attempt.Kind).WastedAttempts++
+	a.metrics.incWastedAttempts(attempt.Kind)
+
+	if attempt.Outcome != "" {
+		a.addWastedCost(attempt)
+	}
+}
+
+// addWastedCost adds the cost of a wasted attempt, once its gas used is known. Must be called with the lock held.
+func (a *relayAnalytics) addWastedCost(attempt *Attempt) {
+	if attempt.GasUsed == 0 || attempt.GasPrice == nil {
+		return
+	}
+
+	cost := new(big.Int).Mul(attempt.GasPrice, new(big.Int).SetUint64(attempt.GasUsed))
+	ks := a.stats.kind(attempt.Kind)
+	ks.WastedWei = new(big.Int).Add(ks.WastedWei, cost)
+	a.metrics.addWastedWei(attempt.Kind, cost)
+}
+
+// findAttempt returns the attempt of a transaction. Must be called with the lock held.
+func (a *relayAnalytics) findAttempt(txHash ethcmn.Hash) *Attempt {
+	for _, attempt := range a.stats.RecentAttempts {
+		if attempt.TxHash == txHash {
+			return attempt
+		}
+	}
+
+	return nil
+}
+
+// persist writes the stats to the stats file, if any. Must be called with the lock held.
+func (a *relayAnalytics) persist() {
+	if a.opts.StatsFile == "" {
+		return
+	}
+
+	bz, err := json.MarshalIndent(a.stats, "", "  ")
+	if err != nil {
+		a.logger.Err(err).Msg("failed to encode relay stats")
+		return
+	}
+
+	// Write to a temporary file first, so a crash never leaves a truncated stats file.
+	tmpFile := filepath.Join(filepath.Dir(a.opts.StatsFile), "."+filepath.Base(a.opts.StatsFile)+".tmp")
+	if err := os.WriteFile(tmpFile, bz, 0o600); err != nil {
+		a.logger.Err(err).Msg("failed to write relay stats")
+		return
+	}
+
+	if err := os.Rename(tmpFile, a.opts.StatsFile); err != nil {
+		a.logger.Err(err).Msg("failed to write relay stats")
+	}
+}
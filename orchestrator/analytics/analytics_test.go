@@ -0,0 +1,293 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umee-network/peggo/mocks"
+	"github.com/umee-network/peggo/orchestrator/ethereum/tracker"
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+var (
+	gravityAddress = ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+	tokenAddress   = ethcmn.HexToAddress("0x2260fac5e5542a773aa44fbcfedf7c193bc2c599")
+	ourAddress     = ethcmn.HexToAddress("0x00000000000000000000000000000000000000a1")
+	otherAddress   = ethcmn.HexToAddress("0x00000000000000000000000000000000000000b1")
+	gwei           = big.NewInt(1_000_000_000)
+)
+
+func TestRelayAnalytics(t *testing.T) {
+	statsFile := filepath.Join(t.TempDir(), "stats.json")
+	registry := prometheus.NewRegistry()
+
+	analytics, err := NewRelayAnalytics(
+		zerolog.Nop(),
+		nil,
+		gravityAddress,
+		ourAddress,
+		OptionStatsFile(statsFile),
+		OptionMetrics(registry),
+	)
+	assert.Nil(t, err)
+
+	a := analytics.(*relayAnalytics)
+
+	wonTx, lostTx, revertedTx := ethcmn.HexToHash("0x01"), ethcmn.HexToHash("0x02"), ethcmn.HexToHash("0x03")
+
+	a.RecordAttempt(KindBatch, tokenAddress, 1, wonTx, gwei)
+	a.RecordAttempt(KindBatch, tokenAddress, 2, lostTx, gwei)
+	a.RecordAttempt(KindValset, ethcmn.Address{}, 7, revertedTx, gwei)
+
+	a.mtx.Lock()
+	a.recordRelay(Relay{Kind: KindBatch, Nonce: 1, TokenContract: tokenAddress, TxHash: wonTx, Relayer: ourAddress, Ours: true})
+	a.recordRelay(Relay{Kind: KindBatch, Nonce: 2, TokenContract: tokenAddress, Relayer: otherAddress, GasPrice: gwei})
+	a.mtx.Unlock()
+
+	// The lost attempt was replaced by a cancellation, and the valset attempt reverted.
+	a.SettleAttempt(lostTx, tracker.Result{Outcome: tracker.OutcomeSuperseded, GasUsed: 21000})
+	a.SettleAttempt(revertedTx, tracker.Result{Outcome: tracker.OutcomeReverted, GasUsed: 100000})
+	a.SettleAttempt(wonTx, tracker.Result{Outcome: tracker.OutcomeConfirmed, GasUsed: 200000})
+
+	stats := a.Stats()
+
+	batchStats := stats.Kinds[KindBatch]
+	assert.Equal(t, uint64(2), batchStats.Relayed)
+	assert.Equal(t, uint64(1), batchStats.Won)
+	assert.Equal(t, uint64(2), batchStats.Attempts)
+	assert.Equal(t, uint64(1), batchStats.WastedAttempts)
+	assert.Equal(t, new(big.Int).Mul(gwei, big.NewInt(21000)), batchStats.WastedWei)
+
+	valsetStats := stats.Kinds[KindValset]
+	assert.Equal(t, uint64(0), valsetStats.Relayed)
+	assert.Equal(t, uint64(1), valsetStats.WastedAttempts)
+	assert.Equal(t, new(big.Int).Mul(gwei, big.NewInt(100000)), valsetStats.WastedWei)
+
+	assert.Equal(t, map[string]uint64{KindBatch: 1}, stats.Competitors[otherAddress].Relays)
+	assert.Equal(t, &otherAddress, stats.RecentAttempts[1].LostTo)
+	assert.False(t, stats.RecentAttempts[0].Wasted)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(a.metrics.relays.WithLabelValues(KindBatch, otherAddress.Hex(), "false")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(a.metrics.wastedAttempts.WithLabelValues(KindValset)))
+	assert.Equal(t, float64(21000e9), testutil.ToFloat64(a.metrics.wastedWei.WithLabelValues(KindBatch)))
+
+	// The stats are resumed from the stats file.
+	resumed, err := NewRelayAnalytics(zerolog.Nop(), nil, gravityAddress, ourAddress, OptionStatsFile(statsFile))
+	assert.Nil(t, err)
+	assert.Equal(t, stats, resumed.Stats())
+
+	// But not by another relayer.
+	other, err := NewRelayAnalytics(zerolog.Nop(), nil, gravityAddress, otherAddress, OptionStatsFile(statsFile))
+	assert.Nil(t, err)
+	assert.Empty(t, other.Stats().Kinds)
+
+	var report bytes.Buffer
+	assert.Nil(t, WriteReport(&report, &stats))
+	assert.Contains(t, report.String(), otherAddress.Hex())
+	assert.Contains(t, report.String(), "50.0%")
+}
+
+func TestSupersedingRelay(t *testing.T) {
+	analytics, err := NewRelayAnalytics(zerolog.Nop(), nil, gravityAddress, ourAddress)
+	assert.Nil(t, err)
+
+	a := analytics.(*relayAnalytics)
+	otherToken := ethcmn.HexToAddress("0x00000000000000000000000000000000000000c1")
+
+	wonTx, pendingTx, newerTx := ethcmn.HexToHash("0x01"), ethcmn.HexToHash("0x02"), ethcmn.HexToHash("0x03")
+	otherTokenTx, valsetTx := ethcmn.HexToHash("0x04"), ethcmn.HexToHash("0x05")
+
+	a.RecordAttempt(KindBatch, tokenAddress, 1, wonTx, gwei)
+	a.RecordAttempt(KindBatch, tokenAddress, 2, pendingTx, gwei)
+	a.RecordAttempt(KindBatch, tokenAddress, 4, newerTx, gwei)
+	a.RecordAttempt(KindBatch, otherToken, 2, otherTokenTx, gwei)
+	a.RecordAttempt(KindValset, ethcmn.Address{}, 7, valsetTx, gwei)
+
+	a.mtx.Lock()
+	a.recordRelay(Relay{Kind: KindBatch, Nonce: 1, TokenContract: tokenAddress, TxHash: wonTx, Relayer: ourAddress, Ours: true})
+	// A newer batch of the token supersedes our pending attempt, but not the one that won or a newer one.
+	a.recordRelay(Relay{Kind: KindBatch, Nonce: 3, TokenContract: tokenAddress, Relayer: otherAddress, GasPrice: gwei})
+	// A newer valset supersedes older valsets.
+	a.recordRelay(Relay{Kind: KindValset, Nonce: 8, Relayer: otherAddress, GasPrice: gwei})
+	a.mtx.Unlock()
+
+	stats := a.Stats()
+	wasted := map[ethcmn.Hash]bool{}
+	for _, attempt := range stats.RecentAttempts {
+		wasted[attempt.TxHash] = attempt.Wasted
+	}
+
+	assert.Equal(t, map[ethcmn.Hash]bool{
+		wonTx:        false,
+		pendingTx:    true,
+		newerTx:      false,
+		otherTokenTx: false,
+		valsetTx:     true,
+	}, wasted)
+	assert.Equal(t, &otherAddress, stats.RecentAttempts[1].LostTo)
+	assert.Equal(t, uint64(1), stats.Kinds[KindBatch].WastedAttempts)
+	assert.Equal(t, uint64(1), stats.Kinds[KindValset].WastedAttempts)
+
+	// The cost of a superseded attempt is counted once it's settled.
+	a.SettleAttempt(pendingTx, tracker.Result{Outcome: tracker.OutcomeSuperseded, GasUsed: 21000})
+	assert.Equal(t, new(big.Int).Mul(gwei, big.NewInt(21000)), a.Stats().Kinds[KindBatch].WastedWei)
+}
+
+func TestReplacedAttemptCost(t *testing.T) {
+	analytics, err := NewRelayAnalytics(zerolog.Nop(), nil, gravityAddress, ourAddress)
+	assert.Nil(t, err)
+
+	a := analytics.(*relayAnalytics)
+	sentTx, cancelTx := ethcmn.HexToHash("0x01"), ethcmn.HexToHash("0x02")
+	bumpedGasPrice := new(big.Int).Mul(gwei, big.NewInt(3))
+
+	a.RecordAttempt(KindBatch, tokenAddress, 1, sentTx, gwei)
+
+	a.mtx.Lock()
+	a.recordRelay(Relay{Kind: KindBatch, Nonce: 1, TokenContract: tokenAddress, Relayer: otherAddress, GasPrice: gwei})
+	a.mtx.Unlock()
+
+	// The stuck attempt was cancelled with bumped fees, and the cancellation was mined.
+	a.SettleAttempt(sentTx, tracker.Result{
+		TxHash:   cancelTx,
+		Outcome:  tracker.OutcomeSuperseded,
+		GasUsed:  21000,
+		GasPrice: bumpedGasPrice,
+	})
+
+	stats := a.Stats()
+	assert.Equal(t, new(big.Int).Mul(bumpedGasPrice, big.NewInt(21000)), stats.Kinds[KindBatch].WastedWei)
+	assert.Equal(t, bumpedGasPrice, stats.RecentAttempts[0].GasPrice)
+}
+
+func TestScan(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	gravityABI, err := wrappers.GravityMetaData.GetAbi()
+	assert.Nil(t, err)
+
+	ourKey, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+
+	newTx := func(nonce uint64, tip int64) *types.Transaction {
+		return types.MustSignNewTx(otherKey, signer, &types.DynamicFeeTx{
+			ChainID:   big.NewInt(1),
+			Nonce:     nonce,
+			GasTipCap: new(big.Int).Mul(gwei, big.NewInt(tip)),
+			GasFeeCap: new(big.Int).Mul(gwei, big.NewInt(100)),
+			Gas:       500000,
+			To:        &gravityAddress,
+		})
+	}
+
+	batchTx := newTx(0, 2)
+	valsetTx := types.MustSignNewTx(ourKey, signer, &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		GasTipCap: gwei,
+		GasFeeCap: new(big.Int).Mul(gwei, big.NewInt(100)),
+		Gas:       500000,
+		To:        &gravityAddress,
+	})
+
+	batchEvent := gravityABI.Events["TransactionBatchExecutedEvent"]
+	batchData, err := batchEvent.Inputs.NonIndexed().Pack(big.NewInt(10))
+	assert.Nil(t, err)
+
+	valsetEvent := gravityABI.Events["ValsetUpdatedEvent"]
+	valsetData, err := valsetEvent.Inputs.NonIndexed().Pack(
+		big.NewInt(11),
+		big.NewInt(0),
+		ethcmn.Address{},
+		[]ethcmn.Address{ourAddress},
+		[]*big.Int{big.NewInt(100)},
+	)
+	assert.Nil(t, err)
+
+	logs := map[ethcmn.Hash][]types.Log{
+		batchEvent.ID: {{
+			Address:     gravityAddress,
+			Topics:      []ethcmn.Hash{batchEvent.ID, ethcmn.BigToHash(big.NewInt(5)), ethcmn.BytesToHash(tokenAddress.Bytes())},
+			Data:        batchData,
+			BlockNumber: 90,
+			TxHash:      batchTx.Hash(),
+		}},
+		valsetEvent.ID: {{
+			Address:     gravityAddress,
+			Topics:      []ethcmn.Hash{valsetEvent.ID, ethcmn.BigToHash(big.NewInt(3))},
+			Data:        valsetData,
+			BlockNumber: 90,
+			TxHash:      valsetTx.Hash(),
+		}},
+	}
+
+	mockProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+	mockProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&types.Header{Number: big.NewInt(100)}, nil)
+	mockProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(90)).Return(&types.Header{
+		Number:  big.NewInt(90),
+		Time:    1700000000,
+		BaseFee: new(big.Int).Mul(gwei, big.NewInt(30)),
+	}, nil)
+	mockProvider.EXPECT().FilterLogs(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+			assert.Equal(t, big.NewInt(1), q.FromBlock)
+			assert.Equal(t, big.NewInt(94), q.ToBlock)
+			return logs[q.Topics[0][0]], nil
+		},
+	).Times(2)
+	mockProvider.EXPECT().TransactionByHash(gomock.Any(), batchTx.Hash()).Return(batchTx, false, nil)
+	mockProvider.EXPECT().TransactionByHash(gomock.Any(), valsetTx.Hash()).Return(valsetTx, false, nil)
+	mockProvider.EXPECT().TransactionReceipt(gomock.Any(), gomock.Any()).Return(&types.Receipt{GasUsed: 150000}, nil).Times(2)
+
+	ourEthAddress := crypto.PubkeyToAddress(ourKey.PublicKey)
+	otherEthAddress := crypto.PubkeyToAddress(otherKey.PublicKey)
+
+	analytics, err := NewRelayAnalytics(zerolog.Nop(), mockProvider, gravityAddress, ourEthAddress)
+	assert.Nil(t, err)
+
+	assert.Nil(t, analytics.Scan(context.Background()))
+
+	stats := analytics.Stats()
+	assert.Equal(t, uint64(94), stats.LastScannedBlock)
+	assert.Equal(t, []Relay{
+		{
+			Kind:          KindBatch,
+			Nonce:         5,
+			TokenContract: tokenAddress,
+			TxHash:        batchTx.Hash(),
+			Relayer:       otherEthAddress,
+			BlockNumber:   90,
+			Time:          stats.RecentRelays[0].Time,
+			GasPrice:      new(big.Int).Mul(gwei, big.NewInt(32)),
+			GasUsed:       150000,
+		},
+		{
+			Kind:        KindValset,
+			Nonce:       3,
+			TxHash:      valsetTx.Hash(),
+			Relayer:     ourEthAddress,
+			Ours:        true,
+			BlockNumber: 90,
+			Time:        stats.RecentRelays[1].Time,
+			GasPrice:    new(big.Int).Mul(gwei, big.NewInt(31)),
+			GasUsed:     150000,
+		},
+	}, stats.RecentRelays)
+	assert.Equal(t, int64(1700000000), stats.RecentRelays[0].Time.Unix())
+	assert.Equal(t, uint64(1), stats.Kinds[KindValset].Won)
+	assert.Equal(t, uint64(0), stats.Kinds[KindBatch].Won)
+}
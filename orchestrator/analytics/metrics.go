@@ -0,0 +1,113 @@
+package analytics
+
+import (
+	"math/big"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "peggo"
+
+// weiPerGwei is used to report gas prices in gwei.
+var weiPerGwei = big.NewFloat(1e9)
+
+// metrics holds the Prometheus metrics of the relay analytics. A nil *metrics is valid and records nothing.
+type metrics struct {
+	relays            *prometheus.CounterVec
+	attempts          *prometheus.CounterVec
+	wastedAttempts    *prometheus.CounterVec
+	wastedWei         *prometheus.CounterVec
+	relayGasPriceGwei *prometheus.HistogramVec
+}
+
+func newMetrics(registerer prometheus.Registerer) (*metrics, error) {
+	m := &metrics{
+		relays: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "relays_total",
+			Help:      "Number of relays to Ethereum, by kind and relayer.",
+		}, []string{"kind", "relayer", "ours"}),
+		attempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "relay_attempts_total",
+			Help:      "Number of relay transactions sent by this relayer, by kind.",
+		}, []string{"kind"}),
+		wastedAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "relay_attempts_wasted_total",
+			Help:      "Number of relay transactions sent by this relayer that lost the race or reverted, by kind.",
+		}, []string{"kind"}),
+		wastedWei: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "relay_wasted_wei_total",
+			Help:      "ETH (in wei) spent on wasted relay transactions, by kind.",
+		}, []string{"kind"}),
+		relayGasPriceGwei: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "relay_gas_price_gwei",
+			Help:      "Gas price (in gwei) paid by the relays to Ethereum, by kind and winner.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+		}, []string{"kind", "ours"}),
+	}
+
+	for _, c := range []prometheus.Collector{
+		m.relays,
+		m.attempts,
+		m.wastedAttempts,
+		m.wastedWei,
+		m.relayGasPriceGwei,
+	} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (m *metrics) observeRelay(relay Relay) {
+	if m == nil {
+		return
+	}
+
+	ours := boolLabel(relay.Ours)
+	m.relays.WithLabelValues(relay.Kind, relay.Relayer.Hex(), ours).Inc()
+
+	if relay.GasPrice != nil {
+		gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(relay.GasPrice), weiPerGwei).Float64()
+		m.relayGasPriceGwei.WithLabelValues(relay.Kind, ours).Observe(gwei)
+	}
+}
+
+func (m *metrics) incAttempts(kind string) {
+	if m == nil {
+		return
+	}
+
+	m.attempts.WithLabelValues(kind).Inc()
+}
+
+func (m *metrics) incWastedAttempts(kind string) {
+	if m == nil {
+		return
+	}
+
+	m.wastedAttempts.WithLabelValues(kind).Inc()
+}
+
+func (m *metrics) addWastedWei(kind string, wei *big.Int) {
+	if m == nil {
+		return
+	}
+
+	value, _ := new(big.Float).SetInt(wei).Float64()
+	m.wastedWei.WithLabelValues(kind).Add(value)
+}
+
+func boolLabel(b bool) string {
+	if b {
+		return "true"
+	}
+
+	return "false"
+}
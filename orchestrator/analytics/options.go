@@ -0,0 +1,65 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type Option func(o *options) error
+
+type options struct {
+	ScanInterval time.Duration
+	StatsFile    string
+	Registerer   prometheus.Registerer
+}
+
+func defaultOptions() *options {
+	return &options{
+		ScanInterval: time.Minute,
+	}
+}
+
+func applyOptions(o *options, opts ...Option) error {
+	for _, oo := range opts {
+		if err := oo(o); err != nil {
+			err = errors.Wrap(err, "failed to apply option to relay analytics")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OptionScanInterval sets how often the relays are scanned.
+func OptionScanInterval(interval time.Duration) Option {
+	return func(o *options) error {
+		if interval <= 0 {
+			return errors.New("scan interval must be greater than zero")
+		}
+
+		o.ScanInterval = interval
+		return nil
+	}
+}
+
+// OptionStatsFile sets the file the stats are stored in, so they survive restarts.
+func OptionStatsFile(path string) Option {
+	return func(o *options) error {
+		o.StatsFile = path
+		return nil
+	}
+}
+
+// OptionMetrics registers the relay metrics in the given registerer.
+func OptionMetrics(registerer prometheus.Registerer) Option {
+	return func(o *options) error {
+		if registerer == nil {
+			return errors.New("metrics registerer can't be nil")
+		}
+
+		o.Registerer = registerer
+		return nil
+	}
+}
@@ -0,0 +1,126 @@
+package analytics
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+)
+
+// maxReportLosses is the number of lost attempts listed in the report.
+const maxReportLosses = 10
+
+// WriteReport writes a human-readable report of the stats.
+func WriteReport(w io.Writer, stats *Stats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Relayer:\t%s\n", stats.RelayerAddress.Hex())
+	fmt.Fprintf(tw, "Last scanned block:\t%d\n\n", stats.LastScannedBlock)
+
+	fmt.Fprintln(tw, "KIND\tRELAYED\tWON\tWIN RATE\tATTEMPTS\tWASTED\tWASTED ETH")
+	for _, kind := range []string{KindBatch, KindValset} {
+		ks, ok := stats.Kinds[kind]
+		if !ok {
+			continue
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%d\t%d\t%s\n",
+			kind,
+			ks.Relayed,
+			ks.Won,
+			percent(ks.Won, ks.Relayed),
+			ks.Attempts,
+			ks.WastedAttempts,
+			weiToETH(ks.WastedWei),
+		)
+	}
+
+	if len(stats.Competitors) > 0 {
+		competitors := make([]ethcmn.Address, 0, len(stats.Competitors))
+		for address := range stats.Competitors {
+			competitors = append(competitors, address)
+		}
+
+		// Most active competitors first.
+		sort.Slice(competitors, func(i, j int) bool {
+			a, b := stats.Competitors[competitors[i]], stats.Competitors[competitors[j]]
+			if totalRelays(a) != totalRelays(b) {
+				return totalRelays(a) > totalRelays(b)
+			}
+			return competitors[i].Hex() < competitors[j].Hex()
+		})
+
+		fmt.Fprintln(tw, "\nCOMPETITOR\tBATCHES\tVALSETS\tLAST RELAY")
+		for _, address := range competitors {
+			competitor := stats.Competitors[address]
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n",
+				address.Hex(),
+				competitor.Relays[KindBatch],
+				competitor.Relays[KindValset],
+				competitor.LastRelay.Format(time.RFC3339),
+			)
+		}
+	}
+
+	var losses []*Attempt
+	for i := len(stats.RecentAttempts) - 1; i >= 0 && len(losses) < maxReportLosses; i-- {
+		if stats.RecentAttempts[i].Wasted {
+			losses = append(losses, stats.RecentAttempts[i])
+		}
+	}
+
+	if len(losses) > 0 {
+		fmt.Fprintln(tw, "\nRECENT WASTED ATTEMPTS\tNONCE\tTX HASH\tOUTCOME\tLOST TO")
+		for _, attempt := range losses {
+			lostTo := "-"
+			if attempt.LostTo != nil {
+				lostTo = attempt.LostTo.Hex()
+			}
+
+			outcome := attempt.Outcome
+			if outcome == "" {
+				outcome = "-"
+			}
+
+			fmt.Fprintf(tw, "%s %s\t%d\t%s\t%s\t%s\n",
+				attempt.Time.Format(time.RFC3339),
+				attempt.Kind,
+				attempt.Nonce,
+				attempt.TxHash.Hex(),
+				outcome,
+				lostTo,
+			)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func totalRelays(competitor *CompetitorStats) uint64 {
+	var total uint64
+	for _, count := range competitor.Relays {
+		total += count
+	}
+
+	return total
+}
+
+func percent(part, total uint64) string {
+	if total == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.1f%%", float64(part)*100/float64(total))
+}
+
+func weiToETH(wei *big.Int) string {
+	if wei == nil {
+		return "0"
+	}
+
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18)).Text('f', 6)
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sync"
 	"time"
 
 	ethcmn "github.com/ethereum/go-ethereum/common"
@@ -26,6 +27,7 @@ type (
 		client *http.Client
 		config *Config
 
+		mtx         sync.RWMutex
 		coinsSymbol map[ethcmn.Address]string // contract addr => token symbol
 
 		logger zerolog.Logger
@@ -47,6 +49,12 @@ type (
 
 // NewCoingecko grabs the symbol, given a contract address.
 func NewCoingecko(logger zerolog.Logger, endpointConfig *Config) *CoinGecko {
+	// Copy the known symbols, so the instances don't share the map.
+	coinsSymbol := make(map[ethcmn.Address]string, len(bridgeTokensCoinSymbols))
+	for addr, symbol := range bridgeTokensCoinSymbols {
+		coinsSymbol[addr] = symbol
+	}
+
 	return &CoinGecko{
 		client: &http.Client{
 			Transport: &http.Transport{
@@ -55,7 +63,7 @@ func NewCoingecko(logger zerolog.Logger, endpointConfig *Config) *CoinGecko {
 			Timeout: maxRespTime,
 		},
 		config:      checkCoingeckoConfig(endpointConfig),
-		coinsSymbol: bridgeTokensCoinSymbols,
+		coinsSymbol: coinsSymbol,
 		logger:      logger.With().Str("oracle", "coingecko").Logger(),
 	}
 }
@@ -72,7 +80,10 @@ func urlJoin(baseURL string, segments ...string) (*url.URL, error) {
 
 // GetTokenSymbol returns the token symbol checked by CoinGecko API.
 func (cp *CoinGecko) GetTokenSymbol(erc20Contract ethcmn.Address) (string, error) {
+	cp.mtx.RLock()
 	symbol, ok := cp.coinsSymbol[erc20Contract]
+	cp.mtx.RUnlock()
+
 	if !ok {
 		symbol, err := cp.requestCoinSymbol(erc20Contract)
 		if err != nil {
@@ -87,6 +98,9 @@ func (cp *CoinGecko) GetTokenSymbol(erc20Contract ethcmn.Address) (string, error
 }
 
 func (cp *CoinGecko) setCoinSymbol(erc20Contract ethcmn.Address, symbol string) {
+	cp.mtx.Lock()
+	defer cp.mtx.Unlock()
+
 	cp.coinsSymbol[erc20Contract] = symbol
 }
 
@@ -0,0 +1,179 @@
+package cosmos
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// grantExpiryWarning is how long before its expiration a grant is logged as about to expire.
+const grantExpiryWarning = 7 * 24 * time.Hour
+
+// GravityMsgTypes are the type URLs of the msgs broadcast by the orchestrator. When broadcasting through authz, the
+// grantee must be granted all of them by the orchestrator address.
+var GravityMsgTypes = []string{
+	sdk.MsgTypeURL(&types.MsgValsetConfirm{}),
+	sdk.MsgTypeURL(&types.MsgConfirmBatch{}),
+	sdk.MsgTypeURL(&types.MsgRequestBatch{}),
+	sdk.MsgTypeURL(&types.MsgSendToCosmosClaim{}),
+	sdk.MsgTypeURL(&types.MsgBatchSendToEthClaim{}),
+	sdk.MsgTypeURL(&types.MsgValsetUpdatedClaim{}),
+	sdk.MsgTypeURL(&types.MsgERC20DeployedClaim{}),
+}
+
+// SetAuthzGranter makes the Gravity Broadcast Client broadcast the msgs of the granter wrapped in authz.MsgExec,
+// signed by the key of the Cosmos client (the grantee).
+func SetAuthzGranter(granter sdk.AccAddress) func(GravityBroadcastClient) {
+	return func(s GravityBroadcastClient) { s.SetAuthzGranter(granter) }
+}
+
+// SetAuthzGranter makes the Gravity Broadcast Client broadcast the msgs of the granter wrapped in authz.MsgExec,
+// signed by the key of the Cosmos client (the grantee).
+func (s *gravityBroadcastClient) SetAuthzGranter(granter sdk.AccAddress) {
+	s.authzGranter = granter
+}
+
+// execMsgs returns the msgs to broadcast. Through authz, each msg is wrapped in its own MsgExec, so the index of a
+// failing msg in a tx is still the index of the Gravity msg.
+func (s *gravityBroadcastClient) execMsgs(msgs ...sdk.Msg) []sdk.Msg {
+	if s.authzGranter.Empty() {
+		return msgs
+	}
+
+	grantee := s.broadcastClient.FromAddress()
+
+	execMsgs := make([]sdk.Msg, 0, len(msgs))
+	for _, msg := range msgs {
+		execMsg := authz.NewMsgExec(grantee, []sdk.Msg{msg})
+		execMsgs = append(execMsgs, &execMsg)
+	}
+
+	return execMsgs
+}
+
+// CheckAuthzGrants returns an error listing the msg types the grantee isn't granted by the granter. Expired grants
+// are pruned by the chain, so they're reported as missing.
+func CheckAuthzGrants(
+	ctx context.Context,
+	logger zerolog.Logger,
+	queryClient authz.QueryClient,
+	granter, grantee sdk.AccAddress,
+) error {
+	var missing []string
+
+	for _, msgType := range GravityMsgTypes {
+		res, err := queryClient.Grants(ctx, &authz.QueryGrantsRequest{
+			Granter:    granter.String(),
+			Grantee:    grantee.String(),
+			MsgTypeUrl: msgType,
+		})
+		if err != nil {
+			if status, ok := grpcstatus.FromError(err); ok && status.Code() == codes.NotFound {
+				missing = append(missing, msgType)
+				continue
+			}
+
+			return errors.Wrapf(err, "failed to query the authz grants of %s", msgType)
+		}
+
+		if len(res.Grants) == 0 {
+			missing = append(missing, msgType)
+			continue
+		}
+
+		for _, grant := range res.Grants {
+			if grant.Expiration != nil && time.Until(*grant.Expiration) < grantExpiryWarning {
+				logger.Warn().
+					Str("msg_type", msgType).
+					Time("expiration", *grant.Expiration).
+					Msg("authz grant expires soon")
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return errors.Errorf(
+			"%s isn't granted by %s to execute: %s",
+			grantee,
+			granter,
+			strings.Join(missing, ", "),
+		)
+	}
+
+	return nil
+}
+
+// CheckFeeAllowance returns an error if the fee granter doesn't allow the fee payer to pay fees for the msg types,
+// or if the allowance expired. The fee payer is the signer of the txs, which is the grantee when broadcasting
+// through authz; its txs only hold authz.MsgExec msgs then.
+func CheckFeeAllowance(
+	ctx context.Context,
+	queryClient feegrant.QueryClient,
+	unpacker codectypes.AnyUnpacker,
+	feeGranter, feePayer sdk.AccAddress,
+	msgTypes []string,
+) error {
+	res, err := queryClient.Allowance(ctx, &feegrant.QueryAllowanceRequest{
+		Granter: feeGranter.String(),
+		Grantee: feePayer.String(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to query the fee allowance of %s by %s", feePayer, feeGranter)
+	}
+
+	if res.Allowance == nil || res.Allowance.Allowance == nil {
+		return errors.Errorf("%s has no fee allowance by %s", feePayer, feeGranter)
+	}
+
+	var allowance feegrant.FeeAllowanceI
+	if err := unpacker.UnpackAny(res.Allowance.Allowance, &allowance); err != nil {
+		return errors.Wrap(err, "failed to unpack the fee allowance")
+	}
+
+	expiration, err := allowance.ExpiresAt()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the expiration of the fee allowance")
+	}
+
+	if expiration != nil && expiration.Before(time.Now()) {
+		return errors.Errorf("the fee allowance of %s by %s expired at %s", feePayer, feeGranter, expiration)
+	}
+
+	allowedMsgAllowance, ok := allowance.(*feegrant.AllowedMsgAllowance)
+	if !ok {
+		return nil
+	}
+
+	allowed := make(map[string]bool, len(allowedMsgAllowance.AllowedMessages))
+	for _, msgType := range allowedMsgAllowance.AllowedMessages {
+		allowed[msgType] = true
+	}
+
+	var notAllowed []string
+	for _, msgType := range msgTypes {
+		if !allowed[msgType] {
+			notAllowed = append(notAllowed, msgType)
+		}
+	}
+
+	if len(notAllowed) > 0 {
+		return errors.Errorf(
+			"the fee allowance of %s by %s doesn't allow: %s",
+			feePayer,
+			feeGranter,
+			strings.Join(notAllowed, ", "),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package cosmos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+
+	"github.com/umee-network/peggo/cmd/peggo/client"
+	"github.com/umee-network/peggo/mocks"
+)
+
+var (
+	authzGranter = sdk.AccAddress("granter_____________")
+	authzGrantee = sdk.AccAddress("grantee_____________")
+)
+
+// authzQueryClient returns the grants of the msg types it holds, and NotFound for the others.
+type authzQueryClient struct {
+	authz.QueryClient
+	grants map[string]*authz.Grant
+}
+
+func (c authzQueryClient) Grants(
+	_ context.Context,
+	req *authz.QueryGrantsRequest,
+	_ ...grpc.CallOption,
+) (*authz.QueryGrantsResponse, error) {
+	grant, ok := c.grants[req.MsgTypeUrl]
+	if !ok {
+		return nil, grpcstatus.Errorf(codes.NotFound, "no authorization found for %s type", req.MsgTypeUrl)
+	}
+
+	return &authz.QueryGrantsResponse{Grants: []*authz.Grant{grant}}, nil
+}
+
+// feegrantQueryClient returns its allowance.
+type feegrantQueryClient struct {
+	feegrant.QueryClient
+	allowance feegrant.FeeAllowanceI
+}
+
+func (c feegrantQueryClient) Allowance(
+	_ context.Context,
+	req *feegrant.QueryAllowanceRequest,
+	_ ...grpc.CallOption,
+) (*feegrant.QueryAllowanceResponse, error) {
+	grant, err := feegrant.NewGrant(
+		sdk.MustAccAddressFromBech32(req.Granter),
+		sdk.MustAccAddressFromBech32(req.Grantee),
+		c.allowance,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &feegrant.QueryAllowanceResponse{Allowance: &grant}, nil
+}
+
+func TestSendValsetConfirmThroughAuthz(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
+	mockCosmos.EXPECT().FromAddress().Return(authzGrantee)
+	mockCosmos.EXPECT().
+		QueueBroadcastMsg(gomock.Any()).
+		DoAndReturn(func(msgs ...sdk.Msg) (*client.BroadcastHandle, error) {
+			assert.Len(t, msgs, 1)
+
+			execMsg, ok := msgs[0].(*authz.MsgExec)
+			assert.True(t, ok)
+			assert.Equal(t, authzGrantee.String(), execMsg.Grantee)
+
+			execMsgs, err := execMsg.GetMessages()
+			assert.Nil(t, err)
+			assert.Len(t, execMsgs, 1)
+			assert.Equal(t, authzGranter.String(), execMsgs[0].(*types.MsgValsetConfirm).Orchestrator)
+
+			return queuedHandle, nil
+		})
+
+	mockPersonalSignFn := func(account ethcmn.Address, data []byte) (sig []byte, err error) {
+		return []byte{}, nil
+	}
+
+	s := NewGravityBroadcastClient(
+		zerolog.Nop(),
+		nil,
+		mockCosmos,
+		nil,
+		mockPersonalSignFn,
+		10,
+		SetAuthzGranter(authzGranter),
+	)
+
+	assert.Equal(t, authzGranter, s.AccFromAddress())
+
+	handle, err := s.SendValsetConfirm(context.Background(), ethcmn.Address{}, "", types.Valset{
+		RewardAmount: sdk.NewInt(0),
+	})
+
+	assert.Nil(t, err)
+	assert.Same(t, queuedHandle, handle)
+}
+
+func TestCheckAuthzGrants(t *testing.T) {
+	expiration := time.Now().Add(time.Hour)
+
+	grants := map[string]*authz.Grant{}
+	for _, msgType := range GravityMsgTypes {
+		grant, err := authz.NewGrant(time.Now(), authz.NewGenericAuthorization(msgType), &expiration)
+		assert.Nil(t, err)
+		grants[msgType] = &grant
+	}
+
+	t.Run("granted", func(t *testing.T) {
+		err := CheckAuthzGrants(
+			context.Background(),
+			zerolog.Nop(),
+			authzQueryClient{grants: grants},
+			authzGranter,
+			authzGrantee,
+		)
+		assert.Nil(t, err)
+	})
+
+	t.Run("missing grants", func(t *testing.T) {
+		delete(grants, sdk.MsgTypeURL(&types.MsgRequestBatch{}))
+		delete(grants, sdk.MsgTypeURL(&types.MsgERC20DeployedClaim{}))
+
+		err := CheckAuthzGrants(
+			context.Background(),
+			zerolog.Nop(),
+			authzQueryClient{grants: grants},
+			authzGranter,
+			authzGrantee,
+		)
+		assert.EqualError(
+			t,
+			err,
+			authzGrantee.String()+" isn't granted by "+authzGranter.String()+" to execute: "+
+				"/gravity.v1.MsgRequestBatch, /gravity.v1.MsgERC20DeployedClaim",
+		)
+	})
+}
+
+func TestCheckFeeAllowance(t *testing.T) {
+	registry := codectypes.NewInterfaceRegistry()
+	feegrant.RegisterInterfaces(registry)
+
+	execMsgTypes := []string{sdk.MsgTypeURL(&authz.MsgExec{})}
+
+	t.Run("basic allowance", func(t *testing.T) {
+		err := CheckFeeAllowance(
+			context.Background(),
+			feegrantQueryClient{allowance: &feegrant.BasicAllowance{}},
+			registry,
+			authzGranter,
+			authzGrantee,
+			execMsgTypes,
+		)
+		assert.Nil(t, err)
+	})
+
+	t.Run("expired allowance", func(t *testing.T) {
+		expiration := time.Now().Add(-time.Hour)
+
+		err := CheckFeeAllowance(
+			context.Background(),
+			feegrantQueryClient{allowance: &feegrant.BasicAllowance{Expiration: &expiration}},
+			registry,
+			authzGranter,
+			authzGrantee,
+			execMsgTypes,
+		)
+		assert.ErrorContains(t, err, "expired")
+	})
+
+	t.Run("msg exec not allowed", func(t *testing.T) {
+		allowance, err := feegrant.NewAllowedMsgAllowance(&feegrant.BasicAllowance{}, GravityMsgTypes)
+		assert.Nil(t, err)
+
+		err = CheckFeeAllowance(
+			context.Background(),
+			feegrantQueryClient{allowance: allowance},
+			registry,
+			authzGranter,
+			authzGrantee,
+			execMsgTypes,
+		)
+		assert.EqualError(
+			t,
+			err,
+			"the fee allowance of "+authzGrantee.String()+" by "+authzGranter.String()+
+				" doesn't allow: /cosmos.authz.v1beta1.MsgExec",
+		)
+	})
+}
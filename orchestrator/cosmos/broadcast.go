@@ -17,24 +17,30 @@ import (
 )
 
 type GravityBroadcastClient interface {
+	// AccFromAddress returns the orchestrator address the Gravity msgs are sent for: the authz granter, if any, or the
+	// address of the Cosmos client.
 	AccFromAddress() sdk.AccAddress
 
+	SetAuthzGranter(granter sdk.AccAddress)
+
 	// SendValsetConfirm broadcasts in a confirmation for a specific validator set for a specific block height.
+	// The confirmation is queued, and the returned handle resolves once it's included in a block or fails.
 	SendValsetConfirm(
 		ctx context.Context,
 		ethFrom ethcmn.Address,
 		gravityID string,
 		valset types.Valset,
-	) error
+	) (*client.BroadcastHandle, error)
 
 	// SendBatchConfirm broadcasts in a confirmation for a specific transaction batch set for a specific block height
-	// since transaction batches also include validator sets this has all the arguments
+	// since transaction batches also include validator sets this has all the arguments. The confirmation is queued,
+	// and the returned handle resolves once it's included in a block or fails.
 	SendBatchConfirm(
 		ctx context.Context,
 		ethFrom ethcmn.Address,
 		gravityID string,
 		batch types.OutgoingTxBatch,
-	) error
+	) (*client.BroadcastHandle, error)
 
 	SendEthereumClaims(
 		ctx context.Context,
@@ -47,10 +53,11 @@ type GravityBroadcastClient interface {
 	) error
 
 	// SendRequestBatch broadcasts a requests a batch of withdrawal transactions to be generated on the chain.
+	// The request is queued, and the returned handle resolves once it's included in a block or fails.
 	SendRequestBatch(
 		ctx context.Context,
 		denom string,
-	) error
+	) (*client.BroadcastHandle, error)
 }
 
 type (
@@ -61,6 +68,7 @@ type (
 		ethSignerFn       keystore.SignerFn
 		ethPersonalSignFn keystore.PersonalSignFn
 		msgsPerTx         int
+		authzGranter      sdk.AccAddress
 	}
 
 	// sortableEvent exists with the only purpose to make a nicer sortable slice
@@ -81,8 +89,9 @@ func NewGravityBroadcastClient(
 	ethSignerFn keystore.SignerFn,
 	ethPersonalSignFn keystore.PersonalSignFn,
 	msgsPerTx int,
+	options ...func(GravityBroadcastClient),
 ) GravityBroadcastClient {
-	return &gravityBroadcastClient{
+	s := &gravityBroadcastClient{
 		logger:            logger.With().Str("module", "gravity_broadcast_client").Logger(),
 		daemonQueryClient: queryClient,
 		broadcastClient:   broadcastClient,
@@ -90,9 +99,19 @@ func NewGravityBroadcastClient(
 		ethPersonalSignFn: ethPersonalSignFn,
 		msgsPerTx:         msgsPerTx,
 	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
 }
 
 func (s *gravityBroadcastClient) AccFromAddress() sdk.AccAddress {
+	if !s.authzGranter.Empty() {
+		return s.authzGranter
+	}
+
 	return s.broadcastClient.FromAddress()
 }
 
@@ -101,13 +120,13 @@ func (s *gravityBroadcastClient) SendValsetConfirm(
 	ethFrom ethcmn.Address,
 	gravityID string,
 	valset types.Valset,
-) error {
+) (*client.BroadcastHandle, error) {
 
 	confirmHash := gravity.EncodeValsetConfirm(gravityID, valset)
 	signature, err := s.ethPersonalSignFn(ethFrom, confirmHash.Bytes())
 	if err != nil {
 		err = errors.New("failed to sign validator address")
-		return err
+		return nil, err
 	}
 
 	// MsgValsetConfirm
@@ -131,12 +150,13 @@ func (s *gravityBroadcastClient) SendValsetConfirm(
 		Nonce:        valset.Nonce,
 		Signature:    ethcmn.Bytes2Hex(signature),
 	}
-	if err = s.broadcastClient.QueueBroadcastMsg(msg); err != nil {
+	handle, err := s.broadcastClient.QueueBroadcastMsg(s.execMsgs(msg)...)
+	if err != nil {
 		err = errors.Wrap(err, "broadcasting MsgValsetConfirm failed")
-		return err
+		return nil, err
 	}
 
-	return nil
+	return handle, nil
 }
 
 func (s *gravityBroadcastClient) SendBatchConfirm(
@@ -144,13 +164,13 @@ func (s *gravityBroadcastClient) SendBatchConfirm(
 	ethFrom ethcmn.Address,
 	gravityID string,
 	batch types.OutgoingTxBatch,
-) error {
+) (*client.BroadcastHandle, error) {
 
 	confirmHash := gravity.EncodeTxBatchConfirm(gravityID, batch)
 	signature, err := s.ethPersonalSignFn(ethFrom, confirmHash.Bytes())
 	if err != nil {
 		err = errors.New("failed to sign validator address")
-		return err
+		return nil, err
 	}
 
 	// MsgConfirmBatch
@@ -168,12 +188,13 @@ func (s *gravityBroadcastClient) SendBatchConfirm(
 		EthSigner:     ethFrom.Hex(),
 		TokenContract: batch.TokenContract,
 	}
-	if err = s.broadcastClient.QueueBroadcastMsg(msg); err != nil {
+	handle, err := s.broadcastClient.QueueBroadcastMsg(s.execMsgs(msg)...)
+	if err != nil {
 		err = errors.Wrap(err, "broadcasting MsgConfirmBatch failed")
-		return err
+		return nil, err
 	}
 
-	return nil
+	return handle, nil
 }
 
 func (s *gravityBroadcastClient) SendEthereumClaims(
@@ -225,13 +246,13 @@ func (s *gravityBroadcastClient) SendEthereumClaims(
 		}
 	}
 
-	return s.broadcastEthereumEvents(allevents)
+	return s.broadcastEthereumEvents(ctx, allevents)
 }
 
 func (s *gravityBroadcastClient) SendRequestBatch(
 	ctx context.Context,
 	denom string,
-) error {
+) (*client.BroadcastHandle, error) {
 	// MsgRequestBatch
 	// this is a message anyone can send that requests a batch of transactions to
 	// send across the bridge be created for whatever block height this message is
@@ -246,15 +267,16 @@ func (s *gravityBroadcastClient) SendRequestBatch(
 		Denom:  denom,
 		Sender: s.AccFromAddress().String(),
 	}
-	if err := s.broadcastClient.QueueBroadcastMsg(msg); err != nil {
+	handle, err := s.broadcastClient.QueueBroadcastMsg(s.execMsgs(msg)...)
+	if err != nil {
 		err = errors.Wrap(err, "broadcasting MsgRequestBatch failed")
-		return err
+		return nil, err
 	}
 
-	return nil
+	return handle, nil
 }
 
-func (s *gravityBroadcastClient) broadcastEthereumEvents(events []sortableEvent) error {
+func (s *gravityBroadcastClient) broadcastEthereumEvents(ctx context.Context, events []sortableEvent) error {
 	msgs := []sdk.Msg{}
 
 	// Use SliceStable so we always get the same order
@@ -281,7 +303,7 @@ func (s *gravityBroadcastClient) broadcastEthereumEvents(events []sortableEvent)
 				Amount:         sdk.NewIntFromBigInt(ev.SendToCosmosEvent.Amount),
 				EthereumSender: ev.SendToCosmosEvent.Sender.Hex(),
 				CosmosReceiver: ev.SendToCosmosEvent.Destination,
-				Orchestrator:   s.broadcastClient.FromAddress().String(),
+				Orchestrator:   s.AccFromAddress().String(),
 			})
 			evCounter["send_to_cosmos"]++
 
@@ -343,22 +365,125 @@ func (s *gravityBroadcastClient) broadcastEthereumEvents(events []sortableEvent)
 	msgSets := splitMsgs(msgs, s.msgsPerTx)
 
 	for _, msgSet := range msgSets {
-		txResponse, err := s.broadcastClient.SyncBroadcastMsg(msgSet...)
-		if err != nil {
+		if err := s.broadcastClaims(ctx, msgSet, len(events)); err != nil {
 			s.logger.Err(err).Msg("broadcasting multiple claims failed")
 			return err
 		}
+	}
+
+	return nil
+}
+
+// broadcastClaims broadcasts the claims, sorted by event nonce, in a single tx. If a claim makes the tx fail, either
+// in a block or during the gas simulation, the claims already observed by the orchestrator are dropped and the rest
+// are resubmitted. If the failing claim isn't one of them, the claims before it are resubmitted and an error is
+// returned, as the claims after it can't be accepted without it. When a Gravity module error doesn't tell which claim
+// failed, the claims are bisected. Other errors, like insufficient fees or funds, are returned as they are.
+func (s *gravityBroadcastClient) broadcastClaims(ctx context.Context, claims []sdk.Msg, totalClaims int) error {
+	for len(claims) > 0 {
+		txResponse, err := s.broadcastClient.SyncBroadcastMsg(s.execMsgs(claims...)...)
+		if err == nil {
+			s.logger.Info().
+				Str("tx_hash", txResponse.TxHash).
+				Int("total_claims", totalClaims).
+				Int("claims_sent", len(claims)).
+				Msg("oracle sent set of claims successfully")
+			return nil
+		}
 
-		s.logger.Info().
-			Str("tx_hash", txResponse.TxHash).
-			Int("total_claims", len(events)).
-			Int("claims_sent", len(msgSet)).
-			Msg("oracle sent set of claims successfully")
+		index, indexKnown, isClaimErr := claimFailure(err)
+		if !isClaimErr {
+			return err
+		}
+
+		remaining, queryErr := s.dropObservedClaims(ctx, claims)
+		if queryErr != nil {
+			return errors.Wrapf(err, "failed to check the claims (%s)", queryErr)
+		}
+
+		if len(remaining) < len(claims) {
+			s.logger.Warn().
+				Int("dropped_claims", len(claims)-len(remaining)).
+				Int("remaining_claims", len(remaining)).
+				Msg("dropped claims already observed; resubmitting the remaining claims")
+
+			claims = remaining
+			continue
+		}
+
+		switch {
+		case indexKnown && index < len(claims):
+			if index > 0 {
+				if err := s.broadcastClaims(ctx, claims[:index], totalClaims); err != nil {
+					return err
+				}
+			}
+
+			return errors.Wrapf(err, "claim with event nonce %d failed", claimEventNonce(claims[index]))
+
+		case len(claims) > 1:
+			half := len(claims) / 2
+			if err := s.broadcastClaims(ctx, claims[:half], totalClaims); err != nil {
+				return err
+			}
+
+			claims = claims[half:]
+
+		default:
+			return err
+		}
 	}
 
 	return nil
 }
 
+// claimFailure returns whether a claims tx failed because of one of its claims, rather than because of the tx itself
+// (fees, funds, gas or sequence) or the node, along with the index of the failing claim when it's known. Msgs only fail
+// with an index while they're executed, in a block or during the simulation; otherwise, only the errors of the
+// Gravity module are caused by the claims.
+func claimFailure(err error) (index int, indexKnown bool, isClaimErr bool) {
+	if index, ok := client.FailedMsgIndex(err); ok {
+		return index, true, true
+	}
+
+	var txErr *client.TxError
+	if errors.As(err, &txErr) && txErr.Response.Codespace == types.ModuleName {
+		return 0, false, true
+	}
+
+	return 0, false, false
+}
+
+// dropObservedClaims returns the claims with an event nonce greater than the last event nonce claimed by the
+// orchestrator.
+func (s *gravityBroadcastClient) dropObservedClaims(ctx context.Context, claims []sdk.Msg) ([]sdk.Msg, error) {
+	lastEventResp, err := s.daemonQueryClient.LastEventNonceByAddr(ctx, &types.QueryLastEventNonceByAddrRequest{
+		Address: s.AccFromAddress().String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query last event nonce")
+	}
+
+	remaining := make([]sdk.Msg, 0, len(claims))
+	for _, msg := range claims {
+		if claim, ok := msg.(types.EthereumClaim); !ok || claim.GetEventNonce() > lastEventResp.EventNonce {
+			remaining = append(remaining, msg)
+		}
+	}
+
+	return remaining, nil
+}
+
+// claimEventNonce returns the event nonce of a claim msg.
+func claimEventNonce(msg sdk.Msg) uint64 {
+	claim, ok := msg.(types.EthereumClaim)
+	if !ok {
+		return 0
+	}
+
+	return claim.GetEventNonce()
+}
+
 func splitMsgs(buf []sdk.Msg, lim int) [][]sdk.Msg {
 	var chunk []sdk.Msg
 	chunks := make([][]sdk.Msg, 0, len(buf)/lim+1)
@@ -2,6 +2,7 @@ package cosmos
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"os"
 	"testing"
@@ -15,11 +16,17 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
 
+	"github.com/umee-network/peggo/cmd/peggo/client"
 	"github.com/umee-network/peggo/mocks"
 	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
 )
 
+// queuedHandle is the handle returned by the mocked queue.
+var queuedHandle = &client.BroadcastHandle{}
+
 func TestSendValsetConfirm(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
@@ -27,7 +34,7 @@ func TestSendValsetConfirm(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
-		mockCosmos.EXPECT().QueueBroadcastMsg(gomock.Any()).Return(nil)
+		mockCosmos.EXPECT().QueueBroadcastMsg(gomock.Any()).Return(queuedHandle, nil)
 		mockCosmos.EXPECT().FromAddress().Return(sdk.AccAddress{})
 
 		mockPersonalSignFn := func(account ethcmn.Address, data []byte) (sig []byte, err error) {
@@ -43,11 +50,12 @@ func TestSendValsetConfirm(t *testing.T) {
 			10,
 		)
 
-		err := s.SendValsetConfirm(context.Background(), ethcmn.Address{}, "", types.Valset{
+		handle, err := s.SendValsetConfirm(context.Background(), ethcmn.Address{}, "", types.Valset{
 			RewardAmount: sdk.NewInt(0),
 		})
 
 		assert.Nil(t, err)
+		assert.Same(t, queuedHandle, handle)
 	})
 
 	t.Run("failed to sign validator address", func(t *testing.T) {
@@ -69,11 +77,12 @@ func TestSendValsetConfirm(t *testing.T) {
 			10,
 		)
 
-		err := s.SendValsetConfirm(context.Background(), ethcmn.Address{}, "", types.Valset{
+		handle, err := s.SendValsetConfirm(context.Background(), ethcmn.Address{}, "", types.Valset{
 			RewardAmount: sdk.NewInt(0),
 		})
 
 		assert.EqualError(t, err, "failed to sign validator address")
+		assert.Nil(t, handle)
 	})
 
 	t.Run("error during broadcast", func(t *testing.T) {
@@ -81,7 +90,7 @@ func TestSendValsetConfirm(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
-		mockCosmos.EXPECT().QueueBroadcastMsg(gomock.Any()).Return(errors.New("some error during broadcast"))
+		mockCosmos.EXPECT().QueueBroadcastMsg(gomock.Any()).Return(nil, errors.New("some error during broadcast"))
 		mockCosmos.EXPECT().FromAddress().Return(sdk.AccAddress{})
 
 		mockPersonalSignFn := func(account ethcmn.Address, data []byte) (sig []byte, err error) {
@@ -97,11 +106,12 @@ func TestSendValsetConfirm(t *testing.T) {
 			10,
 		)
 
-		err := s.SendValsetConfirm(context.Background(), ethcmn.Address{}, "", types.Valset{
+		handle, err := s.SendValsetConfirm(context.Background(), ethcmn.Address{}, "", types.Valset{
 			RewardAmount: sdk.NewInt(0),
 		})
 
 		assert.EqualError(t, err, "broadcasting MsgValsetConfirm failed: some error during broadcast")
+		assert.Nil(t, handle)
 	})
 
 }
@@ -113,7 +123,7 @@ func TestSendBatchConfirm(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
-		mockCosmos.EXPECT().QueueBroadcastMsg(gomock.Any()).Return(nil)
+		mockCosmos.EXPECT().QueueBroadcastMsg(gomock.Any()).Return(queuedHandle, nil)
 		mockCosmos.EXPECT().FromAddress().Return(sdk.AccAddress{})
 
 		mockPersonalSignFn := func(account ethcmn.Address, data []byte) (sig []byte, err error) {
@@ -129,9 +139,10 @@ func TestSendBatchConfirm(t *testing.T) {
 			10,
 		)
 
-		err := s.SendBatchConfirm(context.Background(), ethcmn.Address{}, "", types.OutgoingTxBatch{})
+		handle, err := s.SendBatchConfirm(context.Background(), ethcmn.Address{}, "", types.OutgoingTxBatch{})
 
 		assert.Nil(t, err)
+		assert.Same(t, queuedHandle, handle)
 	})
 
 	t.Run("failed to sign validator address", func(t *testing.T) {
@@ -153,9 +164,10 @@ func TestSendBatchConfirm(t *testing.T) {
 			10,
 		)
 
-		err := s.SendBatchConfirm(context.Background(), ethcmn.Address{}, "", types.OutgoingTxBatch{})
+		handle, err := s.SendBatchConfirm(context.Background(), ethcmn.Address{}, "", types.OutgoingTxBatch{})
 
 		assert.EqualError(t, err, "failed to sign validator address")
+		assert.Nil(t, handle)
 	})
 
 	t.Run("error during broadcast", func(t *testing.T) {
@@ -163,7 +175,7 @@ func TestSendBatchConfirm(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
-		mockCosmos.EXPECT().QueueBroadcastMsg(gomock.Any()).Return(errors.New("some error during broadcast"))
+		mockCosmos.EXPECT().QueueBroadcastMsg(gomock.Any()).Return(nil, errors.New("some error during broadcast"))
 		mockCosmos.EXPECT().FromAddress().Return(sdk.AccAddress{})
 
 		mockPersonalSignFn := func(account ethcmn.Address, data []byte) (sig []byte, err error) {
@@ -179,9 +191,10 @@ func TestSendBatchConfirm(t *testing.T) {
 			10,
 		)
 
-		err := s.SendBatchConfirm(context.Background(), ethcmn.Address{}, "", types.OutgoingTxBatch{})
+		handle, err := s.SendBatchConfirm(context.Background(), ethcmn.Address{}, "", types.OutgoingTxBatch{})
 
 		assert.EqualError(t, err, "broadcasting MsgConfirmBatch failed: some error during broadcast")
+		assert.Nil(t, handle)
 	})
 
 }
@@ -392,7 +405,7 @@ func TestSendRequestBatch(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
-		mockCosmos.EXPECT().QueueBroadcastMsg(gomock.Any()).Return(nil)
+		mockCosmos.EXPECT().QueueBroadcastMsg(gomock.Any()).Return(queuedHandle, nil)
 		mockCosmos.EXPECT().FromAddress().Return(sdk.AccAddress{})
 
 		s := NewGravityBroadcastClient(
@@ -404,9 +417,10 @@ func TestSendRequestBatch(t *testing.T) {
 			10,
 		)
 
-		err := s.SendRequestBatch(context.Background(), "uumee")
+		handle, err := s.SendRequestBatch(context.Background(), "uumee")
 
 		assert.Nil(t, err)
+		assert.Same(t, queuedHandle, handle)
 	})
 
 	t.Run("error during broadcast", func(t *testing.T) {
@@ -414,7 +428,7 @@ func TestSendRequestBatch(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
-		mockCosmos.EXPECT().QueueBroadcastMsg(gomock.Any()).Return(errors.New("some error during broadcast"))
+		mockCosmos.EXPECT().QueueBroadcastMsg(gomock.Any()).Return(nil, errors.New("some error during broadcast"))
 		mockCosmos.EXPECT().FromAddress().Return(sdk.AccAddress{})
 
 		s := NewGravityBroadcastClient(
@@ -426,9 +440,169 @@ func TestSendRequestBatch(t *testing.T) {
 			10,
 		)
 
-		err := s.SendRequestBatch(context.Background(), "uumee")
+		handle, err := s.SendRequestBatch(context.Background(), "uumee")
 
 		assert.EqualError(t, err, "broadcasting MsgRequestBatch failed: some error during broadcast")
+		assert.Nil(t, handle)
 	})
 
 }
+
+type claimNoncesMatcher []uint64
+
+func (m claimNoncesMatcher) Matches(x interface{}) bool {
+	msgs, ok := x.([]sdk.Msg)
+	if !ok || len(msgs) != len(m) {
+		return false
+	}
+
+	for i, msg := range msgs {
+		if claimEventNonce(msg) != m[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m claimNoncesMatcher) String() string {
+	return fmt.Sprintf("has claims with the event nonces %v", []uint64(m))
+}
+
+func HasClaimNonces(nonces ...uint64) gomock.Matcher {
+	return claimNoncesMatcher(nonces)
+}
+
+func TestSendEthereumClaimsPartialFailure(t *testing.T) {
+	deposits := []*wrappers.GravitySendToCosmosEvent{
+		{EventNonce: big.NewInt(1), Amount: big.NewInt(1)},
+		{EventNonce: big.NewInt(2), Amount: big.NewInt(2)},
+		{EventNonce: big.NewInt(3), Amount: big.NewInt(3)},
+		{EventNonce: big.NewInt(4), Amount: big.NewInt(4)},
+	}
+
+	txErr := func(rawLog string) error {
+		return &client.TxError{Response: &sdk.TxResponse{Code: 1, RawLog: rawLog}}
+	}
+
+	gravityTxErr := func(rawLog string) error {
+		return &client.TxError{Response: &sdk.TxResponse{Code: 4, Codespace: types.ModuleName, RawLog: rawLog}}
+	}
+
+	// simulationErr is the error returned when a claim fails during the gas simulation, before the tx is broadcast.
+	simulationErr := func(msg string) error {
+		return errors.Wrap(grpcstatus.Error(codes.Unknown, msg), "failed to CalculateGas")
+	}
+
+	setup := func(t *testing.T, lastEventNonce uint64) (*mocks.MockCosmosClient, *gravityBroadcastClient) {
+		mockCtrl := gomock.NewController(t)
+
+		mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
+		mockCosmos.EXPECT().FromAddress().Return(sdk.AccAddress{}).AnyTimes()
+
+		mockQuery := mocks.NewMockQueryClient(mockCtrl)
+		mockQuery.EXPECT().
+			LastEventNonceByAddr(gomock.Any(), gomock.Any()).
+			Return(&types.QueryLastEventNonceByAddrResponse{EventNonce: lastEventNonce}, nil).
+			AnyTimes()
+
+		return mockCosmos, &gravityBroadcastClient{
+			logger:            zerolog.Nop(),
+			daemonQueryClient: mockQuery,
+			broadcastClient:   mockCosmos,
+			msgsPerTx:         10,
+		}
+	}
+
+	t.Run("drop observed claims", func(t *testing.T) {
+		mockCosmos, s := setup(t, 2)
+		gomock.InOrder(
+			mockCosmos.EXPECT().SyncBroadcastMsg(HasClaimNonces(1, 2, 3, 4)).
+				Return(nil, txErr("failed to execute message; message index: 0: non contiguous event nonce")),
+			mockCosmos.EXPECT().SyncBroadcastMsg(HasClaimNonces(3, 4)).Return(&sdk.TxResponse{}, nil),
+		)
+
+		err := s.SendEthereumClaims(context.Background(), 0, deposits, nil, nil, nil, time.Microsecond)
+		assert.Nil(t, err)
+	})
+
+	t.Run("resubmit claims before the failing one", func(t *testing.T) {
+		mockCosmos, s := setup(t, 0)
+		gomock.InOrder(
+			mockCosmos.EXPECT().SyncBroadcastMsg(HasClaimNonces(1, 2, 3, 4)).
+				Return(nil, txErr("failed to execute message; message index: 2: invalid claim")),
+			mockCosmos.EXPECT().SyncBroadcastMsg(HasClaimNonces(1, 2)).Return(&sdk.TxResponse{}, nil),
+		)
+
+		err := s.SendEthereumClaims(context.Background(), 0, deposits, nil, nil, nil, time.Microsecond)
+		assert.EqualError(
+			t,
+			err,
+			"claim with event nonce 3 failed: tx  failed with error 1 (): "+
+				"failed to execute message; message index: 2: invalid claim",
+		)
+	})
+
+	t.Run("drop observed claims after a simulation error", func(t *testing.T) {
+		mockCosmos, s := setup(t, 2)
+		gomock.InOrder(
+			mockCosmos.EXPECT().SyncBroadcastMsg(HasClaimNonces(1, 2, 3, 4)).
+				Return(nil, simulationErr("failed to execute message; message index: 0: non contiguous event nonce")),
+			mockCosmos.EXPECT().SyncBroadcastMsg(HasClaimNonces(3, 4)).Return(&sdk.TxResponse{}, nil),
+		)
+
+		err := s.SendEthereumClaims(context.Background(), 0, deposits, nil, nil, nil, time.Microsecond)
+		assert.Nil(t, err)
+	})
+
+	t.Run("resubmit claims before the one failing the simulation", func(t *testing.T) {
+		mockCosmos, s := setup(t, 0)
+		gomock.InOrder(
+			mockCosmos.EXPECT().SyncBroadcastMsg(HasClaimNonces(1, 2, 3, 4)).
+				Return(nil, simulationErr("failed to execute message; message index: 1: invalid claim")),
+			mockCosmos.EXPECT().SyncBroadcastMsg(HasClaimNonces(1)).Return(&sdk.TxResponse{}, nil),
+		)
+
+		err := s.SendEthereumClaims(context.Background(), 0, deposits, nil, nil, nil, time.Microsecond)
+		assert.EqualError(
+			t,
+			err,
+			"claim with event nonce 2 failed: failed to CalculateGas: rpc error: code = Unknown desc = "+
+				"failed to execute message; message index: 1: invalid claim",
+		)
+	})
+
+	t.Run("bisect claims", func(t *testing.T) {
+		mockCosmos, s := setup(t, 0)
+		gomock.InOrder(
+			mockCosmos.EXPECT().SyncBroadcastMsg(HasClaimNonces(1, 2, 3, 4)).Return(nil, gravityTxErr("invalid")),
+			mockCosmos.EXPECT().SyncBroadcastMsg(HasClaimNonces(1, 2)).Return(&sdk.TxResponse{}, nil),
+			mockCosmos.EXPECT().SyncBroadcastMsg(HasClaimNonces(3, 4)).Return(&sdk.TxResponse{}, nil),
+		)
+
+		err := s.SendEthereumClaims(context.Background(), 0, deposits, nil, nil, nil, time.Microsecond)
+		assert.Nil(t, err)
+	})
+
+	t.Run("tx errors aren't bisected", func(t *testing.T) {
+		for _, err := range []error{
+			txErr("out of memory"),
+			&client.TxError{Response: &sdk.TxResponse{Code: 5, Codespace: "sdk", RawLog: "insufficient funds"}},
+			simulationErr("insufficient fees; got: 1uumee required: 2uumee: insufficient fee"),
+		} {
+			mockCosmos, s := setup(t, 0)
+			mockCosmos.EXPECT().SyncBroadcastMsg(HasClaimNonces(1, 2, 3, 4)).Return(nil, err)
+
+			sendErr := s.SendEthereumClaims(context.Background(), 0, deposits, nil, nil, nil, time.Microsecond)
+			assert.Equal(t, err, sendErr)
+		}
+	})
+
+	t.Run("broadcast error", func(t *testing.T) {
+		mockCosmos, s := setup(t, 0)
+		mockCosmos.EXPECT().SyncBroadcastMsg(HasClaimNonces(1, 2, 3, 4)).Return(nil, errors.New("connection refused"))
+
+		err := s.SendEthereumClaims(context.Background(), 0, deposits, nil, nil, nil, time.Microsecond)
+		assert.EqualError(t, err, "connection refused")
+	})
+}
@@ -0,0 +1,299 @@
+package emergency
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Actions taken when the valset on Ethereum doesn't match the valset on Cosmos.
+const (
+	// ActionLog only logs the evidence.
+	ActionLog = "log"
+	// ActionAlert sends an alert and dumps the evidence, but keeps relaying and signing.
+	ActionAlert = "alert"
+	// ActionHalt sends an alert, dumps the evidence and stops relaying and signing. Not signing valsets and batches
+	// gets the validator slashed, so the halt is meant for a confirmed hijack only. The halt is persisted to the halt
+	// file in the evidence directory, so it survives restarts until the file is deleted.
+	ActionHalt = "halt"
+)
+
+// HaltFile is the name of the file in the evidence directory that keeps relaying and signing halted.
+const HaltFile = "valset-hijack-halt.json"
+
+// Signature is a signature of a member of the previous valset, submitted to the Gravity contract to approve a valset
+// update.
+type Signature struct {
+	Validator ethcmn.Address `json:"validator"`
+	Power     uint64         `json:"power"`
+	V         uint8          `json:"v"`
+	R         ethcmn.Hash    `json:"r"`
+	S         ethcmn.Hash    `json:"s"`
+}
+
+// Evidence of a valset on Ethereum that doesn't match the one on Cosmos, which could mean validators colluded to
+// hijack the bridge.
+type Evidence struct {
+	DetectedAt time.Time `json:"detected_at"`
+	Reason     string    `json:"reason"`
+
+	// EthereumValset is the valset set on the Gravity contract. CosmosValset is the valset with the same nonce on
+	// Cosmos, or nil if Cosmos doesn't have one.
+	EthereumValset *types.Valset `json:"ethereum_valset"`
+	CosmosValset   *types.Valset `json:"cosmos_valset"`
+
+	// EventTxHash and EventBlock locate the ValsetUpdatedEvent of the Ethereum valset.
+	EventTxHash ethcmn.Hash `json:"event_tx_hash"`
+	EventBlock  uint64      `json:"event_block"`
+
+	// Signatures approving the valset update. They're empty if they couldn't be decoded from the tx, in which case
+	// SignaturesError tells why.
+	Signatures      []Signature `json:"signatures,omitempty"`
+	SignaturesError string      `json:"signatures_error,omitempty"`
+}
+
+// LoadEvidence reads the evidence dumped to a file.
+func LoadEvidence(path string) (*Evidence, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read evidence file")
+	}
+
+	var evidence Evidence
+	if err := json.Unmarshal(bz, &evidence); err != nil {
+		return nil, errors.Wrap(err, "failed to decode evidence file")
+	}
+
+	return &evidence, nil
+}
+
+// Emergency is the response to a valset on Ethereum that doesn't match the one on Cosmos.
+type Emergency interface {
+	// Trigger responds to the evidence with the configured action. Evidence of the same valset update is only
+	// responded to once.
+	Trigger(ctx context.Context, evidence Evidence) error
+
+	// Halted returns true if relaying and signing must stop.
+	Halted() bool
+}
+
+type emergency struct {
+	logger zerolog.Logger
+	opts   *options
+
+	mtx       sync.Mutex
+	halted    bool
+	triggered map[ethcmn.Hash]struct{}
+}
+
+// NewEmergency returns the emergency response configured by the options.
+func NewEmergency(logger zerolog.Logger, opts ...Option) (Emergency, error) {
+	e := &emergency{
+		logger:    logger.With().Str("module", "emergency").Logger(),
+		opts:      defaultOptions(),
+		triggered: map[ethcmn.Hash]struct{}{},
+	}
+
+	if err := applyOptions(e.opts, opts...); err != nil {
+		return nil, err
+	}
+
+	if e.opts.EvidenceDir != "" {
+		haltPath := filepath.Join(e.opts.EvidenceDir, HaltFile)
+		if _, err := os.Stat(haltPath); err == nil {
+			e.halted = true
+			e.logger.Error().
+				Str("halt_file", haltPath).
+				Msg("relaying and signing are halted by a previous valset hijack; delete the halt file to resume")
+		} else if !os.IsNotExist(err) {
+			return nil, errors.Wrap(err, "failed to check halt file")
+		}
+	}
+
+	return e, nil
+}
+
+func (e *emergency) Halted() bool {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	return e.halted
+}
+
+func (e *emergency) Trigger(ctx context.Context, evidence Evidence) error {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	key := evidenceKey(evidence)
+	if _, ok := e.triggered[key]; ok {
+		return nil
+	}
+	e.triggered[key] = struct{}{}
+
+	if evidence.DetectedAt.IsZero() {
+		evidence.DetectedAt = time.Now().UTC()
+	}
+
+	if e.opts.Action == ActionHalt {
+		e.halted = true
+	}
+
+	// The highest level is used so the alert gets the highest severity in the log sinks (e.g. GCP).
+	e.logger.WithLevel(zerolog.PanicLevel).
+		Str("action", e.opts.Action).
+		Str("reason", evidence.Reason).
+		Uint64("eth_valset_nonce", evidence.EthereumValset.Nonce).
+		Str("event_tx_hash", evidence.EventTxHash.Hex()).
+		Msg("valset on Ethereum doesn't match the valset on Cosmos. Possible bridge hijacking!")
+
+	if e.opts.Action == ActionLog {
+		return nil
+	}
+
+	var errs []string
+
+	if e.opts.Action == ActionHalt && e.opts.EvidenceDir != "" {
+		if err := e.persistHalt(evidence); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if e.opts.EvidenceDir != "" {
+		path, err := e.dumpEvidence(evidence)
+		if err != nil {
+			errs = append(errs, err.Error())
+		} else {
+			e.logger.Error().Str("path", path).Msg("dumped valset hijack evidence")
+		}
+	}
+
+	if e.opts.WebhookURL != "" {
+		if err := e.sendAlert(ctx, evidence); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Errorf("emergency response failed: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// dumpEvidence writes the evidence to a new file in the evidence directory and returns its path.
+func (e *emergency) dumpEvidence(evidence Evidence) (string, error) {
+	if err := os.MkdirAll(e.opts.EvidenceDir, 0o700); err != nil {
+		return "", errors.Wrap(err, "failed to create evidence directory")
+	}
+
+	bz, err := json.MarshalIndent(evidence, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode evidence")
+	}
+
+	path := filepath.Join(
+		e.opts.EvidenceDir,
+		fmt.Sprintf("valset-hijack-%d-%d.json", evidence.EthereumValset.Nonce, evidence.DetectedAt.Unix()),
+	)
+
+	if err := os.WriteFile(path, bz, 0o600); err != nil {
+		return "", errors.Wrap(err, "failed to write evidence file")
+	}
+
+	return path, nil
+}
+
+// persistHalt writes the evidence to the halt file, which keeps relaying and signing halted after a restart.
+func (e *emergency) persistHalt(evidence Evidence) error {
+	if err := os.MkdirAll(e.opts.EvidenceDir, 0o700); err != nil {
+		return errors.Wrap(err, "failed to create evidence directory")
+	}
+
+	bz, err := json.MarshalIndent(evidence, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode evidence")
+	}
+
+	haltPath := filepath.Join(e.opts.EvidenceDir, HaltFile)
+	if err := os.WriteFile(haltPath, bz, 0o600); err != nil {
+		return errors.Wrap(err, "failed to write halt file")
+	}
+
+	e.logger.Error().Str("halt_file", haltPath).Msg("relaying and signing are halted; delete the halt file to resume")
+
+	return nil
+}
+
+// alert is the payload posted to the webhook. The text field makes it readable by chat webhooks (e.g. Slack).
+type alert struct {
+	Text     string   `json:"text"`
+	Severity string   `json:"severity"`
+	Action   string   `json:"action"`
+	Evidence Evidence `json:"evidence"`
+}
+
+// sendAlert posts the evidence to the webhook.
+func (e *emergency) sendAlert(ctx context.Context, evidence Evidence) error {
+	text := fmt.Sprintf(
+		"Peggo detected a valset on Ethereum (nonce %d, tx %s) that doesn't match Cosmos: %s. Possible bridge hijacking!",
+		evidence.EthereumValset.Nonce,
+		evidence.EventTxHash.Hex(),
+		evidence.Reason,
+	)
+	if e.opts.Action == ActionHalt {
+		text += " Relaying and signing are halted."
+	}
+
+	bz, err := json.Marshal(alert{
+		Text:     text,
+		Severity: "critical",
+		Action:   e.opts.Action,
+		Evidence: evidence,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode alert")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.opts.WebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.opts.WebhookURL, bytes.NewReader(bz))
+	if err != nil {
+		return errors.Wrap(err, "failed to create alert request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send alert")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("alert webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// evidenceKey identifies the valset update of the evidence.
+func evidenceKey(evidence Evidence) ethcmn.Hash {
+	if evidence.EventTxHash != (ethcmn.Hash{}) {
+		return evidence.EventTxHash
+	}
+
+	return ethcmn.BigToHash(new(big.Int).SetUint64(evidence.EthereumValset.Nonce))
+}
@@ -0,0 +1,152 @@
+package emergency
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	validatorA = ethcmn.HexToAddress("0x00000000000000000000000000000000000000a1")
+	validatorB = ethcmn.HexToAddress("0x00000000000000000000000000000000000000b1")
+	attacker   = ethcmn.HexToAddress("0x00000000000000000000000000000000000000e1")
+)
+
+func testEvidence() Evidence {
+	return Evidence{
+		Reason: "cosmos and Ethereum valsets have different members or powers",
+		EthereumValset: &types.Valset{
+			Nonce:        5,
+			Members:      []types.BridgeValidator{{Power: 4294967295, EthereumAddress: attacker.Hex()}},
+			RewardAmount: sdk.ZeroInt(),
+		},
+		CosmosValset: &types.Valset{
+			Nonce: 5,
+			Members: []types.BridgeValidator{
+				{Power: 3000000000, EthereumAddress: validatorA.Hex()},
+				{Power: 1294967295, EthereumAddress: validatorB.Hex()},
+			},
+			RewardAmount: sdk.ZeroInt(),
+		},
+		EventTxHash: ethcmn.HexToHash("0x01"),
+		EventBlock:  100,
+		Signatures: []Signature{
+			{Validator: validatorA, Power: 3000000000, V: 27, R: ethcmn.HexToHash("0x02"), S: ethcmn.HexToHash("0x03")},
+			{Validator: validatorB, Power: 1294967295},
+		},
+	}
+}
+
+func TestEmergency(t *testing.T) {
+	var alerts []alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a alert
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&a))
+		alerts = append(alerts, a)
+	}))
+	defer server.Close()
+
+	evidenceDir := filepath.Join(t.TempDir(), "evidence")
+
+	e, err := NewEmergency(
+		zerolog.Nop(),
+		OptionAction(ActionHalt),
+		OptionWebhook(server.URL),
+		OptionEvidenceDir(evidenceDir),
+	)
+	assert.Nil(t, err)
+	assert.False(t, e.Halted())
+
+	assert.Nil(t, e.Trigger(context.Background(), testEvidence()))
+	assert.True(t, e.Halted())
+
+	// The same valset update is only responded to once.
+	assert.Nil(t, e.Trigger(context.Background(), testEvidence()))
+
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "critical", alerts[0].Severity)
+	assert.Contains(t, alerts[0].Text, "Relaying and signing are halted")
+	assert.Equal(t, uint64(5), alerts[0].Evidence.EthereumValset.Nonce)
+
+	files, err := filepath.Glob(filepath.Join(evidenceDir, "valset-hijack-5-*.json"))
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+
+	evidence, err := LoadEvidence(files[0])
+	assert.Nil(t, err)
+	assert.Equal(t, testEvidence().Signatures, evidence.Signatures)
+	assert.Equal(t, testEvidence().CosmosValset.Members, evidence.CosmosValset.Members)
+	assert.False(t, evidence.DetectedAt.IsZero())
+
+	var report bytes.Buffer
+	assert.Nil(t, WriteReport(&report, evidence))
+	assert.Contains(t, report.String(), "Signed by 1 of 2 validators of the previous valset, with 69.8% of its power.")
+	assert.Contains(t, report.String(), "MISSING ON ETHEREUM")
+	assert.Contains(t, report.String(), validatorB.Hex())
+
+	t.Run("halt survives restarts", func(t *testing.T) {
+		restarted, err := NewEmergency(zerolog.Nop(), OptionAction(ActionHalt), OptionEvidenceDir(evidenceDir))
+		assert.Nil(t, err)
+		assert.True(t, restarted.Halted())
+
+		haltEvidence, err := LoadEvidence(filepath.Join(evidenceDir, HaltFile))
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(5), haltEvidence.EthereumValset.Nonce)
+
+		// Deleting the halt file resumes relaying and signing.
+		assert.Nil(t, os.Remove(filepath.Join(evidenceDir, HaltFile)))
+
+		resumed, err := NewEmergency(zerolog.Nop(), OptionAction(ActionHalt), OptionEvidenceDir(evidenceDir))
+		assert.Nil(t, err)
+		assert.False(t, resumed.Halted())
+	})
+
+	t.Run("alert", func(t *testing.T) {
+		e, err := NewEmergency(zerolog.Nop(), OptionAction(ActionAlert), OptionEvidenceDir(t.TempDir()))
+		assert.Nil(t, err)
+
+		assert.Nil(t, e.Trigger(context.Background(), testEvidence()))
+		assert.False(t, e.Halted())
+	})
+
+	t.Run("failed alert", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		e, err := NewEmergency(zerolog.Nop(), OptionAction(ActionHalt), OptionWebhook(server.URL))
+		assert.Nil(t, err)
+
+		// Relaying and signing are halted even if the alert fails.
+		assert.Error(t, e.Trigger(context.Background(), testEvidence()))
+		assert.True(t, e.Halted())
+	})
+
+	t.Run("alert by default", func(t *testing.T) {
+		e, err := NewEmergency(zerolog.Nop(), OptionEvidenceDir(t.TempDir()))
+		assert.Nil(t, err)
+
+		assert.Nil(t, e.Trigger(context.Background(), testEvidence()))
+		assert.False(t, e.Halted())
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := NewEmergency(zerolog.Nop(), OptionAction("shutdown"))
+		assert.Error(t, err)
+
+		_, err = NewEmergency(zerolog.Nop(), OptionWebhook("not a url"))
+		assert.Error(t, err)
+	})
+}
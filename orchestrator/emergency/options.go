@@ -0,0 +1,73 @@
+package emergency
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Option func(o *options) error
+
+type options struct {
+	Action         string
+	EvidenceDir    string
+	WebhookURL     string
+	WebhookTimeout time.Duration
+}
+
+func defaultOptions() *options {
+	return &options{
+		Action:         ActionAlert,
+		WebhookTimeout: 10 * time.Second,
+	}
+}
+
+func applyOptions(o *options, opts ...Option) error {
+	for _, oo := range opts {
+		if err := oo(o); err != nil {
+			err = errors.Wrap(err, "failed to apply option to emergency")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OptionAction sets the action taken when a valset hijack is detected (log, alert or halt).
+func OptionAction(action string) Option {
+	return func(o *options) error {
+		switch action {
+		case ActionLog, ActionAlert, ActionHalt:
+		default:
+			return errors.Errorf("invalid emergency action %q", action)
+		}
+
+		o.Action = action
+		return nil
+	}
+}
+
+// OptionEvidenceDir sets the directory the evidence is dumped to.
+func OptionEvidenceDir(dir string) Option {
+	return func(o *options) error {
+		o.EvidenceDir = dir
+		return nil
+	}
+}
+
+// OptionWebhook sets the URL the alerts are posted to.
+func OptionWebhook(webhookURL string) Option {
+	return func(o *options) error {
+		if webhookURL == "" {
+			return nil
+		}
+
+		if _, err := url.ParseRequestURI(webhookURL); err != nil {
+			return errors.Wrap(err, "invalid webhook URL")
+		}
+
+		o.WebhookURL = webhookURL
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+package emergency
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+)
+
+// WriteReport writes a human-readable report of the evidence, meant to be attached to a governance proposal.
+func WriteReport(w io.Writer, evidence *Evidence) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "VALSET HIJACK REPORT")
+	fmt.Fprintf(tw, "Detected at:\t%s\n", evidence.DetectedAt.Format(time.RFC3339))
+	fmt.Fprintf(tw, "Reason:\t%s\n", evidence.Reason)
+	fmt.Fprintf(tw, "Valset nonce:\t%d\n", evidence.EthereumValset.Nonce)
+	fmt.Fprintf(tw, "ValsetUpdated event tx:\t%s\n", evidence.EventTxHash.Hex())
+	fmt.Fprintf(tw, "ValsetUpdated event block:\t%d\n", evidence.EventBlock)
+
+	cosmosPowers := valsetPowers(evidence.CosmosValset)
+	ethPowers := valsetPowers(evidence.EthereumValset)
+
+	fmt.Fprintln(tw, "\nETHEREUM VALSET\tPOWER\tCOSMOS POWER")
+	for _, member := range evidence.EthereumValset.Members {
+		cosmosPower := "-"
+		if power, ok := cosmosPowers[strings.ToLower(member.EthereumAddress)]; ok {
+			cosmosPower = fmt.Sprint(power)
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", member.EthereumAddress, member.Power, cosmosPower)
+	}
+
+	if evidence.CosmosValset == nil {
+		fmt.Fprintln(tw, "\nCosmos has no valset with this nonce.")
+	} else {
+		var missing []types.BridgeValidator
+		for _, member := range evidence.CosmosValset.Members {
+			if _, ok := ethPowers[strings.ToLower(member.EthereumAddress)]; !ok {
+				missing = append(missing, member)
+			}
+		}
+
+		if len(missing) > 0 {
+			fmt.Fprintln(tw, "\nMISSING ON ETHEREUM\tCOSMOS POWER")
+			for _, member := range missing {
+				fmt.Fprintf(tw, "%s\t%d\n", member.EthereumAddress, member.Power)
+			}
+		}
+	}
+
+	if evidence.SignaturesError != "" {
+		fmt.Fprintf(tw, "\nSignatures could not be decoded: %s\n", evidence.SignaturesError)
+	}
+
+	if len(evidence.Signatures) > 0 {
+		var signedPower, totalPower uint64
+		signers := make([]Signature, 0, len(evidence.Signatures))
+		for _, sig := range evidence.Signatures {
+			totalPower += sig.Power
+
+			// The contract skips the validators whose signature has a zero V.
+			if sig.V != 0 {
+				signedPower += sig.Power
+				signers = append(signers, sig)
+			}
+		}
+
+		sort.SliceStable(signers, func(i, j int) bool {
+			return signers[i].Power > signers[j].Power
+		})
+
+		fmt.Fprintf(tw, "\nSigned by %d of %d validators of the previous valset, with %s of its power.\n",
+			len(signers),
+			len(evidence.Signatures),
+			percent(signedPower, totalPower),
+		)
+
+		fmt.Fprintln(tw, "SIGNER\tPOWER\tV\tR\tS")
+		for _, sig := range signers {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\n", sig.Validator.Hex(), sig.Power, sig.V, sig.R.Hex(), sig.S.Hex())
+		}
+	}
+
+	return tw.Flush()
+}
+
+// valsetPowers returns the power of each member of the valset, by lowercase Ethereum address.
+func valsetPowers(valset *types.Valset) map[string]uint64 {
+	powers := map[string]uint64{}
+	if valset == nil {
+		return powers
+	}
+
+	for _, member := range valset.Members {
+		powers[strings.ToLower(member.EthereumAddress)] = member.Power
+	}
+
+	return powers
+}
+
+func percent(part, total uint64) string {
+	if total == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.1f%%", float64(part)*100/float64(total))
+}
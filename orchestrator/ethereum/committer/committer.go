@@ -22,14 +22,61 @@ type EVMCommitter interface {
 		recipient ethcmn.Address,
 		txData []byte,
 		gasCost uint64,
-		gasPrice *big.Int,
+		gasFees GasFees,
 	) (txHash ethcmn.Hash, err error)
 
+	// SendTxWithNonce signs and sends a transaction with the given nonce instead of the next one from the nonce
+	// cache. It's used to replace (speed up or cancel) transactions that are stuck in the mempool.
+	SendTxWithNonce(
+		ctx context.Context,
+		nonce uint64,
+		recipient ethcmn.Address,
+		txData []byte,
+		gasCost uint64,
+		gasFees GasFees,
+	) (txHash ethcmn.Hash, err error)
+
+	// EstimateGas estimates the gas limit and the fee parameters of a transaction. The returned fees are the ones
+	// that should be used in SendTx.
 	EstimateGas(
 		ctx context.Context,
 		recipient ethcmn.Address,
 		txData []byte,
-	) (gasCost uint64, gasPrice *big.Int, err error)
+	) (gasCost uint64, gasFees GasFees, err error)
+}
+
+// GasFees holds the fee parameters of an Ethereum transaction. Legacy transactions only use GasPrice, while
+// dynamic-fee (EIP-1559) transactions use GasFeeCap (maxFeePerGas) and GasTipCap (maxPriorityFeePerGas). BaseFee
+// is the base fee expected for the next block at the time the fees were estimated.
+type GasFees struct {
+	GasPrice  *big.Int
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+	BaseFee   *big.Int
+}
+
+// IsDynamic returns true if the fees are meant for a dynamic-fee (EIP-1559) transaction.
+func (f GasFees) IsDynamic() bool {
+	return f.GasFeeCap != nil && f.GasTipCap != nil
+}
+
+// EffectiveGasPrice returns the price per unit of gas we expect to pay. For legacy transactions that's the gas
+// price, for dynamic-fee transactions it's the base fee plus the tip, capped by the fee cap.
+func (f GasFees) EffectiveGasPrice() *big.Int {
+	if !f.IsDynamic() {
+		return f.GasPrice
+	}
+
+	if f.BaseFee == nil {
+		return f.GasFeeCap
+	}
+
+	price := new(big.Int).Add(f.BaseFee, f.GasTipCap)
+	if price.Cmp(f.GasFeeCap) > 0 {
+		return new(big.Int).Set(f.GasFeeCap)
+	}
+
+	return price
 }
 
 type EVMCommitterOption func(o *options) error
@@ -38,14 +85,23 @@ type options struct {
 	GasPrice   decimal.Decimal
 	GasLimit   uint64
 	RPCTimeout time.Duration
+
+	// LegacyTx forces legacy transactions even if the chain supports EIP-1559.
+	LegacyTx bool
+	// FeeHistoryBlocks is the number of blocks used to compute the priority fee of dynamic-fee transactions.
+	FeeHistoryBlocks uint64
+	// FeeHistoryPercentile is the percentile of the priority fees paid in each block used for our own priority fee.
+	FeeHistoryPercentile float64
 }
 
 func defaultOptions() *options {
 	v, _ := decimal.NewFromString("20")
 	return &options{
-		GasPrice:   v.Shift(9), // 20 gwei
-		GasLimit:   1500000,
-		RPCTimeout: 10 * time.Second,
+		GasPrice:             v.Shift(9), // 20 gwei
+		GasLimit:             1500000,
+		RPCTimeout:           10 * time.Second,
+		FeeHistoryBlocks:     20,
+		FeeHistoryPercentile: 50,
 	}
 }
 
@@ -100,3 +156,29 @@ func TxBroadcastTimeout(dur time.Duration) EVMCommitterOption {
 		return nil
 	}
 }
+
+// OptionLegacyTx forces the committer to send legacy transactions, for chains that don't support EIP-1559.
+func OptionLegacyTx(legacy bool) EVMCommitterOption {
+	return func(o *options) error {
+		o.LegacyTx = legacy
+		return nil
+	}
+}
+
+// OptionFeeHistory sets the number of blocks and the reward percentile used to compute the priority fee of
+// dynamic-fee transactions.
+func OptionFeeHistory(blocks uint64, percentile float64) EVMCommitterOption {
+	return func(o *options) error {
+		if blocks == 0 {
+			return errors.New("fee history blocks must be greater than zero")
+		}
+
+		if percentile < 0 || percentile > 100 {
+			return errors.Errorf("invalid fee history percentile: %f", percentile)
+		}
+
+		o.FeeHistoryBlocks = blocks
+		o.FeeHistoryPercentile = percentile
+		return nil
+	}
+}
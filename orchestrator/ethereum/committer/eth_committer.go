@@ -3,6 +3,7 @@ package committer
 import (
 	"context"
 	"math/big"
+	"sort"
 	"strings"
 
 	"github.com/ethereum/go-ethereum"
@@ -74,39 +75,141 @@ func (e *ethCommitter) EstimateGas(
 	ctx context.Context,
 	recipient ethcmn.Address,
 	txData []byte,
-) (gasCost uint64, gasPrice *big.Int, err error) {
+) (gasCost uint64, gasFees GasFees, err error) {
+	gasFees, err = e.suggestGasFees(ctx)
+	if err != nil {
+		return 0, GasFees{}, err
+	}
 
-	opts := &bind.TransactOpts{
-		From:     e.fromAddress,
-		Signer:   e.fromSigner,
-		GasPrice: e.committerOpts.GasPrice.BigInt(),
-		GasLimit: e.committerOpts.GasLimit,
-		Context:  ctx, // with RPC timeout
+	msg := ethereum.CallMsg{From: e.fromAddress, To: &recipient, Value: nil, Data: txData}
+	if gasFees.IsDynamic() {
+		msg.GasFeeCap = gasFees.GasFeeCap
+		msg.GasTipCap = gasFees.GasTipCap
+	} else {
+		msg.GasPrice = gasFees.GasPrice
+	}
+
+	gasCost, err = e.evmProvider.EstimateGas(ctx, msg)
+
+	// Estimated gas cost may not be accurate, so we multiply the result by the gas limit adjustment factor.
+	gasCost = uint64(float64(gasCost) * e.ethGasLimitAdjustment)
+
+	return gasCost, gasFees, err
+}
+
+// suggestGasFees returns the fees to be used in a new transaction. If the chain supports EIP-1559 (the latest header
+// has a base fee) and legacy transactions are not forced, dynamic fees are derived from eth_feeHistory. Otherwise,
+// the node's suggested gas price is used.
+func (e *ethCommitter) suggestGasFees(ctx context.Context) (GasFees, error) {
+	if !e.committerOpts.LegacyTx {
+		latestHeader, err := e.evmProvider.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return GasFees{}, errors.Wrap(err, "failed to get latest header")
+		}
+
+		if latestHeader.BaseFee != nil {
+			return e.suggestDynamicFees(ctx)
+		}
 	}
 
-	suggestedGasPrice, err := e.evmProvider.SuggestGasPrice(opts.Context)
+	suggestedGasPrice, err := e.evmProvider.SuggestGasPrice(ctx)
 	if err != nil {
-		return 0, nil, errors.Errorf("failed to suggest gas price: %v", err)
+		return GasFees{}, errors.Errorf("failed to suggest gas price: %v", err)
 	}
 
 	// Suggested gas price may not be accurate, so we multiply the result by the gas price adjustment factor.
+	return GasFees{GasPrice: e.adjustGasPrice(suggestedGasPrice)}, nil
+}
+
+// suggestDynamicFees computes the fees of a dynamic-fee transaction. The priority fee is the median of the configured
+// percentile of the priority fees paid in the latest blocks, and the fee cap leaves room for the base fee to double
+// before the transaction becomes unexecutable.
+func (e *ethCommitter) suggestDynamicFees(ctx context.Context) (GasFees, error) {
+	feeHistory, err := e.evmProvider.FeeHistory(
+		ctx,
+		e.committerOpts.FeeHistoryBlocks,
+		nil,
+		[]float64{e.committerOpts.FeeHistoryPercentile},
+	)
+	if err != nil {
+		return GasFees{}, errors.Wrap(err, "failed to get fee history")
+	}
+
+	if len(feeHistory.BaseFee) == 0 {
+		return GasFees{}, errors.New("fee history returned no base fees")
+	}
+
+	// eth_feeHistory returns one extra base fee, which is the base fee of the next block.
+	baseFee := feeHistory.BaseFee[len(feeHistory.BaseFee)-1]
+
+	gasTipCap := feeHistoryTip(feeHistory.Reward)
+	if gasTipCap == nil {
+		gasTipCap, err = e.evmProvider.SuggestGasTipCap(ctx)
+		if err != nil {
+			return GasFees{}, errors.Wrap(err, "failed to suggest gas tip cap")
+		}
+	}
+
+	gasTipCap = e.adjustGasPrice(gasTipCap)
+	gasFeeCap := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), gasTipCap)
+
+	return GasFees{
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+		BaseFee:   baseFee,
+	}, nil
+}
+
+// adjustGasPrice multiplies a price by the gas price adjustment factor.
+func (e *ethCommitter) adjustGasPrice(price *big.Int) *big.Int {
 	incrementedPrice := big.NewFloat(0).Mul(
-		new(big.Float).SetInt(suggestedGasPrice),
+		new(big.Float).SetInt(price),
 		big.NewFloat(e.ethGasPriceAdjustment),
 	)
 
-	gasPrice = new(big.Int)
-	incrementedPrice.Int(gasPrice)
+	adjusted := new(big.Int)
+	incrementedPrice.Int(adjusted)
 
-	opts.GasPrice = gasPrice
-	msg := ethereum.CallMsg{From: opts.From, To: &recipient, GasPrice: gasPrice, Value: nil, Data: txData}
+	return adjusted
+}
 
-	gasCost, err = e.evmProvider.EstimateGas(ctx, msg)
+// feeHistoryTip returns the median of the first reward percentile of each block of a fee history, or nil if there
+// are no rewards.
+func feeHistoryTip(rewards [][]*big.Int) *big.Int {
+	tips := make([]*big.Int, 0, len(rewards))
+	for _, blockRewards := range rewards {
+		if len(blockRewards) == 0 || blockRewards[0] == nil {
+			continue
+		}
 
-	// Estimated gas cost may not be accurate, so we multiply the result by the gas limit adjustment factor.
-	gasCost = uint64(float64(gasCost) * e.ethGasLimitAdjustment)
+		tips = append(tips, blockRewards[0])
+	}
+
+	if len(tips) == 0 {
+		return nil
+	}
+
+	sort.Slice(tips, func(i, j int) bool {
+		return tips[i].Cmp(tips[j]) < 0
+	})
+
+	return new(big.Int).Set(tips[len(tips)/2])
+}
+
+// newTx creates an unsigned transaction, using a dynamic-fee transaction if the fees are dynamic.
+func newTx(nonce uint64, recipient ethcmn.Address, gasLimit uint64, gasFees GasFees, txData []byte) *types.Transaction {
+	if gasFees.IsDynamic() {
+		return types.NewTx(&types.DynamicFeeTx{
+			Nonce:     nonce,
+			GasTipCap: gasFees.GasTipCap,
+			GasFeeCap: gasFees.GasFeeCap,
+			Gas:       gasLimit,
+			To:        &recipient,
+			Data:      txData,
+		})
+	}
 
-	return gasCost, gasPrice, err
+	return types.NewTransaction(nonce, recipient, nil, gasLimit, gasFees.GasPrice, txData)
 }
 
 func (e *ethCommitter) SendTx(
@@ -114,15 +217,17 @@ func (e *ethCommitter) SendTx(
 	recipient ethcmn.Address,
 	txData []byte,
 	gasCost uint64,
-	gasPrice *big.Int,
+	gasFees GasFees,
 ) (txHash ethcmn.Hash, err error) {
 	opts := &bind.TransactOpts{
 		From:   e.fromAddress,
 		Signer: e.fromSigner,
 
-		GasPrice: gasPrice,
-		GasLimit: gasCost,
-		Context:  ctx, // with RPC timeout
+		GasPrice:  gasFees.GasPrice,
+		GasFeeCap: gasFees.GasFeeCap,
+		GasTipCap: gasFees.GasTipCap,
+		GasLimit:  gasCost,
+		Context:   ctx, // with RPC timeout
 	}
 
 	resyncNonces := func(from ethcmn.Address) {
@@ -146,7 +251,7 @@ func (e *ethCommitter) SendTx(
 			opts.Context, cancel = context.WithTimeout(ctx, e.committerOpts.RPCTimeout)
 			defer cancel()
 
-			tx := types.NewTransaction(opts.Nonce.Uint64(), recipient, nil, opts.GasLimit, opts.GasPrice, txData)
+			tx := newTx(opts.Nonce.Uint64(), recipient, opts.GasLimit, gasFees, txData)
 			signedTx, err := opts.Signer(opts.From, tx)
 			if err != nil {
 				err := errors.Wrap(err, "failed to sign transaction")
@@ -218,3 +323,27 @@ func (e *ethCommitter) SendTx(
 
 	return txHash, nil
 }
+
+func (e *ethCommitter) SendTxWithNonce(
+	ctx context.Context,
+	nonce uint64,
+	recipient ethcmn.Address,
+	txData []byte,
+	gasCost uint64,
+	gasFees GasFees,
+) (txHash ethcmn.Hash, err error) {
+	signedTx, err := e.fromSigner(e.fromAddress, newTx(nonce, recipient, gasCost, gasFees, txData))
+	if err != nil {
+		return ethcmn.Hash{}, errors.Wrap(err, "failed to sign transaction")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.committerOpts.RPCTimeout)
+	defer cancel()
+
+	txHash, err = e.evmProvider.SendTransactionWithRet(ctx, signedTx)
+	if err != nil {
+		return signedTx.Hash(), err
+	}
+
+	return txHash, nil
+}
@@ -0,0 +1,110 @@
+package committer
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umee-network/peggo/mocks"
+)
+
+func TestEffectiveGasPrice(t *testing.T) {
+	assert.Equal(t, big.NewInt(10), GasFees{GasPrice: big.NewInt(10)}.EffectiveGasPrice())
+
+	// Without a base fee we can only assume the worst case.
+	assert.Equal(t, big.NewInt(30), GasFees{GasFeeCap: big.NewInt(30), GasTipCap: big.NewInt(2)}.EffectiveGasPrice())
+
+	assert.Equal(t, big.NewInt(12), GasFees{
+		GasFeeCap: big.NewInt(30),
+		GasTipCap: big.NewInt(2),
+		BaseFee:   big.NewInt(10),
+	}.EffectiveGasPrice())
+
+	// The fee cap is the upper bound.
+	assert.Equal(t, big.NewInt(30), GasFees{
+		GasFeeCap: big.NewInt(30),
+		GasTipCap: big.NewInt(2),
+		BaseFee:   big.NewInt(29),
+	}.EffectiveGasPrice())
+}
+
+func TestFeeHistoryTip(t *testing.T) {
+	assert.Nil(t, feeHistoryTip(nil))
+	assert.Nil(t, feeHistoryTip([][]*big.Int{{}, {nil}}))
+
+	assert.Equal(t, big.NewInt(3), feeHistoryTip([][]*big.Int{
+		{big.NewInt(5)},
+		{big.NewInt(1)},
+		{},
+		{big.NewInt(3)},
+	}))
+}
+
+func TestEstimateGas(t *testing.T) {
+	ctx := context.Background()
+	recipient := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+
+	t.Run("dynamic fees", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockEvmProvider.EXPECT().PendingNonceAt(gomock.Any(), gomock.Any()).Return(uint64(0), nil)
+
+		mockEvmProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&types.Header{BaseFee: big.NewInt(90)}, nil)
+		mockEvmProvider.EXPECT().FeeHistory(gomock.Any(), uint64(20), nil, []float64{50}).Return(&ethereum.FeeHistory{
+			Reward:  [][]*big.Int{{big.NewInt(10)}, {big.NewInt(20)}, {big.NewInt(30)}},
+			BaseFee: []*big.Int{big.NewInt(90), big.NewInt(95), big.NewInt(100), big.NewInt(100)},
+		}, nil)
+		mockEvmProvider.EXPECT().EstimateGas(gomock.Any(), ethereum.CallMsg{
+			To:        &recipient,
+			GasFeeCap: big.NewInt(220),
+			GasTipCap: big.NewInt(20),
+		}).Return(uint64(1000), nil)
+
+		ethCommitter, err := NewEthCommitter(zerolog.Nop(), ethcmn.Address{}, 1.0, 1.5, nil, mockEvmProvider)
+		assert.Nil(t, err)
+
+		gasCost, gasFees, err := ethCommitter.EstimateGas(ctx, recipient, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1500), gasCost)
+		assert.Equal(t, GasFees{
+			GasFeeCap: big.NewInt(220),
+			GasTipCap: big.NewInt(20),
+			BaseFee:   big.NewInt(100),
+		}, gasFees)
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockEvmProvider.EXPECT().PendingNonceAt(gomock.Any(), gomock.Any()).Return(uint64(0), nil)
+
+		mockEvmProvider.EXPECT().SuggestGasPrice(gomock.Any()).Return(big.NewInt(100), nil)
+		mockEvmProvider.EXPECT().EstimateGas(gomock.Any(), ethereum.CallMsg{
+			To:       &recipient,
+			GasPrice: big.NewInt(200),
+		}).Return(uint64(1000), nil)
+
+		ethCommitter, err := NewEthCommitter(
+			zerolog.Nop(),
+			ethcmn.Address{},
+			2.0,
+			1.0,
+			nil,
+			mockEvmProvider,
+			OptionLegacyTx(true),
+		)
+		assert.Nil(t, err)
+
+		gasCost, gasFees, err := ethCommitter.EstimateGas(ctx, recipient, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1000), gasCost)
+		assert.Equal(t, GasFees{GasPrice: big.NewInt(200)}, gasFees)
+	})
+}
@@ -0,0 +1,326 @@
+package gravity
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	erc20FieldSymbol   = "symbol"
+	erc20FieldDecimals = "decimals"
+
+	// revertErrorCode is the JSON-RPC error code of the calls that revert.
+	revertErrorCode = 3
+)
+
+var (
+	// erc20SymbolSelector is the selector of symbol().
+	erc20SymbolSelector = []byte{0x95, 0xd8, 0x9b, 0x41}
+	// erc20DecimalsSelector is the selector of decimals().
+	erc20DecimalsSelector = []byte{0x31, 0x3c, 0xe5, 0x67}
+
+	// errMissingMethod is returned when a token doesn't implement the called method.
+	errMissingMethod = errors.New("missing method")
+
+	stringABIArgs = func() abi.Arguments {
+		stringType, _ := abi.NewType("string", "", nil)
+		return abi.Arguments{{Type: stringType}}
+	}()
+)
+
+// UnreadableTokenError is returned when the metadata of a token can't be read, because the token doesn't implement it
+// or returns something that can't be decoded. Unlike a failed call, retrying won't help until the token is upgraded.
+type UnreadableTokenError struct {
+	Token  ethcmn.Address
+	Field  string
+	Reason string
+}
+
+func (e *UnreadableTokenError) Error() string {
+	return fmt.Sprintf("token %s is unreadable: invalid %s: %s", e.Token.Hex(), e.Field, e.Reason)
+}
+
+// undecodableError is returned when the response of a token can't be decoded.
+type undecodableError string
+
+func (e undecodableError) Error() string {
+	return string(e)
+}
+
+// IsUnreadableToken returns true if the error is caused by a token whose metadata can't be read.
+func IsUnreadableToken(err error) bool {
+	var unreadableErr *UnreadableTokenError
+	return errors.As(err, &unreadableErr)
+}
+
+// ERC20MetadataReader reads the symbol and the decimals of ERC20 tokens, including non-standard ones: symbols encoded
+// as bytes32 (e.g. MKR) are decoded as well as strings, and tokens without decimals() get the default decimals.
+// Metadata is cached, and unreadable tokens are cached for a while so they aren't called over and over.
+type ERC20MetadataReader struct {
+	logger           zerolog.Logger
+	caller           bind.ContractCaller
+	defaultDecimals  uint8
+	negativeCacheTTL time.Duration
+
+	mtx         sync.Mutex
+	symbols     map[ethcmn.Address]string
+	decimals    map[ethcmn.Address]uint8
+	unreadables map[erc20Field]unreadableToken
+}
+
+type erc20Field struct {
+	token ethcmn.Address
+	field string
+}
+
+type unreadableToken struct {
+	err       *UnreadableTokenError
+	expiresAt time.Time
+}
+
+// NewERC20MetadataReader returns an ERC20MetadataReader calling the tokens with the caller.
+func NewERC20MetadataReader(
+	logger zerolog.Logger,
+	caller bind.ContractCaller,
+	defaultDecimals uint8,
+	negativeCacheTTL time.Duration,
+) *ERC20MetadataReader {
+	return &ERC20MetadataReader{
+		logger:           logger.With().Str("module", "erc20_metadata").Logger(),
+		caller:           caller,
+		defaultDecimals:  defaultDecimals,
+		negativeCacheTTL: negativeCacheTTL,
+		symbols:          map[ethcmn.Address]string{},
+		decimals:         map[ethcmn.Address]uint8{},
+		unreadables:      map[erc20Field]unreadableToken{},
+	}
+}
+
+// Symbol returns the symbol of the token, encoded either as a string or as bytes32.
+func (r *ERC20MetadataReader) Symbol(ctx context.Context, token, callerAddr ethcmn.Address) (string, error) {
+	r.mtx.Lock()
+	symbol, ok := r.symbols[token]
+	r.mtx.Unlock()
+
+	if ok {
+		return symbol, nil
+	}
+
+	if err := r.cachedUnreadable(token, erc20FieldSymbol); err != nil {
+		return "", err
+	}
+
+	out, err := r.call(ctx, token, callerAddr, erc20SymbolSelector)
+	if err != nil {
+		return "", r.failed(token, erc20FieldSymbol, err)
+	}
+
+	symbol, err = decodeSymbol(out)
+	if err != nil {
+		return "", r.failed(token, erc20FieldSymbol, err)
+	}
+
+	r.mtx.Lock()
+	r.symbols[token] = symbol
+	r.mtx.Unlock()
+
+	return symbol, nil
+}
+
+// Decimals returns the decimals of the token, or the default decimals if the token doesn't implement decimals().
+// Only the decimals read from the token are cached.
+func (r *ERC20MetadataReader) Decimals(ctx context.Context, token, callerAddr ethcmn.Address) (uint8, error) {
+	r.mtx.Lock()
+	decimals, ok := r.decimals[token]
+	r.mtx.Unlock()
+
+	if ok {
+		return decimals, nil
+	}
+
+	if err := r.cachedUnreadable(token, erc20FieldDecimals); err != nil {
+		return 0, err
+	}
+
+	out, err := r.call(ctx, token, callerAddr, erc20DecimalsSelector)
+	switch {
+	case errors.Is(err, errMissingMethod):
+		// The default decimals aren't cached, so the token is read again once it implements decimals() (e.g. after a
+		// proxy upgrade) and a misread revert can't stick.
+		r.logger.Warn().
+			Str("token_contract", token.Hex()).
+			Uint8("default_decimals", r.defaultDecimals).
+			Msg("token doesn't implement decimals(), using the default decimals")
+		return r.defaultDecimals, nil
+
+	case err != nil:
+		return 0, r.failed(token, erc20FieldDecimals, err)
+
+	default:
+		decimals, err = decodeDecimals(out)
+		if err != nil {
+			return 0, r.failed(token, erc20FieldDecimals, err)
+		}
+	}
+
+	r.mtx.Lock()
+	r.decimals[token] = decimals
+	r.mtx.Unlock()
+
+	return decimals, nil
+}
+
+// cachedUnreadable returns the error of the token field if it was found unreadable less than the negative cache TTL
+// ago.
+func (r *ERC20MetadataReader) cachedUnreadable(token ethcmn.Address, field string) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	key := erc20Field{token: token, field: field}
+
+	unreadable, ok := r.unreadables[key]
+	if !ok {
+		return nil
+	}
+
+	if time.Now().After(unreadable.expiresAt) {
+		delete(r.unreadables, key)
+		return nil
+	}
+
+	return unreadable.err
+}
+
+// failed returns the error of reading the token field. Tokens that can't be decoded, or that don't implement the
+// field, are cached as unreadable; other errors, like RPC failures, are returned as is so the next call retries.
+func (r *ERC20MetadataReader) failed(token ethcmn.Address, field string, err error) error {
+	var (
+		reason         string
+		undecodableErr undecodableError
+	)
+
+	switch {
+	case errors.Is(err, errMissingMethod):
+		reason = fmt.Sprintf("%s() is not implemented", field)
+	case errors.As(err, &undecodableErr):
+		reason = undecodableErr.Error()
+	default:
+		return errors.Wrapf(err, "ERC20 '%s' call failed", field)
+	}
+
+	unreadableErr := &UnreadableTokenError{Token: token, Field: field, Reason: reason}
+
+	r.logger.Warn().
+		Str("token_contract", token.Hex()).
+		Str("reason", unreadableErr.Error()).
+		Dur("retry_in", r.negativeCacheTTL).
+		Msg("unreadable token")
+
+	if r.negativeCacheTTL > 0 {
+		r.mtx.Lock()
+		r.unreadables[erc20Field{token: token, field: field}] = unreadableToken{
+			err:       unreadableErr,
+			expiresAt: time.Now().Add(r.negativeCacheTTL),
+		}
+		r.mtx.Unlock()
+	}
+
+	return unreadableErr
+}
+
+// call calls the method of the token without arguments. The call goes through proxies like any other call, and
+// errMissingMethod is returned if the method reverts or returns nothing.
+func (r *ERC20MetadataReader) call(
+	ctx context.Context,
+	token, callerAddr ethcmn.Address,
+	selector []byte,
+) ([]byte, error) {
+	out, err := r.caller.CallContract(ctx, ethereum.CallMsg{
+		From: callerAddr,
+		To:   &token,
+		Data: selector,
+	}, nil)
+	if err != nil {
+		if isCallReverted(err) {
+			return nil, errMissingMethod
+		}
+		return nil, err
+	}
+
+	if len(out) > 0 {
+		return out, nil
+	}
+
+	code, err := r.caller.CodeAt(ctx, token, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(code) == 0 {
+		return nil, undecodableError("no contract code at the token address")
+	}
+
+	return nil, errMissingMethod
+}
+
+// isCallReverted returns true if the error of a call is caused by the contract reverting. Every JSON-RPC error
+// carries data, so only the revert error code or message tell a revert apart from a node failure, like a rate limit
+// or a missing trie node.
+func isCallReverted(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == revertErrorCode {
+		return true
+	}
+
+	return strings.Contains(err.Error(), "execution reverted")
+}
+
+// decodeSymbol decodes a symbol returned either as a string or as bytes32.
+func decodeSymbol(out []byte) (string, error) {
+	if values, err := stringABIArgs.Unpack(out); err == nil {
+		if symbol := strings.TrimSpace(values[0].(string)); symbol != "" {
+			return symbol, nil
+		}
+		return "", undecodableError("empty symbol")
+	}
+
+	if len(out) != 32 {
+		return "", undecodableError(fmt.Sprintf("unexpected symbol encoding of %d bytes", len(out)))
+	}
+
+	symbol := string(bytes.TrimRight(out, "\x00"))
+	if symbol == "" || !utf8.ValidString(symbol) || strings.ContainsRune(symbol, '\x00') {
+		return "", undecodableError("invalid bytes32 symbol")
+	}
+
+	return strings.TrimSpace(symbol), nil
+}
+
+// decodeDecimals decodes the uint8 returned by decimals(). Tokens returning a wider integer are fine as long as the
+// value fits in a uint8.
+func decodeDecimals(out []byte) (uint8, error) {
+	if len(out) < 32 {
+		return 0, undecodableError(fmt.Sprintf("unexpected decimals encoding of %d bytes", len(out)))
+	}
+
+	decimals := new(big.Int).SetBytes(out[:32])
+	if !decimals.IsUint64() || decimals.Uint64() > 255 {
+		return 0, undecodableError(fmt.Sprintf("decimals out of range: %s", decimals))
+	}
+
+	return uint8(decimals.Uint64()), nil
+}
@@ -0,0 +1,196 @@
+package gravity
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umee-network/peggo/mocks"
+)
+
+var (
+	metadataToken  = ethcmn.HexToAddress("0x9f8f72aa9304c8b593d555f12ef6589cc3a579a2")
+	metadataCaller = ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e10c5df2f3f4edc5e4e")
+)
+
+// rpcError is a JSON-RPC error with data, like the ones returned by go-ethereum's RPC client.
+type rpcError struct {
+	code int
+	msg  string
+	data interface{}
+}
+
+func (e rpcError) Error() string          { return e.msg }
+func (e rpcError) ErrorCode() int         { return e.code }
+func (e rpcError) ErrorData() interface{} { return e.data }
+
+func TestERC20MetadataSymbol(t *testing.T) {
+	testCases := []struct {
+		name   string
+		out    string
+		err    error
+		symbol string
+		errMsg string
+	}{
+		{
+			name: "string",
+			out: "0x0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000004" +
+				"5553444300000000000000000000000000000000000000000000000000000000",
+			symbol: "USDC",
+		},
+		{
+			name:   "bytes32",
+			out:    "0x4d4b520000000000000000000000000000000000000000000000000000000000",
+			symbol: "MKR",
+		},
+		{
+			name:   "empty bytes32",
+			out:    "0x0000000000000000000000000000000000000000000000000000000000000000",
+			errMsg: "token " + metadataToken.Hex() + " is unreadable: invalid symbol: empty symbol",
+		},
+		{
+			name:   "reverted",
+			err:    errors.New("execution reverted"),
+			errMsg: "token " + metadataToken.Hex() + " is unreadable: invalid symbol: symbol() is not implemented",
+		},
+		{
+			name:   "reverted with the revert error code",
+			err:    rpcError{code: 3, msg: "reverted", data: "0x"},
+			errMsg: "token " + metadataToken.Hex() + " is unreadable: invalid symbol: symbol() is not implemented",
+		},
+		{
+			name:   "rpc error with data",
+			err:    rpcError{code: -32005, msg: "rate limited", data: map[string]interface{}{"backoff_seconds": 1}},
+			errMsg: "ERC20 'symbol' call failed: rate limited",
+		},
+		{
+			name:   "rpc error",
+			err:    errors.New("connection refused"),
+			errMsg: "ERC20 'symbol' call failed: connection refused",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+
+			var out []byte
+			if tc.out != "" {
+				out = hexutil.MustDecode(tc.out)
+			}
+			mockEvmProvider.EXPECT().CallContract(gomock.Any(), gomock.Any(), nil).Return(out, tc.err)
+
+			reader := NewERC20MetadataReader(zerolog.Nop(), mockEvmProvider, 18, time.Minute)
+			symbol, err := reader.Symbol(context.Background(), metadataToken, metadataCaller)
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.symbol, symbol)
+		})
+	}
+}
+
+func TestERC20MetadataDecimals(t *testing.T) {
+	t.Run("decoded and cached", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockEvmProvider.EXPECT().
+			CallContract(gomock.Any(), gomock.Any(), nil).
+			Return(hexutil.MustDecode("0x0000000000000000000000000000000000000000000000000000000000000006"), nil).
+			Times(1)
+
+		reader := NewERC20MetadataReader(zerolog.Nop(), mockEvmProvider, 18, time.Minute)
+		for i := 0; i < 2; i++ {
+			decimals, err := reader.Decimals(context.Background(), metadataToken, metadataCaller)
+			assert.Nil(t, err)
+			assert.Equal(t, uint8(6), decimals)
+		}
+	})
+
+	t.Run("missing decimals", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockEvmProvider.EXPECT().CallContract(gomock.Any(), gomock.Any(), nil).Return([]byte{}, nil).Times(2)
+		mockEvmProvider.EXPECT().CodeAt(gomock.Any(), metadataToken, nil).Return([]byte{0x60, 0x80}, nil).Times(2)
+
+		// The default decimals aren't cached, so the token is read every time.
+		reader := NewERC20MetadataReader(zerolog.Nop(), mockEvmProvider, 9, time.Minute)
+		for i := 0; i < 2; i++ {
+			decimals, err := reader.Decimals(context.Background(), metadataToken, metadataCaller)
+			assert.Nil(t, err)
+			assert.Equal(t, uint8(9), decimals)
+		}
+	})
+
+	t.Run("rpc error with data", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		gomock.InOrder(
+			mockEvmProvider.EXPECT().
+				CallContract(gomock.Any(), gomock.Any(), nil).
+				Return(nil, rpcError{code: -32000, msg: "missing trie node", data: "0x"}),
+			mockEvmProvider.EXPECT().
+				CallContract(gomock.Any(), gomock.Any(), nil).
+				Return(hexutil.MustDecode("0x0000000000000000000000000000000000000000000000000000000000000006"), nil),
+		)
+
+		// A node failure neither defaults the decimals nor marks the token as unreadable.
+		reader := NewERC20MetadataReader(zerolog.Nop(), mockEvmProvider, 18, time.Minute)
+		_, err := reader.Decimals(context.Background(), metadataToken, metadataCaller)
+		assert.EqualError(t, err, "ERC20 'decimals' call failed: missing trie node")
+		assert.False(t, IsUnreadableToken(err))
+
+		decimals, err := reader.Decimals(context.Background(), metadataToken, metadataCaller)
+		assert.Nil(t, err)
+		assert.Equal(t, uint8(6), decimals)
+	})
+
+	t.Run("no contract", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockEvmProvider.EXPECT().CallContract(gomock.Any(), gomock.Any(), nil).Return([]byte{}, nil).Times(1)
+		mockEvmProvider.EXPECT().CodeAt(gomock.Any(), metadataToken, nil).Return([]byte{}, nil).Times(1)
+
+		reader := NewERC20MetadataReader(zerolog.Nop(), mockEvmProvider, 18, time.Minute)
+
+		// The second read hits the negative cache, without calling the token.
+		for i := 0; i < 2; i++ {
+			_, err := reader.Decimals(context.Background(), metadataToken, metadataCaller)
+			assert.True(t, IsUnreadableToken(err))
+			assert.EqualError(
+				t,
+				err,
+				"token "+metadataToken.Hex()+" is unreadable: invalid decimals: no contract code at the token address",
+			)
+		}
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockEvmProvider.EXPECT().
+			CallContract(gomock.Any(), gomock.Any(), nil).
+			Return(hexutil.MustDecode("0x0000000000000000000000000000000000000000000000000000000000000100"), nil).
+			Times(2)
+
+		// Without a negative cache, unreadable tokens are read again.
+		reader := NewERC20MetadataReader(zerolog.Nop(), mockEvmProvider, 18, 0)
+		for i := 0; i < 2; i++ {
+			_, err := reader.Decimals(context.Background(), metadataToken, metadataCaller)
+			assert.True(t, IsUnreadableToken(err))
+		}
+	})
+}
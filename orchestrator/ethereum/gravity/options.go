@@ -0,0 +1,55 @@
+package gravity
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Option func(o *options) error
+
+type options struct {
+	// DefaultDecimals are the decimals of the tokens that don't implement decimals().
+	DefaultDecimals uint8
+	// UnreadableTokenTTL is the time an unreadable token is cached for before being read again.
+	UnreadableTokenTTL time.Duration
+}
+
+func defaultOptions() *options {
+	return &options{
+		DefaultDecimals:    18,
+		UnreadableTokenTTL: 10 * time.Minute,
+	}
+}
+
+func applyOptions(o *options, opts ...Option) error {
+	for _, oo := range opts {
+		if err := oo(o); err != nil {
+			err = errors.Wrap(err, "failed to apply option to Gravity contract")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OptionDefaultDecimals sets the decimals of the tokens that don't implement decimals().
+func OptionDefaultDecimals(decimals uint8) Option {
+	return func(o *options) error {
+		o.DefaultDecimals = decimals
+		return nil
+	}
+}
+
+// OptionUnreadableTokenTTL sets the time an unreadable token is cached for before being read again; zero disables
+// the cache.
+func OptionUnreadableTokenTTL(ttl time.Duration) Option {
+	return func(o *options) error {
+		if ttl < 0 {
+			return errors.Errorf("invalid unreadable token TTL: %s", ttl)
+		}
+
+		o.UnreadableTokenTTL = ttl
+		return nil
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"math/big"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
@@ -62,6 +61,12 @@ type Contract interface {
 		callerAddress ethcmn.Address,
 	) (*big.Int, error)
 
+	// GetValsetCheckpoint returns the checkpoint of the current valset stored on the Gravity contract.
+	GetValsetCheckpoint(
+		ctx context.Context,
+		callerAddress ethcmn.Address,
+	) (ethcmn.Hash, error)
+
 	GetGravityID(
 		ctx context.Context,
 		callerAddress ethcmn.Address,
@@ -79,9 +84,9 @@ type Contract interface {
 		callerAddress ethcmn.Address,
 	) (decimals uint8, err error)
 
-	// SubscribeToPendingTxs starts a websocket connection to Alchemy's service that listens for new pending txs made
-	// to the Gravity contract.
-	SubscribeToPendingTxs(ctx context.Context, alchemyWebsocketURL string) error
+	// WatchPendingTxs uses the watcher to keep track of pending relay txs made to the Gravity contract, until the
+	// context is done.
+	WatchPendingTxs(ctx context.Context, watcher PendingTxWatcher) error
 
 	// IsPendingTxInput returns true if the input data is found in the pending tx list. If the tx is found but the tx is
 	// older than pendingTxWaitDuration, we consider it stale and return false, so the validator re-sends it.
@@ -97,9 +102,7 @@ type gravityContract struct {
 	gravityAddress     ethcmn.Address
 	ethGravity         *wrappers.Gravity
 	pendingTxInputList PendingTxInputList
-
-	mtx               sync.Mutex
-	erc20DecimalCache map[string]uint8
+	erc20Metadata      *ERC20MetadataReader
 }
 
 func NewGravityContract(
@@ -107,12 +110,26 @@ func NewGravityContract(
 	ethCommitter committer.EVMCommitter,
 	gravityAddress ethcmn.Address,
 	ethGravity *wrappers.Gravity,
+	opts ...Option,
 ) (Contract, error) {
+	o := defaultOptions()
+	if err := applyOptions(o, opts...); err != nil {
+		return nil, err
+	}
+
+	logger = logger.With().Str("module", "gravity_contract").Logger()
+
 	return &gravityContract{
-		logger:         logger.With().Str("module", "gravity_contract").Logger(),
+		logger:         logger,
 		EVMCommitter:   ethCommitter,
 		gravityAddress: gravityAddress,
 		ethGravity:     ethGravity,
+		erc20Metadata: NewERC20MetadataReader(
+			logger,
+			ethCommitter.Provider(),
+			o.DefaultDecimals,
+			o.UnreadableTokenTTL,
+		),
 	}, nil
 }
 
@@ -157,6 +174,24 @@ func (s *gravityContract) GetValsetNonce(
 	return nonce, nil
 }
 
+// Gets the checkpoint of the current valset
+func (s *gravityContract) GetValsetCheckpoint(
+	ctx context.Context,
+	callerAddress ethcmn.Address,
+) (ethcmn.Hash, error) {
+
+	checkpoint, err := s.ethGravity.StateLastValsetCheckpoint(&bind.CallOpts{
+		From:    callerAddress,
+		Context: ctx,
+	})
+
+	if err != nil {
+		return ethcmn.Hash{}, errors.Wrap(err, "StateLastValsetCheckpoint call failed")
+	}
+
+	return checkpoint, nil
+}
+
 // Gets the gravityID
 func (s *gravityContract) GetGravityID(
 	ctx context.Context,
@@ -176,68 +211,24 @@ func (s *gravityContract) GetGravityID(
 	return string(gravityID[:]), nil
 }
 
+// GetERC20Symbol returns the symbol of the token, encoded either as a string or as bytes32. It returns an
+// UnreadableTokenError if the token doesn't implement symbol().
 func (s *gravityContract) GetERC20Symbol(
 	ctx context.Context,
 	erc20ContractAddress ethcmn.Address,
 	callerAddress ethcmn.Address,
 ) (symbol string, err error) {
-
-	erc20Wrapper, err := wrappers.NewERC20(erc20ContractAddress, s.EVMCommitter.Provider())
-	if err != nil {
-		err = errors.Wrap(err, "failed to get ERC20 wrapper")
-		return "", err
-	}
-
-	callOpts := &bind.CallOpts{
-		From:    callerAddress,
-		Context: ctx,
-	}
-
-	symbol, err = erc20Wrapper.Symbol(callOpts)
-	if err != nil {
-		err = errors.Wrap(err, "ERC20 'symbol' call failed")
-		return "", err
-	}
-
-	return symbol, nil
+	return s.erc20Metadata.Symbol(ctx, erc20ContractAddress, callerAddress)
 }
 
+// GetERC20Decimals returns the decimals of the token, or the default decimals if the token doesn't implement
+// decimals(). It returns an UnreadableTokenError if the decimals can't be decoded.
 func (s *gravityContract) GetERC20Decimals(
 	ctx context.Context,
 	tokenAddr ethcmn.Address,
 	callerAddr ethcmn.Address,
 ) (uint8, error) {
-
-	s.mtx.Lock()
-	defer s.mtx.Unlock()
-
-	tokenAddrStr := tokenAddr.String()
-
-	if d, ok := s.erc20DecimalCache[tokenAddrStr]; ok {
-		return d, nil
-	}
-
-	erc20Wrapper, err := wrappers.NewERC20(tokenAddr, s.EVMCommitter.Provider())
-	if err != nil {
-		return 0, errors.Wrap(err, "failed to get ERC20 wrapper")
-	}
-
-	callOpts := &bind.CallOpts{
-		From:    callerAddr,
-		Context: ctx,
-	}
-
-	decimals, err := erc20Wrapper.Decimals(callOpts)
-	if err != nil {
-		return 0, errors.Wrap(err, "ERC20 'decimals' call failed")
-	}
-
-	if s.erc20DecimalCache == nil {
-		s.erc20DecimalCache = map[string]uint8{}
-	}
-
-	s.erc20DecimalCache[tokenAddrStr] = decimals
-	return decimals, nil
+	return s.erc20Metadata.Decimals(ctx, tokenAddr, callerAddr)
 }
 
 func sigToVRS(sigHex string) (v uint8, r, s ethcmn.Hash) {
@@ -3,41 +3,54 @@ package gravity
 import (
 	"bytes"
 	"context"
+	"sync"
 	"time"
 
+	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// maxPendingTxInputs is the number of pending Gravity transactions we keep track of.
+const maxPendingTxInputs = 100
+
 // PendingTxInput contains the data of a pending transaction and the time we first saw it.
 type PendingTxInput struct {
 	InputData    hexutil.Bytes
 	ReceivedTime time.Time
 }
 
-type PendingTxInputList []PendingTxInput
+// PendingTxInputList holds the input data of the latest pending submitBatch and updateValset transactions. It's safe
+// for concurrent use.
+type PendingTxInputList struct {
+	mtx    sync.RWMutex
+	inputs []PendingTxInput
+}
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	Input hexutil.Bytes `json:"input"`
+	Hash  ethcmn.Hash     `json:"hash"`
+	To    *ethcmn.Address `json:"to"`
+	Input hexutil.Bytes   `json:"input"`
 }
 
-// AddPendingTxInput adds pending submitBatch and updateBatch calls to the Gravity contract to the list of pending
-// transactions, any other transaction is ignored.
-func (p *PendingTxInputList) AddPendingTxInput(pendingTx *RPCTransaction) {
-	if len(pendingTx.Input) < 4 {
-		// Return if transaction doesn't contain enough data (method IDs are 4 bytes long).
-		return
+// IsRelayTxInput returns true if the input data is a call to the submitBatch or updateValset methods of the Gravity
+// contract.
+func IsRelayTxInput(input []byte) bool {
+	if len(input) < 4 {
+		// Method IDs are 4 bytes long.
+		return false
 	}
 
-	submitBatchMethod := gravityABI.Methods["submitBatch"]
-	valsetUpdateMethod := gravityABI.Methods["updateValset"]
-
-	// If it's not a submitBatch or updateValset transaction, ignore it.
 	// The first four bytes of the call data for a function call specifies the function to be called.
 	// Ref: https://docs.soliditylang.org/en/develop/abi-spec.html#function-selector
-	if !bytes.Equal(submitBatchMethod.ID, pendingTx.Input[:4]) &&
-		!bytes.Equal(valsetUpdateMethod.ID, pendingTx.Input[:4]) {
+	return bytes.Equal(gravityABI.Methods["submitBatch"].ID, input[:4]) ||
+		bytes.Equal(gravityABI.Methods["updateValset"].ID, input[:4])
+}
+
+// AddPendingTxInput adds pending submitBatch and updateBatch calls to the Gravity contract to the list of pending
+// transactions, any other transaction is ignored.
+func (p *PendingTxInputList) AddPendingTxInput(pendingTx *RPCTransaction) {
+	if !IsRelayTxInput(pendingTx.Input) {
 		return
 	}
 
@@ -46,60 +59,57 @@ func (p *PendingTxInputList) AddPendingTxInput(pendingTx *RPCTransaction) {
 		ReceivedTime: time.Now(),
 	}
 
-	*p = append(*p, pendingTxInput)
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.inputs = append(p.inputs, pendingTxInput)
 	// Persisting top 100 pending txs of Gravity contract only.
-	if len(*p) > 100 {
-		(*p)[0] = PendingTxInput{} // to avoid memory leak
+	if len(p.inputs) > maxPendingTxInputs {
+		p.inputs[0] = PendingTxInput{} // to avoid memory leak
 		// Dequeue pending tx input
-		*p = (*p)[1:]
+		p.inputs = p.inputs[1:]
 	}
 }
 
-func (s *gravityContract) IsPendingTxInput(txData []byte, pendingTxWaitDuration time.Duration) bool {
+// IsPendingTxInput returns true if the input data is found in the list and it's not older than
+// pendingTxWaitDuration.
+func (p *PendingTxInputList) IsPendingTxInput(txData []byte, pendingTxWaitDuration time.Duration) bool {
 	t := time.Now()
 
-	for _, pendingTxInput := range s.pendingTxInputList {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	for _, pendingTxInput := range p.inputs {
 		if bytes.Equal(pendingTxInput.InputData, txData) {
 			// If this tx was for too long in the pending list, consider it stale
 			return t.Before(pendingTxInput.ReceivedTime.Add(pendingTxWaitDuration))
 		}
 	}
+
 	return false
 }
 
-func (s *gravityContract) SubscribeToPendingTxs(ctx context.Context, alchemyWebsocketURL string) error {
-	args := map[string]interface{}{
-		"address": s.gravityAddress.Hex(),
-	}
-
-	wsClient, err := rpc.Dial(alchemyWebsocketURL)
-	if err != nil {
-		s.logger.Fatal().
-			AnErr("err", err).
-			Str("endpoint", alchemyWebsocketURL).
-			Msg("failed to connect to Alchemy websocket")
-		return err
-	}
+// Len returns the number of pending transactions in the list.
+func (p *PendingTxInputList) Len() int {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
 
-	ch := make(chan *RPCTransaction)
-	_, err = wsClient.EthSubscribe(ctx, ch, "alchemy_filteredNewFullPendingTransactions", args)
-	if err != nil {
-		s.logger.Fatal().
-			AnErr("err", err).
-			Str("endpoint", alchemyWebsocketURL).
-			Msg("Failed to subscribe to pending transactions")
-		return err
-	}
+	return len(p.inputs)
+}
 
-	for {
-		select {
-		case pendingTransaction := <-ch:
-			s.pendingTxInputList.AddPendingTxInput(pendingTransaction)
+func (s *gravityContract) IsPendingTxInput(txData []byte, pendingTxWaitDuration time.Duration) bool {
+	return s.pendingTxInputList.IsPendingTxInput(txData, pendingTxWaitDuration)
+}
 
-		case <-ctx.Done():
-			return nil
+func (s *gravityContract) WatchPendingTxs(ctx context.Context, watcher PendingTxWatcher) error {
+	return watcher.Watch(ctx, s.gravityAddress, func(pendingTx *RPCTransaction) {
+		// Watchers may not filter by recipient, so we make sure the tx is sent to the Gravity contract.
+		if pendingTx.To == nil || *pendingTx.To != s.gravityAddress {
+			return
 		}
-	}
+
+		s.pendingTxInputList.AddPendingTxInput(pendingTx)
+	})
 }
 
 func (s *gravityContract) GetPendingTxInputList() *PendingTxInputList {
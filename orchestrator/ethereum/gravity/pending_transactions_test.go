@@ -16,7 +16,7 @@ import (
 )
 
 func TestAddPendingTxInput(t *testing.T) {
-	txList := PendingTxInputList{}
+	txList := &PendingTxInputList{}
 
 	// add a submitBatch tx
 	txList.AddPendingTxInput(&RPCTransaction{
@@ -39,7 +39,7 @@ func TestAddPendingTxInput(t *testing.T) {
 	})
 
 	// Only the first 2 TXs should have been added
-	assert.Len(t, txList, 2)
+	assert.Equal(t, 2, txList.Len())
 
 	for i := 0; i < 110; i++ {
 		txList.AddPendingTxInput(&RPCTransaction{
@@ -48,7 +48,7 @@ func TestAddPendingTxInput(t *testing.T) {
 	}
 
 	// The list should be at full capacity now
-	assert.Len(t, txList, 100)
+	assert.Equal(t, 100, txList.Len())
 }
 
 func TestIsPendingTxInput(t *testing.T) {
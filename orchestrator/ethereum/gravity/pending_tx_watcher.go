@@ -0,0 +1,375 @@
+package gravity
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Allowed pending tx watcher sources
+const (
+	PendingTxSourceAlchemy   = "alchemy"
+	PendingTxSourceSubscribe = "subscribe"
+	PendingTxSourceTxPool    = "txpool"
+)
+
+const (
+	// pendingTxFetchWorkers is the number of concurrent eth_getTransactionByHash requests of the subscription watcher.
+	pendingTxFetchWorkers = 4
+	pendingTxFetchTimeout = 5 * time.Second
+
+	// pendingTxReconnectBackoff is the delay before reconnecting a watcher, doubled after each failed attempt up to
+	// pendingTxMaxReconnectBackoff.
+	pendingTxReconnectBackoff    = time.Second
+	pendingTxMaxReconnectBackoff = time.Minute
+)
+
+// PendingTxWatcher watches the mempool for pending transactions.
+type PendingTxWatcher interface {
+	// Watch blocks until the context is done, calling onTx for the pending transactions it finds. Transactions to
+	// other contracts may be reported too, so the callback should filter them. Connection and subscription failures
+	// don't stop the watcher, which reconnects until the context is done.
+	Watch(ctx context.Context, gravityAddress ethcmn.Address, onTx func(*RPCTransaction)) error
+}
+
+// DialFn returns a connection to an Ethereum node.
+type DialFn func(ctx context.Context) (*rpc.Client, error)
+
+// NewPendingTxWatcher returns a PendingTxWatcher of the given source, connected to the given endpoint. The
+// subscription based sources (alchemy and subscribe) require a websocket endpoint.
+func NewPendingTxWatcher(
+	logger zerolog.Logger,
+	source string,
+	endpoint string,
+	pollInterval time.Duration,
+) (PendingTxWatcher, error) {
+	dial := func(ctx context.Context) (*rpc.Client, error) {
+		return rpc.DialContext(ctx, endpoint)
+	}
+
+	switch source {
+	case PendingTxSourceAlchemy:
+		return NewAlchemyPendingTxWatcher(logger, dial), nil
+	case PendingTxSourceSubscribe:
+		return NewSubscriptionPendingTxWatcher(logger, dial), nil
+	case PendingTxSourceTxPool:
+		return NewTxPoolPendingTxWatcher(logger, dial, pollInterval), nil
+	default:
+		return nil, fmt.Errorf("invalid pending tx source: %s", source)
+	}
+}
+
+// watchWithReconnect calls watch until the context is done. Whenever watch fails, e.g. because the websocket
+// dropped, it's called again after a backoff that doubles with every failure in a row.
+func watchWithReconnect(
+	ctx context.Context,
+	logger zerolog.Logger,
+	backoff time.Duration,
+	watch func(ctx context.Context) error,
+) error {
+	retryIn := backoff
+
+	for {
+		startedAt := time.Now()
+
+		err := watch(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// A watch that lasted longer than the max backoff was connected, so the failures start over.
+		if time.Since(startedAt) > pendingTxMaxReconnectBackoff {
+			retryIn = backoff
+		}
+
+		logger.Warn().Err(err).Dur("retry_in", retryIn).Msg("pending transactions watcher failed; reconnecting")
+
+		select {
+		case <-time.After(retryIn):
+		case <-ctx.Done():
+			return nil
+		}
+
+		retryIn *= 2
+		if retryIn > pendingTxMaxReconnectBackoff {
+			retryIn = pendingTxMaxReconnectBackoff
+		}
+	}
+}
+
+type alchemyPendingTxWatcher struct {
+	logger           zerolog.Logger
+	dial             DialFn
+	reconnectBackoff time.Duration
+}
+
+// NewAlchemyPendingTxWatcher returns a PendingTxWatcher that uses Alchemy's
+// alchemy_filteredNewFullPendingTransactions subscription, which only returns transactions to the Gravity contract.
+func NewAlchemyPendingTxWatcher(logger zerolog.Logger, dial DialFn) PendingTxWatcher {
+	return &alchemyPendingTxWatcher{
+		logger:           logger.With().Str("module", "alchemy_pending_tx_watcher").Logger(),
+		dial:             dial,
+		reconnectBackoff: pendingTxReconnectBackoff,
+	}
+}
+
+func (w *alchemyPendingTxWatcher) Watch(
+	ctx context.Context,
+	gravityAddress ethcmn.Address,
+	onTx func(*RPCTransaction),
+) error {
+	return watchWithReconnect(ctx, w.logger, w.reconnectBackoff, func(ctx context.Context) error {
+		return w.watch(ctx, gravityAddress, onTx)
+	})
+}
+
+// watch subscribes to the pending transactions until the context is done or the subscription fails.
+func (w *alchemyPendingTxWatcher) watch(
+	ctx context.Context,
+	gravityAddress ethcmn.Address,
+	onTx func(*RPCTransaction),
+) error {
+	wsClient, err := w.dial(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to Alchemy websocket")
+	}
+	defer wsClient.Close()
+
+	args := map[string]interface{}{
+		"address": gravityAddress.Hex(),
+	}
+
+	ch := make(chan *RPCTransaction)
+	sub, err := wsClient.EthSubscribe(ctx, ch, "alchemy_filteredNewFullPendingTransactions", args)
+	if err != nil {
+		return errors.Wrap(err, "failed to subscribe to pending transactions")
+	}
+	defer sub.Unsubscribe()
+
+	w.logger.Info().Msg("watching pending transactions")
+
+	for {
+		select {
+		case pendingTx := <-ch:
+			onTx(pendingTx)
+
+		case err := <-sub.Err():
+			if err == nil {
+				return errors.New("pending transactions subscription closed")
+			}
+
+			return errors.Wrap(err, "pending transactions subscription failed")
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+type subscriptionPendingTxWatcher struct {
+	logger           zerolog.Logger
+	dial             DialFn
+	reconnectBackoff time.Duration
+}
+
+// NewSubscriptionPendingTxWatcher returns a PendingTxWatcher that uses the standard eth_subscribe
+// newPendingTransactions subscription, fetching each transaction with eth_getTransactionByHash.
+func NewSubscriptionPendingTxWatcher(logger zerolog.Logger, dial DialFn) PendingTxWatcher {
+	return &subscriptionPendingTxWatcher{
+		logger:           logger.With().Str("module", "subscription_pending_tx_watcher").Logger(),
+		dial:             dial,
+		reconnectBackoff: pendingTxReconnectBackoff,
+	}
+}
+
+func (w *subscriptionPendingTxWatcher) Watch(
+	ctx context.Context,
+	_ ethcmn.Address,
+	onTx func(*RPCTransaction),
+) error {
+	return watchWithReconnect(ctx, w.logger, w.reconnectBackoff, func(ctx context.Context) error {
+		return w.watch(ctx, onTx)
+	})
+}
+
+// watch subscribes to the pending transaction hashes until the context is done or the subscription fails.
+func (w *subscriptionPendingTxWatcher) watch(ctx context.Context, onTx func(*RPCTransaction)) error {
+	wsClient, err := w.dial(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to Ethereum websocket")
+	}
+	defer wsClient.Close()
+
+	hashes := make(chan ethcmn.Hash, 256)
+	sub, err := wsClient.EthSubscribe(ctx, hashes, "newPendingTransactions")
+	if err != nil {
+		return errors.Wrap(err, "failed to subscribe to pending transactions")
+	}
+	defer sub.Unsubscribe()
+
+	w.logger.Info().Msg("watching pending transactions")
+
+	// The workers stop once toFetch is closed, which happens before waiting for them (defers run in reverse order).
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	toFetch := make(chan ethcmn.Hash, 256)
+	defer close(toFetch)
+
+	for i := 0; i < pendingTxFetchWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for hash := range toFetch {
+				if pendingTx := w.fetchTx(ctx, wsClient, hash); pendingTx != nil {
+					onTx(pendingTx)
+				}
+			}
+		}()
+	}
+
+	for {
+		select {
+		case hash := <-hashes:
+			select {
+			case toFetch <- hash:
+			default:
+				// We can't keep up with the mempool; dropping a hash is better than blocking the subscription.
+				w.logger.Debug().Str("tx_hash", hash.Hex()).Msg("pending tx fetch queue is full; skipping tx")
+			}
+
+		case err := <-sub.Err():
+			if err == nil {
+				return errors.New("pending transactions subscription closed")
+			}
+
+			return errors.Wrap(err, "pending transactions subscription failed")
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (w *subscriptionPendingTxWatcher) fetchTx(
+	ctx context.Context,
+	client *rpc.Client,
+	hash ethcmn.Hash,
+) *RPCTransaction {
+	ctx, cancel := context.WithTimeout(ctx, pendingTxFetchTimeout)
+	defer cancel()
+
+	var pendingTx *RPCTransaction
+	if err := client.CallContext(ctx, &pendingTx, "eth_getTransactionByHash", hash); err != nil {
+		w.logger.Debug().Err(err).Str("tx_hash", hash.Hex()).Msg("failed to get pending tx")
+		return nil
+	}
+
+	// The tx may have been mined or dropped already.
+	return pendingTx
+}
+
+type txPoolPendingTxWatcher struct {
+	logger           zerolog.Logger
+	dial             DialFn
+	pollInterval     time.Duration
+	reconnectBackoff time.Duration
+}
+
+// NewTxPoolPendingTxWatcher returns a PendingTxWatcher that polls the txpool_content method, which is available on
+// nodes that expose the txpool namespace (e.g. geth).
+func NewTxPoolPendingTxWatcher(logger zerolog.Logger, dial DialFn, pollInterval time.Duration) PendingTxWatcher {
+	return &txPoolPendingTxWatcher{
+		logger:           logger.With().Str("module", "txpool_pending_tx_watcher").Logger(),
+		dial:             dial,
+		pollInterval:     pollInterval,
+		reconnectBackoff: pendingTxReconnectBackoff,
+	}
+}
+
+// txPoolContent is the response of txpool_content: transactions grouped by status, sender and nonce.
+type txPoolContent struct {
+	Pending map[string]map[string]*RPCTransaction `json:"pending"`
+	Queued  map[string]map[string]*RPCTransaction `json:"queued"`
+}
+
+func (w *txPoolPendingTxWatcher) Watch(
+	ctx context.Context,
+	gravityAddress ethcmn.Address,
+	onTx func(*RPCTransaction),
+) error {
+	return watchWithReconnect(ctx, w.logger, w.reconnectBackoff, func(ctx context.Context) error {
+		return w.watch(ctx, gravityAddress, onTx)
+	})
+}
+
+// watch polls the txpool until the context is done. Failed polls are retried on the next tick, so it only fails
+// when it can't connect to the node.
+func (w *txPoolPendingTxWatcher) watch(
+	ctx context.Context,
+	gravityAddress ethcmn.Address,
+	onTx func(*RPCTransaction),
+) error {
+	client, err := w.dial(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to Ethereum node")
+	}
+	defer client.Close()
+
+	w.logger.Info().Dur("poll_interval", w.pollInterval).Msg("watching pending transactions")
+
+	// seen holds the hashes found in the previous poll, so each tx is only reported once while it's in the pool.
+	seen := map[ethcmn.Hash]struct{}{}
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		seen = w.poll(ctx, client, gravityAddress, seen, onTx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (w *txPoolPendingTxWatcher) poll(
+	ctx context.Context,
+	client *rpc.Client,
+	gravityAddress ethcmn.Address,
+	seen map[ethcmn.Hash]struct{},
+	onTx func(*RPCTransaction),
+) map[ethcmn.Hash]struct{} {
+	var content txPoolContent
+	if err := client.CallContext(ctx, &content, "txpool_content"); err != nil {
+		w.logger.Err(err).Msg("failed to get txpool content")
+		return seen
+	}
+
+	current := map[ethcmn.Hash]struct{}{}
+	for _, txsBySender := range []map[string]map[string]*RPCTransaction{content.Pending, content.Queued} {
+		for _, txsByNonce := range txsBySender {
+			for _, pendingTx := range txsByNonce {
+				if pendingTx == nil || pendingTx.To == nil || *pendingTx.To != gravityAddress {
+					continue
+				}
+
+				current[pendingTx.Hash] = struct{}{}
+				if _, ok := seen[pendingTx.Hash]; !ok {
+					onTx(pendingTx)
+				}
+			}
+		}
+	}
+
+	return current
+}
@@ -0,0 +1,244 @@
+package gravity
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	watcherGravityAddress = ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+	otherAddress          = ethcmn.HexToAddress("0x2260fac5e5542a773aa44fbcfedf7c193bc2c599")
+
+	gravityPendingTx = &RPCTransaction{
+		Hash:  ethcmn.HexToHash("0x01"),
+		To:    &watcherGravityAddress,
+		Input: hexutil.MustDecode("0x8690ff9800000000"),
+	}
+	otherPendingTx = &RPCTransaction{
+		Hash:  ethcmn.HexToHash("0x02"),
+		To:    &otherAddress,
+		Input: hexutil.MustDecode("0x8690ff9800000000"),
+	}
+)
+
+// fakeEthService implements the subscriptions and methods used by the pending tx watchers.
+type fakeEthService struct {
+	txs []*RPCTransaction
+}
+
+func (s *fakeEthService) notifyAll(ctx context.Context, notify func(*RPCTransaction) interface{}) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	sub := notifier.CreateSubscription()
+	go func() {
+		for _, tx := range s.txs {
+			_ = notifier.Notify(sub.ID, notify(tx))
+		}
+	}()
+
+	return sub, nil
+}
+
+func (s *fakeEthService) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	return s.notifyAll(ctx, func(tx *RPCTransaction) interface{} { return tx.Hash })
+}
+
+// nolint: revive,stylecheck // the name must match the Alchemy subscription
+func (s *fakeEthService) Alchemy_filteredNewFullPendingTransactions(
+	ctx context.Context,
+	_ map[string]interface{},
+) (*rpc.Subscription, error) {
+	return s.notifyAll(ctx, func(tx *RPCTransaction) interface{} { return tx })
+}
+
+func (s *fakeEthService) GetTransactionByHash(hash ethcmn.Hash) *RPCTransaction {
+	for _, tx := range s.txs {
+		if tx.Hash == hash {
+			return tx
+		}
+	}
+
+	return nil
+}
+
+type fakeTxPoolService struct {
+	txs []*RPCTransaction
+}
+
+func (s *fakeTxPoolService) Content() txPoolContent {
+	content := txPoolContent{Pending: map[string]map[string]*RPCTransaction{}}
+	for i, tx := range s.txs {
+		content.Pending[tx.Hash.Hex()] = map[string]*RPCTransaction{hexutil.EncodeUint64(uint64(i)): tx}
+	}
+
+	return content
+}
+
+func inProcDial(t *testing.T, txs []*RPCTransaction) DialFn {
+	server := rpc.NewServer()
+	assert.Nil(t, server.RegisterName("eth", &fakeEthService{txs: txs}))
+	assert.Nil(t, server.RegisterName("txpool", &fakeTxPoolService{txs: txs}))
+	t.Cleanup(server.Stop)
+
+	return func(context.Context) (*rpc.Client, error) {
+		return rpc.DialInProc(server), nil
+	}
+}
+
+// reconnectingDial returns a dial func that fails the first time and then returns a client that gets closed right away,
+// as if the websocket dropped, before connecting for good. It also returns the number of dials.
+func reconnectingDial(t *testing.T, txs []*RPCTransaction) (DialFn, *int32) {
+	dial := inProcDial(t, txs)
+	dropped := inProcDial(t, nil)
+
+	var dials int32
+	return func(ctx context.Context) (*rpc.Client, error) {
+		switch atomic.AddInt32(&dials, 1) {
+		case 1:
+			return nil, errors.New("connection refused")
+
+		case 2:
+			client, err := dropped(ctx)
+			time.AfterFunc(10*time.Millisecond, client.Close)
+			return client, err
+
+		default:
+			return dial(ctx)
+		}
+	}, &dials
+}
+
+// watchUntil runs the watcher until it reports the expected number of txs.
+func watchUntil(t *testing.T, watcher PendingTxWatcher, expected int) []*RPCTransaction {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := make(chan *RPCTransaction, 10)
+	done := make(chan error)
+	go func() {
+		done <- watcher.Watch(ctx, watcherGravityAddress, func(tx *RPCTransaction) { received <- tx })
+	}()
+
+	var txs []*RPCTransaction
+	for len(txs) < expected {
+		select {
+		case tx := <-received:
+			txs = append(txs, tx)
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for pending txs")
+		}
+	}
+
+	cancel()
+	assert.Nil(t, <-done)
+
+	return txs
+}
+
+func TestPendingTxWatchers(t *testing.T) {
+	txs := []*RPCTransaction{gravityPendingTx, otherPendingTx}
+
+	t.Run("alchemy", func(t *testing.T) {
+		watcher := NewAlchemyPendingTxWatcher(zerolog.Nop(), inProcDial(t, txs))
+		assert.ElementsMatch(t, txs, watchUntil(t, watcher, 2))
+	})
+
+	t.Run("subscribe", func(t *testing.T) {
+		watcher := NewSubscriptionPendingTxWatcher(zerolog.Nop(), inProcDial(t, txs))
+		assert.ElementsMatch(t, txs, watchUntil(t, watcher, 2))
+	})
+
+	t.Run("txpool", func(t *testing.T) {
+		watcher := NewTxPoolPendingTxWatcher(zerolog.Nop(), inProcDial(t, txs), time.Millisecond)
+
+		// Only txs to the Gravity contract are reported, and only once.
+		received := watchUntil(t, watcher, 1)
+		assert.Equal(t, []*RPCTransaction{gravityPendingTx}, received)
+	})
+
+	t.Run("alchemy reconnects", func(t *testing.T) {
+		dial, dials := reconnectingDial(t, txs)
+		watcher := NewAlchemyPendingTxWatcher(zerolog.Nop(), dial)
+		watcher.(*alchemyPendingTxWatcher).reconnectBackoff = time.Millisecond
+
+		assert.ElementsMatch(t, txs, watchUntil(t, watcher, 2))
+		assert.Equal(t, int32(3), atomic.LoadInt32(dials))
+	})
+
+	t.Run("subscribe reconnects", func(t *testing.T) {
+		dial, dials := reconnectingDial(t, txs)
+		watcher := NewSubscriptionPendingTxWatcher(zerolog.Nop(), dial)
+		watcher.(*subscriptionPendingTxWatcher).reconnectBackoff = time.Millisecond
+
+		assert.ElementsMatch(t, txs, watchUntil(t, watcher, 2))
+		assert.Equal(t, int32(3), atomic.LoadInt32(dials))
+	})
+
+	t.Run("txpool reconnects", func(t *testing.T) {
+		var dials int32
+		dial := inProcDial(t, txs)
+		watcher := NewTxPoolPendingTxWatcher(zerolog.Nop(), func(ctx context.Context) (*rpc.Client, error) {
+			if atomic.AddInt32(&dials, 1) == 1 {
+				return nil, errors.New("connection refused")
+			}
+
+			return dial(ctx)
+		}, time.Millisecond)
+		watcher.(*txPoolPendingTxWatcher).reconnectBackoff = time.Millisecond
+
+		assert.Equal(t, []*RPCTransaction{gravityPendingTx}, watchUntil(t, watcher, 1))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&dials))
+	})
+
+	t.Run("stops on cancel while reconnecting", func(t *testing.T) {
+		watcher := NewAlchemyPendingTxWatcher(zerolog.Nop(), func(context.Context) (*rpc.Client, error) {
+			return nil, errors.New("connection refused")
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- watcher.Watch(ctx, watcherGravityAddress, func(*RPCTransaction) {}) }()
+
+		cancel()
+		assert.Nil(t, <-done)
+	})
+
+	t.Run("invalid source", func(t *testing.T) {
+		_, err := NewPendingTxWatcher(zerolog.Nop(), "invalid", "ws://localhost:8546", time.Second)
+		assert.Error(t, err)
+	})
+}
+
+// stubWatcher reports a fixed list of txs.
+type stubWatcher []*RPCTransaction
+
+func (w stubWatcher) Watch(_ context.Context, _ ethcmn.Address, onTx func(*RPCTransaction)) error {
+	for _, tx := range w {
+		onTx(tx)
+	}
+
+	return nil
+}
+
+func TestWatchPendingTxs(t *testing.T) {
+	contract := &gravityContract{gravityAddress: watcherGravityAddress}
+
+	err := contract.WatchPendingTxs(context.Background(), stubWatcher{gravityPendingTx, otherPendingTx})
+	assert.Nil(t, err)
+
+	// The tx to another contract is ignored.
+	assert.Equal(t, 1, contract.GetPendingTxInputList().Len())
+	assert.True(t, contract.IsPendingTxInput(gravityPendingTx.Input, time.Minute))
+}
@@ -0,0 +1,42 @@
+package gravity
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// DecodeRevertReason decodes the revert data of a Gravity contract call. Besides require/revert strings, it decodes
+// the custom errors of the contract, e.g. "InvalidBatchNonce(newNonce=5, currentNonce=6)". It returns an empty
+// string if the data can't be decoded.
+func DecodeRevertReason(data []byte) string {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason
+	}
+
+	if len(data) < 4 {
+		return ""
+	}
+
+	for _, abiErr := range gravityABI.Errors {
+		if !bytes.Equal(abiErr.ID[:4], data[:4]) {
+			continue
+		}
+
+		values, err := abiErr.Inputs.Unpack(data[4:])
+		if err != nil {
+			return abiErr.Name
+		}
+
+		args := make([]string, len(values))
+		for i, value := range values {
+			args[i] = fmt.Sprintf("%s=%v", abiErr.Inputs[i].Name, value)
+		}
+
+		return fmt.Sprintf("%s(%s)", abiErr.Name, strings.Join(args, ", "))
+	}
+
+	return ""
+}
@@ -0,0 +1,37 @@
+package gravity
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeRevertReason(t *testing.T) {
+	assert.Equal(t, "", DecodeRevertReason(nil))
+	assert.Equal(t, "", DecodeRevertReason([]byte{1, 2, 3, 4, 5}))
+
+	stringType, err := abi.NewType("string", "", nil)
+	assert.Nil(t, err)
+
+	reason, err := abi.Arguments{{Type: stringType}}.Pack("some reason")
+	assert.Nil(t, err)
+
+	revertData := append(crypto.Keccak256([]byte("Error(string)"))[:4], reason...)
+	assert.Equal(t, "some reason", DecodeRevertReason(revertData))
+
+	invalidBatchNonce := gravityABI.Errors["InvalidBatchNonce"]
+	args, err := invalidBatchNonce.Inputs.Pack(big.NewInt(5), big.NewInt(6))
+	assert.Nil(t, err)
+
+	assert.Equal(
+		t,
+		"InvalidBatchNonce(newNonce=5, currentNonce=6)",
+		DecodeRevertReason(append(invalidBatchNonce.ID[:4], args...)),
+	)
+
+	incorrectCheckpoint := gravityABI.Errors["IncorrectCheckpoint"]
+	assert.Equal(t, "IncorrectCheckpoint()", DecodeRevertReason(incorrectCheckpoint.ID[:4]))
+}
@@ -1,10 +1,12 @@
 package gravity
 
 import (
+	"bytes"
 	"context"
 	"math/big"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 
@@ -79,6 +81,25 @@ func (s *gravityContract) EncodeValsetUpdate(
 	return txData, nil
 }
 
+// DecodeValsetUpdate decodes the input data of an updateValset call. It returns the current valset the call was made
+// with, and the signatures of its members over the new valset, in the same order as the members.
+func DecodeValsetUpdate(txData []byte) (wrappers.ValsetArgs, []wrappers.Signature, error) {
+	method := gravityABI.Methods["updateValset"]
+	if len(txData) < 4 || !bytes.Equal(txData[:4], method.ID) {
+		return wrappers.ValsetArgs{}, nil, errors.New("not an updateValset call")
+	}
+
+	values, err := method.Inputs.Unpack(txData[4:])
+	if err != nil {
+		return wrappers.ValsetArgs{}, nil, errors.Wrap(err, "failed to unpack updateValset call")
+	}
+
+	currentValset := *abi.ConvertType(values[1], new(wrappers.ValsetArgs)).(*wrappers.ValsetArgs)
+	sigs := *abi.ConvertType(values[2], new([]wrappers.Signature)).(*[]wrappers.Signature)
+
+	return currentValset, sigs, nil
+}
+
 func validatorsAndPowers(valset types.Valset) (
 	validators []ethcmn.Address,
 	powers []*big.Int,
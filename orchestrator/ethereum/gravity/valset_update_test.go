@@ -100,6 +100,15 @@ func TestEncodeValsetUpdate(t *testing.T) {
 	txDataHash := sha256.Sum256(txData)
 	assert.Equal(t, "f22e880adca043d34ea5af87cb0024e28c0cc0b5767b478ec6eb949705765015", hex.EncodeToString(txDataHash[:]))
 
+	currentValset, sigs, err := DecodeValsetUpdate(txData)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(1), currentValset.ValsetNonce)
+	assert.Len(t, currentValset.Validators, 3)
+	assert.Len(t, sigs, 3)
+	assert.Equal(t, uint8(0), sigs[2].V)
+
+	_, _, err = DecodeValsetUpdate(txData[:3])
+	assert.Error(t, err)
 }
 
 func TestValidatorsAndPowers(t *testing.T) {
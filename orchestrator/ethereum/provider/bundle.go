@@ -0,0 +1,369 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// BundleSignatureHeader is the header used by Flashbots-style relays to authenticate bundle requests.
+	BundleSignatureHeader = "X-Flashbots-Signature"
+
+	// bundleFallbackGraceChecks is the number of extra checks the fallback routine does before giving up, in case the
+	// node is lagging behind.
+	bundleFallbackGraceChecks = 10
+)
+
+// BundleFallbackWindow returns the number of blocks, at one poll per block, a tx sent as bundles targeting the
+// fallback blocks may take to land, either through a bundle or the public mempool fallback. Its nonce must not be
+// reused before then.
+func BundleFallbackWindow(fallbackBlocks uint64) uint64 {
+	return fallbackBlocks + bundleFallbackGraceChecks
+}
+
+type BundleOption func(p *bundleProvider) error
+
+// OptionBundleSigner sets the key used to sign the bundle requests. Relays use it to identify the sender (and
+// build its reputation), it doesn't need to hold any funds. By default, a random key is used.
+func OptionBundleSigner(key *ecdsa.PrivateKey) BundleOption {
+	return func(p *bundleProvider) error {
+		if key == nil {
+			return errors.New("bundle signer key can't be nil")
+		}
+
+		p.signerKey = key
+		return nil
+	}
+}
+
+// OptionBundleFallbackBlocks sets the number of blocks a bundle targets. If the transaction is not included in any of
+// them, it's sent to the public mempool.
+func OptionBundleFallbackBlocks(blocks uint64) BundleOption {
+	return func(p *bundleProvider) error {
+		if blocks == 0 {
+			return errors.New("bundle fallback blocks must be greater than zero")
+		}
+
+		p.fallbackBlocks = blocks
+		return nil
+	}
+}
+
+// OptionBundlePollInterval sets how often the inclusion of a bundle is checked.
+func OptionBundlePollInterval(interval time.Duration) BundleOption {
+	return func(p *bundleProvider) error {
+		if interval <= 0 {
+			return errors.New("bundle poll interval must be greater than zero")
+		}
+
+		p.pollInterval = interval
+		return nil
+	}
+}
+
+type bundleProvider struct {
+	EVMProviderWithRet
+
+	logger         zerolog.Logger
+	relayURL       string
+	httpClient     *http.Client
+	signerKey      *ecdsa.PrivateKey
+	fallbackBlocks uint64
+	pollInterval   time.Duration
+
+	// ctx stops the public mempool fallbacks on shutdown. The context of a send can't be used, since it's canceled
+	// once the send returns.
+	ctx context.Context
+
+	// pending holds the txs waiting for their bundles, which the node doesn't know about until they're included or
+	// sent to the public mempool.
+	pendingMtx sync.Mutex
+	pending    map[ethcmn.Hash]*types.Transaction
+}
+
+// NewBundleProvider returns an EVMProviderWithRet that sends transactions to a private relay as Flashbots-style
+// bundles (eth_sendBundle) instead of the public mempool, so they can't be front-run and failed relays don't cost
+// anything. If a transaction is not included after the fallback blocks, it's sent to the public mempool through the
+// wrapped provider, until the context is done. Everything else is served by the wrapped provider.
+func NewBundleProvider(
+	ctx context.Context,
+	logger zerolog.Logger,
+	evmProvider EVMProviderWithRet,
+	relayURL string,
+	bundleOpts ...BundleOption,
+) (EVMProviderWithRet, error) {
+	p := &bundleProvider{
+		EVMProviderWithRet: evmProvider,
+		logger:             logger.With().Str("module", "bundle_provider").Logger(),
+		relayURL:           relayURL,
+		httpClient:         &http.Client{Timeout: 10 * time.Second},
+		fallbackBlocks:     5,
+		pollInterval:       12 * time.Second,
+		ctx:                ctx,
+		pending:            map[ethcmn.Hash]*types.Transaction{},
+	}
+
+	for _, opt := range bundleOpts {
+		if err := opt(p); err != nil {
+			return nil, errors.Wrap(err, "failed to apply option to bundle provider")
+		}
+	}
+
+	if p.signerKey == nil {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate bundle signer key")
+		}
+
+		p.signerKey = key
+	}
+
+	return p, nil
+}
+
+// SendTransactionWithRet sends the transaction as a bundle targeting each of the next fallback blocks. If the relay
+// rejects every bundle, the transaction is sent to the public mempool right away.
+func (p *bundleProvider) SendTransactionWithRet(ctx context.Context, tx *types.Transaction) (ethcmn.Hash, error) {
+	latestHeader, err := p.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return ethcmn.Hash{}, errors.Wrap(err, "failed to get latest header")
+	}
+
+	rawTx, err := tx.MarshalBinary()
+	if err != nil {
+		return ethcmn.Hash{}, err
+	}
+
+	firstTargetBlock := latestHeader.Number.Uint64() + 1
+	lastTargetBlock := firstTargetBlock + p.fallbackBlocks - 1
+
+	var sent int
+	for block := firstTargetBlock; block <= lastTargetBlock; block++ {
+		if err := p.sendBundle(ctx, [][]byte{rawTx}, block); err != nil {
+			p.logger.Err(err).
+				Str("tx_hash", tx.Hash().Hex()).
+				Uint64("target_block", block).
+				Msg("failed to send bundle")
+			continue
+		}
+
+		sent++
+	}
+
+	if sent == 0 {
+		p.logger.Warn().Str("tx_hash", tx.Hash().Hex()).Msg("no bundle was accepted; sending tx to the public mempool")
+		return p.EVMProviderWithRet.SendTransactionWithRet(ctx, tx)
+	}
+
+	p.logger.Info().
+		Str("tx_hash", tx.Hash().Hex()).
+		Uint64("first_target_block", firstTargetBlock).
+		Uint64("last_target_block", lastTargetBlock).
+		Msg("sent tx bundle to private relay")
+
+	p.pendingMtx.Lock()
+	p.pending[tx.Hash()] = tx
+	p.pendingMtx.Unlock()
+
+	go func() {
+		defer func() {
+			p.pendingMtx.Lock()
+			delete(p.pending, tx.Hash())
+			p.pendingMtx.Unlock()
+		}()
+
+		p.fallbackIfNotIncluded(tx, lastTargetBlock)
+	}()
+
+	return tx.Hash(), nil
+}
+
+// TransactionByHash returns the transaction from the wrapped provider, or, if the node doesn't know it, from the txs
+// waiting for their bundles, as pending. This lets the nonce of a bundled tx be found before it lands.
+func (p *bundleProvider) TransactionByHash(
+	ctx context.Context,
+	txHash ethcmn.Hash,
+) (tx *types.Transaction, isPending bool, err error) {
+	tx, isPending, err = p.EVMProviderWithRet.TransactionByHash(ctx, txHash)
+	if !errors.Is(err, ethereum.NotFound) {
+		return tx, isPending, err
+	}
+
+	p.pendingMtx.Lock()
+	defer p.pendingMtx.Unlock()
+
+	if pendingTx, ok := p.pending[txHash]; ok {
+		return pendingTx, true, nil
+	}
+
+	return tx, isPending, err
+}
+
+// fallbackIfNotIncluded waits until the last target block of a bundle and sends the transaction to the public
+// mempool if it was not included and its nonce was not used by another transaction (e.g. a replacement).
+func (p *bundleProvider) fallbackIfNotIncluded(tx *types.Transaction, lastTargetBlock uint64) {
+	timeout := p.pollInterval * time.Duration(p.fallbackBlocks+bundleFallbackGraceChecks)
+	ctx, cancel := context.WithTimeout(p.ctx, timeout)
+	defer cancel()
+
+	logger := p.logger.With().Str("tx_hash", tx.Hash().Hex()).Logger()
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		logger.Err(err).Msg("failed to get tx sender")
+		return
+	}
+
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Warn().Msg("gave up waiting for the bundle target blocks")
+			return
+		case <-ticker.C:
+		}
+
+		latestHeader, err := p.HeaderByNumber(ctx, nil)
+		if err != nil {
+			logger.Err(err).Msg("failed to get latest header")
+			continue
+		}
+
+		if latestHeader.Number.Uint64() < lastTargetBlock {
+			continue
+		}
+
+		if _, err := p.TransactionReceipt(ctx, tx.Hash()); err == nil {
+			logger.Debug().Msg("tx bundle included")
+			return
+		}
+
+		nonce, err := p.NonceAt(ctx, from, nil)
+		if err != nil {
+			logger.Err(err).Msg("failed to get confirmed nonce")
+			continue
+		}
+
+		if nonce > tx.Nonce() {
+			logger.Debug().Msg("tx nonce already used; skipping public mempool fallback")
+			return
+		}
+
+		if _, err := p.EVMProviderWithRet.SendTransactionWithRet(ctx, tx); err != nil {
+			logger.Err(err).Msg("failed to send tx to the public mempool")
+			return
+		}
+
+		logger.Info().Msg("tx bundle not included; sent tx to the public mempool")
+		return
+	}
+}
+
+type bundleParams struct {
+	Txs         []string `json:"txs"`
+	BlockNumber string   `json:"blockNumber"`
+}
+
+type jsonrpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type jsonrpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// sendBundle sends an eth_sendBundle request for the given target block, signed as expected by Flashbots-style
+// relays.
+func (p *bundleProvider) sendBundle(ctx context.Context, rawTxs [][]byte, targetBlock uint64) error {
+	params := bundleParams{BlockNumber: hexutil.EncodeUint64(targetBlock)}
+	for _, rawTx := range rawTxs {
+		params.Txs = append(params.Txs, hexutil.Encode(rawTx))
+	}
+
+	body, err := json.Marshal(jsonrpcRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "eth_sendBundle",
+		Params:  []interface{}{params},
+	})
+	if err != nil {
+		return err
+	}
+
+	signature, err := SignBundleRequest(p.signerKey, body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.relayURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(BundleSignatureHeader, signature)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to reach bundle relay")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("bundle relay returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var rpcResp jsonrpcResponse
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return errors.Wrap(err, "failed to decode bundle relay response")
+	}
+
+	if rpcResp.Error != nil {
+		return errors.Errorf("bundle relay error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+
+	return nil
+}
+
+// SignBundleRequest returns the value of the signature header of a bundle request: the signer address and the
+// signature of the hex-encoded keccak256 hash of the body, signed as a personal message.
+func SignBundleRequest(key *ecdsa.PrivateKey, body []byte) (string, error) {
+	hashHex := hexutil.Encode(crypto.Keccak256(body))
+
+	signature, err := crypto.Sign(accounts.TextHash([]byte(hashHex)), key)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign bundle request")
+	}
+
+	return fmt.Sprintf("%s:%s", crypto.PubkeyToAddress(key.PublicKey).Hex(), hexutil.Encode(signature)), nil
+}
@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umee-network/peggo/mocks"
+)
+
+// mockBundleRelay is a local bundle relay that checks the request signatures and stores the received bundles.
+type mockBundleRelay struct {
+	t      *testing.T
+	signer ethcmn.Address
+	reject bool
+
+	mtx     sync.Mutex
+	bundles []bundleParams
+}
+
+func (m *mockBundleRelay) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	assert.Nil(m.t, err)
+
+	// Verify the signature header.
+	parts := strings.Split(r.Header.Get(BundleSignatureHeader), ":")
+	assert.Len(m.t, parts, 2)
+	assert.Equal(m.t, m.signer.Hex(), parts[0])
+
+	signature, err := hexutil.Decode(parts[1])
+	assert.Nil(m.t, err)
+
+	pubKey, err := crypto.SigToPub(accounts.TextHash([]byte(hexutil.Encode(crypto.Keccak256(body)))), signature)
+	assert.Nil(m.t, err)
+	assert.Equal(m.t, m.signer, crypto.PubkeyToAddress(*pubKey))
+
+	var req struct {
+		Method string         `json:"method"`
+		Params []bundleParams `json:"params"`
+	}
+	assert.Nil(m.t, json.Unmarshal(body, &req))
+	assert.Equal(m.t, "eth_sendBundle", req.Method)
+
+	if m.reject {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"bundle rejected"}}`))
+		return
+	}
+
+	m.mtx.Lock()
+	m.bundles = append(m.bundles, req.Params...)
+	m.mtx.Unlock()
+
+	_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x01"}}`))
+}
+
+func (m *mockBundleRelay) receivedBundles() []bundleParams {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return m.bundles
+}
+
+func signedTestTx(t *testing.T) *types.Transaction {
+	key, err := crypto.GenerateKey()
+	assert.Nil(t, err)
+
+	tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     7,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(100),
+		Gas:       100000,
+		Data:      []byte{1, 2, 3},
+	}), types.LatestSignerForChainID(big.NewInt(1)), key)
+	assert.Nil(t, err)
+
+	return tx
+}
+
+func TestBundleProvider(t *testing.T) {
+	ctx := context.Background()
+	signerKey, err := crypto.GenerateKey()
+	assert.Nil(t, err)
+
+	t.Run("bundle not included", func(t *testing.T) {
+		relay := &mockBundleRelay{t: t, signer: crypto.PubkeyToAddress(signerKey.PublicKey)}
+		server := httptest.NewServer(relay)
+		defer server.Close()
+
+		tx := signedTestTx(t)
+		rawTx, err := tx.MarshalBinary()
+		assert.Nil(t, err)
+
+		mockCtrl := gomock.NewController(t)
+		mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockEvmProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&types.Header{Number: big.NewInt(100)}, nil)
+		mockEvmProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&types.Header{Number: big.NewInt(103)}, nil)
+		mockEvmProvider.EXPECT().TransactionReceipt(gomock.Any(), tx.Hash()).Return(nil, ethereum.NotFound)
+		mockEvmProvider.EXPECT().NonceAt(gomock.Any(), gomock.Any(), nil).Return(uint64(7), nil)
+
+		fallbackSent := make(chan struct{})
+		mockEvmProvider.EXPECT().SendTransactionWithRet(gomock.Any(), tx).DoAndReturn(
+			func(context.Context, *types.Transaction) (ethcmn.Hash, error) {
+				close(fallbackSent)
+				return tx.Hash(), nil
+			},
+		)
+
+		bundleProvider, err := NewBundleProvider(
+			ctx,
+			zerolog.Nop(),
+			mockEvmProvider,
+			server.URL,
+			OptionBundleSigner(signerKey),
+			OptionBundleFallbackBlocks(3),
+			OptionBundlePollInterval(time.Millisecond),
+		)
+		assert.Nil(t, err)
+
+		txHash, err := bundleProvider.SendTransactionWithRet(ctx, tx)
+		assert.Nil(t, err)
+		assert.Equal(t, tx.Hash(), txHash)
+
+		assert.Equal(t, []bundleParams{
+			{Txs: []string{hexutil.Encode(rawTx)}, BlockNumber: "0x65"},
+			{Txs: []string{hexutil.Encode(rawTx)}, BlockNumber: "0x66"},
+			{Txs: []string{hexutil.Encode(rawTx)}, BlockNumber: "0x67"},
+		}, relay.receivedBundles())
+
+		select {
+		case <-fallbackSent:
+		case <-time.After(5 * time.Second):
+			t.Fatal("tx was not sent to the public mempool")
+		}
+	})
+
+	t.Run("pending bundle lookup", func(t *testing.T) {
+		relay := &mockBundleRelay{t: t, signer: crypto.PubkeyToAddress(signerKey.PublicKey)}
+		server := httptest.NewServer(relay)
+		defer server.Close()
+
+		tx := signedTestTx(t)
+
+		mockCtrl := gomock.NewController(t)
+		mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockEvmProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&types.Header{Number: big.NewInt(100)}, nil)
+		mockEvmProvider.EXPECT().TransactionByHash(gomock.Any(), tx.Hash()).Return(nil, false, ethereum.NotFound).Times(2)
+
+		// The fallback never polls, so the tx waits for its bundle until the context is done.
+		ctx, cancel := context.WithCancel(ctx)
+		bp, err := NewBundleProvider(
+			ctx,
+			zerolog.Nop(),
+			mockEvmProvider,
+			server.URL,
+			OptionBundleSigner(signerKey),
+			OptionBundlePollInterval(time.Hour),
+		)
+		assert.Nil(t, err)
+
+		_, err = bp.SendTransactionWithRet(ctx, tx)
+		assert.Nil(t, err)
+
+		// The node doesn't know the tx, but its nonce can be found while it waits for its bundle.
+		pendingTx, isPending, err := bp.TransactionByHash(ctx, tx.Hash())
+		assert.Nil(t, err)
+		assert.True(t, isPending)
+		assert.Equal(t, tx.Nonce(), pendingTx.Nonce())
+
+		cancel()
+		p := bp.(*bundleProvider)
+		assert.Eventually(t, func() bool {
+			p.pendingMtx.Lock()
+			defer p.pendingMtx.Unlock()
+
+			return len(p.pending) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		_, _, err = bp.TransactionByHash(ctx, tx.Hash())
+		assert.ErrorIs(t, err, ethereum.NotFound)
+	})
+
+	t.Run("bundle rejected", func(t *testing.T) {
+		relay := &mockBundleRelay{t: t, signer: crypto.PubkeyToAddress(signerKey.PublicKey), reject: true}
+		server := httptest.NewServer(relay)
+		defer server.Close()
+
+		tx := signedTestTx(t)
+
+		mockCtrl := gomock.NewController(t)
+		mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockEvmProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&types.Header{Number: big.NewInt(100)}, nil)
+		mockEvmProvider.EXPECT().SendTransactionWithRet(gomock.Any(), tx).Return(tx.Hash(), nil)
+
+		bundleProvider, err := NewBundleProvider(ctx, zerolog.Nop(), mockEvmProvider, server.URL, OptionBundleSigner(signerKey))
+		assert.Nil(t, err)
+
+		txHash, err := bundleProvider.SendTransactionWithRet(ctx, tx)
+		assert.Nil(t, err)
+		assert.Equal(t, tx.Hash(), txHash)
+		assert.Empty(t, relay.receivedBundles())
+	})
+}
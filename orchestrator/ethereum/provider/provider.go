@@ -12,7 +12,6 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -20,7 +19,9 @@ type EVMProvider interface {
 	bind.ContractCaller
 	bind.ContractFilterer
 
+	BalanceAt(ctx context.Context, account ethcmn.Address, blockNumber *big.Int) (*big.Int, error)
 	PendingNonceAt(ctx context.Context, account ethcmn.Address) (uint64, error)
+	NonceAt(ctx context.Context, account ethcmn.Address, blockNumber *big.Int) (uint64, error)
 	PendingCodeAt(ctx context.Context, account ethcmn.Address) ([]byte, error)
 	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
@@ -29,6 +30,12 @@ type EVMProvider interface {
 	SendTransaction(ctx context.Context, tx *types.Transaction) error
 	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
 	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	FeeHistory(
+		ctx context.Context,
+		blockCount uint64,
+		lastBlock *big.Int,
+		rewardPercentiles []float64,
+	) (*ethereum.FeeHistory, error)
 }
 
 type EVMProviderWithRet interface {
@@ -56,7 +63,8 @@ func (p *evmProviderWithRet) SendTransactionWithRet(
 	txHash ethcmn.Hash,
 	err error,
 ) {
-	data, err := rlp.EncodeToBytes(tx)
+	// MarshalBinary returns the RLP encoding for legacy transactions and the typed envelope for the others (e.g. EIP-1559).
+	data, err := tx.MarshalBinary()
 	if err != nil {
 		return ethcmn.Hash{}, err
 	}
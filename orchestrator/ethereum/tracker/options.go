@@ -0,0 +1,116 @@
+package tracker
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/pkg/errors"
+)
+
+// minFeeBumpPercent is the minimum fee increase geth (and most other clients) require to replace a transaction in
+// the mempool.
+const minFeeBumpPercent = uint64(10)
+
+type Option func(o *options) error
+
+type options struct {
+	StuckBlocks    uint64
+	Replace        bool
+	FeeBumpPercent uint64
+	MaxGasPrice    *big.Int
+	RevertDecoder  func(data []byte) string
+}
+
+func defaultOptions() *options {
+	return &options{
+		StuckBlocks:    10,
+		Replace:        true,
+		FeeBumpPercent: 20,
+		RevertDecoder:  decodeRevertString,
+	}
+}
+
+// decodeRevertString decodes a revert reason from a require/revert string (Error(string)).
+func decodeRevertString(data []byte) string {
+	reason, err := abi.UnpackRevert(data)
+	if err != nil {
+		return ""
+	}
+
+	return reason
+}
+
+func applyOptions(o *options, opts ...Option) error {
+	for _, oo := range opts {
+		if err := oo(o); err != nil {
+			err = errors.Wrap(err, "failed to apply option to tx tracker")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OptionStuckBlocks sets the number of blocks after which a transaction that was not mined is replaced. It's also the
+// number of blocks after which a transaction the node doesn't know about is considered dropped.
+func OptionStuckBlocks(blocks uint64) Option {
+	return func(o *options) error {
+		if blocks == 0 {
+			return errors.New("stuck blocks must be greater than zero")
+		}
+
+		o.StuckBlocks = blocks
+		return nil
+	}
+}
+
+// OptionReplace sets whether stuck transactions are replaced. When disabled, transactions are only tracked until they
+// reach a final outcome.
+func OptionReplace(replace bool) Option {
+	return func(o *options) error {
+		o.Replace = replace
+		return nil
+	}
+}
+
+// OptionFeeBump sets the percentage by which the fees of a stuck transaction are increased when replacing it.
+func OptionFeeBump(percent uint64) Option {
+	return func(o *options) error {
+		if percent < minFeeBumpPercent {
+			return errors.Errorf("fee bump must be at least %d%%", minFeeBumpPercent)
+		}
+
+		o.FeeBumpPercent = percent
+		return nil
+	}
+}
+
+// OptionMaxGasPrice sets the maximum gas price (or max fee per gas for dynamic-fee transactions) of a replacement
+// transaction. A nil or zero value means there's no cap.
+func OptionMaxGasPrice(maxGasPrice *big.Int) Option {
+	return func(o *options) error {
+		if maxGasPrice != nil && maxGasPrice.Sign() < 0 {
+			return errors.New("max gas price can't be negative")
+		}
+
+		if maxGasPrice != nil && maxGasPrice.Sign() == 0 {
+			maxGasPrice = nil
+		}
+
+		o.MaxGasPrice = maxGasPrice
+		return nil
+	}
+}
+
+// OptionRevertDecoder sets the function used to decode the revert reason of reverted transactions from the revert
+// data. It should return an empty string if the data can't be decoded.
+func OptionRevertDecoder(decoder func(data []byte) string) Option {
+	return func(o *options) error {
+		if decoder == nil {
+			return errors.New("revert decoder can't be nil")
+		}
+
+		o.RevertDecoder = decoder
+		return nil
+	}
+}
@@ -0,0 +1,624 @@
+package tracker
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/committer"
+)
+
+// cancelGasLimit is the gas limit of a plain value transfer, used to cancel transactions.
+const cancelGasLimit = uint64(21000)
+
+// Outcome defines the state of a tracked transaction.
+type Outcome int64
+
+const (
+	// OutcomePending means the transaction (or one of its replacements) was not mined yet.
+	OutcomePending Outcome = iota
+	// OutcomeConfirmed means the transaction was mined and executed successfully.
+	OutcomeConfirmed
+	// OutcomeReverted means the transaction was mined, but its execution reverted.
+	OutcomeReverted
+	// OutcomeDropped means the transaction is gone from the mempool and its nonce was not used by it.
+	OutcomeDropped
+	// OutcomeSuperseded means the transaction is no longer needed because someone else already did its job, e.g.
+	// another validator relayed the same batch first.
+	OutcomeSuperseded
+)
+
+// String gets the string representation of the outcome.
+func (o Outcome) String() string {
+	return [...]string{"pending", "confirmed", "reverted", "dropped", "superseded"}[o]
+}
+
+// IsDone returns true if the job of the transaction was done, either by us or by someone else.
+func (o Outcome) IsDone() bool {
+	return o == OutcomeConfirmed || o == OutcomeSuperseded
+}
+
+// Result holds the final outcome of a tracked transaction.
+type Result struct {
+	// TxHash is the hash of the transaction that determined the outcome, which may be a replacement.
+	TxHash      ethcmn.Hash
+	Outcome     Outcome
+	BlockNumber uint64
+	// GasUsed is the gas used by the mined transaction, if any.
+	GasUsed uint64
+	// GasPrice is the effective gas price paid by the mined transaction, which may be a replacement with bumped fees.
+	// It's nil if nothing was mined or the price couldn't be fetched.
+	GasPrice     *big.Int
+	RevertReason string
+}
+
+// Replacement describes a transaction sent by the tracker to replace or cancel a stuck one, using its nonce.
+type Replacement struct {
+	// OldTxHash is the hash of the transaction being replaced, which may be a previous replacement.
+	OldTxHash ethcmn.Hash
+	TxHash    ethcmn.Hash
+	Nonce     uint64
+	GasLimit  uint64
+	// GasPrice is the effective gas price of the replacement: the gas price for legacy transactions, or the base fee
+	// of the latest block plus the tip, capped by the fee cap, for dynamic-fee ones.
+	GasPrice *big.Int
+	// Cancel is true if the replacement is a zero-value transfer to ourselves that cancels the transaction.
+	Cancel bool
+}
+
+// Tracker keeps track of the transactions sent to Ethereum by the relayer until they reach a final outcome.
+// Transactions that are not mined after a number of blocks are replaced at the same nonce with bumped fees, or
+// cancelled if they are no longer needed.
+type Tracker interface {
+	// Track adds a transaction to the list of in-flight transactions. isObsolete is used to find out if the
+	// transaction is still needed, e.g. it returns true if the batch was relayed by someone else. onReplace is called
+	// whenever the transaction is replaced or cancelled, and onResult once it reaches a final outcome. Either callback
+	// may be nil.
+	Track(txHash ethcmn.Hash, isObsolete ObsoleteFn, onReplace ReplaceFn, onResult ResultFn)
+
+	// Check polls the receipts of the in-flight transactions, reporting the ones that reached a final outcome and
+	// replacing or cancelling the ones that are stuck.
+	Check(ctx context.Context) error
+
+	// Len returns the number of in-flight transactions.
+	Len() int
+}
+
+// ObsoleteFn returns true if a tracked transaction is no longer needed.
+type ObsoleteFn func(ctx context.Context) (bool, error)
+
+// ReplaceFn receives the transactions sent to replace or cancel a tracked transaction.
+type ReplaceFn func(replacement Replacement)
+
+// ResultFn receives the final outcome of a tracked transaction.
+type ResultFn func(result Result)
+
+type trackedTx struct {
+	// hashes holds the hashes of the original transaction and of all its replacements, as any of them may be mined.
+	hashes     []ethcmn.Hash
+	isObsolete ObsoleteFn
+	onReplace  ReplaceFn
+	onResult   ResultFn
+
+	// replacements holds the replacements sent during a check, reported once the check releases the lock.
+	replacements []Replacement
+
+	// firstCheckBlock is the block of the first check, used to give up on transactions the node never knew about.
+	firstCheckBlock uint64
+
+	// The fields below are filled once the transaction is found on the node.
+	resolved  bool
+	nonce     uint64
+	recipient ethcmn.Address
+	txData    []byte
+	gasCost   uint64
+	gasFees   committer.GasFees
+	sentBlock uint64
+
+	// cancelHash is the hash of the transaction that cancelled this one, if any.
+	cancelHash ethcmn.Hash
+}
+
+type txTracker struct {
+	logger    zerolog.Logger
+	committer committer.EVMCommitter
+	opts      *options
+
+	mtx sync.Mutex
+	txs []*trackedTx
+}
+
+// NewTracker returns a Tracker of the transactions sent by the given committer.
+func NewTracker(logger zerolog.Logger, evmCommitter committer.EVMCommitter, trackerOpts ...Option) (Tracker, error) {
+	t := &txTracker{
+		logger:    logger.With().Str("module", "tx_tracker").Logger(),
+		committer: evmCommitter,
+		opts:      defaultOptions(),
+	}
+
+	if err := applyOptions(t.opts, trackerOpts...); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *txTracker) Track(txHash ethcmn.Hash, isObsolete ObsoleteFn, onReplace ReplaceFn, onResult ResultFn) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.txs = append(t.txs, &trackedTx{
+		hashes:     []ethcmn.Hash{txHash},
+		isObsolete: isObsolete,
+		onReplace:  onReplace,
+		onResult:   onResult,
+	})
+}
+
+func (t *txTracker) Len() int {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	return len(t.txs)
+}
+
+func (t *txTracker) Check(ctx context.Context) error {
+	type finishedTx struct {
+		tx     *trackedTx
+		result Result
+	}
+
+	type replacedTx struct {
+		tx          *trackedTx
+		replacement Replacement
+	}
+
+	var (
+		finished []finishedTx
+		replaced []replacedTx
+	)
+
+	err := func() error {
+		t.mtx.Lock()
+		defer t.mtx.Unlock()
+
+		if len(t.txs) == 0 {
+			return nil
+		}
+
+		ethProvider := t.committer.Provider()
+
+		latestHeader, err := ethProvider.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return errors.Wrap(err, "failed to get latest header")
+		}
+
+		currentBlock := latestHeader.Number.Uint64()
+		baseFee := latestHeader.BaseFee
+
+		// Every nonce below the confirmed one was already used by a mined transaction.
+		confirmedNonce, err := ethProvider.NonceAt(ctx, t.committer.FromAddress(), nil)
+		if err != nil {
+			return errors.Wrap(err, "failed to get confirmed nonce")
+		}
+
+		inFlight := make([]*trackedTx, 0, len(t.txs))
+		for _, tx := range t.txs {
+			result, err := t.checkTx(ctx, tx, currentBlock, baseFee, confirmedNonce)
+			if err != nil {
+				t.logger.Err(err).Str("tx_hash", tx.lastHash().Hex()).Msg("failed to check in-flight transaction")
+			}
+
+			for _, replacement := range tx.replacements {
+				replaced = append(replaced, replacedTx{tx: tx, replacement: replacement})
+			}
+			tx.replacements = nil
+
+			if result == nil {
+				inFlight = append(inFlight, tx)
+				continue
+			}
+
+			finished = append(finished, finishedTx{tx: tx, result: *result})
+		}
+
+		t.txs = inFlight
+
+		return nil
+	}()
+
+	// The callbacks are called without holding the lock, so they are free to track new transactions.
+	for _, r := range replaced {
+		if r.tx.onReplace != nil {
+			r.tx.onReplace(r.replacement)
+		}
+	}
+
+	for _, f := range finished {
+		t.logger.Info().
+			Str("tx_hash", f.result.TxHash.Hex()).
+			Stringer("outcome", f.result.Outcome).
+			Uint64("block", f.result.BlockNumber).
+			Str("revert_reason", f.result.RevertReason).
+			Msg("in-flight transaction finished")
+
+		if f.tx.onResult != nil {
+			f.tx.onResult(f.result)
+		}
+	}
+
+	return err
+}
+
+// checkTx checks a single tracked transaction, returning its result once it reaches a final outcome.
+func (t *txTracker) checkTx(
+	ctx context.Context,
+	tx *trackedTx,
+	currentBlock uint64,
+	baseFee *big.Int,
+	confirmedNonce uint64,
+) (*Result, error) {
+	ethProvider := t.committer.Provider()
+
+	if tx.firstCheckBlock == 0 {
+		tx.firstCheckBlock = currentBlock
+	}
+
+	for _, hash := range tx.hashes {
+		receipt, err := ethProvider.TransactionReceipt(ctx, hash)
+		if err != nil {
+			if errors.Is(err, ethereum.NotFound) {
+				continue
+			}
+
+			return nil, errors.Wrap(err, "failed to get transaction receipt")
+		}
+
+		return t.minedResult(ctx, tx, hash, receipt), nil
+	}
+
+	if !tx.resolved {
+		if err := t.resolve(ctx, tx, currentBlock); err != nil {
+			if errors.Is(err, ethereum.NotFound) && currentBlock >= tx.firstCheckBlock+t.opts.StuckBlocks {
+				// The node never saw the transaction, or it was dropped before we could fetch it. Its nonce is
+				// unknown, so while any transaction of ours is pending, it might be this one, and a relay reusing
+				// the nonce would collide with it.
+				pending, pendingErr := t.hasPendingTxs(ctx, confirmedNonce)
+				if pendingErr != nil {
+					return nil, pendingErr
+				}
+
+				if !pending {
+					return &Result{TxHash: tx.lastHash(), Outcome: OutcomeDropped}, nil
+				}
+			}
+
+			// Without the transaction details we can't replace it, so we wait for the next check.
+			return nil, err
+		}
+	}
+
+	if tx.nonce < confirmedNonce {
+		// Another transaction with the same nonce was mined, which is not one we sent for this job (or we couldn't
+		// fetch its receipt).
+		result := &Result{TxHash: tx.lastHash(), Outcome: OutcomeDropped}
+		if t.isObsolete(ctx, tx) {
+			result.Outcome = OutcomeSuperseded
+		}
+
+		return result, nil
+	}
+
+	if !t.opts.Replace || currentBlock < tx.sentBlock+t.opts.StuckBlocks {
+		return nil, nil
+	}
+
+	if tx.cancelHash == (ethcmn.Hash{}) && t.isObsolete(ctx, tx) {
+		return nil, t.cancel(ctx, tx, currentBlock, baseFee)
+	}
+
+	return nil, t.replace(ctx, tx, currentBlock, baseFee)
+}
+
+// minedResult returns the result of a tracked transaction given the receipt of one of its hashes.
+func (t *txTracker) minedResult(
+	ctx context.Context,
+	tx *trackedTx,
+	hash ethcmn.Hash,
+	receipt *types.Receipt,
+) *Result {
+	result := &Result{
+		TxHash:      hash,
+		BlockNumber: receipt.BlockNumber.Uint64(),
+		GasUsed:     receipt.GasUsed,
+		GasPrice:    t.effectiveGasPrice(ctx, hash, receipt),
+	}
+
+	switch {
+	case hash == tx.cancelHash:
+		// We only cancel transactions whose job was already done by someone else.
+		result.Outcome = OutcomeSuperseded
+
+	case receipt.Status == types.ReceiptStatusSuccessful:
+		result.Outcome = OutcomeConfirmed
+
+	default:
+		result.RevertReason = t.revertReason(ctx, hash, receipt)
+
+		// Most of the time a relay reverts because someone else relayed the same (or a newer) item first.
+		result.Outcome = OutcomeReverted
+		if t.isObsolete(ctx, tx) {
+			result.Outcome = OutcomeSuperseded
+		}
+	}
+
+	return result
+}
+
+// effectiveGasPrice returns the gas price paid by a mined transaction. The receipts don't have it, so for
+// dynamic-fee transactions it's computed from the base fee of the block they were mined in. It returns nil if the
+// transaction or the block can't be fetched.
+func (t *txTracker) effectiveGasPrice(ctx context.Context, hash ethcmn.Hash, receipt *types.Receipt) *big.Int {
+	ethProvider := t.committer.Provider()
+
+	ethTx, _, err := ethProvider.TransactionByHash(ctx, hash)
+	if err != nil {
+		t.logger.Err(err).Str("tx_hash", hash.Hex()).Msg("failed to get mined transaction")
+		return nil
+	}
+
+	if ethTx.Type() != types.DynamicFeeTxType {
+		return ethTx.GasPrice()
+	}
+
+	header, err := ethProvider.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		t.logger.Err(err).Uint64("block", receipt.BlockNumber.Uint64()).Msg("failed to get block of mined transaction")
+		return nil
+	}
+
+	gasFees := committer.GasFees{GasFeeCap: ethTx.GasFeeCap(), GasTipCap: ethTx.GasTipCap(), BaseFee: header.BaseFee}
+	return gasFees.EffectiveGasPrice()
+}
+
+// revertReason replays a reverted transaction on the state of the block it was mined in and decodes the revert
+// reason from the error. Transactions mined after it in the same block are part of that state, so the reason may
+// not always be accurate.
+func (t *txTracker) revertReason(ctx context.Context, hash ethcmn.Hash, receipt *types.Receipt) string {
+	ethProvider := t.committer.Provider()
+
+	ethTx, _, err := ethProvider.TransactionByHash(ctx, hash)
+	if err != nil {
+		t.logger.Err(err).Str("tx_hash", hash.Hex()).Msg("failed to get reverted transaction")
+		return ""
+	}
+
+	msg := ethereum.CallMsg{
+		From:  t.committer.FromAddress(),
+		To:    ethTx.To(),
+		Gas:   ethTx.Gas(),
+		Value: ethTx.Value(),
+		Data:  ethTx.Data(),
+	}
+
+	_, err = ethProvider.CallContract(ctx, msg, receipt.BlockNumber)
+	if err == nil {
+		return ""
+	}
+
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if hexData, ok := dataErr.ErrorData().(string); ok {
+			if data, decodeErr := hexutil.Decode(hexData); decodeErr == nil {
+				if reason := t.opts.RevertDecoder(data); reason != "" {
+					return reason
+				}
+			}
+		}
+	}
+
+	return err.Error()
+}
+
+// hasPendingTxs returns true if the node has transactions of ours that were not mined yet.
+func (t *txTracker) hasPendingTxs(ctx context.Context, confirmedNonce uint64) (bool, error) {
+	pendingNonce, err := t.committer.Provider().PendingNonceAt(ctx, t.committer.FromAddress())
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get pending nonce")
+	}
+
+	return pendingNonce > confirmedNonce, nil
+}
+
+// isObsolete returns true if the job of the transaction was already done by someone else.
+func (t *txTracker) isObsolete(ctx context.Context, tx *trackedTx) bool {
+	if tx.isObsolete == nil {
+		return false
+	}
+
+	obsolete, err := tx.isObsolete(ctx)
+	if err != nil {
+		t.logger.Err(err).Str("tx_hash", tx.lastHash().Hex()).Msg("failed to check if the transaction is obsolete")
+		return false
+	}
+
+	return obsolete
+}
+
+// resolve fetches the nonce, payload and fees of the last sent transaction.
+func (t *txTracker) resolve(ctx context.Context, tx *trackedTx, currentBlock uint64) error {
+	ethTx, _, err := t.committer.Provider().TransactionByHash(ctx, tx.lastHash())
+	if err != nil {
+		return errors.Wrap(err, "failed to get transaction")
+	}
+
+	tx.resolved = true
+	tx.nonce = ethTx.Nonce()
+	tx.txData = ethTx.Data()
+	tx.gasCost = ethTx.Gas()
+	tx.sentBlock = currentBlock
+
+	if ethTx.To() != nil {
+		tx.recipient = *ethTx.To()
+	}
+
+	tx.gasFees = committer.GasFees{GasPrice: ethTx.GasPrice()}
+	if ethTx.Type() != 0 {
+		tx.gasFees = committer.GasFees{GasFeeCap: ethTx.GasFeeCap(), GasTipCap: ethTx.GasTipCap()}
+	}
+
+	return nil
+}
+
+// replace sends the same transaction with bumped fees.
+func (t *txTracker) replace(ctx context.Context, tx *trackedTx, currentBlock uint64, baseFee *big.Int) error {
+	gasFees, ok := t.bumpFees(tx.gasFees)
+	if !ok {
+		t.logger.Warn().
+			Str("tx_hash", tx.lastHash().Hex()).
+			Uint64("nonce", tx.nonce).
+			Msg("in-flight transaction is stuck, but its fees can't be bumped past the max gas price")
+
+		tx.sentBlock = currentBlock
+		return nil
+	}
+
+	txHash, err := t.committer.SendTxWithNonce(ctx, tx.nonce, tx.recipient, tx.txData, tx.gasCost, gasFees)
+	if err != nil {
+		return errors.Wrap(err, "failed to send replacement transaction")
+	}
+
+	t.logger.Info().
+		Str("old_tx_hash", tx.lastHash().Hex()).
+		Str("tx_hash", txHash.Hex()).
+		Uint64("nonce", tx.nonce).
+		Msg("replaced stuck transaction with bumped fees")
+
+	tx.addReplacement(txHash, tx.gasCost, gasFees, baseFee, false)
+	tx.hashes = append(tx.hashes, txHash)
+	tx.gasFees = gasFees
+	tx.sentBlock = currentBlock
+
+	return nil
+}
+
+// cancel replaces the transaction with a zero-value transfer to ourselves, so the nonce is used without calling the
+// Gravity contract.
+func (t *txTracker) cancel(ctx context.Context, tx *trackedTx, currentBlock uint64, baseFee *big.Int) error {
+	gasFees, ok := t.bumpFees(tx.gasFees)
+	if !ok {
+		t.logger.Warn().
+			Str("tx_hash", tx.lastHash().Hex()).
+			Uint64("nonce", tx.nonce).
+			Msg("in-flight transaction is obsolete, but its fees can't be bumped past the max gas price to cancel it")
+
+		tx.sentBlock = currentBlock
+		return nil
+	}
+
+	fromAddress := t.committer.FromAddress()
+
+	txHash, err := t.committer.SendTxWithNonce(ctx, tx.nonce, fromAddress, nil, cancelGasLimit, gasFees)
+	if err != nil {
+		return errors.Wrap(err, "failed to send cancellation transaction")
+	}
+
+	t.logger.Info().
+		Str("old_tx_hash", tx.lastHash().Hex()).
+		Str("tx_hash", txHash.Hex()).
+		Uint64("nonce", tx.nonce).
+		Msg("cancelled obsolete transaction")
+
+	tx.addReplacement(txHash, cancelGasLimit, gasFees, baseFee, true)
+	tx.hashes = append(tx.hashes, txHash)
+	tx.recipient = fromAddress
+	tx.txData = nil
+	tx.gasCost = cancelGasLimit
+	tx.gasFees = gasFees
+	tx.sentBlock = currentBlock
+	tx.cancelHash = txHash
+
+	return nil
+}
+
+// bumpFees increases the fees by the configured percentage, capped by the max gas price. It returns false if the
+// capped fees are not high enough for the node to accept the replacement.
+func (t *txTracker) bumpFees(gasFees committer.GasFees) (committer.GasFees, bool) {
+	if !gasFees.IsDynamic() {
+		gasPrice := t.bump(gasFees.GasPrice)
+		return committer.GasFees{GasPrice: gasPrice}, gasPrice != nil
+	}
+
+	gasFeeCap := t.bump(gasFees.GasFeeCap)
+	if gasFeeCap == nil {
+		return committer.GasFees{}, false
+	}
+
+	gasTipCap := bumpPercent(gasFees.GasTipCap, t.opts.FeeBumpPercent)
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		// The tip can't be greater than the fee cap, but it must still be bumped for the replacement to be accepted.
+		if minTip := bumpPercent(gasFees.GasTipCap, minFeeBumpPercent); minTip.Cmp(gasFeeCap) > 0 {
+			return committer.GasFees{}, false
+		}
+
+		gasTipCap = new(big.Int).Set(gasFeeCap)
+	}
+
+	return committer.GasFees{GasFeeCap: gasFeeCap, GasTipCap: gasTipCap}, true
+}
+
+// bump increases a price by the configured percentage, capped by the max gas price. It returns nil if the capped
+// price is below the minimum bump accepted by nodes.
+func (t *txTracker) bump(price *big.Int) *big.Int {
+	bumped := bumpPercent(price, t.opts.FeeBumpPercent)
+	if t.opts.MaxGasPrice == nil || bumped.Cmp(t.opts.MaxGasPrice) <= 0 {
+		return bumped
+	}
+
+	if bumpPercent(price, minFeeBumpPercent).Cmp(t.opts.MaxGasPrice) > 0 {
+		return nil
+	}
+
+	return new(big.Int).Set(t.opts.MaxGasPrice)
+}
+
+// bumpPercent returns price increased by percent, rounded up.
+func bumpPercent(price *big.Int, percent uint64) *big.Int {
+	bumped := new(big.Int).Mul(price, new(big.Int).SetUint64(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func (tx *trackedTx) lastHash() ethcmn.Hash {
+	return tx.hashes[len(tx.hashes)-1]
+}
+
+// addReplacement queues the report of a replacement of the last sent transaction. Must be called before the
+// replacement is added to the hashes.
+func (tx *trackedTx) addReplacement(
+	txHash ethcmn.Hash,
+	gasLimit uint64,
+	gasFees committer.GasFees,
+	baseFee *big.Int,
+	isCancel bool,
+) {
+	gasFees.BaseFee = baseFee
+
+	tx.replacements = append(tx.replacements, Replacement{
+		OldTxHash: tx.lastHash(),
+		TxHash:    txHash,
+		Nonce:     tx.nonce,
+		GasLimit:  gasLimit,
+		GasPrice:  gasFees.EffectiveGasPrice(),
+		Cancel:    isCancel,
+	})
+}
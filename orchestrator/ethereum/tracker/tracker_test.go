@@ -0,0 +1,343 @@
+package tracker
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umee-network/peggo/mocks"
+	gravityMocks "github.com/umee-network/peggo/mocks/gravity"
+	"github.com/umee-network/peggo/orchestrator/ethereum/committer"
+)
+
+var (
+	fromAddress    = ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e10c5df2f3f4edc5e4e")
+	gravityAddress = ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+	sentTxHash     = ethcmn.HexToHash("0x01")
+	replaceTxHash  = ethcmn.HexToHash("0x02")
+)
+
+func setupMocks(t *testing.T) (*gravityMocks.MockContract, *mocks.MockEVMProviderWithRet) {
+	mockCtrl := gomock.NewController(t)
+	mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+	mockCommitter := gravityMocks.NewMockContract(mockCtrl)
+
+	mockCommitter.EXPECT().Provider().Return(mockEvmProvider).AnyTimes()
+	mockCommitter.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+
+	return mockCommitter, mockEvmProvider
+}
+
+func expectChainState(mockEvmProvider *mocks.MockEVMProviderWithRet, block int64, confirmedNonce uint64) {
+	mockEvmProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&types.Header{Number: big.NewInt(block)}, nil)
+	mockEvmProvider.EXPECT().NonceAt(gomock.Any(), fromAddress, nil).Return(confirmedNonce, nil)
+}
+
+func expectSentTx(mockEvmProvider *mocks.MockEVMProviderWithRet) {
+	mockEvmProvider.EXPECT().TransactionByHash(gomock.Any(), sentTxHash).Return(
+		types.NewTransaction(5, gravityAddress, nil, 100000, big.NewInt(100), []byte{1, 2, 3}),
+		true,
+		nil,
+	)
+}
+
+func notObsolete(context.Context) (bool, error) { return false, nil }
+
+// resultRecorder stores the replacements and the result of a tracked transaction.
+type resultRecorder struct {
+	replacements []Replacement
+	result       *Result
+}
+
+func (r *resultRecorder) onReplace(replacement Replacement) {
+	r.replacements = append(r.replacements, replacement)
+}
+
+func (r *resultRecorder) onResult(result Result) {
+	r.result = &result
+}
+
+// revertError mimics the error returned by nodes when a call reverts.
+type revertError struct {
+	data string
+}
+
+func (e revertError) Error() string          { return "execution reverted" }
+func (e revertError) ErrorData() interface{} { return e.data }
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("no transactions", func(t *testing.T) {
+		mockCommitter, _ := setupMocks(t)
+
+		txTracker, err := NewTracker(zerolog.Nop(), mockCommitter)
+		assert.Nil(t, err)
+		assert.Nil(t, txTracker.Check(ctx))
+	})
+
+	t.Run("mined", func(t *testing.T) {
+		mockCommitter, mockEvmProvider := setupMocks(t)
+		expectChainState(mockEvmProvider, 100, 5)
+		mockEvmProvider.EXPECT().TransactionReceipt(gomock.Any(), sentTxHash).Return(&types.Receipt{
+			Status:      types.ReceiptStatusSuccessful,
+			BlockNumber: big.NewInt(99),
+		}, nil)
+		expectSentTx(mockEvmProvider)
+
+		txTracker, err := NewTracker(zerolog.Nop(), mockCommitter)
+		assert.Nil(t, err)
+
+		recorder := &resultRecorder{}
+		txTracker.Track(sentTxHash, notObsolete, recorder.onReplace, recorder.onResult)
+		assert.Nil(t, txTracker.Check(ctx))
+		assert.Equal(t, 0, txTracker.Len())
+		assert.Equal(t, &Result{
+			TxHash:      sentTxHash,
+			Outcome:     OutcomeConfirmed,
+			BlockNumber: 99,
+			GasPrice:    big.NewInt(100),
+		}, recorder.result)
+	})
+
+	t.Run("reverted", func(t *testing.T) {
+		mockCommitter, mockEvmProvider := setupMocks(t)
+		expectChainState(mockEvmProvider, 100, 6)
+		mockEvmProvider.EXPECT().TransactionReceipt(gomock.Any(), sentTxHash).Return(&types.Receipt{
+			Status:      types.ReceiptStatusFailed,
+			BlockNumber: big.NewInt(99),
+		}, nil)
+		expectSentTx(mockEvmProvider)
+		expectSentTx(mockEvmProvider)
+
+		// Error(string) with "some reason"
+		mockEvmProvider.EXPECT().CallContract(gomock.Any(), gomock.Any(), big.NewInt(99)).Return(nil, revertError{
+			data: "0x08c379a0" +
+				"0000000000000000000000000000000000000000000000000000000000000020" +
+				"000000000000000000000000000000000000000000000000000000000000000b" +
+				"736f6d6520726561736f6e000000000000000000000000000000000000000000",
+		})
+
+		txTracker, err := NewTracker(zerolog.Nop(), mockCommitter)
+		assert.Nil(t, err)
+
+		recorder := &resultRecorder{}
+		txTracker.Track(sentTxHash, notObsolete, recorder.onReplace, recorder.onResult)
+		assert.Nil(t, txTracker.Check(ctx))
+		assert.Equal(t, &Result{
+			TxHash:       sentTxHash,
+			Outcome:      OutcomeReverted,
+			BlockNumber:  99,
+			GasPrice:     big.NewInt(100),
+			RevertReason: "some reason",
+		}, recorder.result)
+	})
+
+	t.Run("unknown to the node", func(t *testing.T) {
+		mockCommitter, mockEvmProvider := setupMocks(t)
+		mockEvmProvider.EXPECT().TransactionReceipt(gomock.Any(), sentTxHash).Return(nil, ethereum.NotFound).Times(2)
+		mockEvmProvider.EXPECT().TransactionByHash(gomock.Any(), sentTxHash).Return(nil, false, ethereum.NotFound).Times(2)
+
+		txTracker, err := NewTracker(zerolog.Nop(), mockCommitter, OptionStuckBlocks(2))
+		assert.Nil(t, err)
+
+		recorder := &resultRecorder{}
+		txTracker.Track(sentTxHash, notObsolete, recorder.onReplace, recorder.onResult)
+
+		expectChainState(mockEvmProvider, 100, 5)
+		assert.Nil(t, txTracker.Check(ctx))
+		assert.Nil(t, recorder.result)
+
+		expectChainState(mockEvmProvider, 102, 5)
+		mockEvmProvider.EXPECT().PendingNonceAt(gomock.Any(), fromAddress).Return(uint64(5), nil)
+		assert.Nil(t, txTracker.Check(ctx))
+		assert.Equal(t, &Result{TxHash: sentTxHash, Outcome: OutcomeDropped}, recorder.result)
+	})
+
+	t.Run("unknown to the node with pending txs", func(t *testing.T) {
+		mockCommitter, mockEvmProvider := setupMocks(t)
+		mockEvmProvider.EXPECT().TransactionReceipt(gomock.Any(), sentTxHash).Return(nil, ethereum.NotFound).Times(3)
+		mockEvmProvider.EXPECT().TransactionByHash(gomock.Any(), sentTxHash).Return(nil, false, ethereum.NotFound).Times(2)
+
+		txTracker, err := NewTracker(zerolog.Nop(), mockCommitter, OptionStuckBlocks(2))
+		assert.Nil(t, err)
+
+		recorder := &resultRecorder{}
+		txTracker.Track(sentTxHash, notObsolete, recorder.onReplace, recorder.onResult)
+
+		expectChainState(mockEvmProvider, 100, 5)
+		assert.Nil(t, txTracker.Check(ctx))
+
+		// A tx of ours is pending, which might be this one (e.g. sent late by a bundle fallback), so its nonce is not
+		// given up on.
+		expectChainState(mockEvmProvider, 102, 5)
+		mockEvmProvider.EXPECT().PendingNonceAt(gomock.Any(), fromAddress).Return(uint64(6), nil)
+		assert.Nil(t, txTracker.Check(ctx))
+		assert.Nil(t, recorder.result)
+		assert.Equal(t, 1, txTracker.Len())
+
+		// Once it's found, its job is tracked as usual.
+		expectChainState(mockEvmProvider, 103, 6)
+		mockEvmProvider.EXPECT().TransactionByHash(gomock.Any(), sentTxHash).Return(
+			types.NewTransaction(5, gravityAddress, nil, 100000, big.NewInt(100), []byte{1, 2, 3}),
+			false,
+			nil,
+		)
+		assert.Nil(t, txTracker.Check(ctx))
+		assert.Equal(t, &Result{TxHash: sentTxHash, Outcome: OutcomeDropped}, recorder.result)
+	})
+
+	t.Run("stuck", func(t *testing.T) {
+		mockCommitter, mockEvmProvider := setupMocks(t)
+		mockEvmProvider.EXPECT().TransactionReceipt(gomock.Any(), gomock.Any()).Return(nil, ethereum.NotFound).AnyTimes()
+
+		txTracker, err := NewTracker(zerolog.Nop(), mockCommitter, OptionStuckBlocks(3))
+		assert.Nil(t, err)
+
+		recorder := &resultRecorder{}
+		txTracker.Track(sentTxHash, notObsolete, recorder.onReplace, recorder.onResult)
+
+		// First check finds the transaction on the node.
+		expectChainState(mockEvmProvider, 100, 5)
+		expectSentTx(mockEvmProvider)
+		assert.Nil(t, txTracker.Check(ctx))
+
+		// Not stuck yet.
+		expectChainState(mockEvmProvider, 102, 5)
+		assert.Nil(t, txTracker.Check(ctx))
+
+		expectChainState(mockEvmProvider, 103, 5)
+		mockCommitter.EXPECT().SendTxWithNonce(
+			gomock.Any(),
+			uint64(5),
+			gravityAddress,
+			[]byte{1, 2, 3},
+			uint64(100000),
+			committer.GasFees{GasPrice: big.NewInt(120)},
+		).Return(replaceTxHash, nil)
+		assert.Nil(t, txTracker.Check(ctx))
+		assert.Equal(t, 1, txTracker.Len())
+		assert.Equal(t, []Replacement{{
+			OldTxHash: sentTxHash,
+			TxHash:    replaceTxHash,
+			Nonce:     5,
+			GasLimit:  100000,
+			GasPrice:  big.NewInt(120),
+		}}, recorder.replacements)
+
+		// Another tx with the same nonce was mined.
+		expectChainState(mockEvmProvider, 104, 6)
+		assert.Nil(t, txTracker.Check(ctx))
+		assert.Equal(t, 0, txTracker.Len())
+		assert.Equal(t, &Result{TxHash: replaceTxHash, Outcome: OutcomeDropped}, recorder.result)
+	})
+
+	t.Run("obsolete", func(t *testing.T) {
+		mockCommitter, mockEvmProvider := setupMocks(t)
+		mockEvmProvider.EXPECT().TransactionReceipt(gomock.Any(), sentTxHash).Return(nil, ethereum.NotFound).AnyTimes()
+
+		txTracker, err := NewTracker(zerolog.Nop(), mockCommitter, OptionStuckBlocks(1))
+		assert.Nil(t, err)
+		recorder := &resultRecorder{}
+		txTracker.Track(
+			sentTxHash,
+			func(context.Context) (bool, error) { return true, nil },
+			recorder.onReplace,
+			recorder.onResult,
+		)
+
+		expectChainState(mockEvmProvider, 100, 5)
+		expectSentTx(mockEvmProvider)
+		assert.Nil(t, txTracker.Check(ctx))
+
+		expectChainState(mockEvmProvider, 101, 5)
+		mockCommitter.EXPECT().SendTxWithNonce(
+			gomock.Any(),
+			uint64(5),
+			fromAddress,
+			nil,
+			cancelGasLimit,
+			committer.GasFees{GasPrice: big.NewInt(120)},
+		).Return(replaceTxHash, nil)
+		assert.Nil(t, txTracker.Check(ctx))
+		assert.Equal(t, []Replacement{{
+			OldTxHash: sentTxHash,
+			TxHash:    replaceTxHash,
+			Nonce:     5,
+			GasLimit:  cancelGasLimit,
+			GasPrice:  big.NewInt(120),
+			Cancel:    true,
+		}}, recorder.replacements)
+
+		// The cancellation is mined.
+		expectChainState(mockEvmProvider, 102, 5)
+		mockEvmProvider.EXPECT().TransactionReceipt(gomock.Any(), replaceTxHash).Return(&types.Receipt{
+			Status:      types.ReceiptStatusSuccessful,
+			BlockNumber: big.NewInt(102),
+		}, nil)
+
+		// A dynamic-fee tx pays the base fee of its block plus the tip.
+		mockEvmProvider.EXPECT().TransactionByHash(gomock.Any(), replaceTxHash).Return(
+			types.NewTx(&types.DynamicFeeTx{Nonce: 5, GasFeeCap: big.NewInt(200), GasTipCap: big.NewInt(20)}),
+			false,
+			nil,
+		)
+		mockEvmProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(102)).Return(
+			&types.Header{Number: big.NewInt(102), BaseFee: big.NewInt(110)},
+			nil,
+		)
+		assert.Nil(t, txTracker.Check(ctx))
+		assert.Equal(t, 0, txTracker.Len())
+		assert.Equal(t, &Result{
+			TxHash:      replaceTxHash,
+			Outcome:     OutcomeSuperseded,
+			BlockNumber: 102,
+			GasPrice:    big.NewInt(130),
+		}, recorder.result)
+	})
+}
+
+func TestAddReplacement(t *testing.T) {
+	tx := &trackedTx{hashes: []ethcmn.Hash{sentTxHash}, nonce: 5}
+	gasFees := committer.GasFees{GasFeeCap: big.NewInt(200), GasTipCap: big.NewInt(20)}
+
+	// Dynamic-fee replacements pay the base fee plus the tip, capped by the fee cap.
+	tx.addReplacement(replaceTxHash, 100000, gasFees, big.NewInt(100), false)
+	tx.addReplacement(replaceTxHash, cancelGasLimit, gasFees, big.NewInt(190), true)
+	tx.addReplacement(replaceTxHash, cancelGasLimit, gasFees, nil, true)
+
+	assert.Equal(t, big.NewInt(120), tx.replacements[0].GasPrice)
+	assert.Equal(t, big.NewInt(200), tx.replacements[1].GasPrice)
+	assert.Equal(t, big.NewInt(200), tx.replacements[2].GasPrice)
+	assert.Equal(t, sentTxHash, tx.replacements[0].OldTxHash)
+}
+
+func TestBumpFees(t *testing.T) {
+	txTracker := &txTracker{opts: defaultOptions()}
+
+	gasFees, ok := txTracker.bumpFees(committer.GasFees{GasPrice: big.NewInt(101)})
+	assert.True(t, ok)
+	assert.Equal(t, committer.GasFees{GasPrice: big.NewInt(122)}, gasFees)
+
+	gasFees, ok = txTracker.bumpFees(committer.GasFees{GasFeeCap: big.NewInt(200), GasTipCap: big.NewInt(10)})
+	assert.True(t, ok)
+	assert.Equal(t, committer.GasFees{GasFeeCap: big.NewInt(240), GasTipCap: big.NewInt(12)}, gasFees)
+
+	txTracker.opts.MaxGasPrice = big.NewInt(115)
+
+	// Capped, but still above the minimum bump.
+	gasFees, ok = txTracker.bumpFees(committer.GasFees{GasPrice: big.NewInt(100)})
+	assert.True(t, ok)
+	assert.Equal(t, committer.GasFees{GasPrice: big.NewInt(115)}, gasFees)
+
+	// The replacement would be rejected by the node.
+	_, ok = txTracker.bumpFees(committer.GasFees{GasPrice: big.NewInt(110)})
+	assert.False(t, ok)
+}
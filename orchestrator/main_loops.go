@@ -12,6 +12,8 @@ import (
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/shopspring/decimal"
 
+	"github.com/umee-network/peggo/cmd/peggo/client"
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
 	"github.com/umee-network/peggo/orchestrator/loops"
 	"github.com/umee-network/peggo/orchestrator/oracle"
 )
@@ -231,6 +233,11 @@ func (p *gravityOrchestrator) EthSignerMainLoop(ctx context.Context) (err error)
 	logger.Debug().Str("gravityID", gravityID).Msg("received gravityID")
 
 	return loops.RunLoop(ctx, p.logger, p.cosmosBlockTime*ethSignerLoopMultiplier, func() error {
+		if p.emergency != nil && p.emergency.Halted() {
+			logger.Error().Msg("signing is halted by the emergency response to a valset hijack")
+			return nil
+		}
+
 		var oldestUnsignedValsets []types.Valset
 		if err := retry.Do(func() error {
 			oldestValsets, err := p.cosmosQueryClient.LastPendingValsetRequestByAddr(
@@ -258,12 +265,17 @@ func (p *gravityOrchestrator) EthSignerMainLoop(ctx context.Context) (err error)
 			return err
 		}
 
+		// The confirms are queued, so they're broadcast together, and awaited at the end of the loop.
+		var confirms []queuedConfirm
+
 		for _, oldestValset := range oldestUnsignedValsets {
 			logger.Info().Uint64("oldest_valset_nonce", oldestValset.Nonce).Msg("sending Valset confirm for nonce")
 			valset := oldestValset
 
-			if err := retry.Do(func() error {
-				return p.gravityBroadcastClient.SendValsetConfirm(ctx, p.ethFrom, gravityID, valset)
+			var handle *client.BroadcastHandle
+			if err := retry.Do(func() (err error) {
+				handle, err = p.gravityBroadcastClient.SendValsetConfirm(ctx, p.ethFrom, gravityID, valset)
+				return err
 			}, retry.Context(ctx), retry.OnRetry(func(n uint, err error) {
 				logger.Err(err).
 					Uint("retry", n).
@@ -272,6 +284,8 @@ func (p *gravityOrchestrator) EthSignerMainLoop(ctx context.Context) (err error)
 				logger.Err(err).Msg("got error, loop exits")
 				return err
 			}
+
+			confirms = append(confirms, queuedConfirm{kind: "Valset", nonce: valset.Nonce, handle: handle})
 		}
 
 		// Try to send batch confirms. If this fails, it means there are pending batches
@@ -311,8 +325,11 @@ func (p *gravityOrchestrator) EthSignerMainLoop(ctx context.Context) (err error)
 			logger.Info().
 				Uint64("batch_nonce", batch.BatchNonce).
 				Msg("sending TransactionBatch confirm for BatchNonce")
-			if err := retry.Do(func() error {
-				return p.gravityBroadcastClient.SendBatchConfirm(ctx, p.ethFrom, gravityID, batch)
+
+			var handle *client.BroadcastHandle
+			if err := retry.Do(func() (err error) {
+				handle, err = p.gravityBroadcastClient.SendBatchConfirm(ctx, p.ethFrom, gravityID, batch)
+				return err
 			}, retry.Context(ctx), retry.OnRetry(func(n uint, err error) {
 				logger.Err(err).
 					Uint("retry", n).
@@ -321,12 +338,43 @@ func (p *gravityOrchestrator) EthSignerMainLoop(ctx context.Context) (err error)
 				logger.Err(err).Msg("got error, loop exits")
 				return err
 			}
+
+			confirms = append(confirms, queuedConfirm{kind: "TransactionBatch", nonce: batch.BatchNonce, handle: handle})
+		}
+
+		// A confirm that failed is still pending, so it's signed and sent again on the next loop.
+		for _, confirm := range confirms {
+			res, err := confirm.handle.Wait(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+
+				logger.Err(err).
+					Str("kind", confirm.kind).
+					Uint64("nonce", confirm.nonce).
+					Msg("confirmation failed; it will be sent again on the next loop")
+				continue
+			}
+
+			logger.Info().
+				Str("kind", confirm.kind).
+				Uint64("nonce", confirm.nonce).
+				Str("tx_hash", res.TxHash).
+				Msg("confirmation included in a block")
 		}
 
 		return nil
 	})
 }
 
+// queuedConfirm is a valset or batch confirmation queued for broadcast.
+type queuedConfirm struct {
+	kind   string
+	nonce  uint64
+	handle *client.BroadcastHandle
+}
+
 // BatchRequesterLoop sends a batch request to Cosmos (Umee).
 func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error) {
 	logger := p.logger.With().Str("loop", "BatchRequesterLoop").Logger()
@@ -342,6 +390,12 @@ func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error
 		gasPrice := big.NewInt(0)
 		tokensPrices := make(map[string]decimal.Decimal)
 		tokensDecimals := make(map[string]uint8)
+		// staleReasons holds why the price of each token is too old to be used, and ethStaleReason why the ETH price
+		// is; batches are only requested with fresh prices.
+		staleReasons := make(map[string]string)
+		var ethStaleReason string
+		// unreadableReasons holds why the metadata of each token can't be read; these tokens can't be priced.
+		unreadableReasons := make(map[string]string)
 
 		pg.Go(func() error {
 			var unbatchedTokensWithFees []types.BatchFees
@@ -360,12 +414,19 @@ func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error
 						return fmt.Errorf("failed to get Ethereum gas estimate: %w", err)
 					}
 
-					usdEthPrice, err := p.oracle.GetPrice(oracle.SymbolETH)
+					usdEthPrice, err := p.oracle.GetPriceInfo(oracle.SymbolETH)
 					if err != nil {
 						return err
 					}
 
-					usdEthPriceDec, err = decimal.NewFromString(usdEthPrice.String())
+					ethStaleReason = ""
+					if err := oracle.CheckPriceAge(oracle.SymbolETH, usdEthPrice, p.relayer.GetMaxPriceAge()); err != nil {
+						// The batches can't be priced without the ETH price.
+						ethStaleReason = err.Error()
+						return nil
+					}
+
+					usdEthPriceDec, err = decimal.NewFromString(usdEthPrice.Price.String())
 					if err != nil {
 						return err
 					}
@@ -374,10 +435,14 @@ func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error
 						if _, ok := tokensPrices[token.Token]; !ok {
 							baseSymbol, err := p.symbolRetriever.GetTokenSymbol(ethcmn.HexToAddress(token.Token))
 							if err != nil {
+								if gravity.IsUnreadableToken(err) {
+									unreadableReasons[token.Token] = err.Error()
+									continue
+								}
 								return err
 							}
 
-							price, err := p.oracle.GetPrice(baseSymbol)
+							price, err := p.oracle.GetPriceInfo(baseSymbol)
 							if err != nil {
 								// Our providers may not yet be subscribed to their websockets.
 								if err := p.oracle.SubscribeSymbols(baseSymbol); err != nil {
@@ -387,11 +452,16 @@ func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error
 								return err
 							}
 
-							priceDec, err := decimal.NewFromString(price.String())
+							if err := oracle.CheckPriceAge(baseSymbol, price, p.relayer.GetMaxPriceAge()); err != nil {
+								staleReasons[token.Token] = err.Error()
+								continue
+							}
+							delete(staleReasons, token.Token)
+
+							priceDec, err := decimal.NewFromString(price.Price.String())
 							if err != nil {
 								return err
 							}
-							tokensPrices[token.Token] = priceDec
 
 							tokensDecimals[token.Token], err = p.gravityContract.GetERC20Decimals(
 								ctx,
@@ -399,8 +469,15 @@ func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error
 								p.gravityContract.FromAddress(),
 							)
 							if err != nil {
+								if gravity.IsUnreadableToken(err) {
+									unreadableReasons[token.Token] = err.Error()
+									continue
+								}
 								return err
 							}
+							delete(unreadableReasons, token.Token)
+
+							tokensPrices[token.Token] = priceDec
 						}
 					}
 				}
@@ -413,6 +490,9 @@ func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error
 				return nil
 			}
 
+			// The batch requests are queued, so they're broadcast together, and awaited once all of them are sent.
+			requests := make(map[string]*client.BroadcastHandle)
+
 			for _, unbatchedToken := range unbatchedTokensWithFees {
 				unbatchedToken := unbatchedToken
 				tokenAddr := ethcmn.HexToAddress(unbatchedToken.Token)
@@ -427,6 +507,29 @@ func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error
 				shouldRequestBatch := true
 
 				if p.relayer.GetProfitMultiplier() > 0.0 {
+					staleReason := ethStaleReason
+					if staleReason == "" {
+						staleReason = staleReasons[unbatchedToken.Token]
+					}
+
+					if staleReason != "" {
+						logger.Warn().
+							Str("token_contract", tokenAddr.String()).
+							Str("denom", denom).
+							Str("reason", staleReason).
+							Msg("stale price, skipping batch creation")
+						continue
+					}
+
+					if unreadableReason, ok := unreadableReasons[unbatchedToken.Token]; ok {
+						logger.Warn().
+							Str("token_contract", tokenAddr.String()).
+							Str("denom", denom).
+							Str("reason", unreadableReason).
+							Msg("unreadable token, skipping batch creation")
+						continue
+					}
+
 					// First we get the cost of the transaction in USD
 					totalETHcost := big.NewInt(0).Mul(gasPrice, big.NewInt(estimatedGasCosts[unbatchedToken.TxCount-1]))
 					// Ethereum decimals are 18 and that's a constant.
@@ -445,9 +548,13 @@ func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error
 				if shouldRequestBatch {
 					logger.Info().Str("token_contract", tokenAddr.String()).Str("denom", denom).Msg("sending batch request")
 
-					if err := p.gravityBroadcastClient.SendRequestBatch(ctx, denom); err != nil {
+					handle, err := p.gravityBroadcastClient.SendRequestBatch(ctx, denom)
+					if err != nil {
 						logger.Err(err).Msg("failed to send batch request")
+						continue
 					}
+
+					requests[denom] = handle
 				} else {
 					logger.Debug().
 						Str("token_contract", tokenAddr.String()).
@@ -456,6 +563,20 @@ func (p *gravityOrchestrator) BatchRequesterLoop(ctx context.Context) (err error
 				}
 			}
 
+			for denom, handle := range requests {
+				res, err := handle.Wait(ctx)
+				if err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+
+					logger.Err(err).Str("denom", denom).Msg("batch request failed")
+					continue
+				}
+
+				logger.Info().Str("denom", denom).Str("tx_hash", res.TxHash).Msg("batch request included in a block")
+			}
+
 			return nil
 		})
 
@@ -0,0 +1,494 @@
+package oracle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Uniswap pool versions
+const (
+	DexPoolV2 = "v2"
+	DexPoolV3 = "v3"
+)
+
+// Quote tokens of the DEX pools
+const (
+	SymbolWETH = "WETH"
+	SymbolUSDC = "USDC"
+)
+
+const (
+	// dexCallTimeout is the timeout of the eth_calls made to price a symbol.
+	dexCallTimeout = 10 * time.Second
+	// defaultTWAPWindow is the default period of the time-weighted average price of V3 pools.
+	defaultTWAPWindow = 10 * time.Minute
+)
+
+var (
+	// Mainnet addresses of the quote tokens.
+	defaultWETHAddress = ethcmn.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+	defaultUSDCAddress = ethcmn.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+
+	erc20ABI = mustParseABI(`[
+		{"name":"decimals","type":"function","stateMutability":"view","inputs":[],"outputs":[{"type":"uint8"}]},
+		{"name":"balanceOf","type":"function","stateMutability":"view","inputs":[{"name":"account","type":"address"}],
+			"outputs":[{"type":"uint256"}]}
+	]`)
+	uniV2ABI = mustParseABI(`[
+		{"name":"token0","type":"function","stateMutability":"view","inputs":[],"outputs":[{"type":"address"}]},
+		{"name":"token1","type":"function","stateMutability":"view","inputs":[],"outputs":[{"type":"address"}]},
+		{"name":"getReserves","type":"function","stateMutability":"view","inputs":[],
+			"outputs":[{"name":"reserve0","type":"uint112"},{"name":"reserve1","type":"uint112"},
+				{"name":"blockTimestampLast","type":"uint32"}]}
+	]`)
+	uniV3ABI = mustParseABI(`[
+		{"name":"token0","type":"function","stateMutability":"view","inputs":[],"outputs":[{"type":"address"}]},
+		{"name":"token1","type":"function","stateMutability":"view","inputs":[],"outputs":[{"type":"address"}]},
+		{"name":"observe","type":"function","stateMutability":"view","inputs":[{"name":"secondsAgos","type":"uint32[]"}],
+			"outputs":[{"name":"tickCumulatives","type":"int56[]"},
+				{"name":"secondsPerLiquidityCumulativeX128s","type":"uint160[]"}]}
+	]`)
+
+	// tickBase is the price ratio between two consecutive ticks of a V3 pool.
+	tickBase = 1.0001
+	// usdcUSDRate is the assumed USD price of USDC.
+	usdcUSDRate = sdk.OneDec()
+)
+
+func mustParseABI(def string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(def))
+	if err != nil {
+		panic(err)
+	}
+
+	return parsed
+}
+
+// DexPool defines a Uniswap pool used to price a symbol. The pool must pair the token of the symbol with the quote
+// token (WETH or USDC).
+type DexPool struct {
+	Symbol  string         `json:"symbol"`
+	Address ethcmn.Address `json:"address"`
+	Version string         `json:"version"`
+	Quote   string         `json:"quote"`
+	// TWAPWindow is the period of the time-weighted average price of V3 pools, e.g. "30m".
+	TWAPWindow string `json:"twap_window,omitempty"`
+}
+
+// DexConfig defines the pools of the DEX oracle. The quote token addresses default to the mainnet ones.
+type DexConfig struct {
+	WETHAddress *ethcmn.Address `json:"weth_address,omitempty"`
+	USDCAddress *ethcmn.Address `json:"usdc_address,omitempty"`
+	Pools       []DexPool       `json:"pools"`
+}
+
+// LoadDexConfig reads a DEX oracle config from a JSON file.
+func LoadDexConfig(path string) (DexConfig, error) {
+	var cfg DexConfig
+
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, errors.Wrap(err, "failed to read DEX pools file")
+	}
+
+	if err := json.Unmarshal(bz, &cfg); err != nil {
+		return cfg, errors.Wrap(err, "failed to decode DEX pools file")
+	}
+
+	return cfg, nil
+}
+
+// dexPool is a configured pool, along with its tokens once they're resolved.
+type dexPool struct {
+	DexPool
+	quoteToken   ethcmn.Address
+	quotedInWETH bool
+	twapWindow   time.Duration
+
+	resolved      bool
+	baseIsToken0  bool
+	baseDecimals  uint8
+	quoteDecimals uint8
+}
+
+// DexOracle prices symbols with the on-chain state of Uniswap pools, read with eth_call. V2 pools are priced with
+// their reserves and V3 pools with the time-weighted average tick of their observations. Prices quoted in WETH are
+// converted to USD with the ETH price, and USDC is assumed to be worth one dollar. Pools whose quote token balance
+// is worth less than the minimum liquidity are not used, since their price is easy to manipulate.
+type DexOracle struct {
+	logger         zerolog.Logger
+	caller         bind.ContractCaller
+	minLiquidity   sdk.Dec
+	ethPriceSource ethPriceSource
+
+	mtx   sync.Mutex
+	pools map[string]*dexPool // symbol => pool
+}
+
+// ethPriceSource returns the USD price of ETH, used to convert prices quoted in WETH.
+type ethPriceSource interface {
+	GetPrice(baseSymbol string) (sdk.Dec, error)
+}
+
+// NewDexOracle returns a DexOracle of the configured pools. The ETH price is taken from the pool of the ETH symbol
+// if there's one, or from ethPrices otherwise, which may be nil if no pool is quoted in WETH. minLiquidityUSD is the
+// minimum value in USD of the quote token balance of a pool.
+func NewDexOracle(
+	logger zerolog.Logger,
+	caller bind.ContractCaller,
+	cfg DexConfig,
+	minLiquidityUSD sdk.Dec,
+	ethPrices ethPriceSource,
+) (*DexOracle, error) {
+	wethAddress, usdcAddress := defaultWETHAddress, defaultUSDCAddress
+	if cfg.WETHAddress != nil {
+		wethAddress = *cfg.WETHAddress
+	}
+	if cfg.USDCAddress != nil {
+		usdcAddress = *cfg.USDCAddress
+	}
+
+	pools := make(map[string]*dexPool, len(cfg.Pools))
+	for _, pool := range cfg.Pools {
+		symbol := strings.ToUpper(pool.Symbol)
+		if symbol == "" {
+			return nil, errors.Errorf("missing symbol of DEX pool %s", pool.Address.Hex())
+		}
+
+		if _, ok := pools[symbol]; ok {
+			return nil, errors.Errorf("duplicated DEX pool for %s", symbol)
+		}
+
+		if pool.Version != DexPoolV2 && pool.Version != DexPoolV3 {
+			return nil, errors.Errorf("invalid version of the %s DEX pool: %s", symbol, pool.Version)
+		}
+
+		p := &dexPool{DexPool: pool, twapWindow: defaultTWAPWindow}
+
+		switch strings.ToUpper(pool.Quote) {
+		case SymbolWETH:
+			if symbol == SymbolETH {
+				return nil, errors.New("the ETH DEX pool must be quoted in USDC")
+			}
+			p.quoteToken = wethAddress
+			p.quotedInWETH = true
+		case SymbolUSDC:
+			p.quoteToken = usdcAddress
+		default:
+			return nil, errors.Errorf("invalid quote of the %s DEX pool: %s", symbol, pool.Quote)
+		}
+
+		if pool.TWAPWindow != "" {
+			window, err := time.ParseDuration(pool.TWAPWindow)
+			if err != nil || window < time.Second {
+				return nil, errors.Errorf("invalid TWAP window of the %s DEX pool: %s", symbol, pool.TWAPWindow)
+			}
+			p.twapWindow = window
+		}
+
+		pools[symbol] = p
+	}
+
+	return &DexOracle{
+		logger:         logger.With().Str("module", "dex_oracle").Logger(),
+		caller:         caller,
+		minLiquidity:   minLiquidityUSD,
+		ethPriceSource: ethPrices,
+		pools:          pools,
+	}, nil
+}
+
+// GetPrices returns the price for the provided base symbols.
+func (o *DexOracle) GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error) {
+	prices := make(map[string]sdk.Dec, len(baseSymbols))
+
+	for _, baseSymbol := range baseSymbols {
+		price, err := o.GetPrice(baseSymbol)
+		if err != nil {
+			return nil, err
+		}
+		prices[baseSymbol] = price
+	}
+
+	return prices, nil
+}
+
+// GetPrice returns the USD price of a symbol from its pool.
+func (o *DexOracle) GetPrice(baseSymbol string) (sdk.Dec, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dexCallTimeout)
+	defer cancel()
+
+	price, err := o.getPrice(ctx, strings.ToUpper(baseSymbol))
+	if err != nil {
+		return sdk.Dec{}, errors.Wrapf(err, "error getting DEX price for %s", baseSymbol)
+	}
+
+	return price, nil
+}
+
+// GetPriceInfo returns the USD price of a symbol from its pool, which is read when it's called.
+func (o *DexOracle) GetPriceInfo(baseSymbol string) (PriceInfo, error) {
+	price, err := o.GetPrice(baseSymbol)
+	if err != nil {
+		return PriceInfo{}, err
+	}
+
+	return PriceInfo{
+		Price:      price,
+		ComputedAt: time.Now(),
+		Providers:  []string{"uniswap-" + o.pools[strings.ToUpper(baseSymbol)].Version},
+		Deviation:  sdk.ZeroDec(),
+	}, nil
+}
+
+// SubscribeSymbols checks that all the symbols have a pool, since pools can't be discovered.
+func (o *DexOracle) SubscribeSymbols(baseSymbols ...string) error {
+	for _, baseSymbol := range baseSymbols {
+		if _, ok := o.pools[strings.ToUpper(baseSymbol)]; !ok {
+			return fmt.Errorf("no DEX pool configured for %s", baseSymbol)
+		}
+	}
+
+	return nil
+}
+
+func (o *DexOracle) getPrice(ctx context.Context, symbol string) (sdk.Dec, error) {
+	pool, ok := o.pools[symbol]
+	if !ok {
+		return sdk.Dec{}, fmt.Errorf("no DEX pool configured for %s", symbol)
+	}
+
+	if err := o.resolvePool(ctx, pool); err != nil {
+		return sdk.Dec{}, err
+	}
+
+	quoteUSDPrice := usdcUSDRate
+	if pool.quotedInWETH {
+		var err error
+		quoteUSDPrice, err = o.ethPrice(ctx)
+		if err != nil {
+			return sdk.Dec{}, err
+		}
+	}
+
+	if err := o.checkLiquidity(ctx, pool, quoteUSDPrice); err != nil {
+		return sdk.Dec{}, err
+	}
+
+	var (
+		price sdk.Dec
+		err   error
+	)
+
+	switch pool.Version {
+	case DexPoolV2:
+		price, err = o.v2Price(ctx, pool)
+	default:
+		price, err = o.v3Price(ctx, pool)
+	}
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	o.logger.Debug().
+		Str("symbol", symbol).
+		Str("pool", pool.Address.Hex()).
+		Str("price_in_quote", price.String()).
+		Str("quote_usd_price", quoteUSDPrice.String()).
+		Msg("got DEX price")
+
+	return price.Mul(quoteUSDPrice), nil
+}
+
+// ethPrice returns the USD price of ETH from the ETH pool or, if there isn't one, from the ETH price source.
+func (o *DexOracle) ethPrice(ctx context.Context) (sdk.Dec, error) {
+	if _, ok := o.pools[SymbolETH]; ok {
+		return o.getPrice(ctx, SymbolETH)
+	}
+
+	if o.ethPriceSource == nil {
+		return sdk.Dec{}, errors.New("no ETH price source to convert WETH prices")
+	}
+
+	price, err := o.ethPriceSource.GetPrice(SymbolETH)
+	if err != nil {
+		return sdk.Dec{}, errors.Wrap(err, "failed to get ETH price")
+	}
+
+	return price, nil
+}
+
+// resolvePool reads the tokens of a pool and their decimals, once.
+func (o *DexOracle) resolvePool(ctx context.Context, pool *dexPool) error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	if pool.resolved {
+		return nil
+	}
+
+	poolABI := uniV2ABI
+	if pool.Version == DexPoolV3 {
+		poolABI = uniV3ABI
+	}
+
+	var token0, token1 ethcmn.Address
+	if err := o.call(ctx, pool.Address, poolABI, &token0, "token0"); err != nil {
+		return err
+	}
+	if err := o.call(ctx, pool.Address, poolABI, &token1, "token1"); err != nil {
+		return err
+	}
+
+	var baseToken ethcmn.Address
+	switch pool.quoteToken {
+	case token0:
+		baseToken = token1
+	case token1:
+		baseToken = token0
+		pool.baseIsToken0 = true
+	default:
+		return errors.Errorf("pool %s doesn't pair the %s token", pool.Address.Hex(), pool.Quote)
+	}
+
+	if err := o.call(ctx, baseToken, erc20ABI, &pool.baseDecimals, "decimals"); err != nil {
+		return err
+	}
+	if err := o.call(ctx, pool.quoteToken, erc20ABI, &pool.quoteDecimals, "decimals"); err != nil {
+		return err
+	}
+
+	pool.resolved = true
+	return nil
+}
+
+// checkLiquidity returns an error if the quote token balance of the pool is worth less than the minimum liquidity.
+func (o *DexOracle) checkLiquidity(ctx context.Context, pool *dexPool, quoteUSDPrice sdk.Dec) error {
+	var balance *big.Int
+	if err := o.call(ctx, pool.quoteToken, erc20ABI, &balance, "balanceOf", pool.Address); err != nil {
+		return err
+	}
+
+	liquidity := sdk.NewDecFromBigIntWithPrec(balance, int64(pool.quoteDecimals)).Mul(quoteUSDPrice)
+	if liquidity.LT(o.minLiquidity) {
+		return errors.Errorf(
+			"liquidity of pool %s is too low: %s USD < %s USD",
+			pool.Address.Hex(),
+			liquidity.String(),
+			o.minLiquidity.String(),
+		)
+	}
+
+	return nil
+}
+
+// v2Price returns the price of the base token in quote tokens from the reserves of a V2 pool.
+func (o *DexOracle) v2Price(ctx context.Context, pool *dexPool) (sdk.Dec, error) {
+	var out []interface{}
+	if err := bind.NewBoundContract(pool.Address, uniV2ABI, o.caller, nil, nil).Call(
+		&bind.CallOpts{Context: ctx},
+		&out,
+		"getReserves",
+	); err != nil {
+		return sdk.Dec{}, errors.Wrapf(err, "failed to get reserves of pool %s", pool.Address.Hex())
+	}
+
+	reserve0, reserve1 := out[0].(*big.Int), out[1].(*big.Int)
+	baseReserve, quoteReserve := reserve1, reserve0
+	if pool.baseIsToken0 {
+		baseReserve, quoteReserve = reserve0, reserve1
+	}
+
+	if baseReserve.Sign() == 0 {
+		return sdk.Dec{}, errors.Errorf("pool %s has no reserves", pool.Address.Hex())
+	}
+
+	return sdk.NewDecFromBigIntWithPrec(quoteReserve, int64(pool.quoteDecimals)).
+		Quo(sdk.NewDecFromBigIntWithPrec(baseReserve, int64(pool.baseDecimals))), nil
+}
+
+// v3Price returns the price of the base token in quote tokens from the average tick of a V3 pool over its TWAP
+// window. The price of token0 in token1 units is 1.0001^tick.
+func (o *DexOracle) v3Price(ctx context.Context, pool *dexPool) (sdk.Dec, error) {
+	window := uint32(pool.twapWindow.Seconds())
+
+	var out []interface{}
+	if err := bind.NewBoundContract(pool.Address, uniV3ABI, o.caller, nil, nil).Call(
+		&bind.CallOpts{Context: ctx},
+		&out,
+		"observe",
+		[]uint32{window, 0},
+	); err != nil {
+		return sdk.Dec{}, errors.Wrapf(err, "failed to observe pool %s", pool.Address.Hex())
+	}
+
+	tickCumulatives := out[0].([]*big.Int)
+	if len(tickCumulatives) != 2 {
+		return sdk.Dec{}, errors.Errorf("unexpected observations of pool %s", pool.Address.Hex())
+	}
+
+	// The average tick is rounded towards negative infinity, as the Uniswap oracle library does.
+	delta := new(big.Int).Sub(tickCumulatives[1], tickCumulatives[0])
+	avgTick, rem := new(big.Int).QuoRem(delta, big.NewInt(int64(window)), new(big.Int))
+	if rem.Sign() < 0 {
+		avgTick.Sub(avgTick, big.NewInt(1))
+	}
+
+	decimals0, decimals1 := pool.quoteDecimals, pool.baseDecimals
+	if pool.baseIsToken0 {
+		decimals0, decimals1 = pool.baseDecimals, pool.quoteDecimals
+	}
+
+	price0In1 := math.Pow(tickBase, float64(avgTick.Int64())) * math.Pow10(int(decimals0)-int(decimals1))
+	if !pool.baseIsToken0 {
+		price0In1 = 1 / price0In1
+	}
+
+	price, err := sdk.NewDecFromStr(strconv.FormatFloat(price0In1, 'f', sdk.Precision, 64))
+	if err != nil {
+		return sdk.Dec{}, errors.Wrapf(err, "failed to convert the price of pool %s", pool.Address.Hex())
+	}
+
+	return price, nil
+}
+
+// call makes a contract call with a single output.
+func (o *DexOracle) call(
+	ctx context.Context,
+	address ethcmn.Address,
+	contractABI abi.ABI,
+	result interface{},
+	method string,
+	params ...interface{},
+) error {
+	var out []interface{}
+	if err := bind.NewBoundContract(address, contractABI, o.caller, nil, nil).Call(
+		&bind.CallOpts{Context: ctx},
+		&out,
+		method,
+		params...,
+	); err != nil {
+		return errors.Wrapf(err, "'%s' call to %s failed", method, address.Hex())
+	}
+
+	if err := contractABI.Methods[method].Outputs.Copy(result, out); err != nil {
+		return errors.Wrapf(err, "failed to decode '%s' output of %s", method, address.Hex())
+	}
+
+	return nil
+}
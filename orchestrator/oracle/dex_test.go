@@ -0,0 +1,217 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	tokenA      = ethcmn.HexToAddress("0x00000000000000000000000000000000000000a1")
+	tokenB      = ethcmn.HexToAddress("0x00000000000000000000000000000000000000b1")
+	tokenC      = ethcmn.HexToAddress("0x00000000000000000000000000000000000000c1")
+	poolA       = ethcmn.HexToAddress("0x00000000000000000000000000000000000000a2")
+	poolB       = ethcmn.HexToAddress("0x00000000000000000000000000000000000000b2")
+	poolC       = ethcmn.HexToAddress("0x00000000000000000000000000000000000000c2")
+	poolETH     = ethcmn.HexToAddress("0x00000000000000000000000000000000000000e2")
+	e18         = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	e6          = big.NewInt(1_000_000)
+	twapSeconds = int64(600)
+)
+
+// fakeCaller answers contract calls with the outputs set for each contract and method.
+type fakeCaller struct {
+	contracts map[ethcmn.Address]abi.ABI
+	outputs   map[ethcmn.Address]map[string][]interface{}
+}
+
+func newFakeCaller() *fakeCaller {
+	return &fakeCaller{
+		contracts: map[ethcmn.Address]abi.ABI{},
+		outputs:   map[ethcmn.Address]map[string][]interface{}{},
+	}
+}
+
+func (c *fakeCaller) set(address ethcmn.Address, contractABI abi.ABI, method string, values ...interface{}) {
+	c.contracts[address] = contractABI
+	if c.outputs[address] == nil {
+		c.outputs[address] = map[string][]interface{}{}
+	}
+	c.outputs[address][method] = values
+}
+
+// setToken sets the decimals and the balances of the pools of an ERC20 token. All pools hold the same balance.
+func (c *fakeCaller) setToken(address ethcmn.Address, decimals uint8, balance *big.Int) {
+	c.set(address, erc20ABI, "decimals", decimals)
+	c.set(address, erc20ABI, "balanceOf", balance)
+}
+
+func (c *fakeCaller) CodeAt(context.Context, ethcmn.Address, *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (c *fakeCaller) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	contractABI, ok := c.contracts[*call.To]
+	if !ok {
+		return nil, fmt.Errorf("unknown contract %s", call.To.Hex())
+	}
+
+	method, err := contractABI.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := c.outputs[*call.To][method.Name]
+	if !ok {
+		return nil, fmt.Errorf("unexpected call to %s", method.Name)
+	}
+
+	return method.Outputs.Pack(values...)
+}
+
+// staticETHPrice is an ETH price source with a fixed price.
+type staticETHPrice sdk.Dec
+
+func (p staticETHPrice) GetPrice(string) (sdk.Dec, error) {
+	return sdk.Dec(p), nil
+}
+
+func TestDexOracle(t *testing.T) {
+	usdc := defaultUSDCAddress
+	weth := defaultWETHAddress
+
+	caller := newFakeCaller()
+	caller.setToken(tokenA, 18, nil)
+	caller.setToken(tokenB, 6, nil)
+	caller.setToken(tokenC, 18, nil)
+	caller.setToken(usdc, 6, new(big.Int).Mul(big.NewInt(2500), e6))
+	caller.setToken(weth, 18, new(big.Int).Mul(big.NewInt(10), e18))
+
+	// A/USDC V2 pool: 1000 A for 2500 USDC.
+	caller.set(poolA, uniV2ABI, "token0", tokenA)
+	caller.set(poolA, uniV2ABI, "token1", usdc)
+	caller.set(poolA, uniV2ABI, "getReserves",
+		new(big.Int).Mul(big.NewInt(1000), e18), new(big.Int).Mul(big.NewInt(2500), e6), uint32(0))
+
+	// WETH/B V2 pool: 10 WETH for 20000 B, so B is worth 0.0005 ETH.
+	caller.set(poolB, uniV2ABI, "token0", weth)
+	caller.set(poolB, uniV2ABI, "token1", tokenB)
+	caller.set(poolB, uniV2ABI, "getReserves",
+		new(big.Int).Mul(big.NewInt(10), e18), new(big.Int).Mul(big.NewInt(20000), e6), uint32(0))
+
+	// C/USDC V3 pool: C is worth about 2 USDC. The price of C in USDC units is 1.0001^tick = 2 * 10^(6-18).
+	tickC := int64(math.Floor(math.Log(2e-12) / math.Log(tickBase)))
+	caller.set(poolC, uniV3ABI, "token0", tokenC)
+	caller.set(poolC, uniV3ABI, "token1", usdc)
+	caller.set(poolC, uniV3ABI, "observe",
+		[]*big.Int{big.NewInt(0), big.NewInt(tickC*twapSeconds + 1)}, []*big.Int{big.NewInt(0), big.NewInt(0)})
+
+	cfg := DexConfig{Pools: []DexPool{
+		{Symbol: "tokA", Address: poolA, Version: DexPoolV2, Quote: SymbolUSDC},
+		{Symbol: "TOKB", Address: poolB, Version: DexPoolV2, Quote: SymbolWETH},
+		{Symbol: "TOKC", Address: poolC, Version: DexPoolV3, Quote: SymbolUSDC, TWAPWindow: "10m"},
+	}}
+
+	o, err := NewDexOracle(zerolog.Nop(), caller, cfg, sdk.NewDec(1000), staticETHPrice(sdk.NewDec(2000)))
+	assert.Nil(t, err)
+
+	t.Run("v2 quoted in USDC", func(t *testing.T) {
+		price, err := o.GetPrice("TOKA")
+		assert.Nil(t, err)
+		assert.Equal(t, sdk.MustNewDecFromStr("2.5"), price)
+	})
+
+	t.Run("v2 quoted in WETH", func(t *testing.T) {
+		price, err := o.GetPrice("TOKB")
+		assert.Nil(t, err)
+		assert.Equal(t, sdk.OneDec(), price)
+	})
+
+	t.Run("v3 TWAP", func(t *testing.T) {
+		price, err := o.GetPrice("TOKC")
+		assert.Nil(t, err)
+		assert.InDelta(t, 2, price.MustFloat64(), 0.001)
+	})
+
+	t.Run("unknown symbol", func(t *testing.T) {
+		_, err := o.GetPrice("UMEE")
+		assert.Error(t, err)
+		assert.Error(t, o.SubscribeSymbols("TOKA", "UMEE"))
+		assert.Nil(t, o.SubscribeSymbols("TOKA", "tokb"))
+	})
+
+	t.Run("prices", func(t *testing.T) {
+		prices, err := o.GetPrices("TOKA", "TOKB")
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]sdk.Dec{"TOKA": sdk.MustNewDecFromStr("2.5"), "TOKB": sdk.OneDec()}, prices)
+	})
+
+	t.Run("min liquidity", func(t *testing.T) {
+		// The A pool holds 2500 USDC.
+		o, err := NewDexOracle(zerolog.Nop(), caller, cfg, sdk.NewDec(3000), nil)
+		assert.Nil(t, err)
+
+		_, err = o.GetPrice("TOKA")
+		assert.ErrorContains(t, err, "liquidity")
+
+		// The B pool holds 10 WETH, but there's no ETH price to value them.
+		_, err = o.GetPrice("TOKB")
+		assert.ErrorContains(t, err, "ETH price")
+	})
+
+	t.Run("ETH pool", func(t *testing.T) {
+		// ETH/USDC V2 pool: 1 WETH for 2500 USDC.
+		caller.set(poolETH, uniV2ABI, "token0", usdc)
+		caller.set(poolETH, uniV2ABI, "token1", weth)
+		caller.set(poolETH, uniV2ABI, "getReserves",
+			new(big.Int).Mul(big.NewInt(2500), e6), e18, uint32(0))
+
+		cfg := DexConfig{Pools: []DexPool{
+			{Symbol: "ETH", Address: poolETH, Version: DexPoolV2, Quote: SymbolUSDC},
+			{Symbol: "TOKB", Address: poolB, Version: DexPoolV2, Quote: SymbolWETH},
+		}}
+
+		// The ETH price of the pool is used instead of the price source.
+		o, err := NewDexOracle(zerolog.Nop(), caller, cfg, sdk.NewDec(1000), staticETHPrice(sdk.NewDec(2000)))
+		assert.Nil(t, err)
+
+		price, err := o.GetPrice("TOKB")
+		assert.Nil(t, err)
+		assert.Equal(t, sdk.MustNewDecFromStr("1.25"), price)
+	})
+}
+
+func TestNewDexOracle(t *testing.T) {
+	testCases := []struct {
+		name string
+		pool DexPool
+	}{
+		{"missing symbol", DexPool{Address: poolA, Version: DexPoolV2, Quote: SymbolUSDC}},
+		{"invalid version", DexPool{Symbol: "A", Address: poolA, Version: "v4", Quote: SymbolUSDC}},
+		{"invalid quote", DexPool{Symbol: "A", Address: poolA, Version: DexPoolV2, Quote: "DAI"}},
+		{"ETH quoted in WETH", DexPool{Symbol: "ETH", Address: poolA, Version: DexPoolV2, Quote: SymbolWETH}},
+		{"invalid TWAP window", DexPool{Symbol: "A", Address: poolA, Version: DexPoolV3, Quote: SymbolUSDC, TWAPWindow: "1ms"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewDexOracle(zerolog.Nop(), newFakeCaller(), DexConfig{Pools: []DexPool{tc.pool}}, sdk.ZeroDec(), nil)
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("duplicated symbol", func(t *testing.T) {
+		pool := DexPool{Symbol: "A", Address: poolA, Version: DexPoolV2, Quote: SymbolUSDC}
+		_, err := NewDexOracle(zerolog.Nop(), newFakeCaller(), DexConfig{Pools: []DexPool{pool, pool}}, sdk.ZeroDec(), nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,310 @@
+package oracle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// httpSourceTimeout is the timeout of the requests of the HTTP source.
+	httpSourceTimeout = 10 * time.Second
+	// symbolPlaceholder is replaced by the symbol in the URL and the selector of the HTTP source.
+	symbolPlaceholder = "{symbol}"
+)
+
+// HTTPSource prices symbols with a generic HTTP endpoint returning JSON. The price is picked from the response with a
+// JSONPath-like selector, supporting the root ($), keys (.key or ['key']) and array indexes ([0]), e.g.
+// "$.data[0].price". The price may be a JSON number or a numeric string.
+type HTTPSource struct {
+	client   *http.Client
+	url      string
+	host     string
+	selector string
+	symbols  map[string]string // symbol => ID used in the URL and selector
+	cacheTTL time.Duration
+
+	mtx   sync.Mutex
+	cache map[string]cachedPrice // symbol => price
+}
+
+type cachedPrice struct {
+	price     sdk.Dec
+	fetchedAt time.Time
+}
+
+// NewHTTPSource returns an HTTPSource of the URL and selector. symbols maps symbols to the IDs replacing the {symbol}
+// placeholder (e.g. CoinGecko IDs); a symbol without an ID replaces it as is.
+func NewHTTPSource(rawURL, selector string, symbols map[string]string, cacheTTL time.Duration) (*HTTPSource, error) {
+	parsedURL, err := url.ParseRequestURI(strings.ReplaceAll(rawURL, symbolPlaceholder, "symbol"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid URL")
+	}
+
+	if _, err := parseSelector(strings.ReplaceAll(selector, symbolPlaceholder, "symbol")); err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]string, len(symbols))
+	for symbol, id := range symbols {
+		ids[strings.ToUpper(symbol)] = id
+	}
+
+	return &HTTPSource{
+		client:   &http.Client{Timeout: httpSourceTimeout},
+		url:      rawURL,
+		host:     parsedURL.Host,
+		selector: selector,
+		symbols:  ids,
+		cacheTTL: cacheTTL,
+		cache:    map[string]cachedPrice{},
+	}, nil
+}
+
+func newHTTPSourceFromConfig(_ context.Context, _ SourceDeps, cfg SourceConfig) (Source, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("missing URL")
+	}
+
+	if cfg.Selector == "" {
+		return nil, errors.New("missing selector")
+	}
+
+	cacheTTL, err := parseCacheTTL(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHTTPSource(cfg.URL, cfg.Selector, cfg.Symbols, cacheTTL)
+}
+
+// GetPrices returns the price for the provided base symbols.
+func (s *HTTPSource) GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error) {
+	prices := make(map[string]sdk.Dec, len(baseSymbols))
+
+	for _, baseSymbol := range baseSymbols {
+		price, err := s.GetPrice(baseSymbol)
+		if err != nil {
+			return nil, err
+		}
+		prices[baseSymbol] = price
+	}
+
+	return prices, nil
+}
+
+// GetPrice returns the price of the symbol, fetching it if the cached one expired.
+func (s *HTTPSource) GetPrice(baseSymbol string) (sdk.Dec, error) {
+	info, err := s.GetPriceInfo(baseSymbol)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	return info.Price, nil
+}
+
+// GetPriceInfo returns the price of the symbol, fetching it if the cached one expired. The price is computed when
+// it's fetched.
+func (s *HTTPSource) GetPriceInfo(baseSymbol string) (PriceInfo, error) {
+	symbol := strings.ToUpper(baseSymbol)
+
+	s.mtx.Lock()
+	cached, ok := s.cache[symbol]
+	s.mtx.Unlock()
+
+	if !ok || time.Since(cached.fetchedAt) >= s.cacheTTL {
+		ctx, cancel := context.WithTimeout(context.Background(), httpSourceTimeout)
+		defer cancel()
+
+		price, err := s.fetchPrice(ctx, symbol)
+		if err != nil {
+			return PriceInfo{}, errors.Wrapf(err, "error getting HTTP price for %s", baseSymbol)
+		}
+
+		cached = cachedPrice{price: price, fetchedAt: time.Now()}
+
+		s.mtx.Lock()
+		s.cache[symbol] = cached
+		s.mtx.Unlock()
+	}
+
+	return PriceInfo{
+		Price:      cached.price,
+		ComputedAt: cached.fetchedAt,
+		Providers:  []string{s.host},
+		Deviation:  sdk.ZeroDec(),
+	}, nil
+}
+
+// SubscribeSymbols checks that the endpoint can price all the symbols.
+func (s *HTTPSource) SubscribeSymbols(baseSymbols ...string) error {
+	for _, baseSymbol := range baseSymbols {
+		if _, err := s.GetPrice(baseSymbol); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *HTTPSource) fetchPrice(ctx context.Context, symbol string) (sdk.Dec, error) {
+	id, ok := s.symbols[symbol]
+	if !ok {
+		id = symbol
+	}
+
+	reqURL := strings.ReplaceAll(s.url, symbolPlaceholder, url.PathEscape(id))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return sdk.Dec{}, errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return sdk.Dec{}, errors.Wrap(err, "request failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return sdk.Dec{}, errors.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+
+	var body interface{}
+	if err := decoder.Decode(&body); err != nil {
+		return sdk.Dec{}, errors.Wrap(err, "failed to decode response")
+	}
+
+	value, err := selectJSON(body, strings.ReplaceAll(s.selector, symbolPlaceholder, id))
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	var price sdk.Dec
+	switch v := value.(type) {
+	case json.Number:
+		price, err = parseDecimal(v.String())
+	case string:
+		price, err = parseDecimal(v)
+	default:
+		return sdk.Dec{}, errors.Errorf("selected value %v is not a number", value)
+	}
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	if !price.IsPositive() {
+		return sdk.Dec{}, errors.Errorf("invalid price %s", price)
+	}
+
+	return price, nil
+}
+
+// selectorStep is a key or an array index of a selector.
+type selectorStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseSelector parses a JSONPath-like selector into its steps.
+func parseSelector(selector string) ([]selectorStep, error) {
+	path := strings.TrimPrefix(strings.TrimSpace(selector), "$")
+	steps := []selectorStep{}
+
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			if end == 0 {
+				return nil, errors.Errorf("invalid selector %q: empty key", selector)
+			}
+			steps = append(steps, selectorStep{key: path[:end]})
+			path = path[end:]
+
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, errors.Errorf("invalid selector %q: unclosed bracket", selector)
+			}
+			inner := path[1:end]
+
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, selectorStep{key: inner[1 : len(inner)-1]})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, errors.Errorf("invalid selector %q: invalid index %s", selector, inner)
+				}
+				steps = append(steps, selectorStep{index: index, isIndex: true})
+			}
+			path = path[end+1:]
+
+		default:
+			return nil, errors.Errorf("invalid selector %q", selector)
+		}
+	}
+
+	return steps, nil
+}
+
+// selectJSON returns the value of the decoded JSON picked by the selector.
+func selectJSON(value interface{}, selector string) (interface{}, error) {
+	steps, err := parseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, step := range steps {
+		if step.isIndex {
+			array, ok := value.([]interface{})
+			if !ok || step.index >= len(array) {
+				return nil, fmt.Errorf("index %d not found in response", step.index)
+			}
+			value = array[step.index]
+			continue
+		}
+
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("key %s not found in response", step.key)
+		}
+
+		value, ok = object[step.key]
+		if !ok {
+			return nil, fmt.Errorf("key %s not found in response", step.key)
+		}
+	}
+
+	return value, nil
+}
+
+// parseDecimal parses a decimal number, which may be in scientific notation or have more decimals than sdk.Dec.
+func parseDecimal(s string) (sdk.Dec, error) {
+	if dec, err := sdk.NewDecFromStr(s); err == nil {
+		return dec, nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return sdk.Dec{}, errors.Errorf("invalid price %q", s)
+	}
+
+	return sdk.NewDecFromStr(strconv.FormatFloat(f, 'f', sdk.Precision, 64))
+}
@@ -0,0 +1,87 @@
+package oracle
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPSource(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		switch r.URL.Path {
+		case "/price/umee":
+			fmt.Fprint(w, `{"data": [{"price": 0.0123}]}`)
+		case "/price/atom":
+			fmt.Fprint(w, `{"data": [{"price": "9.87"}]}`)
+		case "/price/tiny":
+			fmt.Fprint(w, `{"data": [{"price": 1.5e-7}]}`)
+		case "/price/bad":
+			fmt.Fprint(w, `{"data": [{"price": true}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s, err := NewHTTPSource(
+		server.URL+"/price/{symbol}",
+		"$.data[0]['price']",
+		map[string]string{"UMEE": "umee", "ATOM": "atom", "TINY": "tiny", "BAD": "bad"},
+		time.Minute,
+	)
+	assert.Nil(t, err)
+
+	prices, err := s.GetPrices("UMEE", "atom", "TINY")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]sdk.Dec{
+		"UMEE": sdk.MustNewDecFromStr("0.0123"),
+		"atom": sdk.MustNewDecFromStr("9.87"),
+		"TINY": sdk.MustNewDecFromStr("0.00000015"),
+	}, prices)
+	assert.Equal(t, 3, requests)
+
+	// The prices are cached.
+	assert.Nil(t, s.SubscribeSymbols("UMEE", "ATOM"))
+	assert.Equal(t, 3, requests)
+
+	_, err = s.GetPrice("BAD")
+	assert.Error(t, err)
+
+	_, err = s.GetPrice("ETH")
+	assert.Error(t, err)
+
+	t.Run("symbol in selector", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "umee,cosmos", r.URL.Query().Get("ids"))
+			fmt.Fprint(w, `{"umee": {"usd": 0.01}, "cosmos": {"usd": 10}}`)
+		}))
+		defer server.Close()
+
+		s, err := NewHTTPSource(
+			server.URL+"/simple/price?ids=umee,cosmos",
+			`$["{symbol}"].usd`,
+			map[string]string{"ATOM": "cosmos", "UMEE": "umee"},
+			0,
+		)
+		assert.Nil(t, err)
+
+		price, err := s.GetPrice("ATOM")
+		assert.Nil(t, err)
+		assert.Equal(t, sdk.NewDec(10), price)
+	})
+
+	t.Run("invalid selector", func(t *testing.T) {
+		for _, selector := range []string{"$.data[", "$.data[-1]", "$..price", "price"} {
+			_, err := NewHTTPSource(server.URL, selector, nil, time.Minute)
+			assert.Error(t, err, selector)
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package oracle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+)
+
+// WeightedSource is a price source along with the weight of its prices in the median.
+type WeightedSource struct {
+	Name   string
+	Source Source
+	Weight float64
+}
+
+// MedianOracle aggregates price sources, pricing each symbol with the weighted median of the prices of the sources
+// that can price it. A source failing to price a symbol doesn't fail the oracle, as long as another one can.
+type MedianOracle struct {
+	logger  zerolog.Logger
+	sources []WeightedSource
+	// maxPriceAge is the max age of the prices of the sources used in the median. Zero means there's no limit.
+	maxPriceAge time.Duration
+}
+
+// NewMedianOracle returns a MedianOracle of the given sources. Prices older than maxPriceAge are left out of the
+// median while fresher ones are available; zero means there's no limit.
+func NewMedianOracle(logger zerolog.Logger, maxPriceAge time.Duration, sources ...WeightedSource) *MedianOracle {
+	return &MedianOracle{
+		logger:      logger.With().Str("module", "median_oracle").Logger(),
+		sources:     sources,
+		maxPriceAge: maxPriceAge,
+	}
+}
+
+// GetPrices returns the price for the provided base symbols.
+func (o *MedianOracle) GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error) {
+	prices := make(map[string]sdk.Dec, len(baseSymbols))
+
+	for _, baseSymbol := range baseSymbols {
+		price, err := o.GetPrice(baseSymbol)
+		if err != nil {
+			return nil, err
+		}
+		prices[baseSymbol] = price
+	}
+
+	return prices, nil
+}
+
+// GetPrice returns the weighted median of the prices of the symbol.
+func (o *MedianOracle) GetPrice(baseSymbol string) (sdk.Dec, error) {
+	info, err := o.GetPriceInfo(baseSymbol)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	return info.Price, nil
+}
+
+// GetPriceInfo returns the weighted median of the prices of the symbol. Prices older than the max price age are left
+// out, unless all of them are, in which case the stale median is returned for the caller to reject. It's as old as
+// the oldest price it's computed from, and its providers are the ones of the sources whose prices were used,
+// prefixed by the source name.
+func (o *MedianOracle) GetPriceInfo(baseSymbol string) (PriceInfo, error) {
+	fresh := make([]sourcePriceInfo, 0, len(o.sources))
+	stale := make([]sourcePriceInfo, 0, len(o.sources))
+	errs := make([]string, 0, len(o.sources))
+
+	for _, source := range o.sources {
+		info, err := source.Source.GetPriceInfo(baseSymbol)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", source.Name, err))
+			continue
+		}
+
+		if !info.Price.IsPositive() {
+			errs = append(errs, fmt.Sprintf("%s: invalid price %s", source.Name, info.Price))
+			continue
+		}
+
+		if o.maxPriceAge > 0 && info.Age() > o.maxPriceAge {
+			stale = append(stale, sourcePriceInfo{source: source, info: info})
+		} else {
+			fresh = append(fresh, sourcePriceInfo{source: source, info: info})
+		}
+	}
+
+	if len(fresh) == 0 && len(stale) == 0 {
+		return PriceInfo{}, fmt.Errorf("error getting price for %s: %s", baseSymbol, strings.Join(errs, "; "))
+	}
+
+	if len(errs) > 0 {
+		o.logger.Debug().
+			Str("symbol", baseSymbol).
+			Strs("errors", errs).
+			Msg("some price sources failed to price the symbol")
+	}
+
+	used := fresh
+	if len(fresh) == 0 {
+		used = stale
+	} else if len(stale) > 0 {
+		names := make([]string, len(stale))
+		for i, s := range stale {
+			names[i] = s.source.Name
+		}
+
+		o.logger.Debug().
+			Str("symbol", baseSymbol).
+			Strs("sources", names).
+			Msg("leaving stale prices out of the median")
+	}
+
+	return medianPriceInfo(used), nil
+}
+
+// sourcePriceInfo is the price info of a symbol given by a source.
+type sourcePriceInfo struct {
+	source WeightedSource
+	info   PriceInfo
+}
+
+// medianPriceInfo returns the weighted median of the prices. It's as old as the oldest of them, and its providers are
+// the ones of the sources, prefixed by the source name.
+func medianPriceInfo(infos []sourcePriceInfo) PriceInfo {
+	prices := make([]weightedPrice, len(infos))
+	sourcePrices := make([]sdk.Dec, len(infos))
+	providers := map[string]struct{}{}
+
+	result := PriceInfo{
+		ComputedAt: infos[0].info.ComputedAt,
+		Deviation:  infos[0].info.Deviation,
+	}
+
+	for i, s := range infos {
+		prices[i] = weightedPrice{price: s.info.Price, weight: s.source.Weight}
+		sourcePrices[i] = s.info.Price
+
+		for _, provider := range s.info.Providers {
+			providers[s.source.Name+"/"+provider] = struct{}{}
+		}
+
+		if s.info.ComputedAt.Before(result.ComputedAt) {
+			result.ComputedAt = s.info.ComputedAt
+		}
+	}
+
+	result.Price = weightedMedian(prices)
+	result.Providers = sortedKeys(providers)
+	if len(infos) > 1 {
+		result.Deviation = priceDeviation(sourcePrices)
+	}
+
+	return result
+}
+
+// SubscribeSymbols subscribes the symbols in all the sources. It only fails if none of the sources can subscribe
+// them.
+func (o *MedianOracle) SubscribeSymbols(baseSymbols ...string) error {
+	errs := make([]string, 0, len(o.sources))
+
+	for _, source := range o.sources {
+		if err := source.Source.SubscribeSymbols(baseSymbols...); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", source.Name, err))
+		}
+	}
+
+	if len(o.sources) > 0 && len(errs) == len(o.sources) {
+		return fmt.Errorf("failed to subscribe symbols %v: %s", baseSymbols, strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+type weightedPrice struct {
+	price  sdk.Dec
+	weight float64
+}
+
+// weightedMedian returns the price at which the sorted prices reach half of the total weight. When the lower half
+// weighs exactly half of the total, the median is the average of the prices around it.
+func weightedMedian(prices []weightedPrice) sdk.Dec {
+	sort.SliceStable(prices, func(i, j int) bool {
+		return prices[i].price.LT(prices[j].price)
+	})
+
+	var total float64
+	for _, p := range prices {
+		total += p.weight
+	}
+
+	var cumulative float64
+	for i, p := range prices {
+		cumulative += p.weight
+
+		if cumulative*2 == total && i+1 < len(prices) {
+			return p.price.Add(prices[i+1].price).QuoInt64(2)
+		}
+
+		if cumulative*2 >= total {
+			return p.price
+		}
+	}
+
+	return prices[len(prices)-1].price
+}
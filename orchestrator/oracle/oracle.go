@@ -35,11 +35,20 @@ type Oracle struct {
 
 	mtx                   sync.RWMutex
 	providers             map[pfprovider.Name]*Provider // providerName => Provider
-	prices                map[string]sdk.Dec            // baseSymbol => price ex.: UMEE, ETH => sdk.Dec
+	prices                map[string]PriceInfo          // baseSymbol => price ex.: UMEE, ETH => PriceInfo
 	subscribedBaseSymbols map[string]struct{}           // baseSymbol => nothing
 	// this field could be calculated each time by looping providers.subscribedPairs
 	// but the time to process is not worth the amount of memory
 	providerSubscribedPairs map[pfprovider.Name][]pftypes.CurrencyPair // providerName => []CurrencyPair
+	// tickerUpdates keeps the last ticker of each provider and base symbol along with when it changed, since tickers
+	// don't have a timestamp. It's only used by the oracle loop.
+	tickerUpdates map[pfprovider.Name]map[string]tickerUpdate // providerName => baseSymbol => tickerUpdate
+}
+
+// tickerUpdate is a ticker along with when a provider started reporting it.
+type tickerUpdate struct {
+	ticker    pftypes.TickerPrice
+	updatedAt time.Time
 }
 
 // Provider wraps the umee provider interface.
@@ -105,7 +114,7 @@ func (o *Oracle) GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error) {
 		if !ok {
 			return nil, fmt.Errorf("error getting price for %s", baseSymbol)
 		}
-		prices[baseSymbol] = price
+		prices[baseSymbol] = price.Price
 	}
 
 	return prices, nil
@@ -113,15 +122,26 @@ func (o *Oracle) GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error) {
 
 // GetPrice returns the price based on the symbol ex.: UMEE, ETH.
 func (o *Oracle) GetPrice(baseSymbol string) (sdk.Dec, error) {
+	info, err := o.GetPriceInfo(baseSymbol)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	return info.Price, nil
+}
+
+// GetPriceInfo returns the price of the symbol along with when it was computed and the providers it was computed
+// from. The price is the last one computed, so it may be stale if the providers stopped reporting prices.
+func (o *Oracle) GetPriceInfo(baseSymbol string) (PriceInfo, error) {
 	o.mtx.RLock()
 	defer o.mtx.RUnlock()
 
-	price, ok := o.prices[baseSymbol]
+	info, ok := o.prices[baseSymbol]
 	if !ok {
-		return sdk.Dec{}, fmt.Errorf("error getting price for %s", baseSymbol)
+		return PriceInfo{}, fmt.Errorf("error getting price for %s", baseSymbol)
 	}
 
-	return price, nil
+	return info, nil
 }
 
 // SubscribeSymbols attempts to subscribe the symbols in all the providers.
@@ -385,10 +405,115 @@ func (o *Oracle) setPrices() error {
 		return err
 	}
 
-	o.prices = computedPrices
+	updatedAt := o.updateTimes(providerPrices, providerCandles, time.Now())
+	o.prices = newPriceInfos(computedPrices, providerPrices, providerCandles, updatedAt)
 	return nil
 }
 
+// updateTimes returns, by base symbol, the last time the data of any of its providers changed: the timestamp of the
+// newest candle, or when the ticker last changed price or volume, as tickers don't have a timestamp. Providers keep
+// serving their last tickers and candles after losing their connection, so the update time of a symbol stops moving
+// when all its feeds go stale.
+func (o *Oracle) updateTimes(
+	providerPrices pfprovider.AggregatedProviderPrices,
+	providerCandles pfprovider.AggregatedProviderCandles,
+	now time.Time,
+) map[string]time.Time {
+	if o.tickerUpdates == nil {
+		o.tickerUpdates = map[pfprovider.Name]map[string]tickerUpdate{}
+	}
+
+	updatedAt := map[string]time.Time{}
+	setUpdatedAt := func(base string, at time.Time) {
+		if at.After(now) {
+			at = now
+		}
+
+		if at.After(updatedAt[base]) {
+			updatedAt[base] = at
+		}
+	}
+
+	for providerName, tickers := range providerPrices {
+		if o.tickerUpdates[providerName] == nil {
+			o.tickerUpdates[providerName] = map[string]tickerUpdate{}
+		}
+
+		for base, ticker := range tickers {
+			last, ok := o.tickerUpdates[providerName][base]
+			if !ok ||
+				last.ticker.Price.String() != ticker.Price.String() ||
+				last.ticker.Volume.String() != ticker.Volume.String() {
+				last = tickerUpdate{ticker: ticker, updatedAt: now}
+				o.tickerUpdates[providerName][base] = last
+			}
+
+			setUpdatedAt(base, last.updatedAt)
+		}
+	}
+
+	for _, candles := range providerCandles {
+		for base, baseCandles := range candles {
+			for _, candle := range baseCandles {
+				setUpdatedAt(base, time.UnixMilli(candle.TimeStamp))
+			}
+		}
+	}
+
+	return updatedAt
+}
+
+// newPriceInfos adds to the computed prices the providers that reported a ticker or a candle of their symbol, the
+// standard deviation of the providers' prices, and the update time of the providers' data as the time the price
+// was computed at.
+func newPriceInfos(
+	computedPrices map[string]sdk.Dec,
+	providerPrices pfprovider.AggregatedProviderPrices,
+	providerCandles pfprovider.AggregatedProviderCandles,
+	updatedAt map[string]time.Time,
+) map[string]PriceInfo {
+	providers := map[string]map[string]struct{}{} // baseSymbol => provider names
+	addProvider := func(base string, providerName pfprovider.Name) {
+		if providers[base] == nil {
+			providers[base] = map[string]struct{}{}
+		}
+		providers[base][string(providerName)] = struct{}{}
+	}
+
+	for providerName, tickers := range providerPrices {
+		for base := range tickers {
+			addProvider(base, providerName)
+		}
+	}
+	for providerName, candles := range providerCandles {
+		for base := range candles {
+			addProvider(base, providerName)
+		}
+	}
+
+	deviations, _, err := pforacle.StandardDeviation(pforacle.ComputeVwapsByProvider(providerPrices))
+	if err != nil {
+		deviations = map[string]sdk.Dec{}
+	}
+
+	prices := make(map[string]PriceInfo, len(computedPrices))
+	for base, price := range computedPrices {
+		deviation, ok := deviations[base]
+		if !ok {
+			deviation = sdk.ZeroDec()
+		}
+
+		prices[base] = PriceInfo{
+			Price:      price,
+			ComputedAt: updatedAt[base],
+			Providers:  sortedKeys(providers[base]),
+			Deviation:  deviation,
+		}
+	}
+
+	return prices
+}
+
 func (o *Oracle) tick() error {
 	if err := o.setPrices(); err != nil {
 		return err
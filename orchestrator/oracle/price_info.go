@@ -0,0 +1,94 @@
+package oracle
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// PriceInfo is a price along with its freshness and provenance.
+type PriceInfo struct {
+	Price sdk.Dec
+	// ComputedAt is when the price was computed from the data of its providers. For exchange providers, it's when
+	// their data last changed, so a price computed from feeds that stopped updating gets old.
+	ComputedAt time.Time
+	// Providers are the providers the price was computed from.
+	Providers []string
+	// Deviation is the standard deviation of the prices of the providers. It's zero when there are too few providers
+	// for it to be meaningful.
+	Deviation sdk.Dec
+}
+
+// Age returns how long ago the price was computed.
+func (p PriceInfo) Age() time.Duration {
+	return time.Since(p.ComputedAt)
+}
+
+// StalePriceError is returned when a price is older than the max price age.
+type StalePriceError struct {
+	Symbol string
+	Age    time.Duration
+	MaxAge time.Duration
+}
+
+func (e *StalePriceError) Error() string {
+	return fmt.Sprintf(
+		"price of %s is stale: computed %s ago, more than the max price age of %s",
+		e.Symbol,
+		e.Age.Round(time.Second),
+		e.MaxAge,
+	)
+}
+
+// CheckPriceAge returns a StalePriceError if the price is older than maxAge. A zero max age disables the check.
+func CheckPriceAge(baseSymbol string, info PriceInfo, maxAge time.Duration) error {
+	if maxAge <= 0 {
+		return nil
+	}
+
+	if age := info.Age(); age > maxAge {
+		return &StalePriceError{Symbol: baseSymbol, Age: age, MaxAge: maxAge}
+	}
+
+	return nil
+}
+
+// priceDeviation returns the standard deviation of the prices, or zero if there are less than two.
+func priceDeviation(prices []sdk.Dec) sdk.Dec {
+	if len(prices) < 2 {
+		return sdk.ZeroDec()
+	}
+
+	mean := sdk.ZeroDec()
+	for _, price := range prices {
+		mean = mean.Add(price)
+	}
+	mean = mean.QuoInt64(int64(len(prices)))
+
+	variance := sdk.ZeroDec()
+	for _, price := range prices {
+		diff := price.Sub(mean)
+		variance = variance.Add(diff.Mul(diff))
+	}
+	variance = variance.QuoInt64(int64(len(prices)))
+
+	deviation, err := variance.ApproxSqrt()
+	if err != nil {
+		return sdk.ZeroDec()
+	}
+
+	return deviation
+}
+
+// sortedKeys returns the keys of the set, sorted.
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
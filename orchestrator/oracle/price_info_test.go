@@ -0,0 +1,213 @@
+package oracle
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+	pftypes "github.com/umee-network/umee/price-feeder/v2/oracle/types"
+)
+
+// infoSource is a price source returning a fixed price info for every symbol.
+type infoSource PriceInfo
+
+func (s infoSource) GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s infoSource) GetPrice(string) (sdk.Dec, error) {
+	return s.Price, nil
+}
+
+func (s infoSource) GetPriceInfo(string) (PriceInfo, error) {
+	return PriceInfo(s), nil
+}
+
+func (s infoSource) SubscribeSymbols(...string) error {
+	return nil
+}
+
+func TestCheckPriceAge(t *testing.T) {
+	info := PriceInfo{Price: sdk.OneDec(), ComputedAt: time.Now().Add(-10 * time.Minute)}
+
+	assert.Nil(t, CheckPriceAge("ETH", info, 0))
+	assert.Nil(t, CheckPriceAge("ETH", info, time.Hour))
+
+	err := CheckPriceAge("ETH", info, 5*time.Minute)
+	var staleErr *StalePriceError
+	assert.True(t, errors.As(err, &staleErr))
+	assert.Equal(t, "ETH", staleErr.Symbol)
+	assert.Equal(t, "price of ETH is stale: computed 10m0s ago, more than the max price age of 5m0s", err.Error())
+}
+
+func TestMedianPriceInfo(t *testing.T) {
+	computedAt := time.Now().Add(-time.Minute)
+
+	o := NewMedianOracle(zerolog.Nop(), 0,
+		WeightedSource{Name: "a", Weight: 1, Source: infoSource{
+			Price:      sdk.NewDec(10),
+			ComputedAt: time.Now(),
+			Providers:  []string{"binance", "kraken"},
+			Deviation:  sdk.NewDec(1),
+		}},
+		WeightedSource{Name: "b", Weight: 1, Source: infoSource{
+			Price:      sdk.NewDec(12),
+			ComputedAt: computedAt,
+			Providers:  []string{SourceUmeeOracle},
+			Deviation:  sdk.ZeroDec(),
+		}},
+	)
+
+	info, err := o.GetPriceInfo("UMEE")
+	assert.Nil(t, err)
+	assert.Equal(t, sdk.NewDec(11), info.Price)
+	assert.Equal(t, computedAt, info.ComputedAt)
+	assert.Equal(t, []string{"a/binance", "a/kraken", "b/umee-oracle"}, info.Providers)
+	assert.Equal(t, sdk.NewDec(1), info.Deviation)
+}
+
+func TestMedianPriceInfoStaleSource(t *testing.T) {
+	now := time.Now()
+	freshAt := now.Add(-time.Minute)
+	staleAt := now.Add(-time.Hour)
+
+	fresh := WeightedSource{Name: "http", Weight: 1, Source: infoSource{
+		Price:      sdk.NewDec(10),
+		ComputedAt: now,
+		Providers:  []string{"coingecko"},
+	}}
+	otherFresh := WeightedSource{Name: "umee", Weight: 1, Source: infoSource{
+		Price:      sdk.NewDec(12),
+		ComputedAt: freshAt,
+		Providers:  []string{SourceUmeeOracle},
+	}}
+	// The price-feeder source got disconnected, so its price is stale and way off.
+	stale := WeightedSource{Name: "price-feeder", Weight: 5, Source: infoSource{
+		Price:      sdk.NewDec(100),
+		ComputedAt: staleAt,
+		Providers:  []string{"binance"},
+	}}
+
+	o := NewMedianOracle(zerolog.Nop(), 5*time.Minute, fresh, otherFresh, stale)
+
+	info, err := o.GetPriceInfo("UMEE")
+	assert.Nil(t, err)
+	assert.Equal(t, sdk.NewDec(11), info.Price)
+	assert.Equal(t, freshAt, info.ComputedAt)
+	assert.Equal(t, []string{"http/coingecko", "umee/umee-oracle"}, info.Providers)
+	assert.Nil(t, CheckPriceAge("UMEE", info, 5*time.Minute))
+
+	t.Run("all stale", func(t *testing.T) {
+		o := NewMedianOracle(zerolog.Nop(), 5*time.Minute, stale)
+
+		info, err := o.GetPriceInfo("UMEE")
+		assert.Nil(t, err)
+		assert.Equal(t, staleAt, info.ComputedAt)
+
+		var staleErr *StalePriceError
+		assert.True(t, errors.As(CheckPriceAge("UMEE", info, 5*time.Minute), &staleErr))
+	})
+
+	t.Run("no max price age", func(t *testing.T) {
+		o := NewMedianOracle(zerolog.Nop(), 0, fresh, otherFresh, stale)
+
+		info, err := o.GetPriceInfo("UMEE")
+		assert.Nil(t, err)
+		assert.Equal(t, sdk.NewDec(100), info.Price)
+		assert.Equal(t, staleAt, info.ComputedAt)
+	})
+}
+
+func TestNewPriceInfos(t *testing.T) {
+	ticker := func(price int64) pftypes.TickerPrice {
+		return pftypes.TickerPrice{Price: sdk.NewDec(price), Volume: sdk.OneDec()}
+	}
+
+	computedAt := time.Now()
+	otherComputedAt := computedAt.Add(-time.Minute)
+	infos := newPriceInfos(
+		map[string]sdk.Dec{"ETH": sdk.NewDec(1000), "UMEE": sdk.MustNewDecFromStr("0.01")},
+		pfprovider.AggregatedProviderPrices{
+			pfprovider.ProviderBinance: {"ETH": ticker(990)},
+			pfprovider.ProviderKraken:  {"ETH": ticker(1000)},
+			pfprovider.ProviderGate:    {"ETH": ticker(1010)},
+		},
+		pfprovider.AggregatedProviderCandles{
+			pfprovider.ProviderOkx: {"UMEE": []pftypes.CandlePrice{{Price: sdk.MustNewDecFromStr("0.01")}}},
+		},
+		map[string]time.Time{"ETH": computedAt, "UMEE": otherComputedAt},
+	)
+
+	assert.Equal(t, []string{"binance", "gate", "kraken"}, infos["ETH"].Providers)
+	assert.Equal(t, computedAt, infos["ETH"].ComputedAt)
+	assert.Equal(t, otherComputedAt, infos["UMEE"].ComputedAt)
+	assert.True(t, infos["ETH"].Deviation.GT(sdk.NewDec(8)) && infos["ETH"].Deviation.LT(sdk.NewDec(9)))
+
+	assert.Equal(t, []string{"okx"}, infos["UMEE"].Providers)
+	assert.True(t, infos["UMEE"].Deviation.IsZero())
+}
+
+func TestOracleUpdateTimes(t *testing.T) {
+	ticker := func(price, volume int64) pftypes.TickerPrice {
+		return pftypes.TickerPrice{Price: sdk.NewDec(price), Volume: sdk.NewDec(volume)}
+	}
+
+	o := &Oracle{}
+	// The data is reported from an hour ago, so the ages of the prices are real.
+	start := time.Now().Add(-time.Hour)
+	maxAge := 5 * time.Minute
+
+	updatedAt := o.updateTimes(
+		pfprovider.AggregatedProviderPrices{pfprovider.ProviderBinance: {"ETH": ticker(1000, 5)}},
+		nil,
+		start,
+	)
+	assert.Equal(t, start, updatedAt["ETH"])
+
+	t.Run("stale ticker", func(t *testing.T) {
+		// The provider keeps serving its last ticker after its websocket dropped.
+		now := start.Add(10 * time.Minute)
+		updatedAt := o.updateTimes(
+			pfprovider.AggregatedProviderPrices{pfprovider.ProviderBinance: {"ETH": ticker(1000, 5)}},
+			nil,
+			now,
+		)
+		assert.Equal(t, start, updatedAt["ETH"])
+
+		infos := newPriceInfos(map[string]sdk.Dec{"ETH": sdk.NewDec(1000)}, nil, nil, updatedAt)
+		var staleErr *StalePriceError
+		assert.ErrorAs(t, CheckPriceAge("ETH", infos["ETH"], maxAge), &staleErr)
+	})
+
+	t.Run("updated ticker", func(t *testing.T) {
+		now := start.Add(11 * time.Minute)
+		updatedAt := o.updateTimes(
+			pfprovider.AggregatedProviderPrices{pfprovider.ProviderBinance: {"ETH": ticker(1000, 6)}},
+			nil,
+			now,
+		)
+		assert.Equal(t, now, updatedAt["ETH"])
+	})
+
+	t.Run("stale candles", func(t *testing.T) {
+		lastCandle := start.Add(-time.Hour).Truncate(time.Millisecond)
+		updatedAt := o.updateTimes(
+			nil,
+			pfprovider.AggregatedProviderCandles{pfprovider.ProviderOkx: {"UMEE": []pftypes.CandlePrice{
+				{Price: sdk.OneDec(), Volume: sdk.OneDec(), TimeStamp: lastCandle.Add(-time.Minute).UnixMilli()},
+				{Price: sdk.OneDec(), Volume: sdk.OneDec(), TimeStamp: lastCandle.UnixMilli()},
+			}}},
+			start,
+		)
+		assert.True(t, lastCandle.Equal(updatedAt["UMEE"]))
+
+		infos := newPriceInfos(map[string]sdk.Dec{"UMEE": sdk.OneDec()}, nil, nil, updatedAt)
+		var staleErr *StalePriceError
+		assert.ErrorAs(t, CheckPriceAge("UMEE", infos["UMEE"], maxAge), &staleErr)
+	})
+}
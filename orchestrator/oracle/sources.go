@@ -0,0 +1,217 @@
+package oracle
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpc1 "github.com/gogo/protobuf/grpc"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	pfprovider "github.com/umee-network/umee/price-feeder/v2/oracle/provider"
+)
+
+// Types of the price sources
+const (
+	SourcePriceFeeder = "price-feeder"
+	SourceStatic      = "static"
+	SourceHTTP        = "http"
+	SourceUmeeOracle  = "umee-oracle"
+)
+
+// defaultSourceCacheTTL is how long the prices fetched by the HTTP and Umee oracle sources are reused.
+const defaultSourceCacheTTL = time.Minute
+
+// Source is a backend of USD prices by base symbol. It has the same methods as the relayer oracle.
+type Source interface {
+	// GetPrices returns the price for the provided base symbols.
+	GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error)
+
+	// GetPrice returns the price based on the base symbol ex.: UMEE, ETH.
+	GetPrice(baseSymbol string) (sdk.Dec, error)
+
+	// GetPriceInfo returns the price of the base symbol along with its freshness and provenance.
+	GetPriceInfo(baseSymbol string) (PriceInfo, error)
+
+	// SubscribeSymbols attempts to subscribe the symbols in the source.
+	SubscribeSymbols(baseSymbols ...string) error
+}
+
+// SourceConfig defines a price source. Besides the common fields, each type of source uses its own fields.
+type SourceConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Weight of the prices of the source in the median; it defaults to 1.
+	Weight float64 `json:"weight,omitempty"`
+
+	// Providers are the price-feeder providers; they default to the ones set by --oracle-providers.
+	Providers []string `json:"providers,omitempty"`
+
+	// Prices of the static source by symbol, set inline or in a JSON file of the same format.
+	Prices map[string]string `json:"prices,omitempty"`
+	File   string            `json:"file,omitempty"`
+
+	// URL of the HTTP source. The {symbol} placeholder is replaced by the symbol (or its entry in Symbols), both in the
+	// URL and in the selector of the price in the JSON response, e.g. "$.data.price" or "$['{symbol}'].usd".
+	URL      string            `json:"url,omitempty"`
+	Selector string            `json:"selector,omitempty"`
+	Symbols  map[string]string `json:"symbols,omitempty"`
+
+	// CacheTTL is how long the prices fetched by the HTTP and Umee oracle sources are reused, e.g. "30s".
+	CacheTTL string `json:"cache_ttl,omitempty"`
+}
+
+// SourcesConfig defines the price sources aggregated by the oracle.
+type SourcesConfig struct {
+	Sources []SourceConfig `json:"sources"`
+}
+
+// LoadSourcesConfig reads a price sources config from a JSON file.
+func LoadSourcesConfig(path string) (SourcesConfig, error) {
+	var cfg SourcesConfig
+
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, errors.Wrap(err, "failed to read price sources file")
+	}
+
+	if err := json.Unmarshal(bz, &cfg); err != nil {
+		return cfg, errors.Wrap(err, "failed to decode price sources file")
+	}
+
+	return cfg, nil
+}
+
+// SourceDeps are the dependencies shared by the price sources.
+type SourceDeps struct {
+	Logger zerolog.Logger
+	// CosmosConn is the gRPC connection to the Umee node, used by the Umee oracle source.
+	CosmosConn grpc1.ClientConn
+	// PriceFeederProviders are the default providers of the price-feeder sources.
+	PriceFeederProviders []pfprovider.Name
+	// MaxPriceAge is the max age of the prices of the sources used in the median. Zero means there's no limit.
+	MaxPriceAge time.Duration
+}
+
+// SourceFactory creates a price source from its config.
+type SourceFactory func(ctx context.Context, deps SourceDeps, cfg SourceConfig) (Source, error)
+
+// Registry holds the factories of the types of price sources.
+type Registry struct {
+	factories map[string]SourceFactory
+}
+
+// NewRegistry returns a registry with the built-in types of price sources.
+func NewRegistry() *Registry {
+	r := &Registry{factories: map[string]SourceFactory{}}
+
+	r.Register(SourcePriceFeeder, newPriceFeederSource)
+	r.Register(SourceStatic, newStaticSourceFromConfig)
+	r.Register(SourceHTTP, newHTTPSourceFromConfig)
+	r.Register(SourceUmeeOracle, newUmeeOracleSourceFromConfig)
+
+	return r
+}
+
+// Register sets the factory of a type of price source, replacing the previous one.
+func (r *Registry) Register(sourceType string, factory SourceFactory) {
+	r.factories[sourceType] = factory
+}
+
+// Types returns the registered types of price sources.
+func (r *Registry) Types() []string {
+	types := make([]string, 0, len(r.factories))
+	for sourceType := range r.factories {
+		types = append(types, sourceType)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// NewOracle creates the configured price sources and aggregates them with a weighted median.
+func (r *Registry) NewOracle(ctx context.Context, deps SourceDeps, cfg SourcesConfig) (*MedianOracle, error) {
+	if len(cfg.Sources) == 0 {
+		return nil, errors.New("no price sources configured")
+	}
+
+	names := map[string]struct{}{}
+	sources := make([]WeightedSource, 0, len(cfg.Sources))
+
+	for _, sourceCfg := range cfg.Sources {
+		if sourceCfg.Name == "" {
+			sourceCfg.Name = sourceCfg.Type
+		}
+
+		if _, ok := names[sourceCfg.Name]; ok {
+			return nil, errors.Errorf("duplicated price source %s", sourceCfg.Name)
+		}
+		names[sourceCfg.Name] = struct{}{}
+
+		factory, ok := r.factories[sourceCfg.Type]
+		if !ok {
+			return nil, errors.Errorf(
+				"invalid type %q of price source %s; valid types: %s",
+				sourceCfg.Type,
+				sourceCfg.Name,
+				strings.Join(r.Types(), ", "),
+			)
+		}
+
+		weight := sourceCfg.Weight
+		if weight == 0 {
+			weight = 1
+		} else if weight < 0 {
+			return nil, errors.Errorf("weight of price source %s can't be negative", sourceCfg.Name)
+		}
+
+		source, err := factory(ctx, deps, sourceCfg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create price source %s", sourceCfg.Name)
+		}
+
+		sources = append(sources, WeightedSource{Name: sourceCfg.Name, Source: source, Weight: weight})
+	}
+
+	return NewMedianOracle(deps.Logger, deps.MaxPriceAge, sources...), nil
+}
+
+// newPriceFeederSource creates an oracle of price-feeder providers.
+func newPriceFeederSource(ctx context.Context, deps SourceDeps, cfg SourceConfig) (Source, error) {
+	providers := deps.PriceFeederProviders
+	if len(cfg.Providers) > 0 {
+		providers = make([]pfprovider.Name, len(cfg.Providers))
+		for i, provider := range cfg.Providers {
+			providers[i] = pfprovider.Name(provider)
+		}
+	}
+
+	if len(providers) == 0 {
+		return nil, errors.New("no price-feeder providers set")
+	}
+
+	return New(ctx, deps.Logger.With().Str("module", "oracle").Logger(), providers)
+}
+
+// parseCacheTTL parses the cache TTL of a source config, which defaults to defaultSourceCacheTTL.
+func parseCacheTTL(cfg SourceConfig) (time.Duration, error) {
+	if cfg.CacheTTL == "" {
+		return defaultSourceCacheTTL, nil
+	}
+
+	ttl, err := time.ParseDuration(cfg.CacheTTL)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid cache TTL")
+	}
+
+	if ttl < 0 {
+		return 0, errors.New("cache TTL can't be negative")
+	}
+
+	return ttl, nil
+}
@@ -0,0 +1,137 @@
+package oracle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	pricesFile := filepath.Join(t.TempDir(), "prices.json")
+	assert.Nil(t, os.WriteFile(pricesFile, []byte(`{"umee": "0.012", "ATOM": "9.8"}`), 0o600))
+
+	sourcesFile := filepath.Join(t.TempDir(), "sources.json")
+	assert.Nil(t, os.WriteFile(sourcesFile, []byte(`{"sources": [
+		{"name": "inline", "type": "static", "prices": {"UMEE": "0.01", "ETH": "1200"}},
+		{"name": "file", "type": "static", "weight": 2, "file": "`+pricesFile+`"}
+	]}`), 0o600))
+
+	cfg, err := LoadSourcesConfig(sourcesFile)
+	assert.Nil(t, err)
+
+	o, err := NewRegistry().NewOracle(context.Background(), SourceDeps{Logger: zerolog.Nop()}, cfg)
+	assert.Nil(t, err)
+
+	// The file source weighs more than the inline one.
+	price, err := o.GetPrice("UMEE")
+	assert.Nil(t, err)
+	assert.Equal(t, sdk.MustNewDecFromStr("0.012"), price)
+
+	// Each symbol is priced by the sources that know it.
+	prices, err := o.GetPrices("ETH", "ATOM")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]sdk.Dec{
+		"ETH":  sdk.MustNewDecFromStr("1200"),
+		"ATOM": sdk.MustNewDecFromStr("9.8"),
+	}, prices)
+
+	_, err = o.GetPrice("OSMO")
+	assert.Error(t, err)
+
+	assert.Nil(t, o.SubscribeSymbols("ETH"))
+	assert.Error(t, o.SubscribeSymbols("OSMO"))
+
+	t.Run("custom type", func(t *testing.T) {
+		r := NewRegistry()
+		r.Register("constant", func(context.Context, SourceDeps, SourceConfig) (Source, error) {
+			return NewStaticSource(map[string]string{"UMEE": "0.02"})
+		})
+		assert.Contains(t, r.Types(), "constant")
+
+		o, err := r.NewOracle(context.Background(), SourceDeps{Logger: zerolog.Nop()}, SourcesConfig{
+			Sources: []SourceConfig{{Type: "constant"}},
+		})
+		assert.Nil(t, err)
+
+		price, err := o.GetPrice("UMEE")
+		assert.Nil(t, err)
+		assert.Equal(t, sdk.MustNewDecFromStr("0.02"), price)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		for name, cfg := range map[string]SourcesConfig{
+			"no sources":       {},
+			"unknown type":     {Sources: []SourceConfig{{Type: "chainlink"}}},
+			"negative weight":  {Sources: []SourceConfig{{Type: SourceStatic, Weight: -1, Prices: map[string]string{"A": "1"}}}},
+			"duplicated name":  {Sources: []SourceConfig{{Type: SourceStatic, Prices: map[string]string{"A": "1"}}, {Type: SourceStatic, Prices: map[string]string{"A": "1"}}}}, //nolint: lll
+			"no static prices": {Sources: []SourceConfig{{Type: SourceStatic}}},
+			"invalid price":    {Sources: []SourceConfig{{Type: SourceStatic, Prices: map[string]string{"A": "-1"}}}},
+			"no HTTP URL":      {Sources: []SourceConfig{{Type: SourceHTTP, Selector: "$.price"}}},
+			"no gRPC conn":     {Sources: []SourceConfig{{Type: SourceUmeeOracle}}},
+			"invalid TTL":      {Sources: []SourceConfig{{Type: SourceUmeeOracle, CacheTTL: "soon"}}},
+		} {
+			_, err := NewRegistry().NewOracle(context.Background(), SourceDeps{Logger: zerolog.Nop()}, cfg)
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestWeightedMedian(t *testing.T) {
+	testCases := []struct {
+		name     string
+		prices   []weightedPrice
+		expected string
+	}{
+		{
+			name:     "single price",
+			prices:   []weightedPrice{{sdk.MustNewDecFromStr("1.5"), 1}},
+			expected: "1.5",
+		},
+		{
+			name: "odd number of prices",
+			prices: []weightedPrice{
+				{sdk.MustNewDecFromStr("3"), 1},
+				{sdk.MustNewDecFromStr("100"), 1},
+				{sdk.MustNewDecFromStr("1"), 1},
+			},
+			expected: "3",
+		},
+		{
+			name: "even number of prices",
+			prices: []weightedPrice{
+				{sdk.MustNewDecFromStr("2"), 1},
+				{sdk.MustNewDecFromStr("1"), 1},
+			},
+			expected: "1.5",
+		},
+		{
+			name: "heavier outlier",
+			prices: []weightedPrice{
+				{sdk.MustNewDecFromStr("1"), 1},
+				{sdk.MustNewDecFromStr("2"), 1},
+				{sdk.MustNewDecFromStr("10"), 3},
+			},
+			expected: "10",
+		},
+		{
+			name: "lighter outliers",
+			prices: []weightedPrice{
+				{sdk.MustNewDecFromStr("1"), 0.5},
+				{sdk.MustNewDecFromStr("2"), 2},
+				{sdk.MustNewDecFromStr("10"), 0.5},
+			},
+			expected: "2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, sdk.MustNewDecFromStr(tc.expected), weightedMedian(tc.prices))
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package oracle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+)
+
+// StaticSource prices symbols with fixed prices, e.g. for testnets whose tokens aren't listed anywhere.
+type StaticSource struct {
+	prices map[string]sdk.Dec // symbol => price
+}
+
+// NewStaticSource returns a StaticSource of the prices by symbol.
+func NewStaticSource(prices map[string]string) (*StaticSource, error) {
+	s := &StaticSource{prices: make(map[string]sdk.Dec, len(prices))}
+
+	for symbol, price := range prices {
+		dec, err := sdk.NewDecFromStr(price)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid static price of %s", symbol)
+		}
+
+		if !dec.IsPositive() {
+			return nil, errors.Errorf("static price of %s must be positive", symbol)
+		}
+
+		s.prices[strings.ToUpper(symbol)] = dec
+	}
+
+	return s, nil
+}
+
+// newStaticSourceFromConfig creates a StaticSource of the inline prices and the ones of the prices file, which take
+// precedence.
+func newStaticSourceFromConfig(_ context.Context, _ SourceDeps, cfg SourceConfig) (Source, error) {
+	prices := make(map[string]string, len(cfg.Prices))
+	for symbol, price := range cfg.Prices {
+		prices[symbol] = price
+	}
+
+	if cfg.File != "" {
+		bz, err := os.ReadFile(cfg.File)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read static prices file")
+		}
+
+		var filePrices map[string]string
+		if err := json.Unmarshal(bz, &filePrices); err != nil {
+			return nil, errors.Wrap(err, "failed to decode static prices file")
+		}
+
+		for symbol, price := range filePrices {
+			prices[symbol] = price
+		}
+	}
+
+	if len(prices) == 0 {
+		return nil, errors.New("no static prices set")
+	}
+
+	return NewStaticSource(prices)
+}
+
+// GetPrices returns the price for the provided base symbols.
+func (s *StaticSource) GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error) {
+	prices := make(map[string]sdk.Dec, len(baseSymbols))
+
+	for _, baseSymbol := range baseSymbols {
+		price, err := s.GetPrice(baseSymbol)
+		if err != nil {
+			return nil, err
+		}
+		prices[baseSymbol] = price
+	}
+
+	return prices, nil
+}
+
+// GetPrice returns the fixed price of the symbol.
+func (s *StaticSource) GetPrice(baseSymbol string) (sdk.Dec, error) {
+	price, ok := s.prices[strings.ToUpper(baseSymbol)]
+	if !ok {
+		return sdk.Dec{}, fmt.Errorf("no static price for %s", baseSymbol)
+	}
+
+	return price, nil
+}
+
+// GetPriceInfo returns the fixed price of the symbol. Fixed prices never get stale, so they're always computed now.
+func (s *StaticSource) GetPriceInfo(baseSymbol string) (PriceInfo, error) {
+	price, err := s.GetPrice(baseSymbol)
+	if err != nil {
+		return PriceInfo{}, err
+	}
+
+	return PriceInfo{
+		Price:      price,
+		ComputedAt: time.Now(),
+		Providers:  []string{SourceStatic},
+		Deviation:  sdk.ZeroDec(),
+	}, nil
+}
+
+// SubscribeSymbols checks that all the symbols have a price.
+func (s *StaticSource) SubscribeSymbols(baseSymbols ...string) error {
+	for _, baseSymbol := range baseSymbols {
+		if _, ok := s.prices[strings.ToUpper(baseSymbol)]; !ok {
+			return fmt.Errorf("no static price for %s", baseSymbol)
+		}
+	}
+
+	return nil
+}
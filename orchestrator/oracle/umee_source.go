@@ -0,0 +1,143 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	oracletypes "github.com/umee-network/umee/v3/x/oracle/types"
+)
+
+// umeeQueryTimeout is the timeout of the exchange rates query of the Umee oracle source.
+const umeeQueryTimeout = 10 * time.Second
+
+// exchangeRatesQuerier queries the exchange rates of the Umee x/oracle module.
+type exchangeRatesQuerier interface {
+	ExchangeRates(
+		ctx context.Context,
+		in *oracletypes.QueryExchangeRates,
+		opts ...grpc.CallOption,
+	) (*oracletypes.QueryExchangeRatesResponse, error)
+}
+
+// UmeeOracleSource prices symbols with the USD exchange rates voted by the validators in the x/oracle module of the
+// Umee chain.
+type UmeeOracleSource struct {
+	querier  exchangeRatesQuerier
+	cacheTTL time.Duration
+
+	mtx       sync.Mutex
+	rates     map[string]sdk.Dec // symbol => exchange rate
+	fetchedAt time.Time
+}
+
+// NewUmeeOracleSource returns an UmeeOracleSource of the x/oracle querier.
+func NewUmeeOracleSource(querier exchangeRatesQuerier, cacheTTL time.Duration) *UmeeOracleSource {
+	return &UmeeOracleSource{
+		querier:  querier,
+		cacheTTL: cacheTTL,
+	}
+}
+
+func newUmeeOracleSourceFromConfig(_ context.Context, deps SourceDeps, cfg SourceConfig) (Source, error) {
+	if deps.CosmosConn == nil {
+		return nil, errors.New("no gRPC connection to the Umee node")
+	}
+
+	cacheTTL, err := parseCacheTTL(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewUmeeOracleSource(oracletypes.NewQueryClient(deps.CosmosConn), cacheTTL), nil
+}
+
+// GetPrices returns the price for the provided base symbols.
+func (s *UmeeOracleSource) GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error) {
+	rates, _, err := s.exchangeRates()
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]sdk.Dec, len(baseSymbols))
+	for _, baseSymbol := range baseSymbols {
+		price, ok := rates[strings.ToUpper(baseSymbol)]
+		if !ok {
+			return nil, fmt.Errorf("no Umee oracle exchange rate for %s", baseSymbol)
+		}
+		prices[baseSymbol] = price
+	}
+
+	return prices, nil
+}
+
+// GetPrice returns the exchange rate of the symbol.
+func (s *UmeeOracleSource) GetPrice(baseSymbol string) (sdk.Dec, error) {
+	prices, err := s.GetPrices(baseSymbol)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	return prices[baseSymbol], nil
+}
+
+// GetPriceInfo returns the exchange rate of the symbol. The exchange rates are computed when they're queried.
+func (s *UmeeOracleSource) GetPriceInfo(baseSymbol string) (PriceInfo, error) {
+	rates, fetchedAt, err := s.exchangeRates()
+	if err != nil {
+		return PriceInfo{}, err
+	}
+
+	price, ok := rates[strings.ToUpper(baseSymbol)]
+	if !ok {
+		return PriceInfo{}, fmt.Errorf("no Umee oracle exchange rate for %s", baseSymbol)
+	}
+
+	return PriceInfo{
+		Price:      price,
+		ComputedAt: fetchedAt,
+		Providers:  []string{SourceUmeeOracle},
+		Deviation:  sdk.ZeroDec(),
+	}, nil
+}
+
+// SubscribeSymbols checks that the x/oracle module has an exchange rate for all the symbols.
+func (s *UmeeOracleSource) SubscribeSymbols(baseSymbols ...string) error {
+	_, err := s.GetPrices(baseSymbols...)
+	return err
+}
+
+// exchangeRates returns all the exchange rates and when they were queried, querying them if the cached ones
+// expired.
+func (s *UmeeOracleSource) exchangeRates() (map[string]sdk.Dec, time.Time, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.rates != nil && time.Since(s.fetchedAt) < s.cacheTTL {
+		return s.rates, s.fetchedAt, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), umeeQueryTimeout)
+	defer cancel()
+
+	resp, err := s.querier.ExchangeRates(ctx, &oracletypes.QueryExchangeRates{})
+	if err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "failed to query Umee oracle exchange rates")
+	}
+
+	rates := make(map[string]sdk.Dec, len(resp.ExchangeRates))
+	for _, rate := range resp.ExchangeRates {
+		rates[strings.ToUpper(rate.Denom)] = rate.Amount
+	}
+
+	s.rates = rates
+	s.fetchedAt = time.Now()
+
+	return rates, s.fetchedAt, nil
+}
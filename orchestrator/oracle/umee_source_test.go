@@ -0,0 +1,64 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	oracletypes "github.com/umee-network/umee/v3/x/oracle/types"
+)
+
+// fakeExchangeRatesQuerier returns the set exchange rates and counts the queries.
+type fakeExchangeRatesQuerier struct {
+	rates   sdk.DecCoins
+	err     error
+	queries int
+}
+
+func (q *fakeExchangeRatesQuerier) ExchangeRates(
+	context.Context,
+	*oracletypes.QueryExchangeRates,
+	...grpc.CallOption,
+) (*oracletypes.QueryExchangeRatesResponse, error) {
+	q.queries++
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	return &oracletypes.QueryExchangeRatesResponse{ExchangeRates: q.rates}, nil
+}
+
+func TestUmeeOracleSource(t *testing.T) {
+	querier := &fakeExchangeRatesQuerier{
+		rates: sdk.DecCoins{
+			sdk.NewDecCoinFromDec("ATOM", sdk.MustNewDecFromStr("9.8")),
+			sdk.NewDecCoinFromDec("UMEE", sdk.MustNewDecFromStr("0.012")),
+		},
+	}
+
+	s := NewUmeeOracleSource(querier, time.Minute)
+
+	prices, err := s.GetPrices("umee", "ATOM")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]sdk.Dec{
+		"umee": sdk.MustNewDecFromStr("0.012"),
+		"ATOM": sdk.MustNewDecFromStr("9.8"),
+	}, prices)
+
+	// The exchange rates are cached.
+	assert.Nil(t, s.SubscribeSymbols("UMEE"))
+	assert.Error(t, s.SubscribeSymbols("ETH"))
+	assert.Equal(t, 1, querier.queries)
+
+	t.Run("failed query", func(t *testing.T) {
+		s := NewUmeeOracleSource(&fakeExchangeRatesQuerier{err: errors.New("connection refused")}, time.Minute)
+
+		_, err := s.GetPrice("UMEE")
+		assert.Error(t, err)
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/rs/zerolog"
 
 	sidechain "github.com/umee-network/peggo/orchestrator/cosmos"
+	"github.com/umee-network/peggo/orchestrator/emergency"
 	gravity "github.com/umee-network/peggo/orchestrator/ethereum/gravity"
 	"github.com/umee-network/peggo/orchestrator/ethereum/keystore"
 	"github.com/umee-network/peggo/orchestrator/ethereum/provider"
@@ -24,6 +25,9 @@ type GravityOrchestrator interface {
 	EthSignerMainLoop(ctx context.Context) error
 	BatchRequesterLoop(ctx context.Context) error
 	RelayerMainLoop(ctx context.Context) error
+
+	// SetEmergency sets the emergency response to a valset hijack, which may halt the signing of valsets and batches.
+	SetEmergency(emergency.Emergency)
 }
 
 type gravityOrchestrator struct {
@@ -43,6 +47,7 @@ type gravityOrchestrator struct {
 	bridgeStartHeight          uint64
 	symbolRetriever            relayer.SymbolRetriever
 	oracle                     relayer.Oracle
+	emergency                  emergency.Emergency
 
 	mtx             sync.Mutex
 	erc20DenomCache map[string]string
@@ -95,3 +100,13 @@ func NewGravityOrchestrator(
 
 	return orch
 }
+
+// SetEmergency sets the emergency response to a valset hijack, which may halt the signing of valsets and batches.
+func SetEmergency(e emergency.Emergency) func(GravityOrchestrator) {
+	return func(s GravityOrchestrator) { s.SetEmergency(e) }
+}
+
+// SetEmergency sets the emergency response to a valset hijack, which may halt the signing of valsets and batches.
+func (p *gravityOrchestrator) SetEmergency(e emergency.Emergency) {
+	p.emergency = e
+}
@@ -1,13 +1,18 @@
 package relayer
 
 import (
+	"bytes"
 	"context"
 	"math/big"
 	"sort"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	"github.com/umee-network/peggo/orchestrator/ethereum/tracker"
 	"github.com/umee-network/peggo/orchestrator/oracle"
 )
 
@@ -41,7 +46,7 @@ func (s *gravityRelayer) getBatchesAndSignatures(
 	for _, batch := range outTxBatches.Batches {
 
 		// We might have already sent this same batch. Skip it.
-		if s.lastSentBatchNonce >= batch.BatchNonce {
+		if s.isBatchSent(ethcmn.HexToAddress(batch.TokenContract), batch.BatchNonce) {
 			continue
 		}
 
@@ -98,12 +103,12 @@ func (s *gravityRelayer) getBatchesAndSignatures(
 
 // RelayBatches attempts to submit batches with valid signatures, checking the state of the Ethereum chain to ensure
 // that it is valid to submit a given batch, more specifically that the correctly signed batch has not timed out or
-// already been submitted. The goal of this function is to submit batches in chronological order of their creation.
-// This function estimates the cost of submitting a batch before submitting it to Ethereum, if it is determined that
-// the ETH cost to submit is too high the batch will be skipped and a later, more profitable, batch may be submitted.
-// Keep in mind that many other relayers are making this same computation and some may have different standards for
-// their profit margin, therefore there may be a race not only to submit individual batches but also batches in
-// different orders.
+// already been submitted. This function estimates the cost of submitting each batch and skips the ones whose fees
+// don't cover it. The remaining batches of every token are ranked by expected profit by the relay planner, which picks
+// at most one batch per token and stops once the gas budget of the loop runs out; the planned batches are then sent in
+// descending profit order. Keep in mind that many other relayers are making this same computation and some may have
+// different standards for their profit margin, therefore there may be a race not only to submit individual batches
+// but also batches in different orders.
 func (s *gravityRelayer) RelayBatches(
 	ctx context.Context,
 	currentValset types.Valset,
@@ -120,12 +125,21 @@ func (s *gravityRelayer) RelayBatches(
 
 	ethBlockHeight := lastEthereumHeader.Number.Uint64()
 
-	for tokenContract, batches := range possibleBatches {
+	// Go maps are iterated in random order, so we sort the tokens to evaluate the batches deterministically.
+	tokenContracts := make([]ethcmn.Address, 0, len(possibleBatches))
+	for tokenContract := range possibleBatches {
+		tokenContracts = append(tokenContracts, tokenContract)
+	}
+
+	sort.Slice(tokenContracts, func(i, j int) bool {
+		return bytes.Compare(tokenContracts[i].Bytes(), tokenContracts[j].Bytes()) < 0
+	})
 
-		// Requests data from Ethereum only once per token type, this is valid because we are
-		// iterating from oldest to newest, so submitting a batch earlier in the loop won't
-		// ever invalidate submitting a batch later in the loop. Another relayer could always
-		// do that though.
+	var candidates []batchCandidate
+	for _, tokenContract := range tokenContracts {
+		// Requests data from Ethereum only once per token type, this is valid because submitting a batch during this
+		// loop won't ever invalidate the other candidates, as only one batch per token is relayed. Another relayer
+		// could always do that though.
 		latestEthereumBatch, err := s.gravityContract.GetTxBatchNonce(
 			ctx,
 			tokenContract,
@@ -137,7 +151,7 @@ func (s *gravityRelayer) RelayBatches(
 		}
 
 		// Now we iterate through batches per token type.
-		for _, batch := range batches {
+		for _, batch := range possibleBatches[tokenContract] {
 			if batch.Batch.BatchTimeout < ethBlockHeight {
 				s.logger.Debug().
 					Uint64("batch_nonce", batch.Batch.BatchNonce).
@@ -163,7 +177,7 @@ func (s *gravityRelayer) RelayBatches(
 				continue
 			}
 
-			estimatedGasCost, gasPrice, err := s.gravityContract.EstimateGas(ctx, s.gravityContract.Address(), txData)
+			estimatedGasCost, gasFees, err := s.gravityContract.EstimateGas(ctx, s.gravityContract.Address(), txData)
 			if err != nil {
 				s.logger.Err(err).Msg("failed to estimate gas cost")
 				// Here we shouldn't return, as it could be just another "nonce must be greater than the current nonce"
@@ -172,40 +186,174 @@ func (s *gravityRelayer) RelayBatches(
 			}
 
 			// If the batch is not profitable, move on to the next one.
-			if !s.IsBatchProfitable(ctx, batch.Batch, estimatedGasCost, gasPrice, s.profitMultiplier) {
+			isProfitable, profit := s.evaluateBatchProfit(
+				ctx,
+				batch.Batch,
+				estimatedGasCost,
+				gasFees.EffectiveGasPrice(),
+				s.profitMultiplier,
+			)
+			if !isProfitable {
 				continue
 			}
 
-			// Checking in pending txs(mempool) if tx with same input is already submitted
-			// We have to check this at the last moment because any other relayer could have submitted.
-			if s.gravityContract.IsPendingTxInput(txData, s.pendingTxWait) {
-				s.logger.Debug().
-					Msg("Transaction with same batch input data is already present in mempool")
-				continue
-			}
+			candidates = append(candidates, batchCandidate{
+				batch:               batch,
+				tokenContract:       tokenContract,
+				latestEthereumBatch: latestEthereumBatch.Uint64(),
+				txData:              txData,
+				gasCost:             estimatedGasCost,
+				gasFees:             gasFees,
+				profit:              profit,
+			})
+		}
+	}
 
-			s.logger.Info().
-				Uint64("latest_batch", batch.Batch.BatchNonce).
-				Uint64("latest_ethereum_batch", latestEthereumBatch.Uint64()).
-				Msg("we have detected a newer profitable batch; sending an update")
+	plan := planBatchRelays(candidates, s.batchGasBudget)
+	s.logBatchRelayPlan(plan)
 
-			txHash, err := s.gravityContract.SendTx(ctx, s.gravityContract.Address(), txData, estimatedGasCost, gasPrice)
-			if err != nil {
-				s.logger.Err(err).Str("tx_hash", txHash.Hex()).Msg("failed to sign and submit (Gravity submitBatch) to EVM")
-				continue
-			}
+	for _, relay := range plan.relays {
+		// Checking in pending txs(mempool) if tx with same input is already submitted
+		// We have to check this at the last moment because any other relayer could have submitted.
+		if s.gravityContract.IsPendingTxInput(relay.txData, s.pendingTxWait) {
+			s.logger.Debug().
+				Msg("Transaction with same batch input data is already present in mempool")
+			continue
+		}
 
-			s.logger.Info().Str("tx_hash", txHash.Hex()).Msg("sent Tx (Gravity submitBatch)")
+		if !s.isWithinSpendBudget(relay.gasCost, relay.gasFees.EffectiveGasPrice()) {
+			continue
+		}
 
-			// Update our local tracker of the latest batch.
-			s.lastSentBatchNonce = batch.Batch.BatchNonce
+		s.logger.Info().
+			Uint64("latest_batch", relay.batch.Batch.BatchNonce).
+			Uint64("latest_ethereum_batch", relay.latestEthereumBatch).
+			Msg("we have detected a newer profitable batch; sending an update")
+
+		txHash, err := s.gravityContract.SendTx(
+			ctx,
+			s.gravityContract.Address(),
+			relay.txData,
+			relay.gasCost,
+			relay.gasFees,
+		)
+		if err != nil {
+			s.logger.Err(err).Str("tx_hash", txHash.Hex()).Msg("failed to sign and submit (Gravity submitBatch) to EVM")
+			continue
 		}
 
+		s.logger.Info().Str("tx_hash", txHash.Hex()).Msg("sent Tx (Gravity submitBatch)")
+
+		s.recordSpend(
+			txHash,
+			relayKindBatch,
+			relay.batch.Batch.BatchNonce,
+			relay.gasCost,
+			relay.gasFees.EffectiveGasPrice(),
+		)
+		s.recordAttempt(
+			txHash,
+			relayKindBatch,
+			relay.tokenContract,
+			relay.batch.Batch.BatchNonce,
+			relay.gasFees.EffectiveGasPrice(),
+		)
+
+		s.trackBatch(txHash, relay.tokenContract, relay.batch.Batch.BatchNonce)
 	}
 
 	return nil
 }
 
+// trackBatch keeps track of a relayed batch. Without a tx tracker, the batch is considered relayed as soon as the
+// transaction is sent. Otherwise it's pending until the transaction is confirmed or superseded, and it can be relayed
+// again if the transaction reverts or is dropped. Batches are tracked per token contract, so a batch of one token
+// being confirmed never marks a batch of another token as relayed, whatever their nonces.
+func (s *gravityRelayer) trackBatch(txHash ethcmn.Hash, tokenContract ethcmn.Address, batchNonce uint64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.txTracker == nil {
+		s.setLastSentBatchNonce(tokenContract, batchNonce)
+		return
+	}
+
+	if s.pendingBatchNonces == nil {
+		s.pendingBatchNonces = map[ethcmn.Address]map[uint64]struct{}{}
+	}
+
+	if s.pendingBatchNonces[tokenContract] == nil {
+		s.pendingBatchNonces[tokenContract] = map[uint64]struct{}{}
+	}
+
+	s.pendingBatchNonces[tokenContract][batchNonce] = struct{}{}
+
+	onReplace := func(replacement tracker.Replacement) {
+		s.recordReplacementSpend(txHash, replacement)
+	}
+
+	s.txTracker.Track(txHash, s.isBatchRelayed(tokenContract, batchNonce), onReplace, func(result tracker.Result) {
+		s.logRelayResult(result, "batch_nonce", batchNonce)
+		s.settleSpend(txHash, result)
+		s.settleAttempt(txHash, result)
+
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+
+		delete(s.pendingBatchNonces[tokenContract], batchNonce)
+		if len(s.pendingBatchNonces[tokenContract]) == 0 {
+			delete(s.pendingBatchNonces, tokenContract)
+		}
+
+		if result.Outcome.IsDone() {
+			s.setLastSentBatchNonce(tokenContract, batchNonce)
+		}
+	})
+}
+
+// setLastSentBatchNonce stores the nonce of the last batch of a token that was relayed, unless a newer one already
+// was. It must be called with the mutex locked.
+func (s *gravityRelayer) setLastSentBatchNonce(tokenContract ethcmn.Address, batchNonce uint64) {
+	if s.lastSentBatchNonces == nil {
+		s.lastSentBatchNonces = map[ethcmn.Address]uint64{}
+	}
+
+	if batchNonce > s.lastSentBatchNonces[tokenContract] {
+		s.lastSentBatchNonces[tokenContract] = batchNonce
+	}
+}
+
+// isBatchSent returns true if the batch (or a newer one of the same token) was already relayed, or its relay
+// transaction is still pending.
+func (s *gravityRelayer) isBatchSent(tokenContract ethcmn.Address, batchNonce uint64) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.lastSentBatchNonces[tokenContract] >= batchNonce {
+		return true
+	}
+
+	_, pending := s.pendingBatchNonces[tokenContract][batchNonce]
+	return pending
+}
+
+// isBatchRelayed returns a function that checks if a batch (or a newer one of the same token) was already relayed to
+// Ethereum, which makes any pending transaction relaying it obsolete.
+func (s *gravityRelayer) isBatchRelayed(tokenContract ethcmn.Address, batchNonce uint64) tracker.ObsoleteFn {
+	return func(ctx context.Context) (bool, error) {
+		latestEthereumBatch, err := s.gravityContract.GetTxBatchNonce(
+			ctx,
+			tokenContract,
+			s.gravityContract.FromAddress(),
+		)
+		if err != nil {
+			return false, err
+		}
+
+		return latestEthereumBatch.Uint64() >= batchNonce, nil
+	}
+}
+
 // IsBatchProfitable gets the current prices in USD of ETH and the ERC20 token and compares the value of the estimated
 // gas cost of the transaction to the fees paid by the batch. If the estimated gas cost is greater than the batch's
 // fees, the batch is not profitable and should not be submitted.
@@ -216,83 +364,172 @@ func (s *gravityRelayer) IsBatchProfitable(
 	gasPrice *big.Int,
 	profitMultiplier float64,
 ) bool {
+	isProfitable, _ := s.evaluateBatchProfit(ctx, batch, ethGasCost, gasPrice, profitMultiplier)
+	return isProfitable
+}
+
+// evaluateBatchProfit checks if a batch is profitable, as described in IsBatchProfitable, and returns its expected
+// profit in USD: the value of its fees minus the gas cost. The profit is zero if the relayer can't price batches.
+func (s *gravityRelayer) evaluateBatchProfit(
+	ctx context.Context,
+	batch types.OutgoingTxBatch,
+	ethGasCost uint64,
+	gasPrice *big.Int,
+	profitMultiplier float64,
+) (bool, decimal.Decimal) {
 	if s.symbolRetriever == nil || s.oracle == nil || profitMultiplier == 0 {
-		return true
+		return true, decimal.Zero
 	}
 
 	// First we get the cost of the transaction in USD
-	usdEthPrice, err := s.oracle.GetPrice(oracle.SymbolETH)
+	gasCostInUSDDec, err := s.gasCostInUSD(ethGasCost, gasPrice)
 	if err != nil {
-		s.logger.Err(err).Msg("failed to get ETH price")
-		return false
-	}
-	usdEthPriceDec, err := decimal.NewFromString(usdEthPrice.String())
-	if err != nil {
-		s.logger.Err(err).Msg("failed to parse ETH price")
-		return false
+		if !s.logStalePriceSkip(err, relayKindBatch, batch.BatchNonce) {
+			s.logger.Err(err).Msg("failed to get gas cost in USD")
+		}
+		return false, decimal.Zero
 	}
-	totalETHcost := big.NewInt(0).Mul(gasPrice, big.NewInt(int64(ethGasCost)))
 
-	// Ethereum decimals are 18 and that's a constant.
-	gasCostInUSDDec := decimal.NewFromBigInt(totalETHcost, -18).Mul(usdEthPriceDec)
+	// We calculate the total fee in ERC20 tokens
+	totalBatchFees := big.NewInt(0)
+	for _, tx := range batch.Transactions {
+		totalBatchFees = totalBatchFees.Add(tx.Erc20Fee.Amount.BigInt(), totalBatchFees)
+	}
 
 	// Then we get the fees of the batch in USD
-	decimals, err := s.gravityContract.GetERC20Decimals(
+	totalFeeInUSDDec, usdTokenPrice, err := s.tokenAmountInUSD(
 		ctx,
 		ethcmn.HexToAddress(batch.TokenContract),
-		s.gravityContract.FromAddress(),
+		totalBatchFees,
 	)
 	if err != nil {
-		s.logger.Err(err).Str("token_contract", batch.TokenContract).Msg("failed to get token decimals")
-		return false
+		if s.logStalePriceSkip(err, relayKindBatch, batch.BatchNonce) {
+			return false, decimal.Zero
+		}
+
+		if gravity.IsUnreadableToken(err) {
+			s.logger.Warn().
+				Str("kind", relayKindBatch).
+				Uint64("nonce", batch.BatchNonce).
+				Str("token_contract", batch.TokenContract).
+				Str("reason", err.Error()).
+				Msg("relay skipped, unreadable token")
+		} else {
+			s.logger.Debug().Err(err).Str("token_contract", batch.TokenContract).Msg("failed to get batch fees in USD")
+		}
+		return false, decimal.Zero
 	}
 
+	// Simplified: totalFee > (gasCost * profitMultiplier).
+	isProfitable := totalFeeInUSDDec.GreaterThanOrEqual(gasCostInUSDDec.Mul(decimal.NewFromFloat(profitMultiplier)))
+
 	s.logger.Debug().
-		Uint8("decimals", decimals).
 		Str("token_contract", batch.TokenContract).
-		Msg("got token decimals")
+		Str("token_price_in_usd", usdTokenPrice.String()).
+		Int64("total_fees", totalBatchFees.Int64()).
+		Float64("total_fee_in_usd", totalFeeInUSDDec.InexactFloat64()).
+		Float64("gas_cost_in_usd", gasCostInUSDDec.InexactFloat64()).
+		Float64("profit_multiplier", profitMultiplier).
+		Bool("is_profitable", isProfitable).
+		Msg("checking if batch is profitable")
 
-	tokenSymbol, err := s.symbolRetriever.GetTokenSymbol(ethcmn.HexToAddress(batch.TokenContract))
+	return isProfitable, totalFeeInUSDDec.Sub(gasCostInUSDDec)
+}
+
+// getPrice returns the oracle price of the symbol. It fails with an oracle.StalePriceError if the price is older
+// than the max price age, since deciding whether a relay is profitable with a stale price is a gamble.
+func (s *gravityRelayer) getPrice(baseSymbol string) (oracle.PriceInfo, error) {
+	info, err := s.oracle.GetPriceInfo(baseSymbol)
 	if err != nil {
-		return false
+		return oracle.PriceInfo{}, err
 	}
 
-	if err := s.oracle.SubscribeSymbols(tokenSymbol); err != nil {
-		return false
+	if err := oracle.CheckPriceAge(baseSymbol, info, s.maxPriceAge); err != nil {
+		return oracle.PriceInfo{}, err
 	}
 
-	usdTokenPrice, err := s.oracle.GetPrice(tokenSymbol)
-	if err != nil {
+	s.logger.Debug().
+		Str("symbol", baseSymbol).
+		Str("price", info.Price.String()).
+		Time("computed_at", info.ComputedAt).
+		Strs("providers", info.Providers).
+		Str("deviation", info.Deviation.String()).
+		Msg("got oracle price")
+
+	return info, nil
+}
+
+// logStalePriceSkip logs that a relay is skipped because a price is older than the max price age. It returns false
+// if err isn't caused by a stale price.
+func (s *gravityRelayer) logStalePriceSkip(err error, kind string, nonce uint64) bool {
+	var staleErr *oracle.StalePriceError
+	if !errors.As(err, &staleErr) {
 		return false
 	}
 
-	usdTokenPriceDec, err := decimal.NewFromString(usdTokenPrice.String())
+	s.logger.Warn().
+		Str("kind", kind).
+		Uint64("nonce", nonce).
+		Str("reason", staleErr.Error()).
+		Msg("relay skipped")
+
+	return true
+}
+
+// gasCostInUSD returns the cost in USD of spending ethGasCost units of gas at the given gas price (in wei).
+func (s *gravityRelayer) gasCostInUSD(ethGasCost uint64, gasPrice *big.Int) (decimal.Decimal, error) {
+	usdEthPrice, err := s.getPrice(oracle.SymbolETH)
 	if err != nil {
-		return false
+		return decimal.Decimal{}, errors.Wrap(err, "failed to get ETH price")
 	}
 
-	// We calculate the total fee in ERC20 tokens
-	totalBatchFees := big.NewInt(0)
-	for _, tx := range batch.Transactions {
-		totalBatchFees = totalBatchFees.Add(tx.Erc20Fee.Amount.BigInt(), totalBatchFees)
+	usdEthPriceDec, err := decimal.NewFromString(usdEthPrice.Price.String())
+	if err != nil {
+		return decimal.Decimal{}, errors.Wrap(err, "failed to parse ETH price")
 	}
 
-	// Decimals (uint8) can be safely casted into int32 because the max uint8 is 255 and the max int32 is 2147483647.
-	totalFeeInUSDDec := decimal.NewFromBigInt(totalBatchFees, -int32(decimals)).Mul(usdTokenPriceDec)
+	totalETHcost := big.NewInt(0).Mul(gasPrice, new(big.Int).SetUint64(ethGasCost))
 
-	// Simplified: totalFee > (gasCost * profitMultiplier).
-	isProfitable := totalFeeInUSDDec.GreaterThanOrEqual(gasCostInUSDDec.Mul(decimal.NewFromFloat(profitMultiplier)))
+	// Ethereum decimals are 18 and that's a constant.
+	return decimal.NewFromBigInt(totalETHcost, -18).Mul(usdEthPriceDec), nil
+}
+
+// tokenAmountInUSD returns the value in USD of an amount (in the smallest unit) of an ERC20 token, along with the
+// USD price of a single token.
+func (s *gravityRelayer) tokenAmountInUSD(
+	ctx context.Context,
+	tokenContract ethcmn.Address,
+	amount *big.Int,
+) (decimal.Decimal, decimal.Decimal, error) {
+	decimals, err := s.gravityContract.GetERC20Decimals(ctx, tokenContract, s.gravityContract.FromAddress())
+	if err != nil {
+		return decimal.Decimal{}, decimal.Decimal{}, errors.Wrap(err, "failed to get token decimals")
+	}
 
 	s.logger.Debug().
-		Str("token_contract", batch.TokenContract).
-		Str("token_price_in_usd", usdTokenPrice.String()).
-		Int64("total_fees", totalBatchFees.Int64()).
-		Float64("total_fee_in_usd", totalFeeInUSDDec.InexactFloat64()).
-		Float64("gas_cost_in_usd", gasCostInUSDDec.InexactFloat64()).
-		Float64("profit_multiplier", profitMultiplier).
-		Bool("is_profitable", isProfitable).
-		Msg("checking if batch is profitable")
+		Uint8("decimals", decimals).
+		Str("token_contract", tokenContract.Hex()).
+		Msg("got token decimals")
 
-	return isProfitable
+	tokenSymbol, err := s.symbolRetriever.GetTokenSymbol(tokenContract)
+	if err != nil {
+		return decimal.Decimal{}, decimal.Decimal{}, errors.Wrap(err, "failed to get token symbol")
+	}
+
+	if err := s.oracle.SubscribeSymbols(tokenSymbol); err != nil {
+		return decimal.Decimal{}, decimal.Decimal{}, errors.Wrapf(err, "failed to subscribe to %s", tokenSymbol)
+	}
+
+	usdTokenPrice, err := s.getPrice(tokenSymbol)
+	if err != nil {
+		return decimal.Decimal{}, decimal.Decimal{}, errors.Wrapf(err, "failed to get %s price", tokenSymbol)
+	}
 
+	usdTokenPriceDec, err := decimal.NewFromString(usdTokenPrice.Price.String())
+	if err != nil {
+		return decimal.Decimal{}, decimal.Decimal{}, errors.Wrapf(err, "failed to parse %s price", tokenSymbol)
+	}
+
+	// Decimals (uint8) can be safely casted into int32 because the max uint8 is 255 and the max int32 is 2147483647.
+	return decimal.NewFromBigInt(amount, -int32(decimals)).Mul(usdTokenPriceDec), usdTokenPriceDec, nil
 }
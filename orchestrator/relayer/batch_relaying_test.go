@@ -10,6 +10,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/ethereum/go-ethereum"
@@ -26,10 +27,14 @@ import (
 	"github.com/umee-network/peggo/orchestrator/coingecko"
 	"github.com/umee-network/peggo/orchestrator/ethereum/committer"
 	"github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	"github.com/umee-network/peggo/orchestrator/ethereum/tracker"
+	"github.com/umee-network/peggo/orchestrator/oracle"
 )
 
 type mockOracle struct {
 	prices map[string]sdk.Dec
+	// computedAt is when the prices were computed; they're fresh if it's zero.
+	computedAt time.Time
 }
 
 func (m mockOracle) GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error) {
@@ -40,6 +45,20 @@ func (m mockOracle) GetPrice(baseSymbol string) (sdk.Dec, error) {
 	return m.prices[baseSymbol], nil
 }
 
+func (m mockOracle) GetPriceInfo(baseSymbol string) (oracle.PriceInfo, error) {
+	computedAt := m.computedAt
+	if computedAt.IsZero() {
+		computedAt = time.Now()
+	}
+
+	return oracle.PriceInfo{
+		Price:      m.prices[baseSymbol],
+		ComputedAt: computedAt,
+		Providers:  []string{"mock"},
+		Deviation:  sdk.ZeroDec(),
+	}, nil
+}
+
 func (m mockOracle) SubscribeSymbols(baseSymbols ...string) error {
 	return nil
 }
@@ -53,6 +72,14 @@ func NewMockOracle() Oracle {
 	}
 }
 
+// newStaleMockOracle returns the mock oracle with prices computed age ago.
+func newStaleMockOracle(age time.Duration) Oracle {
+	o := NewMockOracle().(mockOracle)
+	o.computedAt = time.Now().Add(-age)
+
+	return o
+}
+
 func TestIsBatchProfitable(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -161,6 +188,23 @@ func TestIsBatchProfitable(t *testing.T) {
 	)
 
 	assert.False(t, isNotProfitable)
+
+	// Prices older than the max price age can't be used to evaluate a batch.
+	relayer.maxPriceAge = time.Minute
+	relayer.oracle = newStaleMockOracle(2 * time.Minute)
+
+	_, err := relayer.getPrice("ETH")
+	assert.ErrorContains(t, err, "price of ETH is stale: computed 2m0s ago, more than the max price age of 1m0s")
+
+	isStaleProfitable := relayer.IsBatchProfitable(
+		context.Background(),
+		types.OutgoingTxBatch{TokenContract: erc20Address.Hex()},
+		99000,
+		big.NewInt(100),
+		1.1,
+	)
+
+	assert.False(t, isStaleProfitable)
 }
 
 func TestGetBatchesAndSignatures(t *testing.T) {
@@ -429,7 +473,7 @@ func TestRelayBatches(t *testing.T) {
 		mockGravityContract.EXPECT().GetTxBatchNonce(gomock.Any(), gomock.Any(), gomock.Any()).Return(big.NewInt(1), nil)
 		mockGravityContract.EXPECT().EncodeTransactionBatch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil)
 		mockGravityContract.EXPECT().Address().Return(gravityAddress).AnyTimes()
-		mockGravityContract.EXPECT().EstimateGas(gomock.Any(), gomock.Any(), gomock.Any()).Return(uint64(99999), big.NewInt(1), nil)
+		mockGravityContract.EXPECT().EstimateGas(gomock.Any(), gomock.Any(), gomock.Any()).Return(uint64(99999), committer.GasFees{GasPrice: big.NewInt(1)}, nil)
 		mockGravityContract.EXPECT().IsPendingTxInput(gomock.Any(), gomock.Any()).Return(false)
 
 		mockGravityContract.EXPECT().SendTx(
@@ -437,7 +481,7 @@ func TestRelayBatches(t *testing.T) {
 			gravityAddress,
 			[]byte{},
 			uint64(99999),
-			big.NewInt(1),
+			committer.GasFees{GasPrice: big.NewInt(1)},
 		).Return(ethcmn.HexToHash("0x01010101"), nil)
 
 		relayer := gravityRelayer{
@@ -461,7 +505,7 @@ func TestRelayBatches(t *testing.T) {
 
 		err := relayer.RelayBatches(context.Background(), types.Valset{}, possibleBatches)
 		assert.NoError(t, err)
-		assert.Equal(t, uint64(2), relayer.lastSentBatchNonce)
+		assert.Equal(t, uint64(2), relayer.lastSentBatchNonces[ethcmn.HexToAddress("0x0")])
 	})
 
 	t.Run("batch timeout, no error", func(t *testing.T) {
@@ -502,6 +546,194 @@ func TestRelayBatches(t *testing.T) {
 
 		err := relayer.RelayBatches(context.Background(), types.Valset{}, possibleBatches)
 		assert.NoError(t, err)
-		assert.Equal(t, uint64(0), relayer.lastSentBatchNonce)
+		assert.Equal(t, uint64(0), relayer.lastSentBatchNonces[ethcmn.HexToAddress("0x0")])
+	})
+}
+
+func TestRelayBatchesPlan(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+	mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+
+	gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+	fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+	tokenA := ethcmn.HexToAddress("0x0000000000000000000000000000000000000001")
+	tokenB := ethcmn.HexToAddress("0x0000000000000000000000000000000000000002")
+	gasFees := committer.GasFees{GasPrice: big.NewInt(1)}
+
+	ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{Number: big.NewInt(112)}, nil)
+
+	mockGravityContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+	mockGravityContract.EXPECT().Address().Return(gravityAddress).AnyTimes()
+	mockGravityContract.EXPECT().GetTxBatchNonce(gomock.Any(), tokenA, fromAddress).Return(big.NewInt(1), nil)
+	mockGravityContract.EXPECT().GetTxBatchNonce(gomock.Any(), tokenB, fromAddress).Return(big.NewInt(1), nil)
+	mockGravityContract.EXPECT().EncodeTransactionBatch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ types.Valset, batch types.OutgoingTxBatch, _ []types.MsgConfirmBatch) ([]byte, error) {
+			return []byte{byte(batch.BatchNonce)}, nil
+		},
+	).Times(3)
+	mockGravityContract.EXPECT().EstimateGas(gomock.Any(), gravityAddress, gomock.Any()).Return(uint64(1000), gasFees, nil).Times(3)
+	mockGravityContract.EXPECT().IsPendingTxInput(gomock.Any(), gomock.Any()).Return(false).Times(2)
+
+	// Without prices, the newest batch of each token is relayed, in token order.
+	gomock.InOrder(
+		mockGravityContract.EXPECT().SendTx(gomock.Any(), gravityAddress, []byte{3}, uint64(1000), gasFees).
+			Return(ethcmn.HexToHash("0x03"), nil),
+		mockGravityContract.EXPECT().SendTx(gomock.Any(), gravityAddress, []byte{5}, uint64(1000), gasFees).
+			Return(ethcmn.HexToHash("0x05"), nil),
+	)
+
+	relayer := gravityRelayer{
+		logger:          zerolog.Nop(),
+		gravityContract: mockGravityContract,
+		ethProvider:     ethProvider,
+	}
+
+	possibleBatches := map[ethcmn.Address][]SubmittableBatch{
+		tokenB: {
+			{Batch: types.OutgoingTxBatch{BatchTimeout: 113, BatchNonce: 5, TokenContract: tokenB.Hex()}},
+		},
+		tokenA: {
+			{Batch: types.OutgoingTxBatch{BatchTimeout: 113, BatchNonce: 3, TokenContract: tokenA.Hex()}},
+			{Batch: types.OutgoingTxBatch{BatchTimeout: 113, BatchNonce: 2, TokenContract: tokenA.Hex()}},
+		},
+	}
+
+	err := relayer.RelayBatches(context.Background(), types.Valset{}, possibleBatches)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), relayer.lastSentBatchNonces[tokenA])
+	assert.Equal(t, uint64(5), relayer.lastSentBatchNonces[tokenB])
+}
+
+func TestRelayBatchesOutOfNonceOrder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+	mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+
+	gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+	fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+	tokenA := ethcmn.HexToAddress("0x0000000000000000000000000000000000000001")
+	tokenB := ethcmn.HexToAddress("0x0000000000000000000000000000000000000002")
+	gasFees := committer.GasFees{GasPrice: big.NewInt(1)}
+	txA := ethcmn.HexToHash("0x0a")
+	txB := ethcmn.HexToHash("0x0b")
+
+	ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{Number: big.NewInt(112)}, nil)
+
+	mockGravityContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+	mockGravityContract.EXPECT().Address().Return(gravityAddress).AnyTimes()
+	mockGravityContract.EXPECT().GetTxBatchNonce(gomock.Any(), gomock.Any(), fromAddress).Return(big.NewInt(1), nil).Times(2)
+	mockGravityContract.EXPECT().EncodeTransactionBatch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ types.Valset, batch types.OutgoingTxBatch, _ []types.MsgConfirmBatch) ([]byte, error) {
+			return []byte{byte(batch.BatchNonce)}, nil
+		},
+	).Times(2)
+	mockGravityContract.EXPECT().EstimateGas(gomock.Any(), gravityAddress, gomock.Any()).Return(uint64(1000), gasFees, nil).Times(2)
+	mockGravityContract.EXPECT().IsPendingTxInput(gomock.Any(), gomock.Any()).Return(false).Times(2)
+
+	// The batch of tokenA is relayed first, although its nonce is higher than the one of tokenB.
+	gomock.InOrder(
+		mockGravityContract.EXPECT().SendTx(gomock.Any(), gravityAddress, []byte{5}, uint64(1000), gasFees).Return(txA, nil),
+		mockGravityContract.EXPECT().SendTx(gomock.Any(), gravityAddress, []byte{3}, uint64(1000), gasFees).Return(txB, nil),
+	)
+
+	txTracker := &mockTxTracker{}
+	relayer := gravityRelayer{
+		logger:          zerolog.Nop(),
+		gravityContract: mockGravityContract,
+		ethProvider:     ethProvider,
+		txTracker:       txTracker,
+	}
+
+	possibleBatches := map[ethcmn.Address][]SubmittableBatch{
+		tokenA: {{Batch: types.OutgoingTxBatch{BatchTimeout: 113, BatchNonce: 5, TokenContract: tokenA.Hex()}}},
+		tokenB: {{Batch: types.OutgoingTxBatch{BatchTimeout: 113, BatchNonce: 3, TokenContract: tokenB.Hex()}}},
+	}
+
+	err := relayer.RelayBatches(context.Background(), types.Valset{}, possibleBatches)
+	assert.NoError(t, err)
+
+	// The batch of tokenA being confirmed doesn't mark the older batch of tokenB as relayed, so it's retried once its
+	// transaction is dropped.
+	txTracker.onResult[txA](tracker.Result{TxHash: txA, Outcome: tracker.OutcomeConfirmed})
+	txTracker.onResult[txB](tracker.Result{TxHash: txB, Outcome: tracker.OutcomeDropped})
+
+	assert.True(t, relayer.isBatchSent(tokenA, 5))
+	assert.False(t, relayer.isBatchSent(tokenB, 3))
+}
+
+// mockTxTracker stores the callbacks of the tracked transactions, so tests can report their replacements and
+// outcomes.
+type mockTxTracker struct {
+	onReplace map[ethcmn.Hash]tracker.ReplaceFn
+	onResult  map[ethcmn.Hash]tracker.ResultFn
+}
+
+func (m *mockTxTracker) Track(
+	txHash ethcmn.Hash,
+	_ tracker.ObsoleteFn,
+	onReplace tracker.ReplaceFn,
+	onResult tracker.ResultFn,
+) {
+	if m.onResult == nil {
+		m.onReplace = map[ethcmn.Hash]tracker.ReplaceFn{}
+		m.onResult = map[ethcmn.Hash]tracker.ResultFn{}
+	}
+
+	m.onReplace[txHash] = onReplace
+	m.onResult[txHash] = onResult
+}
+
+func (m *mockTxTracker) Check(context.Context) error { return nil }
+
+func (m *mockTxTracker) Len() int { return len(m.onResult) }
+
+func TestTrackBatch(t *testing.T) {
+	tokenContract := ethcmn.HexToAddress("0x2260fac5e5542a773aa44fbcfedf7c193bc2c599")
+	revertedTx := ethcmn.HexToHash("0x01")
+	confirmedTx := ethcmn.HexToHash("0x02")
+
+	t.Run("without tracker", func(t *testing.T) {
+		relayer := &gravityRelayer{logger: zerolog.Nop()}
+
+		relayer.trackBatch(confirmedTx, tokenContract, 2)
+		assert.Equal(t, uint64(2), relayer.lastSentBatchNonces[tokenContract])
+		assert.True(t, relayer.isBatchSent(tokenContract, 2))
+	})
+
+	t.Run("with tracker", func(t *testing.T) {
+		txTracker := &mockTxTracker{}
+		relayer := &gravityRelayer{logger: zerolog.Nop(), txTracker: txTracker}
+
+		relayer.trackBatch(revertedTx, tokenContract, 2)
+		assert.Equal(t, uint64(0), relayer.lastSentBatchNonces[tokenContract])
+		assert.True(t, relayer.isBatchSent(tokenContract, 2))
+
+		// A reverted batch can be relayed again.
+		txTracker.onResult[revertedTx](tracker.Result{TxHash: revertedTx, Outcome: tracker.OutcomeReverted})
+		assert.Equal(t, uint64(0), relayer.lastSentBatchNonces[tokenContract])
+		assert.False(t, relayer.isBatchSent(tokenContract, 2))
+
+		relayer.trackBatch(confirmedTx, tokenContract, 2)
+		txTracker.onResult[confirmedTx](tracker.Result{TxHash: confirmedTx, Outcome: tracker.OutcomeConfirmed})
+		assert.Equal(t, uint64(2), relayer.lastSentBatchNonces[tokenContract])
+		assert.True(t, relayer.isBatchSent(tokenContract, 2))
+	})
+
+	t.Run("per token", func(t *testing.T) {
+		txTracker := &mockTxTracker{}
+		relayer := &gravityRelayer{logger: zerolog.Nop(), txTracker: txTracker}
+		otherTokenContract := ethcmn.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+
+		relayer.trackBatch(confirmedTx, tokenContract, 5)
+		relayer.trackBatch(revertedTx, otherTokenContract, 3)
+
+		// A newer batch of a token being confirmed supersedes the older batches of that token only.
+		txTracker.onResult[confirmedTx](tracker.Result{TxHash: confirmedTx, Outcome: tracker.OutcomeConfirmed})
+		assert.True(t, relayer.isBatchSent(tokenContract, 4))
+		assert.True(t, relayer.isBatchSent(otherTokenContract, 3))
+
+		txTracker.onResult[revertedTx](tracker.Result{TxHash: revertedTx, Outcome: tracker.OutcomeReverted})
+		assert.False(t, relayer.isBatchSent(otherTokenContract, 3))
 	})
 }
@@ -15,11 +15,10 @@ import (
 
 const defaultBlocksToSearch = 2000
 
-// FindLatestValset finds the latest valset on the Gravity contract by looking back through the event
-// history and finding the most recent ValsetUpdatedEvent. Most of the time this will be very fast
-// as the latest update will be in recent blockchain history and the search moves from the present
-// backwards in time. In the case that the validator set has not been updated for a very long time
-// this will take longer.
+// FindLatestValset returns the latest valset on the Gravity contract. The valset is tracked in memory and updated
+// with the ValsetUpdatedEvents emitted since the previous call, then verified against the checkpoint stored on the
+// contract. The event history is only searched backwards when there's no valset tracked yet or the checkpoint doesn't
+// match, e.g. after a reorg.
 func (s *gravityRelayer) FindLatestValset(ctx context.Context) (*types.Valset, error) {
 	latestHeader, err := s.ethProvider.HeaderByNumber(ctx, nil)
 	if err != nil {
@@ -28,6 +27,37 @@ func (s *gravityRelayer) FindLatestValset(ctx context.Context) (*types.Valset, e
 	}
 	currentBlock := latestHeader.Number.Uint64()
 
+	s.valsetTracker.mtx.Lock()
+	defer s.valsetTracker.mtx.Unlock()
+
+	if s.valsetTracker.valset != nil {
+		ok, err := s.updateTrackedValset(ctx, currentBlock)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			return cloneValset(s.valsetTracker.valset), nil
+		}
+	}
+
+	valset, err := s.scanLatestValset(ctx, currentBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	s.valsetTracker.valset = valset
+	s.valsetTracker.lastScannedBlock = currentBlock
+
+	return cloneValset(valset), nil
+}
+
+// scanLatestValset finds the latest valset on the Gravity contract by looking back through the event
+// history and finding the most recent ValsetUpdatedEvent. Most of the time this will be very fast
+// as the latest update will be in recent blockchain history and the search moves from the present
+// backwards in time. In the case that the validator set has not been updated for a very long time
+// this will take longer.
+func (s *gravityRelayer) scanLatestValset(ctx context.Context, currentBlock uint64) (*types.Valset, error) {
 	gravityFilterer, err := wrappers.NewGravityFilterer(s.gravityContract.Address(), s.ethProvider)
 	if err != nil {
 		err = errors.Wrap(err, "failed to init Gravity events filterer")
@@ -89,22 +119,9 @@ func (s *gravityRelayer) FindLatestValset(ctx context.Context) (*types.Valset, e
 				Int("valset_updated_events_num", len(valsetUpdatedEvents)).
 				Msg("found ValsetUpdated events")
 
-			event := valsetUpdatedEvents[0]
-			valset := &types.Valset{
-				Nonce:        event.NewValsetNonce.Uint64(),
-				Members:      make([]types.BridgeValidator, len(event.Powers)),
-				RewardAmount: sdk.NewIntFromBigInt(event.RewardAmount),
-				RewardToken:  event.RewardToken.Hex(),
-			}
-
-			for idx, p := range event.Powers {
-				valset.Members[idx] = types.BridgeValidator{
-					Power:           p.Uint64(),
-					EthereumAddress: event.Validators[idx].Hex(),
-				}
-			}
-
-			s.checkIfValsetsDiffer(cosmosValset.Valset, valset)
+			valset := valsetFromEvent(valsetUpdatedEvents[0])
+
+			s.compareValsets(ctx, cosmosValset.Valset, valset, valsetUpdatedEvents[0])
 			return valset, nil
 		}
 
@@ -116,6 +133,33 @@ func (s *gravityRelayer) FindLatestValset(ctx context.Context) (*types.Valset, e
 
 var ErrNotFound = errors.New("not found")
 
+// valsetFromEvent builds the valset set on the Gravity contract by a ValsetUpdatedEvent.
+func valsetFromEvent(event *wrappers.GravityValsetUpdatedEvent) *types.Valset {
+	valset := &types.Valset{
+		Nonce:        event.NewValsetNonce.Uint64(),
+		Members:      make([]types.BridgeValidator, len(event.Powers)),
+		RewardAmount: sdk.NewIntFromBigInt(event.RewardAmount),
+		RewardToken:  event.RewardToken.Hex(),
+	}
+
+	for idx, p := range event.Powers {
+		valset.Members[idx] = types.BridgeValidator{
+			Power:           p.Uint64(),
+			EthereumAddress: event.Validators[idx].Hex(),
+		}
+	}
+
+	return valset
+}
+
+// cloneValset returns a copy of the valset that can be modified (e.g. sorted) without changing the original one.
+func cloneValset(valset *types.Valset) *types.Valset {
+	clone := *valset
+	clone.Members = append([]types.BridgeValidator(nil), valset.Members...)
+
+	return &clone
+}
+
 type GravityValsetUpdatedEvents []*wrappers.GravityValsetUpdatedEvent
 
 func (a GravityValsetUpdatedEvents) Len() int { return len(a) }
@@ -131,23 +175,18 @@ func (a GravityValsetUpdatedEvents) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 // the loop early once a vote has enough power, if a relayer where to submit things in the reverse order
 // they could grief users of the contract into paying more in gas.
 // The other (and far worse) way a disagreement here could occur is if validators are colluding to steal
-// funds from the Gravity contract and have submitted a hijacking update. If slashing for off Cosmos chain
-// Ethereum signatures is implemented you would put that handler here.
-func (s *gravityRelayer) checkIfValsetsDiffer(cosmosValset, ethereumValset *types.Valset) {
-	if cosmosValset == nil && ethereumValset.Nonce == 0 {
-		// bootstrapping case
-		return
-	} else if cosmosValset == nil {
-		s.logger.Error().
-			Uint64("eth_valset_nonce", ethereumValset.Nonce).
-			Msg("cosmos does not have a valset for nonce from Ethereum chain. Possible bridge hijacking!")
-		return
+// funds from the Gravity contract and have submitted a hijacking update. In that case it returns the
+// reason of the disagreement, so the emergency response can be triggered; it returns an empty string
+// otherwise. A missing Cosmos valset is not a disagreement: a lagging or pruned node doesn't have it either.
+func (s *gravityRelayer) checkIfValsetsDiffer(cosmosValset, ethereumValset *types.Valset) string {
+	if cosmosValset == nil {
+		return ""
 	}
 
 	// Do not check if nonces are not equal.
 	// We queried cosmosValset using ethereumValset.Nonce so it is not necessary.
 	if cosmosValset.Nonce != ethereumValset.Nonce {
-		return
+		return ""
 	}
 
 	if len(cosmosValset.Members) != len(ethereumValset.Members) {
@@ -155,20 +194,26 @@ func (s *gravityRelayer) checkIfValsetsDiffer(cosmosValset, ethereumValset *type
 			Int("eth_valset", len(ethereumValset.Members)).
 			Int("cosmos_valset", len(cosmosValset.Members)).
 			Msg("cosmos and Ethereum Valsets have different length. Possible bridge hijacking!")
-		return
+		return "cosmos and Ethereum valsets have different length"
 	}
 
 	BridgeValidators(cosmosValset.Members).Sort()
 	BridgeValidators(ethereumValset.Members).Sort()
 
+	// Both valsets are sorted the same way, so different members can't be explained by the sorting order.
+	var reason string
 	for idx, member := range cosmosValset.Members {
 		if !strings.EqualFold(ethereumValset.Members[idx].EthereumAddress, member.EthereumAddress) {
 			s.logger.Error().Msg("valsets are different, a sorting error?")
+			reason = "cosmos and Ethereum valsets have different members or powers"
 		}
 		if ethereumValset.Members[idx].Power != member.Power {
 			s.logger.Error().Msg("valsets are different, a sorting error?")
+			reason = "cosmos and Ethereum valsets have different members or powers"
 		}
 	}
+
+	return reason
 }
 
 type BridgeValidators []types.BridgeValidator
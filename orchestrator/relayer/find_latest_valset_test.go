@@ -153,7 +153,7 @@ func TestFindLatestValset(t *testing.T) {
 }
 
 func TestCheckIfValsetsDiffer(t *testing.T) {
-	// this function doesn't return a value. Running different scenarios just to increase code coverage.
+	// It returns the reason the valsets differ if they can't differ just because of their order.
 
 	t.Run("ok", func(t *testing.T) {
 		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
@@ -162,11 +162,14 @@ func TestCheckIfValsetsDiffer(t *testing.T) {
 			logger: logger,
 		}
 
-		relayer.checkIfValsetsDiffer(&types.Valset{}, &types.Valset{})
-		relayer.checkIfValsetsDiffer(nil, &types.Valset{})
-		relayer.checkIfValsetsDiffer(nil, &types.Valset{Nonce: 2})
-		relayer.checkIfValsetsDiffer(&types.Valset{Nonce: 12}, &types.Valset{Nonce: 11})
-		relayer.checkIfValsetsDiffer(&types.Valset{}, &types.Valset{Members: []types.BridgeValidator{{EthereumAddress: "0x0"}}})
+		assert.Empty(t, relayer.checkIfValsetsDiffer(&types.Valset{}, &types.Valset{}))
+		assert.Empty(t, relayer.checkIfValsetsDiffer(nil, &types.Valset{}))
+		assert.Empty(t, relayer.checkIfValsetsDiffer(nil, &types.Valset{Nonce: 2}))
+		assert.Empty(t, relayer.checkIfValsetsDiffer(&types.Valset{Nonce: 12}, &types.Valset{Nonce: 11}))
+		assert.NotEmpty(t, relayer.checkIfValsetsDiffer(
+			&types.Valset{},
+			&types.Valset{Members: []types.BridgeValidator{{EthereumAddress: "0x0"}}},
+		))
 	})
 
 }
@@ -28,6 +28,18 @@ func (s *gravityRelayer) Start(ctx context.Context) error {
 			err           error
 		)
 
+		if s.isHalted() {
+			logger.Error().Msg("relaying is halted by the emergency response to a valset hijack")
+			return nil
+		}
+
+		// Replace or cancel our stuck transactions before sending new ones, as they block every later nonce.
+		if s.txTracker != nil {
+			if err := s.txTracker.Check(ctx); err != nil {
+				logger.Err(err).Msg("failed to check in-flight transactions")
+			}
+		}
+
 		err = retry.Do(func() error {
 			currentValset, err = s.FindLatestValset(ctx)
 			if err != nil {
@@ -43,6 +55,12 @@ func (s *gravityRelayer) Start(ctx context.Context) error {
 			s.logger.Panic().Err(err).Msg("exhausted retries to get latest valset")
 		}
 
+		// The latest valset might have triggered the emergency response.
+		if s.isHalted() {
+			logger.Error().Msg("relaying is halted by the emergency response to a valset hijack")
+			return nil
+		}
+
 		var pg loops.ParanoidGroup
 		if s.valsetRelayMode != ValsetRelayModeNone {
 			pg.Go(func() error {
@@ -79,3 +97,8 @@ func (s *gravityRelayer) Start(ctx context.Context) error {
 		return nil
 	})
 }
+
+// isHalted returns true if relaying was halted by the emergency response.
+func (s *gravityRelayer) isHalted() bool {
+	return s.emergency != nil && s.emergency.Halted()
+}
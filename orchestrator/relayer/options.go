@@ -1,5 +1,14 @@
 package relayer
 
+import (
+	"time"
+
+	"github.com/umee-network/peggo/orchestrator/analytics"
+	"github.com/umee-network/peggo/orchestrator/emergency"
+	"github.com/umee-network/peggo/orchestrator/ethereum/tracker"
+	"github.com/umee-network/peggo/orchestrator/wallet"
+)
+
 func SetSymbolRetriever(coinGecko SymbolRetriever) func(GravityRelayer) {
 	return func(s GravityRelayer) { s.SetSymbolRetriever(coinGecko) }
 }
@@ -17,3 +26,75 @@ func SetOracle(o Oracle) func(GravityRelayer) {
 func (s *gravityRelayer) SetOracle(o Oracle) {
 	s.oracle = o
 }
+
+// SetRelayOutdatedValsets sets whether unprofitable valset updates should be relayed when the Ethereum valset is
+// outdated.
+func SetRelayOutdatedValsets(relay bool) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetRelayOutdatedValsets(relay) }
+}
+
+// SetRelayOutdatedValsets sets whether unprofitable valset updates should be relayed when the Ethereum valset is
+// outdated.
+func (s *gravityRelayer) SetRelayOutdatedValsets(relay bool) {
+	s.relayOutdatedValsets = relay
+}
+
+// SetMaxPriceAge sets the max age of the prices used to evaluate the profit of relays. Zero means there's no limit.
+func SetMaxPriceAge(maxAge time.Duration) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetMaxPriceAge(maxAge) }
+}
+
+// SetMaxPriceAge sets the max age of the prices used to evaluate the profit of relays. Zero means there's no limit.
+func (s *gravityRelayer) SetMaxPriceAge(maxAge time.Duration) {
+	s.maxPriceAge = maxAge
+}
+
+// SetTxTracker sets the tracker of the transactions sent by the Gravity Relayer.
+func SetTxTracker(t tracker.Tracker) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetTxTracker(t) }
+}
+
+// SetTxTracker sets the tracker of the transactions sent by the Gravity Relayer.
+func (s *gravityRelayer) SetTxTracker(t tracker.Tracker) {
+	s.txTracker = t
+}
+
+// SetSpendBudget sets the budget that limits the ETH spent on relays.
+func SetSpendBudget(b wallet.SpendBudget) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetSpendBudget(b) }
+}
+
+// SetSpendBudget sets the budget that limits the ETH spent on relays.
+func (s *gravityRelayer) SetSpendBudget(b wallet.SpendBudget) {
+	s.spendBudget = b
+}
+
+// SetBatchGasBudget sets the maximum gas of the batch relays sent in each loop. Zero means there's no limit.
+func SetBatchGasBudget(gas uint64) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetBatchGasBudget(gas) }
+}
+
+// SetBatchGasBudget sets the maximum gas of the batch relays sent in each loop. Zero means there's no limit.
+func (s *gravityRelayer) SetBatchGasBudget(gas uint64) {
+	s.batchGasBudget = gas
+}
+
+// SetRelayRecorder sets the recorder of the relay transactions sent by the Gravity Relayer.
+func SetRelayRecorder(r analytics.Recorder) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetRelayRecorder(r) }
+}
+
+// SetRelayRecorder sets the recorder of the relay transactions sent by the Gravity Relayer.
+func (s *gravityRelayer) SetRelayRecorder(r analytics.Recorder) {
+	s.relayRecorder = r
+}
+
+// SetEmergency sets the emergency response to a valset on Ethereum that doesn't match the one on Cosmos.
+func SetEmergency(e emergency.Emergency) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetEmergency(e) }
+}
+
+// SetEmergency sets the emergency response to a valset on Ethereum that doesn't match the one on Cosmos.
+func (s *gravityRelayer) SetEmergency(e emergency.Emergency) {
+	s.emergency = e
+}
@@ -1,7 +1,12 @@
 package relayer
 
 import (
+	"fmt"
+	"strings"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/umee-network/peggo/orchestrator/oracle"
 )
 
 // Oracle defines the Oracle interface that the relayer depends on.
@@ -12,7 +17,80 @@ type Oracle interface {
 	// GetPrice returns the price based on the base symbol ex.: UMEE, ETH.
 	GetPrice(baseSymbol string) (sdk.Dec, error)
 
+	// GetPriceInfo returns the price of the base symbol along with when it was computed, the providers it was
+	// computed from and their deviation.
+	GetPriceInfo(baseSymbol string) (oracle.PriceInfo, error)
+
 	// SubscribeSymbols attempts to subscribe the symbols in all the providers.
 	// baseSymbols is the base to be subscribed ex.: ["UMEE", "ATOM"].
 	SubscribeSymbols(baseSymbols ...string) error
 }
+
+// fallbackOracle chains oracles, using the price of the first one that can price a symbol.
+type fallbackOracle struct {
+	oracles []Oracle
+}
+
+// NewFallbackOracle returns an Oracle that tries the given oracles in order, e.g. the price-feeder oracle first and
+// an on-chain DEX oracle for the tokens that aren't listed on exchanges.
+func NewFallbackOracle(oracles ...Oracle) Oracle {
+	return &fallbackOracle{oracles: oracles}
+}
+
+// GetPrices returns the price for the provided base symbols. Each symbol may be priced by a different oracle.
+func (o *fallbackOracle) GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error) {
+	prices := make(map[string]sdk.Dec, len(baseSymbols))
+
+	for _, baseSymbol := range baseSymbols {
+		price, err := o.GetPrice(baseSymbol)
+		if err != nil {
+			return nil, err
+		}
+		prices[baseSymbol] = price
+	}
+
+	return prices, nil
+}
+
+// GetPrice returns the price of the first oracle that can price the symbol.
+func (o *fallbackOracle) GetPrice(baseSymbol string) (sdk.Dec, error) {
+	info, err := o.GetPriceInfo(baseSymbol)
+	if err != nil {
+		return sdk.Dec{}, err
+	}
+
+	return info.Price, nil
+}
+
+// GetPriceInfo returns the price of the first oracle that can price the symbol.
+func (o *fallbackOracle) GetPriceInfo(baseSymbol string) (oracle.PriceInfo, error) {
+	errs := make([]string, 0, len(o.oracles))
+
+	for _, candidate := range o.oracles {
+		info, err := candidate.GetPriceInfo(baseSymbol)
+		if err == nil {
+			return info, nil
+		}
+		errs = append(errs, err.Error())
+	}
+
+	return oracle.PriceInfo{}, fmt.Errorf("error getting price for %s: %s", baseSymbol, strings.Join(errs, "; "))
+}
+
+// SubscribeSymbols subscribes the symbols in all the oracles. It only fails if none of the oracles can subscribe
+// them, since the symbols of long-tail tokens are usually known by a single oracle.
+func (o *fallbackOracle) SubscribeSymbols(baseSymbols ...string) error {
+	errs := make([]string, 0, len(o.oracles))
+
+	for _, candidate := range o.oracles {
+		if err := candidate.SubscribeSymbols(baseSymbols...); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(o.oracles) > 0 && len(errs) == len(o.oracles) {
+		return fmt.Errorf("failed to subscribe symbols %v: %s", baseSymbols, strings.Join(errs, "; "))
+	}
+
+	return nil
+}
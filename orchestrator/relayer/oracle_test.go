@@ -0,0 +1,77 @@
+package relayer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umee-network/peggo/orchestrator/oracle"
+)
+
+// symbolsOracle prices and subscribes a fixed set of symbols.
+type symbolsOracle map[string]sdk.Dec
+
+func (o symbolsOracle) GetPrices(baseSymbols ...string) (map[string]sdk.Dec, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (o symbolsOracle) GetPrice(baseSymbol string) (sdk.Dec, error) {
+	price, ok := o[baseSymbol]
+	if !ok {
+		return sdk.Dec{}, errors.New("unknown symbol")
+	}
+
+	return price, nil
+}
+
+func (o symbolsOracle) GetPriceInfo(baseSymbol string) (oracle.PriceInfo, error) {
+	price, err := o.GetPrice(baseSymbol)
+	if err != nil {
+		return oracle.PriceInfo{}, err
+	}
+
+	return oracle.PriceInfo{Price: price, ComputedAt: time.Now(), Deviation: sdk.ZeroDec()}, nil
+}
+
+func (o symbolsOracle) SubscribeSymbols(baseSymbols ...string) error {
+	for _, baseSymbol := range baseSymbols {
+		if _, ok := o[baseSymbol]; !ok {
+			return errors.New("unknown symbol")
+		}
+	}
+
+	return nil
+}
+
+func TestFallbackOracle(t *testing.T) {
+	cex := symbolsOracle{"ETH": sdk.NewDec(2000), "UMEE": sdk.MustNewDecFromStr("0.01")}
+	dex := symbolsOracle{"ETH": sdk.NewDec(1990), "FOO": sdk.NewDec(3)}
+
+	o := NewFallbackOracle(cex, dex)
+
+	price, err := o.GetPrice("ETH")
+	assert.Nil(t, err)
+	assert.Equal(t, sdk.NewDec(2000), price)
+
+	price, err = o.GetPrice("FOO")
+	assert.Nil(t, err)
+	assert.Equal(t, sdk.NewDec(3), price)
+
+	_, err = o.GetPrice("BAR")
+	assert.Error(t, err)
+
+	prices, err := o.GetPrices("UMEE", "FOO")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]sdk.Dec{"UMEE": sdk.MustNewDecFromStr("0.01"), "FOO": sdk.NewDec(3)}, prices)
+
+	_, err = o.GetPrices("UMEE", "BAR")
+	assert.Error(t, err)
+
+	// Subscribing only fails if no oracle knows the symbols.
+	assert.Nil(t, o.SubscribeSymbols("FOO"))
+	assert.Nil(t, o.SubscribeSymbols("UMEE"))
+	assert.Error(t, o.SubscribeSymbols("BAR"))
+}
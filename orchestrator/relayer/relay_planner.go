@@ -0,0 +1,130 @@
+package relayer
+
+import (
+	"bytes"
+	"sort"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/committer"
+)
+
+// Reasons why the relay planner skips a batch
+const (
+	skipReasonSameToken        = "a more profitable batch of the same token is planned"
+	skipReasonGasBudgetReached = "gas budget of the loop exhausted"
+)
+
+// batchCandidate is a batch that can be relayed, along with its estimated cost and profit.
+type batchCandidate struct {
+	batch               SubmittableBatch
+	tokenContract       ethcmn.Address
+	latestEthereumBatch uint64
+	txData              []byte
+	gasCost             uint64
+	gasFees             committer.GasFees
+
+	// profit is the value in USD of the batch fees minus the gas cost. It's zero if the relayer can't price batches.
+	profit decimal.Decimal
+}
+
+// skippedBatch is a candidate left out of the relay plan.
+type skippedBatch struct {
+	candidate batchCandidate
+	reason    string
+}
+
+// batchRelayPlan holds the batches to relay in a loop, in the order they must be sent, and the ones left out.
+type batchRelayPlan struct {
+	relays  []batchCandidate
+	skipped []skippedBatch
+	gas     uint64
+}
+
+// planBatchRelays ranks the candidates of every token by expected profit and picks the ones to relay. Relaying a
+// batch invalidates the older batches of its token, so at most one batch per token is planned: the most profitable
+// one. Candidates are planned in descending profit order until the gas budget runs out; a zero budget means there's
+// no limit. Ties are broken by token address and batch nonce (newer first), so the plan is deterministic and, when
+// the relayer can't price batches, the newest batch of each token is relayed, as it clears the older ones in a single
+// tx. Batch nonces are shared by all tokens, so the batches of different tokens are often relayed out of nonce order;
+// the relayer tracks them per token for that reason.
+func planBatchRelays(candidates []batchCandidate, gasBudget uint64) batchRelayPlan {
+	ranked := make([]batchCandidate, len(candidates))
+	copy(ranked, candidates)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+
+		if cmp := a.profit.Cmp(b.profit); cmp != 0 {
+			return cmp > 0
+		}
+
+		if cmp := bytes.Compare(a.tokenContract.Bytes(), b.tokenContract.Bytes()); cmp != 0 {
+			return cmp < 0
+		}
+
+		return a.batch.Batch.BatchNonce > b.batch.Batch.BatchNonce
+	})
+
+	var (
+		plan            batchRelayPlan
+		plannedTokens   = map[ethcmn.Address]struct{}{}
+		budgetExhausted bool
+	)
+
+	for _, candidate := range ranked {
+		if _, ok := plannedTokens[candidate.tokenContract]; ok {
+			plan.skipped = append(plan.skipped, skippedBatch{candidate: candidate, reason: skipReasonSameToken})
+			continue
+		}
+
+		// Once a batch doesn't fit, we stop planning so cheaper but less profitable batches don't jump the queue.
+		if budgetExhausted || (gasBudget > 0 && plan.gas+candidate.gasCost > gasBudget) {
+			budgetExhausted = true
+			plan.skipped = append(plan.skipped, skippedBatch{candidate: candidate, reason: skipReasonGasBudgetReached})
+			continue
+		}
+
+		plannedTokens[candidate.tokenContract] = struct{}{}
+		plan.relays = append(plan.relays, candidate)
+		plan.gas += candidate.gasCost
+	}
+
+	return plan
+}
+
+// logBatchRelayPlan logs the relay plan, explaining why every candidate was planned or skipped.
+func (s *gravityRelayer) logBatchRelayPlan(plan batchRelayPlan) {
+	if len(plan.relays) == 0 && len(plan.skipped) == 0 {
+		return
+	}
+
+	s.logger.Info().
+		Int("planned_batches", len(plan.relays)).
+		Int("skipped_batches", len(plan.skipped)).
+		Uint64("planned_gas", plan.gas).
+		Uint64("gas_budget", s.batchGasBudget).
+		Msg("planned batch relays")
+
+	for i, relay := range plan.relays {
+		s.logger.Info().
+			Int("rank", i+1).
+			Str("token_contract", relay.tokenContract.Hex()).
+			Uint64("batch_nonce", relay.batch.Batch.BatchNonce).
+			Str("expected_profit_usd", relay.profit.StringFixed(2)).
+			Uint64("gas_cost", relay.gasCost).
+			Str("gas_price", relay.gasFees.EffectiveGasPrice().String()).
+			Msg("batch relay planned")
+	}
+
+	for _, skipped := range plan.skipped {
+		s.logger.Debug().
+			Str("token_contract", skipped.candidate.tokenContract.Hex()).
+			Uint64("batch_nonce", skipped.candidate.batch.Batch.BatchNonce).
+			Str("expected_profit_usd", skipped.candidate.profit.StringFixed(2)).
+			Uint64("gas_cost", skipped.candidate.gasCost).
+			Str("reason", skipped.reason).
+			Msg("batch relay skipped")
+	}
+}
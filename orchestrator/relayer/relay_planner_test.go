@@ -0,0 +1,88 @@
+package relayer
+
+import (
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanBatchRelays(t *testing.T) {
+	tokenA := ethcmn.HexToAddress("0x0000000000000000000000000000000000000001")
+	tokenB := ethcmn.HexToAddress("0x0000000000000000000000000000000000000002")
+	tokenC := ethcmn.HexToAddress("0x0000000000000000000000000000000000000003")
+
+	candidate := func(token ethcmn.Address, nonce uint64, profit int64, gasCost uint64) batchCandidate {
+		return batchCandidate{
+			batch:         SubmittableBatch{Batch: types.OutgoingTxBatch{BatchNonce: nonce, TokenContract: token.Hex()}},
+			tokenContract: token,
+			gasCost:       gasCost,
+			profit:        decimal.NewFromInt(profit),
+		}
+	}
+
+	// planned returns the token and nonce of the planned batches, in order.
+	planned := func(plan batchRelayPlan) [][2]interface{} {
+		var res [][2]interface{}
+		for _, relay := range plan.relays {
+			res = append(res, [2]interface{}{relay.tokenContract, relay.batch.Batch.BatchNonce})
+		}
+
+		return res
+	}
+
+	t.Run("descending profit, one batch per token", func(t *testing.T) {
+		plan := planBatchRelays([]batchCandidate{
+			candidate(tokenA, 1, 10, 100),
+			candidate(tokenA, 2, 30, 100),
+			candidate(tokenB, 5, 20, 100),
+			candidate(tokenC, 7, 40, 100),
+		}, 0)
+
+		assert.Equal(t, [][2]interface{}{{tokenC, uint64(7)}, {tokenA, uint64(2)}, {tokenB, uint64(5)}}, planned(plan))
+		assert.Equal(t, uint64(300), plan.gas)
+		assert.Len(t, plan.skipped, 1)
+		assert.Equal(t, uint64(1), plan.skipped[0].candidate.batch.Batch.BatchNonce)
+		assert.Equal(t, skipReasonSameToken, plan.skipped[0].reason)
+	})
+
+	t.Run("ties are broken by token and nonce", func(t *testing.T) {
+		plan := planBatchRelays([]batchCandidate{
+			candidate(tokenB, 4, 0, 100),
+			candidate(tokenA, 3, 0, 200),
+			candidate(tokenA, 2, 0, 300),
+		}, 0)
+
+		// Without prices, the newest batch of each token is relayed, so the older ones don't cost another tx.
+		assert.Equal(t, [][2]interface{}{{tokenA, uint64(3)}, {tokenB, uint64(4)}}, planned(plan))
+		assert.Equal(t, uint64(2), plan.skipped[0].candidate.batch.Batch.BatchNonce)
+		assert.Equal(t, skipReasonSameToken, plan.skipped[0].reason)
+	})
+
+	t.Run("profit order across tokens isn't nonce order", func(t *testing.T) {
+		plan := planBatchRelays([]batchCandidate{
+			candidate(tokenA, 3, 10, 100),
+			candidate(tokenB, 8, 50, 100),
+		}, 0)
+
+		assert.Equal(t, [][2]interface{}{{tokenB, uint64(8)}, {tokenA, uint64(3)}}, planned(plan))
+	})
+
+	t.Run("gas budget", func(t *testing.T) {
+		plan := planBatchRelays([]batchCandidate{
+			candidate(tokenA, 1, 30, 100),
+			candidate(tokenB, 2, 20, 300),
+			candidate(tokenC, 3, 10, 50),
+		}, 350)
+
+		// The batch of tokenC would fit, but it's less profitable than the one that ran out of budget.
+		assert.Equal(t, [][2]interface{}{{tokenA, uint64(1)}}, planned(plan))
+		assert.Equal(t, uint64(100), plan.gas)
+		assert.Len(t, plan.skipped, 2)
+		for _, skipped := range plan.skipped {
+			assert.Equal(t, skipReasonGasBudgetReached, skipped.reason)
+		}
+	})
+}
@@ -2,20 +2,32 @@ package relayer
 
 import (
 	"context"
+	"math/big"
+	"sync"
 	"time"
 
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 
+	"github.com/umee-network/peggo/orchestrator/analytics"
+	"github.com/umee-network/peggo/orchestrator/emergency"
 	gravity "github.com/umee-network/peggo/orchestrator/ethereum/gravity"
 	"github.com/umee-network/peggo/orchestrator/ethereum/provider"
+	"github.com/umee-network/peggo/orchestrator/ethereum/tracker"
+	"github.com/umee-network/peggo/orchestrator/wallet"
 
 	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 )
 
 const ethBlocksValsetOutdated = uint64(2000)
 
+// Relay kinds recorded in the spend ledger and the relay analytics
+const (
+	relayKindBatch  = analytics.KindBatch
+	relayKindValset = analytics.KindValset
+)
+
 // ValsetRelayMode defines an enumerated validator set relay mode.
 type ValsetRelayMode int64
 
@@ -24,11 +36,12 @@ const (
 	ValsetRelayModeNone ValsetRelayMode = iota
 	ValsetRelayModeMinimum
 	ValsetRelayModeAll
+	ValsetRelayModeProfitable
 )
 
 // String gets the string representation of the validator set relay mode.
 func (d ValsetRelayMode) String() string {
-	return [...]string{"none", "minimum", "all"}[d]
+	return [...]string{"none", "minimum", "all", "profitable"}[d]
 }
 
 type GravityRelayer interface {
@@ -51,7 +64,33 @@ type GravityRelayer interface {
 	// batch calculations.
 	SetOracle(Oracle)
 
+	// SetRelayOutdatedValsets sets whether unprofitable valset updates should still be relayed when the valset on
+	// Ethereum is outdated. Only used by the profitable valset relay mode.
+	SetRelayOutdatedValsets(bool)
+
+	// SetTxTracker sets the tracker used to replace or cancel relayed transactions that get stuck in the mempool.
+	SetTxTracker(tracker.Tracker)
+
+	// SetSpendBudget sets the budget that limits the ETH spent on relays within a rolling period.
+	SetSpendBudget(wallet.SpendBudget)
+
+	// SetBatchGasBudget sets the maximum gas of the batch relays sent in each loop.
+	SetBatchGasBudget(uint64)
+
+	// SetRelayRecorder sets the recorder of the relay transactions we send, used by the relay analytics.
+	SetRelayRecorder(analytics.Recorder)
+
+	// SetEmergency sets the emergency response to a valset on Ethereum that doesn't match the one on Cosmos, which
+	// may halt the relayer.
+	SetEmergency(emergency.Emergency)
+
+	// SetMaxPriceAge sets the max age of the prices used to evaluate the profit of relays.
+	SetMaxPriceAge(time.Duration)
+
 	GetProfitMultiplier() float64
+
+	// GetMaxPriceAge returns the max age of the prices used to evaluate the profit of relays and batch requests.
+	GetMaxPriceAge() time.Duration
 }
 
 type gravityRelayer struct {
@@ -67,10 +106,41 @@ type gravityRelayer struct {
 	symbolRetriever   SymbolRetriever
 	oracle            Oracle
 
+	// maxPriceAge is the max age of the oracle prices used to evaluate the profit of relays; older prices make the
+	// relays get skipped. Zero means there's no limit.
+	maxPriceAge time.Duration
+
+	// relayOutdatedValsets overrides the profitability check of valset updates when the valset on Ethereum is
+	// outdated, so the bridge doesn't get stuck with an old valset.
+	relayOutdatedValsets bool
+
+	// txTracker keeps track of the transactions we sent, replacing the ones that get stuck.
+	txTracker tracker.Tracker
+
+	// spendBudget limits the ETH spent on relays and keeps a ledger of the gas spent by each one.
+	spendBudget wallet.SpendBudget
+
+	// batchGasBudget is the maximum gas of the batch relays planned in each loop; zero means there's no limit.
+	batchGasBudget uint64
+
+	// relayRecorder records our relay attempts and their outcomes, to find out how many are wasted.
+	relayRecorder analytics.Recorder
+
+	// emergency responds to valsets on Ethereum that don't match the ones on Cosmos; relaying stops when it's halted.
+	emergency emergency.Emergency
+
+	// valsetTracker keeps the current valset on Ethereum, updated with the new ValsetUpdatedEvents on each loop.
+	valsetTracker valsetTracker
+
 	// Store locally the last tx this validator made to avoid sending duplicates
-	// or invalid txs.
-	lastSentBatchNonce         uint64
+	// or invalid txs. When a tx tracker is set, the nonces are only stored once the tx is confirmed (or someone else
+	// relayed the same item), while pending ones are kept apart so they can be retried if the tx fails. Batch nonces
+	// are stored per token contract, since relaying a batch only supersedes the older batches of its own token.
+	mtx                        sync.Mutex
+	lastSentBatchNonces        map[ethcmn.Address]uint64
 	lastSentValsetNonce        uint64
+	pendingBatchNonces         map[ethcmn.Address]map[uint64]struct{}
+	pendingValsetNonce         uint64
 	latestValsetEthBlockNumber uint64
 }
 
@@ -108,6 +178,10 @@ func (s *gravityRelayer) GetProfitMultiplier() float64 {
 	return s.profitMultiplier
 }
 
+func (s *gravityRelayer) GetMaxPriceAge() time.Duration {
+	return s.maxPriceAge
+}
+
 // UpdateLatestValsetEthBlockNumber only updates the last valset eth block number
 // if the number is bigger than the one already stored in memory
 func (s *gravityRelayer) UpdateLatestValsetEthBlockNumber(lastestValsetEthBlockNumber uint64) {
@@ -140,3 +214,111 @@ func (s *gravityRelayer) IsLastestValsetUpdateOutdated(ctx context.Context) bool
 
 	return (currentBlock - s.latestValsetEthBlockNumber) > ethBlocksValsetOutdated
 }
+
+// logRelayResult logs the final outcome of a relay transaction.
+func (s *gravityRelayer) logRelayResult(result tracker.Result, nonceKey string, nonce uint64) {
+	var event *zerolog.Event
+
+	switch result.Outcome {
+	case tracker.OutcomeReverted:
+		event = s.logger.Error()
+	case tracker.OutcomeDropped:
+		event = s.logger.Warn()
+	default:
+		event = s.logger.Info()
+	}
+
+	event = event.
+		Str("tx_hash", result.TxHash.Hex()).
+		Uint64(nonceKey, nonce).
+		Stringer("outcome", result.Outcome)
+
+	if result.RevertReason != "" {
+		event = event.Str("revert_reason", result.RevertReason)
+	}
+
+	event.Msg("relay transaction finished")
+}
+
+// isWithinSpendBudget returns true if a relay sent with the given gas limit and price fits the spend budget.
+func (s *gravityRelayer) isWithinSpendBudget(gasLimit uint64, gasPrice *big.Int) bool {
+	if s.spendBudget == nil {
+		return true
+	}
+
+	cost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit))
+	if s.spendBudget.Allow(cost) {
+		return true
+	}
+
+	s.logger.Warn().
+		Str("cost_wei", cost.String()).
+		Str("spent_wei", s.spendBudget.Spent().String()).
+		Str("budget_wei", s.spendBudget.Limit().String()).
+		Msg("relay would exceed the ETH spend budget; skipping")
+
+	return false
+}
+
+// recordSpend adds a relay transaction to the spend ledger.
+func (s *gravityRelayer) recordSpend(txHash ethcmn.Hash, kind string, nonce, gasLimit uint64, gasPrice *big.Int) {
+	if s.spendBudget == nil {
+		return
+	}
+
+	if err := s.spendBudget.Record(txHash, kind, nonce, gasLimit, gasPrice); err != nil {
+		s.logger.Err(err).Str("tx_hash", txHash.Hex()).Msg("failed to record relay in the spend ledger")
+	}
+}
+
+// recordReplacementSpend adds a transaction the tx tracker sent to replace or cancel a relay transaction to the spend
+// ledger, at the effective gas price it was sent with.
+func (s *gravityRelayer) recordReplacementSpend(txHash ethcmn.Hash, replacement tracker.Replacement) {
+	if s.spendBudget == nil {
+		return
+	}
+
+	err := s.spendBudget.RecordReplacement(txHash, replacement.TxHash, replacement.GasLimit, replacement.GasPrice)
+	if err != nil {
+		s.logger.Err(err).
+			Str("tx_hash", replacement.TxHash.Hex()).
+			Str("relay_tx_hash", txHash.Hex()).
+			Msg("failed to record relay replacement in the spend ledger")
+	}
+}
+
+// settleSpend updates the spend ledger with the gas used by a relay transaction (or the replacement that was mined)
+// that reached a final outcome.
+func (s *gravityRelayer) settleSpend(txHash ethcmn.Hash, result tracker.Result) {
+	if s.spendBudget == nil {
+		return
+	}
+
+	if err := s.spendBudget.Settle(txHash, result.TxHash, result.GasUsed); err != nil {
+		s.logger.Err(err).Str("tx_hash", txHash.Hex()).Msg("failed to settle relay in the spend ledger")
+	}
+}
+
+// recordAttempt adds a relay transaction to the relay analytics.
+func (s *gravityRelayer) recordAttempt(
+	txHash ethcmn.Hash,
+	kind string,
+	tokenContract ethcmn.Address,
+	nonce uint64,
+	gasPrice *big.Int,
+) {
+	if s.relayRecorder == nil {
+		return
+	}
+
+	s.relayRecorder.RecordAttempt(kind, tokenContract, nonce, txHash, gasPrice)
+}
+
+// settleAttempt records the final outcome of a relay transaction in the relay analytics.
+func (s *gravityRelayer) settleAttempt(txHash ethcmn.Hash, result tracker.Result) {
+	if s.relayRecorder == nil {
+		return
+	}
+
+	s.relayRecorder.SettleAttempt(txHash, result)
+}
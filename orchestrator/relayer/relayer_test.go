@@ -1,16 +1,21 @@
 package relayer
 
 import (
+	"math/big"
 	"os"
 	"testing"
 	"time"
 
+	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/golang/mock/gomock"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/umee-network/peggo/mocks"
 	gravityMocks "github.com/umee-network/peggo/mocks/gravity"
+	"github.com/umee-network/peggo/orchestrator/analytics"
 	"github.com/umee-network/peggo/orchestrator/ethereum/provider"
+	"github.com/umee-network/peggo/orchestrator/ethereum/tracker"
+	"github.com/umee-network/peggo/orchestrator/wallet"
 )
 
 func TestNewGravityRelayer(t *testing.T) {
@@ -35,3 +40,83 @@ func TestNewGravityRelayer(t *testing.T) {
 
 	assert.NotNil(t, relayer)
 }
+
+func TestSpendBudget(t *testing.T) {
+	spendBudget, err := wallet.NewSpendBudget(zerolog.Nop(), big.NewInt(1000000), 24*time.Hour, "")
+	assert.Nil(t, err)
+
+	txTracker := &mockTxTracker{}
+	relayer := &gravityRelayer{logger: zerolog.Nop(), txTracker: txTracker, spendBudget: spendBudget}
+	tokenContract := ethcmn.HexToAddress("0x2260fac5e5542a773aa44fbcfedf7c193bc2c599")
+	txHash := ethcmn.HexToHash("0x01")
+
+	assert.True(t, relayer.isWithinSpendBudget(100000, big.NewInt(10)))
+	assert.False(t, relayer.isWithinSpendBudget(100001, big.NewInt(10)))
+
+	// The estimated cost is recorded when the tx is sent.
+	relayer.recordSpend(txHash, relayKindBatch, 2, 60000, big.NewInt(10))
+	relayer.trackBatch(txHash, tokenContract, 2)
+	assert.Equal(t, big.NewInt(600000), spendBudget.Spent())
+	assert.False(t, relayer.isWithinSpendBudget(60000, big.NewInt(10)))
+
+	// And settled with the gas used once the tx is mined.
+	txTracker.onResult[txHash](tracker.Result{TxHash: txHash, Outcome: tracker.OutcomeConfirmed, GasUsed: 40000})
+	assert.Equal(t, big.NewInt(400000), spendBudget.Spent())
+	assert.True(t, relayer.isWithinSpendBudget(60000, big.NewInt(10)))
+}
+
+func TestSpendBudgetReplacements(t *testing.T) {
+	spendBudget, err := wallet.NewSpendBudget(zerolog.Nop(), big.NewInt(10000000), 24*time.Hour, "")
+	assert.Nil(t, err)
+
+	txTracker := &mockTxTracker{}
+	relayer := &gravityRelayer{logger: zerolog.Nop(), txTracker: txTracker, spendBudget: spendBudget}
+	txHash := ethcmn.HexToHash("0x01")
+	replacementHash := ethcmn.HexToHash("0x02")
+	cancelHash := ethcmn.HexToHash("0x03")
+
+	relayer.recordSpend(txHash, relayKindValset, 3, 60000, big.NewInt(10))
+	relayer.trackValset(txHash, 3)
+
+	// Only the last tx sent for the relay counts, at the price it was sent with.
+	txTracker.onReplace[txHash](tracker.Replacement{
+		OldTxHash: txHash,
+		TxHash:    replacementHash,
+		GasLimit:  60000,
+		GasPrice:  big.NewInt(12),
+	})
+	assert.Equal(t, big.NewInt(720000), spendBudget.Spent())
+
+	txTracker.onReplace[txHash](tracker.Replacement{
+		OldTxHash: replacementHash,
+		TxHash:    cancelHash,
+		GasLimit:  21000,
+		GasPrice:  big.NewInt(15),
+		Cancel:    true,
+	})
+	assert.Equal(t, big.NewInt(315000), spendBudget.Spent())
+
+	// The cancellation is mined.
+	txTracker.onResult[txHash](tracker.Result{TxHash: cancelHash, Outcome: tracker.OutcomeSuperseded, GasUsed: 21000})
+	assert.Equal(t, big.NewInt(315000), spendBudget.Spent())
+}
+
+func TestRelayRecorder(t *testing.T) {
+	relayAnalytics, err := analytics.NewRelayAnalytics(zerolog.Nop(), nil, ethcmn.Address{}, ethcmn.Address{})
+	assert.Nil(t, err)
+
+	txTracker := &mockTxTracker{}
+	relayer := &gravityRelayer{logger: zerolog.Nop(), txTracker: txTracker, relayRecorder: relayAnalytics}
+	txHash := ethcmn.HexToHash("0x01")
+
+	relayer.recordAttempt(txHash, relayKindValset, ethcmn.Address{}, 3, big.NewInt(10))
+	relayer.trackValset(txHash, 3)
+
+	// A reverted relay is a wasted attempt.
+	txTracker.onResult[txHash](tracker.Result{TxHash: txHash, Outcome: tracker.OutcomeReverted, GasUsed: 40000})
+
+	stats := relayAnalytics.Stats().Kinds[analytics.KindValset]
+	assert.Equal(t, uint64(1), stats.Attempts)
+	assert.Equal(t, uint64(1), stats.WastedAttempts)
+	assert.Equal(t, big.NewInt(400000), stats.WastedWei)
+}
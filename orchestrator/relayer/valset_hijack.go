@@ -0,0 +1,102 @@
+package relayer
+
+import (
+	"context"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/umee-network/peggo/orchestrator/emergency"
+	gravity "github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+// compareValsets compares a valset found on Ethereum with the valset of the same nonce on Cosmos, triggering the
+// emergency response if they differ. The members are compared sorted, but the Ethereum valset keeps the order of
+// the event, as it's the one the checkpoint on the contract was made with. If Cosmos doesn't have the valset, it's
+// queried again, and the valsets are only compared if it's found: the node might be lagging or pruned.
+func (s *gravityRelayer) compareValsets(
+	ctx context.Context,
+	cosmosValset *types.Valset,
+	ethereumValset *types.Valset,
+	event *wrappers.GravityValsetUpdatedEvent,
+) {
+	if cosmosValset == nil && ethereumValset.Nonce != 0 {
+		cosmosValset = s.requeryCosmosValset(ctx, ethereumValset.Nonce)
+		if cosmosValset == nil {
+			return
+		}
+	}
+
+	reason := s.checkIfValsetsDiffer(cosmosValset, cloneValset(ethereumValset))
+	if reason == "" || s.emergency == nil {
+		return
+	}
+
+	evidence := emergency.Evidence{
+		Reason:         reason,
+		EthereumValset: cloneValset(ethereumValset),
+		CosmosValset:   cosmosValset,
+		EventTxHash:    event.Raw.TxHash,
+		EventBlock:     event.Raw.BlockNumber,
+	}
+
+	sigs, err := s.valsetUpdateSignatures(ctx, event.Raw.TxHash)
+	if err != nil {
+		s.logger.Err(err).Str("tx_hash", event.Raw.TxHash.Hex()).Msg("failed to get the signatures of the valset update")
+		evidence.SignaturesError = err.Error()
+	}
+	evidence.Signatures = sigs
+
+	if err := s.emergency.Trigger(ctx, evidence); err != nil {
+		s.logger.Err(err).Msg("failed to respond to the valset mismatch")
+	}
+}
+
+// requeryCosmosValset queries the valset of the nonce again, once Cosmos didn't have it. It returns nil if Cosmos
+// still doesn't have it, as the valsets can't be compared then.
+func (s *gravityRelayer) requeryCosmosValset(ctx context.Context, nonce uint64) *types.Valset {
+	res, err := s.cosmosQueryClient.ValsetRequest(ctx, &types.QueryValsetRequestRequest{Nonce: nonce})
+	if err != nil || res == nil || res.Valset == nil {
+		s.logger.Warn().
+			Err(err).
+			Uint64("eth_valset_nonce", nonce).
+			Msg("cosmos does not have the valset of the nonce from Ethereum, the node might be lagging or pruned; " +
+				"skipping the valset comparison")
+		return nil
+	}
+
+	return res.Valset
+}
+
+// valsetUpdateSignatures returns the signatures submitted with an updateValset tx, along with the members of the
+// previous valset who made them.
+func (s *gravityRelayer) valsetUpdateSignatures(ctx context.Context, txHash ethcmn.Hash) ([]emergency.Signature, error) {
+	tx, _, err := s.ethProvider.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get valset update tx")
+	}
+
+	currentValset, sigs, err := gravity.DecodeValsetUpdate(tx.Data())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sigs) != len(currentValset.Validators) || len(sigs) != len(currentValset.Powers) {
+		return nil, errors.New("the number of signatures doesn't match the number of validators")
+	}
+
+	signatures := make([]emergency.Signature, len(sigs))
+	for i, sig := range sigs {
+		signatures[i] = emergency.Signature{
+			Validator: currentValset.Validators[i],
+			Power:     currentValset.Powers[i].Uint64(),
+			V:         sig.V,
+			R:         sig.R,
+			S:         sig.S,
+		}
+	}
+
+	return signatures, nil
+}
@@ -0,0 +1,152 @@
+package relayer
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umee-network/peggo/mocks"
+	"github.com/umee-network/peggo/orchestrator/emergency"
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+// recordingEmergency records the evidence it's triggered with.
+type recordingEmergency struct {
+	evidence []emergency.Evidence
+}
+
+func (e *recordingEmergency) Trigger(_ context.Context, evidence emergency.Evidence) error {
+	e.evidence = append(e.evidence, evidence)
+	return nil
+}
+
+func (e *recordingEmergency) Halted() bool {
+	return len(e.evidence) > 0
+}
+
+func TestCompareValsets(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+
+	validatorA := ethcmn.HexToAddress("0x00000000000000000000000000000000000000a1")
+	validatorB := ethcmn.HexToAddress("0x00000000000000000000000000000000000000b1")
+	attacker := ethcmn.HexToAddress("0x00000000000000000000000000000000000000e1")
+
+	cosmosValset := &types.Valset{
+		Nonce: 2,
+		Members: []types.BridgeValidator{
+			{Power: 3000000000, EthereumAddress: validatorA.Hex()},
+			{Power: 1294967295, EthereumAddress: validatorB.Hex()},
+		},
+		RewardAmount: sdk.ZeroInt(),
+	}
+
+	ethereumValset := &types.Valset{
+		Nonce: 2,
+		Members: []types.BridgeValidator{
+			{Power: 3000000000, EthereumAddress: attacker.Hex()},
+			{Power: 1294967295, EthereumAddress: validatorB.Hex()},
+		},
+		RewardAmount: sdk.ZeroInt(),
+	}
+
+	gravityABI, err := wrappers.GravityMetaData.GetAbi()
+	assert.Nil(t, err)
+
+	sigA := wrappers.Signature{V: 27, R: ethcmn.HexToHash("0x02"), S: ethcmn.HexToHash("0x03")}
+	txData, err := gravityABI.Pack("updateValset",
+		wrappers.ValsetArgs{
+			Validators:   []ethcmn.Address{attacker, validatorB},
+			Powers:       []*big.Int{big.NewInt(3000000000), big.NewInt(1294967295)},
+			ValsetNonce:  big.NewInt(2),
+			RewardAmount: big.NewInt(0),
+		},
+		wrappers.ValsetArgs{
+			Validators:   []ethcmn.Address{validatorA, validatorB},
+			Powers:       []*big.Int{big.NewInt(3000000000), big.NewInt(1294967295)},
+			ValsetNonce:  big.NewInt(1),
+			RewardAmount: big.NewInt(0),
+		},
+		[]wrappers.Signature{sigA, {}},
+	)
+	assert.Nil(t, err)
+
+	txHash := ethcmn.HexToHash("0x01")
+	ethProvider.EXPECT().TransactionByHash(gomock.Any(), txHash).
+		Return(ethtypes.NewTx(&ethtypes.LegacyTx{Data: txData}), false, nil)
+
+	em := &recordingEmergency{}
+	relayer := gravityRelayer{
+		logger:      zerolog.Nop(),
+		ethProvider: ethProvider,
+		emergency:   em,
+	}
+
+	event := &wrappers.GravityValsetUpdatedEvent{Raw: ethtypes.Log{TxHash: txHash, BlockNumber: 100}}
+
+	// Matching valsets don't trigger the emergency response.
+	relayer.compareValsets(context.Background(), cloneValset(cosmosValset), cloneValset(cosmosValset), event)
+	assert.False(t, relayer.isHalted())
+
+	relayer.compareValsets(context.Background(), cosmosValset, ethereumValset, event)
+	assert.True(t, relayer.isHalted())
+
+	assert.Equal(t, []emergency.Evidence{{
+		Reason:         "cosmos and Ethereum valsets have different members or powers",
+		EthereumValset: ethereumValset,
+		CosmosValset:   cosmosValset,
+		EventTxHash:    txHash,
+		EventBlock:     100,
+		Signatures: []emergency.Signature{
+			{Validator: validatorA, Power: 3000000000, V: 27, R: sigA.R, S: sigA.S},
+			{Validator: validatorB, Power: 1294967295},
+		},
+	}}, em.evidence)
+
+	// The Ethereum valset keeps the order of the event.
+	assert.Equal(t, attacker.Hex(), ethereumValset.Members[0].EthereumAddress)
+}
+
+func TestCompareValsetsMissingCosmosValset(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockQClient := mocks.NewMockQueryClient(mockCtrl)
+
+	ethereumValset := &types.Valset{
+		Nonce:        2,
+		Members:      []types.BridgeValidator{{Power: 4294967295, EthereumAddress: ethcmn.HexToAddress("0xa1").Hex()}},
+		RewardAmount: sdk.ZeroInt(),
+	}
+
+	em, err := emergency.NewEmergency(zerolog.Nop(), emergency.OptionAction(emergency.ActionHalt))
+	assert.Nil(t, err)
+
+	relayer := gravityRelayer{
+		logger:            zerolog.Nop(),
+		cosmosQueryClient: mockQClient,
+		emergency:         em,
+	}
+
+	event := &wrappers.GravityValsetUpdatedEvent{Raw: ethtypes.Log{TxHash: ethcmn.HexToHash("0x01"), BlockNumber: 100}}
+
+	// A lagging or pruned Cosmos node doesn't have the valset, even when it's queried again.
+	mockQClient.EXPECT().ValsetRequest(gomock.Any(), &types.QueryValsetRequestRequest{Nonce: 2}).
+		Return(&types.QueryValsetRequestResponse{}, nil)
+
+	relayer.compareValsets(context.Background(), nil, cloneValset(ethereumValset), event)
+	assert.False(t, relayer.isHalted())
+
+	// Once the node has caught up, the valsets are compared.
+	mockQClient.EXPECT().ValsetRequest(gomock.Any(), &types.QueryValsetRequestRequest{Nonce: 2}).
+		Return(&types.QueryValsetRequestResponse{Valset: cloneValset(ethereumValset)}, nil)
+
+	relayer.compareValsets(context.Background(), nil, cloneValset(ethereumValset), event)
+	assert.False(t, relayer.isHalted())
+}
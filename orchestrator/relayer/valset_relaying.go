@@ -2,9 +2,14 @@ package relayer
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/tracker"
 )
 
 // RelayValsets checks the last validator set on Ethereum, if it's lower than our latest validator
@@ -42,7 +47,7 @@ func (s *gravityRelayer) RelayValsets(ctx context.Context, currentValset types.V
 		return nil
 	}
 
-	if s.lastSentValsetNonce >= latestValidValset.Nonce {
+	if s.isValsetSent(latestValidValset.Nonce) {
 		s.logger.Debug().Msg("already relayed this valset; skipping")
 		return nil
 	}
@@ -92,15 +97,27 @@ func (s *gravityRelayer) RelayValsets(ctx context.Context, currentValset types.V
 		return nil
 	}
 
-	estimatedGasCost, gasPrice, err := s.gravityContract.EstimateGas(ctx, s.gravityContract.Address(), txData)
+	estimatedGasCost, gasFees, err := s.gravityContract.EstimateGas(ctx, s.gravityContract.Address(), txData)
 	if err != nil {
 		s.logger.Err(err).Msg("failed to estimate gas cost of ValsetUpdate")
 		return nil
 	}
 
-	// TODO: Estimate profitability using "valset reward" param.
-	//
-	// Ref: https://github.com/umee-network/peggo/issues/56
+	// In the profitable mode we only relay valset updates whose reward covers the gas cost, unless the valset on
+	// Ethereum is outdated and the operator asked us to relay it anyway.
+	if s.valsetRelayMode == ValsetRelayModeProfitable &&
+		!s.IsValsetProfitable(ctx, *latestValidValset, estimatedGasCost, gasFees.EffectiveGasPrice(), s.profitMultiplier) {
+		if !s.relayOutdatedValsets || !s.IsLastestValsetUpdateOutdated(ctx) {
+			s.logger.Info().
+				Uint64("latest_cosmos_confirmed_nonce", latestValidValset.Nonce).
+				Msg("valset update is not profitable; skipping")
+			return nil
+		}
+
+		s.logger.Info().
+			Uint64("latest_cosmos_confirmed_nonce", latestValidValset.Nonce).
+			Msg("valset update is not profitable, but the valset on Ethereum is outdated; relaying anyway")
+	}
 
 	// Checking in pending txs (mempool) if tx with same input is already submitted.
 	// We have to check this at the very last moment because any other relayer could have submitted.
@@ -110,8 +127,12 @@ func (s *gravityRelayer) RelayValsets(ctx context.Context, currentValset types.V
 		return nil
 	}
 
+	if !s.isWithinSpendBudget(estimatedGasCost, gasFees.EffectiveGasPrice()) {
+		return nil
+	}
+
 	// Send Valset Update to Ethereum
-	txHash, err := s.gravityContract.SendTx(ctx, s.gravityContract.Address(), txData, estimatedGasCost, gasPrice)
+	txHash, err := s.gravityContract.SendTx(ctx, s.gravityContract.Address(), txData, estimatedGasCost, gasFees)
 	if err != nil {
 		s.logger.Err(err).
 			Str("tx_hash", txHash.Hex()).
@@ -122,12 +143,71 @@ func (s *gravityRelayer) RelayValsets(ctx context.Context, currentValset types.V
 
 	s.logger.Info().Str("tx_hash", txHash.Hex()).Msg("sent Tx (Gravity updateValset)")
 
-	// update our local tracker of the latest valset
-	s.lastSentValsetNonce = latestValidValset.Nonce
+	s.recordSpend(txHash, relayKindValset, latestValidValset.Nonce, estimatedGasCost, gasFees.EffectiveGasPrice())
+	s.recordAttempt(txHash, relayKindValset, ethcmn.Address{}, latestValidValset.Nonce, gasFees.EffectiveGasPrice())
+
+	s.trackValset(txHash, latestValidValset.Nonce)
 
 	return nil
 }
 
+// trackValset keeps track of a relayed valset update. Without a tx tracker, the valset is considered relayed as soon
+// as the transaction is sent. Otherwise it's pending until the transaction is confirmed or superseded, and it can be
+// relayed again if the transaction reverts or is dropped.
+func (s *gravityRelayer) trackValset(txHash ethcmn.Hash, valsetNonce uint64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.txTracker == nil {
+		s.lastSentValsetNonce = valsetNonce
+		return
+	}
+
+	s.pendingValsetNonce = valsetNonce
+
+	onReplace := func(replacement tracker.Replacement) {
+		s.recordReplacementSpend(txHash, replacement)
+	}
+
+	s.txTracker.Track(txHash, s.isValsetRelayed(valsetNonce), onReplace, func(result tracker.Result) {
+		s.logRelayResult(result, "valset_nonce", valsetNonce)
+		s.settleSpend(txHash, result)
+		s.settleAttempt(txHash, result)
+
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+
+		if s.pendingValsetNonce == valsetNonce {
+			s.pendingValsetNonce = 0
+		}
+
+		if result.Outcome.IsDone() && valsetNonce > s.lastSentValsetNonce {
+			s.lastSentValsetNonce = valsetNonce
+		}
+	})
+}
+
+// isValsetSent returns true if the valset update was already relayed or its relay transaction is still pending.
+func (s *gravityRelayer) isValsetSent(valsetNonce uint64) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.lastSentValsetNonce >= valsetNonce || s.pendingValsetNonce >= valsetNonce
+}
+
+// isValsetRelayed returns a function that checks if a valset update (or a newer one) was already relayed to
+// Ethereum, which makes any pending transaction relaying it obsolete.
+func (s *gravityRelayer) isValsetRelayed(valsetNonce uint64) tracker.ObsoleteFn {
+	return func(ctx context.Context) (bool, error) {
+		latestEthereumValsetNonce, err := s.gravityContract.GetValsetNonce(ctx, s.gravityContract.FromAddress())
+		if err != nil {
+			return false, err
+		}
+
+		return latestEthereumValsetNonce.Uint64() >= valsetNonce, nil
+	}
+}
+
 func (s *gravityRelayer) findLatestValidValset(
 	ctx context.Context,
 	currentValset types.Valset,
@@ -182,3 +262,56 @@ func (s *gravityRelayer) findLatestValidValset(
 	// If we couldn't find a valid valset with a greater nonce, then that means we are up to date.
 	return nil, nil, nil
 }
+
+// IsValsetProfitable gets the current prices in USD of ETH and the valset reward token and compares the value of the
+// estimated gas cost of the valset update to the reward paid by the Gravity contract to the relayer. A valset without
+// a reward is never profitable.
+func (s *gravityRelayer) IsValsetProfitable(
+	ctx context.Context,
+	valset types.Valset,
+	ethGasCost uint64,
+	gasPrice *big.Int,
+	profitMultiplier float64,
+) bool {
+	if s.symbolRetriever == nil || s.oracle == nil || profitMultiplier == 0 {
+		return true
+	}
+
+	rewardToken := ethcmn.HexToAddress(valset.RewardToken)
+	if valset.RewardAmount.IsNil() || !valset.RewardAmount.IsPositive() || rewardToken == (ethcmn.Address{}) {
+		s.logger.Debug().Uint64("valset_nonce", valset.Nonce).Msg("valset has no reward")
+		return false
+	}
+
+	gasCostInUSDDec, err := s.gasCostInUSD(ethGasCost, gasPrice)
+	if err != nil {
+		if !s.logStalePriceSkip(err, relayKindValset, valset.Nonce) {
+			s.logger.Err(err).Msg("failed to get gas cost in USD")
+		}
+		return false
+	}
+
+	rewardInUSDDec, usdTokenPrice, err := s.tokenAmountInUSD(ctx, rewardToken, valset.RewardAmount.BigInt())
+	if err != nil {
+		if !s.logStalePriceSkip(err, relayKindValset, valset.Nonce) {
+			s.logger.Err(err).Str("reward_token", valset.RewardToken).Msg("failed to get valset reward in USD")
+		}
+		return false
+	}
+
+	// Simplified: reward > (gasCost * profitMultiplier).
+	isProfitable := rewardInUSDDec.GreaterThanOrEqual(gasCostInUSDDec.Mul(decimal.NewFromFloat(profitMultiplier)))
+
+	s.logger.Debug().
+		Uint64("valset_nonce", valset.Nonce).
+		Str("reward_token", valset.RewardToken).
+		Str("reward_token_price_in_usd", usdTokenPrice.String()).
+		Str("reward_amount", valset.RewardAmount.String()).
+		Float64("reward_in_usd", rewardInUSDDec.InexactFloat64()).
+		Float64("gas_cost_in_usd", gasCostInUSDDec.InexactFloat64()).
+		Float64("profit_multiplier", profitMultiplier).
+		Bool("is_profitable", isProfitable).
+		Msg("checking if valset update is profitable")
+
+	return isProfitable
+}
@@ -7,12 +7,14 @@ import (
 	"testing"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/umee-network/peggo/mocks"
 	gravityMocks "github.com/umee-network/peggo/mocks/gravity"
+	"github.com/umee-network/peggo/orchestrator/ethereum/committer"
 )
 
 func TestRelayValsets(t *testing.T) {
@@ -89,7 +91,7 @@ func TestRelayValsets(t *testing.T) {
 		mockGravityContract.EXPECT().Address().Return(gravityAddress).AnyTimes()
 		mockGravityContract.EXPECT().
 			EstimateGas(gomock.Any(), gravityAddress, []byte{1, 2, 3}).
-			Return(uint64(1000), big.NewInt(100), nil)
+			Return(uint64(1000), committer.GasFees{GasPrice: big.NewInt(100)}, nil)
 
 		mockGravityContract.EXPECT().IsPendingTxInput([]byte{1, 2, 3}, gomock.Any()).Return(false)
 
@@ -98,7 +100,7 @@ func TestRelayValsets(t *testing.T) {
 			gravityAddress,
 			[]byte{1, 2, 3},
 			uint64(1000),
-			big.NewInt(100),
+			committer.GasFees{GasPrice: big.NewInt(100)},
 		).Return(ethcmn.HexToHash("0x01010101"), nil)
 
 		relayer := gravityRelayer{
@@ -360,7 +362,7 @@ func TestRelayValsets(t *testing.T) {
 			Return([]byte{1, 2, 3}, nil).Times(2)
 		mockGravityContract.EXPECT().
 			EstimateGas(gomock.Any(), gravityAddress, []byte{1, 2, 3}).
-			Return(uint64(1000), big.NewInt(100), nil)
+			Return(uint64(1000), committer.GasFees{GasPrice: big.NewInt(100)}, nil)
 
 		mockGravityContract.EXPECT().IsPendingTxInput([]byte{1, 2, 3}, gomock.Any()).Return(false)
 
@@ -369,7 +371,7 @@ func TestRelayValsets(t *testing.T) {
 			gravityAddress,
 			[]byte{1, 2, 3},
 			uint64(1000),
-			big.NewInt(100),
+			committer.GasFees{GasPrice: big.NewInt(100)},
 		).Return(ethcmn.HexToHash("0x0"), nil)
 
 		relayer := gravityRelayer{
@@ -454,7 +456,7 @@ func TestRelayValsets(t *testing.T) {
 		mockGravityContract.EXPECT().Address().Return(gravityAddress).AnyTimes()
 		mockGravityContract.EXPECT().
 			EstimateGas(gomock.Any(), gravityAddress, []byte{1, 2, 3}).
-			Return(uint64(1000), big.NewInt(100), nil)
+			Return(uint64(1000), committer.GasFees{GasPrice: big.NewInt(100)}, nil)
 
 		mockGravityContract.EXPECT().IsPendingTxInput([]byte{1, 2, 3}, gomock.Any()).Return(false)
 
@@ -463,7 +465,7 @@ func TestRelayValsets(t *testing.T) {
 			gravityAddress,
 			[]byte{1, 2, 3},
 			uint64(1000),
-			big.NewInt(100),
+			committer.GasFees{GasPrice: big.NewInt(100)},
 		).Return(ethcmn.HexToHash("0x0"), errors.New("some error while sending tx"))
 
 		relayer := gravityRelayer{
@@ -476,3 +478,77 @@ func TestRelayValsets(t *testing.T) {
 	})
 
 }
+
+type mockSymbolRetriever map[ethcmn.Address]string
+
+func (m mockSymbolRetriever) GetTokenSymbol(erc20Contract ethcmn.Address) (string, error) {
+	symbol, ok := m[erc20Contract]
+	if !ok {
+		return "", errors.New("symbol not found")
+	}
+
+	return symbol, nil
+}
+
+func TestIsValsetProfitable(t *testing.T) {
+	fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+	usdtAddress := ethcmn.HexToAddress("0xdac17f958d2ee523a2206206994597c13d831ec7")
+
+	newRelayer := func(mockCtrl *gomock.Controller) gravityRelayer {
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+		mockGravityContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+		mockGravityContract.EXPECT().
+			GetERC20Decimals(gomock.Any(), usdtAddress, fromAddress).
+			Return(uint8(6), nil).
+			AnyTimes()
+
+		return gravityRelayer{
+			gravityContract: mockGravityContract,
+			symbolRetriever: mockSymbolRetriever{usdtAddress: "USDT"},
+			oracle:          NewMockOracle(),
+		}
+	}
+
+	t.Run("profitable", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		relayer := newRelayer(mockCtrl)
+		valset := types.Valset{Nonce: 2, RewardAmount: sdk.NewInt(100000000), RewardToken: usdtAddress.Hex()}
+
+		// 100 USDT of reward against 200000 gas at 50 gwei (~42.7 USD).
+		assert.True(t, relayer.IsValsetProfitable(context.Background(), valset, 200000, big.NewInt(50e9), 1.1))
+	})
+
+	t.Run("not profitable", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		relayer := newRelayer(mockCtrl)
+		valset := types.Valset{Nonce: 2, RewardAmount: sdk.NewInt(10000000), RewardToken: usdtAddress.Hex()}
+
+		// 10 USDT of reward against 200000 gas at 50 gwei (~42.7 USD).
+		assert.False(t, relayer.IsValsetProfitable(context.Background(), valset, 200000, big.NewInt(50e9), 1.1))
+	})
+
+	t.Run("no reward", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		relayer := newRelayer(mockCtrl)
+		valset := types.Valset{Nonce: 2, RewardAmount: sdk.ZeroInt(), RewardToken: usdtAddress.Hex()}
+		assert.False(t, relayer.IsValsetProfitable(context.Background(), valset, 200000, big.NewInt(50e9), 1.1))
+
+		valset = types.Valset{Nonce: 2, RewardAmount: sdk.NewInt(100000000), RewardToken: ethcmn.Address{}.Hex()}
+		assert.False(t, relayer.IsValsetProfitable(context.Background(), valset, 200000, big.NewInt(50e9), 1.1))
+	})
+
+	t.Run("profit multiplier disabled", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		relayer := newRelayer(mockCtrl)
+		valset := types.Valset{Nonce: 2, RewardAmount: sdk.ZeroInt(), RewardToken: usdtAddress.Hex()}
+		assert.True(t, relayer.IsValsetProfitable(context.Background(), valset, 200000, big.NewInt(50e9), 0))
+	})
+}
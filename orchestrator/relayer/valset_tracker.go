@@ -0,0 +1,133 @@
+package relayer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pkg/errors"
+
+	gravity "github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+// valsetTracker keeps the current valset on the Gravity contract in memory, so it doesn't need to be searched through
+// the event history on every relayer loop.
+type valsetTracker struct {
+	mtx sync.Mutex
+
+	// gravityID is used to compute the checkpoints of the valsets. It's queried once from the contract.
+	gravityID string
+
+	// valset is the latest valset found on the contract, or nil if there's none yet.
+	valset *types.Valset
+
+	// lastScannedBlock is the last block whose ValsetUpdatedEvents were applied to the valset.
+	lastScannedBlock uint64
+}
+
+// updateTrackedValset applies the ValsetUpdatedEvents emitted since the last scanned block to the tracked valset and
+// verifies it against the checkpoint stored on the Gravity contract. It returns false if the checkpoint doesn't match,
+// in which case the tracked valset is dropped and the latest valset must be searched again. The caller must hold the
+// valset tracker lock.
+func (s *gravityRelayer) updateTrackedValset(ctx context.Context, currentBlock uint64) (bool, error) {
+	vt := &s.valsetTracker
+
+	if currentBlock > vt.lastScannedBlock {
+		gravityFilterer, err := wrappers.NewGravityFilterer(s.gravityContract.Address(), s.ethProvider)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to init Gravity events filterer")
+		}
+
+		var latestEvent *wrappers.GravityValsetUpdatedEvent
+
+		for startBlock := vt.lastScannedBlock + 1; startBlock <= currentBlock; startBlock += defaultBlocksToSearch {
+			endBlock := startBlock + defaultBlocksToSearch - 1
+			if endBlock > currentBlock {
+				endBlock = currentBlock
+			}
+
+			iter, err := gravityFilterer.FilterValsetUpdatedEvent(&bind.FilterOpts{
+				Start:   startBlock,
+				End:     &endBlock,
+				Context: ctx,
+			}, nil)
+			if err != nil {
+				return false, errors.Wrap(err, "failed to filter new ValsetUpdated events from Ethereum")
+			}
+
+			for iter.Next() {
+				if latestEvent == nil || iter.Event.NewValsetNonce.Cmp(latestEvent.NewValsetNonce) > 0 {
+					latestEvent = iter.Event
+				}
+				s.UpdateLatestValsetEthBlockNumber(iter.Event.Raw.BlockNumber)
+			}
+
+			iter.Close()
+		}
+
+		if latestEvent != nil && latestEvent.NewValsetNonce.Uint64() > vt.valset.Nonce {
+			valset := valsetFromEvent(latestEvent)
+
+			s.logger.Debug().
+				Uint64("valset_nonce", valset.Nonce).
+				Uint64("eth_block", latestEvent.Raw.BlockNumber).
+				Msg("found new ValsetUpdated event")
+
+			if err := s.checkCosmosValset(ctx, valset, latestEvent); err != nil {
+				return false, err
+			}
+
+			vt.valset = valset
+		}
+
+		vt.lastScannedBlock = currentBlock
+	}
+
+	if vt.gravityID == "" {
+		gravityID, err := s.gravityContract.GetGravityID(ctx, s.gravityContract.FromAddress())
+		if err != nil {
+			return false, errors.Wrap(err, "failed to get gravity ID")
+		}
+
+		vt.gravityID = gravityID
+	}
+
+	checkpoint, err := s.gravityContract.GetValsetCheckpoint(ctx, s.gravityContract.FromAddress())
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get valset checkpoint")
+	}
+
+	if checkpoint != gravity.EncodeValsetConfirm(vt.gravityID, *vt.valset) {
+		s.logger.Warn().
+			Uint64("valset_nonce", vt.valset.Nonce).
+			Uint64("last_scanned_block", vt.lastScannedBlock).
+			Msg("tracked valset doesn't match the checkpoint on Ethereum; searching the latest valset again")
+
+		vt.valset = nil
+		vt.lastScannedBlock = 0
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// checkCosmosValset compares a valset found on Ethereum with the valset of the same nonce on Cosmos.
+func (s *gravityRelayer) checkCosmosValset(
+	ctx context.Context,
+	valset *types.Valset,
+	event *wrappers.GravityValsetUpdatedEvent,
+) error {
+	cosmosValset, err := s.cosmosQueryClient.ValsetRequest(ctx, &types.QueryValsetRequestRequest{
+		Nonce: valset.Nonce,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to get cosmos Valset")
+	} else if cosmosValset == nil {
+		return errors.New("failed to get cosmos Valset, empty response")
+	}
+
+	s.compareValsets(ctx, cosmosValset.Valset, valset, event)
+	return nil
+}
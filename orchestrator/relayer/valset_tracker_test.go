@@ -0,0 +1,164 @@
+package relayer
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umee-network/peggo/mocks"
+	gravityMocks "github.com/umee-network/peggo/mocks/gravity"
+	gravity "github.com/umee-network/peggo/orchestrator/ethereum/gravity"
+	wrappers "github.com/umee-network/peggo/solwrappers/Gravity.sol"
+)
+
+func TestValsetTracker(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockQClient := mocks.NewMockQueryClient(mockCtrl)
+	ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+	mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+
+	gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+	fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+	gravityID := "umee-gravity"
+
+	gravityABI, err := wrappers.GravityMetaData.GetAbi()
+	assert.Nil(t, err)
+	valsetEvent := gravityABI.Events["ValsetUpdatedEvent"]
+
+	newValset := func(nonce uint64, validator ethcmn.Address) *types.Valset {
+		return &types.Valset{
+			Nonce:        nonce,
+			Members:      []types.BridgeValidator{{Power: 4294967295, EthereumAddress: validator.Hex()}},
+			RewardAmount: sdk.NewIntFromBigInt(big.NewInt(0)),
+			RewardToken:  ethcmn.Address{}.Hex(),
+		}
+	}
+
+	newLog := func(valset *types.Valset, blockNumber uint64) ethtypes.Log {
+		data, err := valsetEvent.Inputs.NonIndexed().Pack(
+			big.NewInt(1),
+			big.NewInt(0),
+			ethcmn.Address{},
+			[]ethcmn.Address{ethcmn.HexToAddress(valset.Members[0].EthereumAddress)},
+			[]*big.Int{new(big.Int).SetUint64(valset.Members[0].Power)},
+		)
+		assert.Nil(t, err)
+
+		return ethtypes.Log{
+			Address:     gravityAddress,
+			Topics:      []ethcmn.Hash{valsetEvent.ID, ethcmn.BigToHash(new(big.Int).SetUint64(valset.Nonce))},
+			Data:        data,
+			BlockNumber: blockNumber,
+		}
+	}
+
+	expectFilterLogs := func(fromBlock, toBlock int64, logs ...ethtypes.Log) {
+		ethProvider.EXPECT().FilterLogs(
+			gomock.Any(),
+			MatchFilterQuery(ethereum.FilterQuery{
+				FromBlock: big.NewInt(fromBlock),
+				ToBlock:   big.NewInt(toBlock),
+				Addresses: []ethcmn.Address{gravityAddress},
+				Topics:    [][]ethcmn.Hash{{valsetEvent.ID}, {}},
+			})).
+			Return(logs, nil)
+	}
+
+	expectCosmosValset := func(valset *types.Valset) {
+		mockQClient.EXPECT().ValsetRequest(gomock.Any(), &types.QueryValsetRequestRequest{
+			Nonce: valset.Nonce,
+		}).Return(&types.QueryValsetRequestResponse{Valset: valset}, nil)
+	}
+
+	expectFullScan := func(valset *types.Valset) {
+		mockGravityContract.EXPECT().GetValsetNonce(gomock.Any(), fromAddress).
+			Return(new(big.Int).SetUint64(valset.Nonce), nil)
+		expectCosmosValset(valset)
+	}
+
+	expectCheckpoint := func(valset *types.Valset) {
+		mockGravityContract.EXPECT().GetValsetCheckpoint(gomock.Any(), fromAddress).
+			Return(gravity.EncodeValsetConfirm(gravityID, *valset), nil)
+	}
+
+	expectHeader := func(number int64) {
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{
+			Number: big.NewInt(number),
+		}, nil)
+	}
+
+	mockGravityContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+	mockGravityContract.EXPECT().Address().Return(gravityAddress).AnyTimes()
+	mockGravityContract.EXPECT().GetGravityID(gomock.Any(), fromAddress).Return(gravityID, nil).Times(1)
+
+	relayer := gravityRelayer{
+		logger:            zerolog.Nop(),
+		cosmosQueryClient: mockQClient,
+		gravityContract:   mockGravityContract,
+		ethProvider:       ethProvider,
+	}
+
+	valset1 := newValset(1, ethcmn.HexToAddress("0x05a64fe82628217900ced80bf3747b5ef88bfa21"))
+	valset2 := newValset(2, ethcmn.HexToAddress("0x1f71f2a59d19030bf79961e3e57c82922feecca0"))
+
+	// There's no tracked valset yet, so the event history is searched.
+	expectHeader(100)
+	expectFullScan(valset1)
+	expectFilterLogs(0, 100, newLog(valset1, 10))
+
+	valset, err := relayer.FindLatestValset(context.Background())
+	assert.Nil(t, err)
+	assertValset(t, valset1, valset)
+
+	// Only the new blocks are scanned and the new valset is verified with the checkpoint.
+	expectHeader(2200)
+	expectFilterLogs(101, 2100, newLog(valset2, 2050))
+	expectFilterLogs(2101, 2200)
+	expectCosmosValset(valset2)
+	expectCheckpoint(valset2)
+
+	valset, err = relayer.FindLatestValset(context.Background())
+	assert.Nil(t, err)
+	assertValset(t, valset2, valset)
+	assert.Equal(t, uint64(2050), relayer.latestValsetEthBlockNumber)
+
+	// The returned valset is a copy of the tracked one.
+	valset.Members[0].Power = 1
+
+	// No new blocks, and the checkpoint still matches.
+	expectHeader(2200)
+	expectCheckpoint(valset2)
+
+	valset, err = relayer.FindLatestValset(context.Background())
+	assert.Nil(t, err)
+	assertValset(t, valset2, valset)
+
+	// The checkpoint doesn't match the tracked valset (e.g. the valset update was reorged out), so the
+	// event history is searched again.
+	expectHeader(2210)
+	expectFilterLogs(2201, 2210)
+	expectCheckpoint(valset1)
+	expectFullScan(valset1)
+	expectFilterLogs(210, 2210)
+	expectFilterLogs(0, 210, newLog(valset1, 10))
+
+	valset, err = relayer.FindLatestValset(context.Background())
+	assert.Nil(t, err)
+	assertValset(t, valset1, valset)
+}
+
+func assertValset(t *testing.T, expected, actual *types.Valset) {
+	assert.Equal(t, expected.Nonce, actual.Nonce)
+	assert.Equal(t, expected.Members, actual.Members)
+	assert.True(t, expected.RewardAmount.Equal(actual.RewardAmount))
+	assert.Equal(t, expected.RewardToken, actual.RewardToken)
+}
@@ -0,0 +1,74 @@
+package symbols
+
+import (
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+type Option func(o *options) error
+
+type options struct {
+	Overrides map[ethcmn.Address]string
+	Fallback  Fallback
+	CacheFile string
+}
+
+func defaultOptions() *options {
+	return &options{
+		Overrides: map[ethcmn.Address]string{},
+	}
+}
+
+func applyOptions(o *options, opts ...Option) error {
+	for _, oo := range opts {
+		if err := oo(o); err != nil {
+			err = errors.Wrap(err, "failed to apply option to symbol retriever")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OptionOverrides sets the symbols some tokens are priced with, regardless of their on-chain symbol.
+func OptionOverrides(overrides map[ethcmn.Address]string) Option {
+	return func(o *options) error {
+		for token, symbol := range overrides {
+			o.Overrides[token] = normalizeSymbol(symbol)
+		}
+
+		return nil
+	}
+}
+
+// OptionOverridesFile sets the symbol overrides of a JSON file mapping token addresses to symbols.
+func OptionOverridesFile(path string) Option {
+	return func(o *options) error {
+		if path == "" {
+			return nil
+		}
+
+		overrides, err := LoadOverrides(path)
+		if err != nil {
+			return err
+		}
+
+		return OptionOverrides(overrides)(o)
+	}
+}
+
+// OptionFallback sets the retriever used when the symbol of a token can't be read on-chain.
+func OptionFallback(fallback Fallback) Option {
+	return func(o *options) error {
+		o.Fallback = fallback
+		return nil
+	}
+}
+
+// OptionCacheFile sets the file the resolved symbols are cached in, so they survive restarts.
+func OptionCacheFile(path string) Option {
+	return func(o *options) error {
+		o.CacheFile = path
+		return nil
+	}
+}
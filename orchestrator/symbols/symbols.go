@@ -0,0 +1,201 @@
+package symbols
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// symbolCallTimeout is the timeout of the ERC20 symbol() calls.
+const symbolCallTimeout = 10 * time.Second
+
+// SymbolReader reads the symbol of an ERC20 token on-chain, e.g. the Gravity contract.
+type SymbolReader interface {
+	GetERC20Symbol(ctx context.Context, erc20Contract, callerAddress ethcmn.Address) (string, error)
+	FromAddress() ethcmn.Address
+}
+
+// Fallback resolves the symbol of a token when it can't be read on-chain, e.g. CoinGecko.
+type Fallback interface {
+	GetTokenSymbol(erc20Contract ethcmn.Address) (string, error)
+}
+
+// Retriever resolves the price symbol of an ERC20 token. The symbol comes from the overrides set by the operator if
+// there's one for the token, or from the symbol() of the token otherwise, falling back to an optional Fallback if
+// the call fails. The overrides fix the tokens whose symbol isn't the ticker they're priced with, like wrapped or
+// bridged tokens (e.g. WETH => ETH). Resolved symbols are cached, on disk if a cache file is set.
+type Retriever struct {
+	logger zerolog.Logger
+	reader SymbolReader
+	opts   *options
+
+	mtx   sync.RWMutex
+	cache map[ethcmn.Address]string // token => symbol
+}
+
+// NewRetriever returns a Retriever of the symbols read with the reader.
+func NewRetriever(logger zerolog.Logger, reader SymbolReader, opts ...Option) (*Retriever, error) {
+	r := &Retriever{
+		logger: logger.With().Str("module", "symbol_retriever").Logger(),
+		reader: reader,
+		opts:   defaultOptions(),
+		cache:  map[ethcmn.Address]string{},
+	}
+
+	if err := applyOptions(r.opts, opts...); err != nil {
+		return nil, err
+	}
+
+	if r.opts.CacheFile != "" {
+		cache, err := loadSymbols(r.opts.CacheFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, errors.Wrap(err, "failed to load symbol cache")
+		}
+
+		for token, symbol := range cache {
+			r.cache[token] = symbol
+		}
+	}
+
+	return r, nil
+}
+
+// GetTokenSymbol returns the price symbol of the token.
+func (r *Retriever) GetTokenSymbol(erc20Contract ethcmn.Address) (string, error) {
+	if symbol, ok := r.opts.Overrides[erc20Contract]; ok {
+		return symbol, nil
+	}
+
+	r.mtx.RLock()
+	symbol, ok := r.cache[erc20Contract]
+	r.mtx.RUnlock()
+
+	if ok {
+		return symbol, nil
+	}
+
+	symbol, err := r.resolveSymbol(erc20Contract)
+	if err != nil {
+		return "", err
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.cache[erc20Contract] = symbol
+	r.persist()
+
+	return symbol, nil
+}
+
+// resolveSymbol reads the symbol of the token on-chain, or with the fallback if the call fails.
+func (r *Retriever) resolveSymbol(erc20Contract ethcmn.Address) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), symbolCallTimeout)
+	defer cancel()
+
+	symbol, err := r.reader.GetERC20Symbol(ctx, erc20Contract, r.reader.FromAddress())
+	if err == nil {
+		symbol = normalizeSymbol(symbol)
+	}
+
+	if err == nil && symbol != "" {
+		r.logger.Debug().
+			Str("token_contract", erc20Contract.Hex()).
+			Str("symbol", symbol).
+			Msg("resolved token symbol on-chain")
+		return symbol, nil
+	}
+
+	if err == nil {
+		err = errors.New("empty symbol")
+	}
+
+	if r.opts.Fallback == nil {
+		return "", errors.Wrapf(err, "failed to get symbol of token %s", erc20Contract.Hex())
+	}
+
+	r.logger.Debug().Err(err).Str("token_contract", erc20Contract.Hex()).Msg("failed to get token symbol on-chain")
+
+	symbol, fallbackErr := r.opts.Fallback.GetTokenSymbol(erc20Contract)
+	if fallbackErr != nil {
+		return "", errors.Wrapf(
+			fallbackErr,
+			"failed to get symbol of token %s (on-chain: %s)",
+			erc20Contract.Hex(),
+			err,
+		)
+	}
+
+	return normalizeSymbol(symbol), nil
+}
+
+// persist writes the cached symbols to the cache file, if any. Must be called with the lock held.
+func (r *Retriever) persist() {
+	if r.opts.CacheFile == "" {
+		return
+	}
+
+	bz, err := json.MarshalIndent(r.cache, "", "  ")
+	if err != nil {
+		r.logger.Err(err).Msg("failed to encode symbol cache")
+		return
+	}
+
+	// Write to a temporary file first, so a crash never leaves a truncated cache file.
+	tmpFile := filepath.Join(filepath.Dir(r.opts.CacheFile), "."+filepath.Base(r.opts.CacheFile)+".tmp")
+	if err := os.WriteFile(tmpFile, bz, 0o600); err != nil {
+		r.logger.Err(err).Msg("failed to write symbol cache")
+		return
+	}
+
+	if err := os.Rename(tmpFile, r.opts.CacheFile); err != nil {
+		r.logger.Err(err).Msg("failed to write symbol cache")
+	}
+}
+
+// LoadOverrides reads a JSON file mapping token addresses to the symbols they're priced with.
+func LoadOverrides(path string) (map[ethcmn.Address]string, error) {
+	overrides, err := loadSymbols(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load symbol overrides")
+	}
+
+	for token, symbol := range overrides {
+		if symbol == "" {
+			return nil, errors.Errorf("empty symbol override for token %s", token.Hex())
+		}
+	}
+
+	return overrides, nil
+}
+
+// loadSymbols reads a JSON file of symbols by token address.
+func loadSymbols(path string) (map[ethcmn.Address]string, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var symbols map[ethcmn.Address]string
+	if err := json.Unmarshal(bz, &symbols); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s", path)
+	}
+
+	for token, symbol := range symbols {
+		symbols[token] = normalizeSymbol(symbol)
+	}
+
+	return symbols, nil
+}
+
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
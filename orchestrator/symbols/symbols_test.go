@@ -0,0 +1,148 @@
+package symbols
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	gravityMocks "github.com/umee-network/peggo/mocks/gravity"
+)
+
+var (
+	fromAddress = ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e10c5df2f3f4edc5e4e")
+	wethAddress = ethcmn.HexToAddress("0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2")
+	umeeAddress = ethcmn.HexToAddress("0xc0a4df35568f116c370e6a6a6022ceb908eeddac")
+)
+
+type fallbackFunc func(erc20Contract ethcmn.Address) (string, error)
+
+func (f fallbackFunc) GetTokenSymbol(erc20Contract ethcmn.Address) (string, error) {
+	return f(erc20Contract)
+}
+
+func newMockReader(t *testing.T) *gravityMocks.MockContract {
+	mockCtrl := gomock.NewController(t)
+	mockContract := gravityMocks.NewMockContract(mockCtrl)
+	mockContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+
+	return mockContract
+}
+
+func TestGetTokenSymbol(t *testing.T) {
+	t.Run("override", func(t *testing.T) {
+		r, err := NewRetriever(zerolog.Nop(), newMockReader(t), OptionOverrides(map[ethcmn.Address]string{
+			wethAddress: "eth",
+		}))
+		assert.Nil(t, err)
+
+		symbol, err := r.GetTokenSymbol(wethAddress)
+		assert.Nil(t, err)
+		assert.Equal(t, "ETH", symbol)
+	})
+
+	t.Run("on-chain", func(t *testing.T) {
+		mockContract := newMockReader(t)
+		mockContract.EXPECT().GetERC20Symbol(gomock.Any(), umeeAddress, fromAddress).Return(" umee ", nil).Times(1)
+
+		r, err := NewRetriever(zerolog.Nop(), mockContract)
+		assert.Nil(t, err)
+
+		for i := 0; i < 2; i++ {
+			symbol, err := r.GetTokenSymbol(umeeAddress)
+			assert.Nil(t, err)
+			assert.Equal(t, "UMEE", symbol)
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		mockContract := newMockReader(t)
+		mockContract.EXPECT().GetERC20Symbol(gomock.Any(), umeeAddress, fromAddress).Return("", errors.New("reverted"))
+
+		r, err := NewRetriever(zerolog.Nop(), mockContract, OptionFallback(
+			fallbackFunc(func(ethcmn.Address) (string, error) { return "umee", nil }),
+		))
+		assert.Nil(t, err)
+
+		symbol, err := r.GetTokenSymbol(umeeAddress)
+		assert.Nil(t, err)
+		assert.Equal(t, "UMEE", symbol)
+	})
+
+	t.Run("no fallback", func(t *testing.T) {
+		mockContract := newMockReader(t)
+		mockContract.EXPECT().GetERC20Symbol(gomock.Any(), umeeAddress, fromAddress).Return("", nil)
+
+		r, err := NewRetriever(zerolog.Nop(), mockContract)
+		assert.Nil(t, err)
+
+		_, err = r.GetTokenSymbol(umeeAddress)
+		assert.EqualError(t, err, "failed to get symbol of token "+umeeAddress.Hex()+": empty symbol")
+	})
+
+	t.Run("fallback error", func(t *testing.T) {
+		mockContract := newMockReader(t)
+		mockContract.EXPECT().GetERC20Symbol(gomock.Any(), umeeAddress, fromAddress).Return("", errors.New("reverted"))
+
+		r, err := NewRetriever(zerolog.Nop(), mockContract, OptionFallback(
+			fallbackFunc(func(ethcmn.Address) (string, error) { return "", errors.New("not found") }),
+		))
+		assert.Nil(t, err)
+
+		_, err = r.GetTokenSymbol(umeeAddress)
+		assert.EqualError(t, err, "failed to get symbol of token "+umeeAddress.Hex()+" (on-chain: reverted): not found")
+	})
+}
+
+func TestSymbolCache(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "symbols.json")
+
+	mockContract := newMockReader(t)
+	mockContract.EXPECT().GetERC20Symbol(gomock.Any(), umeeAddress, fromAddress).Return("UMEE", nil).Times(1)
+
+	r, err := NewRetriever(zerolog.Nop(), mockContract, OptionCacheFile(cacheFile))
+	assert.Nil(t, err)
+
+	symbol, err := r.GetTokenSymbol(umeeAddress)
+	assert.Nil(t, err)
+	assert.Equal(t, "UMEE", symbol)
+
+	// A new retriever reads the symbol from the cache file, without calling the token.
+	r, err = NewRetriever(zerolog.Nop(), newMockReader(t), OptionCacheFile(cacheFile))
+	assert.Nil(t, err)
+
+	symbol, err = r.GetTokenSymbol(umeeAddress)
+	assert.Nil(t, err)
+	assert.Equal(t, "UMEE", symbol)
+}
+
+func TestLoadOverrides(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.json")
+	assert.Nil(t, os.WriteFile(valid, []byte(`{"`+wethAddress.Hex()+`": "eth"}`), 0o600))
+
+	overrides, err := LoadOverrides(valid)
+	assert.Nil(t, err)
+	assert.Equal(t, map[ethcmn.Address]string{wethAddress: "ETH"}, overrides)
+
+	empty := filepath.Join(dir, "empty.json")
+	assert.Nil(t, os.WriteFile(empty, []byte(`{"`+wethAddress.Hex()+`": " "}`), 0o600))
+
+	_, err = LoadOverrides(empty)
+	assert.EqualError(t, err, "empty symbol override for token "+wethAddress.Hex())
+
+	invalid := filepath.Join(dir, "invalid.json")
+	assert.Nil(t, os.WriteFile(invalid, []byte(`{"not an address": "ETH"}`), 0o600))
+
+	_, err = LoadOverrides(invalid)
+	assert.NotNil(t, err)
+
+	_, err = NewRetriever(zerolog.Nop(), newMockReader(t), OptionOverridesFile(invalid))
+	assert.NotNil(t, err)
+}
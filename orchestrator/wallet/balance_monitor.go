@@ -0,0 +1,208 @@
+package wallet
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// BalanceLevel defines how a balance compares to its thresholds.
+type BalanceLevel int64
+
+const (
+	// BalanceLevelOK means the balance is above the warning threshold.
+	BalanceLevelOK BalanceLevel = iota
+	// BalanceLevelWarning means the balance is below the warning threshold.
+	BalanceLevelWarning
+	// BalanceLevelCritical means the balance is below the critical threshold.
+	BalanceLevelCritical
+)
+
+// String gets the string representation of the balance level.
+func (l BalanceLevel) String() string {
+	return [...]string{"ok", "warning", "critical"}[l]
+}
+
+// BalanceStatus holds the result of a balance check. The fields of an account that is not monitored are left empty.
+type BalanceStatus struct {
+	EthBalance    *big.Int
+	EthLevel      BalanceLevel
+	CosmosBalance sdk.Coin
+	CosmosLevel   BalanceLevel
+
+	FeeGranterBalance sdk.Coin
+	FeeGranterLevel   BalanceLevel
+}
+
+// BalanceMonitor checks the balances of the Ethereum relayer address, the Cosmos orchestrator account and the fee
+// granter paying its fees, if any, logging a warning or an error when they fall below their thresholds.
+type BalanceMonitor interface {
+	// Check queries the balances of the monitored accounts and logs the ones below their thresholds.
+	Check(ctx context.Context) (BalanceStatus, error)
+
+	// Start checks the balances periodically until the context is done.
+	Start(ctx context.Context) error
+}
+
+type balanceMonitor struct {
+	logger zerolog.Logger
+	opts   *monitorOptions
+}
+
+// NewBalanceMonitor returns a BalanceMonitor of the accounts set by the options. At least one account is required.
+func NewBalanceMonitor(logger zerolog.Logger, monitorOpts ...MonitorOption) (BalanceMonitor, error) {
+	m := &balanceMonitor{
+		logger: logger.With().Str("module", "balance_monitor").Logger(),
+		opts:   defaultMonitorOptions(),
+	}
+
+	if err := applyMonitorOptions(m.opts, monitorOpts...); err != nil {
+		return nil, err
+	}
+
+	if m.opts.EthProvider == nil && m.opts.BankQueryClient == nil {
+		return nil, errors.New("balance monitor requires at least one account")
+	}
+
+	if !m.opts.FeeGranterAddress.Empty() && m.opts.BankQueryClient == nil {
+		return nil, errors.New("fee granter monitoring requires the Cosmos account thresholds")
+	}
+
+	return m, nil
+}
+
+func (m *balanceMonitor) Start(ctx context.Context) error {
+	ticker := time.NewTicker(m.opts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := m.Check(ctx); err != nil {
+			m.logger.Err(err).Msg("failed to check balances")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *balanceMonitor) Check(ctx context.Context) (BalanceStatus, error) {
+	var status BalanceStatus
+
+	if m.opts.EthProvider != nil {
+		balance, err := m.opts.EthProvider.BalanceAt(ctx, m.opts.EthAddress, nil)
+		if err != nil {
+			return status, errors.Wrap(err, "failed to get Ethereum balance")
+		}
+
+		status.EthBalance = balance
+		status.EthLevel = balanceLevel(balance, m.opts.EthWarnThreshold, m.opts.EthCriticalThreshold)
+
+		m.logBalance(status.EthLevel).
+			Str("eth_address", m.opts.EthAddress.Hex()).
+			Str("balance_wei", balance.String()).
+			Str("warn_threshold_wei", m.opts.EthWarnThreshold.String()).
+			Str("critical_threshold_wei", m.opts.EthCriticalThreshold.String()).
+			Msg(balanceMessage(status.EthLevel, "Ethereum relayer"))
+	}
+
+	if m.opts.BankQueryClient != nil {
+		balance, level, err := m.checkCosmosBalance(ctx, m.opts.CosmosAddress, "Cosmos orchestrator")
+		if err != nil {
+			return status, errors.Wrap(err, "failed to get Cosmos balance")
+		}
+
+		status.CosmosBalance = balance
+		status.CosmosLevel = level
+
+		if !m.opts.FeeGranterAddress.Empty() {
+			balance, level, err := m.checkCosmosBalance(ctx, m.opts.FeeGranterAddress, "Cosmos fee granter")
+			if err != nil {
+				return status, errors.Wrap(err, "failed to get fee granter balance")
+			}
+
+			status.FeeGranterBalance = balance
+			status.FeeGranterLevel = level
+		}
+	}
+
+	return status, nil
+}
+
+// checkCosmosBalance queries the balance of a Cosmos account in the denom of the thresholds and logs how it compares
+// to them.
+func (m *balanceMonitor) checkCosmosBalance(
+	ctx context.Context,
+	address sdk.AccAddress,
+	account string,
+) (sdk.Coin, BalanceLevel, error) {
+	res, err := m.opts.BankQueryClient.Balance(ctx, &banktypes.QueryBalanceRequest{
+		Address: address.String(),
+		Denom:   m.opts.CosmosWarnThreshold.Denom,
+	})
+	if err != nil {
+		return sdk.Coin{}, BalanceLevelOK, err
+	}
+
+	balance := sdk.NewCoin(m.opts.CosmosWarnThreshold.Denom, sdk.ZeroInt())
+	if res.Balance != nil {
+		balance = *res.Balance
+	}
+
+	level := balanceLevel(
+		balance.Amount.BigInt(),
+		m.opts.CosmosWarnThreshold.Amount.BigInt(),
+		m.opts.CosmosCriticalThreshold.Amount.BigInt(),
+	)
+
+	m.logBalance(level).
+		Str("cosmos_address", address.String()).
+		Str("balance", balance.String()).
+		Str("warn_threshold", m.opts.CosmosWarnThreshold.String()).
+		Str("critical_threshold", m.opts.CosmosCriticalThreshold.String()).
+		Msg(balanceMessage(level, account))
+
+	return balance, level, nil
+}
+
+// logBalance returns a log event of the severity of the given level.
+func (m *balanceMonitor) logBalance(level BalanceLevel) *zerolog.Event {
+	switch level {
+	case BalanceLevelCritical:
+		return m.logger.Error()
+	case BalanceLevelWarning:
+		return m.logger.Warn()
+	default:
+		return m.logger.Debug()
+	}
+}
+
+func balanceMessage(level BalanceLevel, account string) string {
+	switch level {
+	case BalanceLevelCritical:
+		return account + " balance is critically low; transactions will fail soon"
+	case BalanceLevelWarning:
+		return account + " balance is low"
+	default:
+		return account + " balance is ok"
+	}
+}
+
+// balanceLevel compares a balance to its thresholds. A balance equal to a threshold is not below it.
+func balanceLevel(balance, warn, critical *big.Int) BalanceLevel {
+	switch {
+	case critical != nil && balance.Cmp(critical) < 0:
+		return BalanceLevelCritical
+	case warn != nil && balance.Cmp(warn) < 0:
+		return BalanceLevelWarning
+	default:
+		return BalanceLevelOK
+	}
+}
@@ -0,0 +1,171 @@
+package wallet
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/umee-network/peggo/mocks"
+)
+
+// mockBankQueryClient returns a fixed balance, or the balance of the account if it's set.
+type mockBankQueryClient struct {
+	banktypes.QueryClient
+
+	balance         sdk.Coin
+	accountBalances map[string]sdk.Coin
+}
+
+func (m *mockBankQueryClient) Balance(
+	_ context.Context,
+	req *banktypes.QueryBalanceRequest,
+	_ ...grpc.CallOption,
+) (*banktypes.QueryBalanceResponse, error) {
+	if balance, ok := m.accountBalances[req.Address]; ok {
+		return &banktypes.QueryBalanceResponse{Balance: &balance}, nil
+	}
+
+	if req.Denom != m.balance.Denom {
+		return &banktypes.QueryBalanceResponse{}, nil
+	}
+
+	return &banktypes.QueryBalanceResponse{Balance: &m.balance}, nil
+}
+
+func TestBalanceMonitor(t *testing.T) {
+	ethAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e10c5df2f3f4edc5e4e")
+	cosmosAddress := sdk.AccAddress([]byte("orchestrator"))
+
+	testCases := []struct {
+		name          string
+		ethBalance    int64
+		cosmosBalance sdk.Coin
+		expected      BalanceStatus
+	}{
+		{
+			name:          "ok",
+			ethBalance:    1000,
+			cosmosBalance: sdk.NewInt64Coin("uumee", 1000),
+			expected: BalanceStatus{
+				EthBalance:    big.NewInt(1000),
+				EthLevel:      BalanceLevelOK,
+				CosmosBalance: sdk.NewInt64Coin("uumee", 1000),
+				CosmosLevel:   BalanceLevelOK,
+			},
+		},
+		{
+			name:          "warning",
+			ethBalance:    99,
+			cosmosBalance: sdk.NewInt64Coin("uumee", 99),
+			expected: BalanceStatus{
+				EthBalance:    big.NewInt(99),
+				EthLevel:      BalanceLevelWarning,
+				CosmosBalance: sdk.NewInt64Coin("uumee", 99),
+				CosmosLevel:   BalanceLevelWarning,
+			},
+		},
+		{
+			name:          "critical",
+			ethBalance:    9,
+			cosmosBalance: sdk.NewInt64Coin("uatom", 1000),
+			expected: BalanceStatus{
+				EthBalance:    big.NewInt(9),
+				EthLevel:      BalanceLevelCritical,
+				CosmosBalance: sdk.NewInt64Coin("uumee", 0),
+				CosmosLevel:   BalanceLevelCritical,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+			mockEvmProvider.EXPECT().BalanceAt(gomock.Any(), ethAddress, nil).Return(big.NewInt(tc.ethBalance), nil)
+
+			monitor, err := NewBalanceMonitor(
+				zerolog.Nop(),
+				OptionEthAccount(mockEvmProvider, ethAddress, big.NewInt(100), big.NewInt(10)),
+				OptionCosmosAccount(
+					&mockBankQueryClient{balance: tc.cosmosBalance},
+					cosmosAddress,
+					sdk.NewInt64Coin("uumee", 100),
+					sdk.NewInt64Coin("uumee", 10),
+				),
+			)
+			assert.Nil(t, err)
+
+			status, err := monitor.Check(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, status)
+		})
+	}
+
+	t.Run("fee granter", func(t *testing.T) {
+		feeGranter := sdk.AccAddress([]byte("fee_granter"))
+
+		monitor, err := NewBalanceMonitor(
+			zerolog.Nop(),
+			OptionCosmosAccount(
+				&mockBankQueryClient{
+					balance: sdk.NewInt64Coin("uumee", 1000),
+					accountBalances: map[string]sdk.Coin{
+						feeGranter.String(): sdk.NewInt64Coin("uumee", 50),
+					},
+				},
+				cosmosAddress,
+				sdk.NewInt64Coin("uumee", 100),
+				sdk.NewInt64Coin("uumee", 10),
+			),
+			OptionCosmosFeeGranter(feeGranter),
+		)
+		assert.Nil(t, err)
+
+		status, err := monitor.Check(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, BalanceStatus{
+			CosmosBalance:     sdk.NewInt64Coin("uumee", 1000),
+			CosmosLevel:       BalanceLevelOK,
+			FeeGranterBalance: sdk.NewInt64Coin("uumee", 50),
+			FeeGranterLevel:   BalanceLevelWarning,
+		}, status)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := NewBalanceMonitor(zerolog.Nop())
+		assert.Error(t, err)
+
+		_, err = NewBalanceMonitor(
+			zerolog.Nop(),
+			OptionEthAccount(mocks.NewMockEVMProviderWithRet(gomock.NewController(t)), ethAddress, big.NewInt(1), big.NewInt(2)),
+		)
+		assert.Error(t, err)
+
+		_, err = NewBalanceMonitor(
+			zerolog.Nop(),
+			OptionCosmosAccount(
+				&mockBankQueryClient{},
+				cosmosAddress,
+				sdk.NewInt64Coin("uumee", 100),
+				sdk.NewInt64Coin("uatom", 10),
+			),
+		)
+		assert.Error(t, err)
+
+		_, err = NewBalanceMonitor(
+			zerolog.Nop(),
+			OptionEthAccount(mocks.NewMockEVMProviderWithRet(gomock.NewController(t)), ethAddress, big.NewInt(2), big.NewInt(1)),
+			OptionCosmosFeeGranter(sdk.AccAddress([]byte("fee_granter"))),
+		)
+		assert.Error(t, err)
+	})
+}
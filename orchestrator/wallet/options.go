@@ -0,0 +1,129 @@
+package wallet
+
+import (
+	"math/big"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/umee-network/peggo/orchestrator/ethereum/provider"
+)
+
+type MonitorOption func(o *monitorOptions) error
+
+type monitorOptions struct {
+	CheckInterval time.Duration
+
+	EthProvider          provider.EVMProvider
+	EthAddress           ethcmn.Address
+	EthWarnThreshold     *big.Int
+	EthCriticalThreshold *big.Int
+
+	BankQueryClient         banktypes.QueryClient
+	CosmosAddress           sdk.AccAddress
+	CosmosWarnThreshold     sdk.Coin
+	CosmosCriticalThreshold sdk.Coin
+	FeeGranterAddress       sdk.AccAddress
+}
+
+func defaultMonitorOptions() *monitorOptions {
+	return &monitorOptions{
+		CheckInterval: 5 * time.Minute,
+	}
+}
+
+func applyMonitorOptions(o *monitorOptions, opts ...MonitorOption) error {
+	for _, oo := range opts {
+		if err := oo(o); err != nil {
+			err = errors.Wrap(err, "failed to apply option to balance monitor")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OptionCheckInterval sets how often the balances are checked.
+func OptionCheckInterval(interval time.Duration) MonitorOption {
+	return func(o *monitorOptions) error {
+		if interval <= 0 {
+			return errors.New("check interval must be greater than zero")
+		}
+
+		o.CheckInterval = interval
+		return nil
+	}
+}
+
+// OptionEthAccount enables the monitoring of the ETH balance (in wei) of an Ethereum address.
+func OptionEthAccount(
+	ethProvider provider.EVMProvider,
+	address ethcmn.Address,
+	warnThreshold *big.Int,
+	criticalThreshold *big.Int,
+) MonitorOption {
+	return func(o *monitorOptions) error {
+		if ethProvider == nil {
+			return errors.New("eth provider can't be nil")
+		}
+
+		if warnThreshold == nil || criticalThreshold == nil {
+			return errors.New("eth balance thresholds can't be nil")
+		}
+
+		if warnThreshold.Cmp(criticalThreshold) < 0 {
+			return errors.New("eth warning threshold can't be lower than the critical threshold")
+		}
+
+		o.EthProvider = ethProvider
+		o.EthAddress = address
+		o.EthWarnThreshold = warnThreshold
+		o.EthCriticalThreshold = criticalThreshold
+		return nil
+	}
+}
+
+// OptionCosmosAccount enables the monitoring of the balance of a Cosmos account. Both thresholds must have the same
+// denom, which is the one monitored.
+func OptionCosmosAccount(
+	bankQueryClient banktypes.QueryClient,
+	address sdk.AccAddress,
+	warnThreshold sdk.Coin,
+	criticalThreshold sdk.Coin,
+) MonitorOption {
+	return func(o *monitorOptions) error {
+		if bankQueryClient == nil {
+			return errors.New("bank query client can't be nil")
+		}
+
+		if warnThreshold.Denom != criticalThreshold.Denom {
+			return errors.New("cosmos balance thresholds must have the same denom")
+		}
+
+		if warnThreshold.IsLT(criticalThreshold) {
+			return errors.New("cosmos warning threshold can't be lower than the critical threshold")
+		}
+
+		o.BankQueryClient = bankQueryClient
+		o.CosmosAddress = address
+		o.CosmosWarnThreshold = warnThreshold
+		o.CosmosCriticalThreshold = criticalThreshold
+		return nil
+	}
+}
+
+// OptionCosmosFeeGranter enables the monitoring of the balance of the fee granter paying the fees of the Cosmos
+// account, with the thresholds of OptionCosmosAccount, which is required.
+func OptionCosmosFeeGranter(address sdk.AccAddress) MonitorOption {
+	return func(o *monitorOptions) error {
+		if address.Empty() {
+			return errors.New("fee granter address can't be empty")
+		}
+
+		o.FeeGranterAddress = address
+		return nil
+	}
+}
@@ -0,0 +1,427 @@
+package wallet
+
+import (
+	"bufio"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Allowed spend budget periods
+const (
+	BudgetPeriodDay  = "day"
+	BudgetPeriodWeek = "week"
+)
+
+// ParseBudgetPeriod returns the duration of a spend budget period.
+func ParseBudgetPeriod(period string) (time.Duration, error) {
+	switch period {
+	case BudgetPeriodDay:
+		return 24 * time.Hour, nil
+	case BudgetPeriodWeek:
+		return 7 * 24 * time.Hour, nil
+	default:
+		return 0, errors.Errorf("invalid spend budget period: %s", period)
+	}
+}
+
+// ledgerCompactMinLines is the number of ledger lines that can be appended before the ledger file is compacted.
+const ledgerCompactMinLines = 100
+
+// LedgerEntry records the ETH spent by a relay transaction. Entries are first recorded with the estimated cost when
+// the transaction is sent, and settled with the cost of the gas used once it reaches a final outcome.
+type LedgerEntry struct {
+	Time     time.Time   `json:"time"`
+	TxHash   ethcmn.Hash `json:"tx_hash"`
+	Kind     string      `json:"kind"`
+	Nonce    uint64      `json:"nonce"`
+	GasLimit uint64      `json:"gas_limit"`
+	GasPrice *big.Int    `json:"gas_price"`
+	GasUsed  uint64      `json:"gas_used"`
+	Cost     *big.Int    `json:"cost"`
+	Settled  bool        `json:"settled"`
+
+	// Replaces is the hash of the relay transaction replaced or cancelled by this one, if any. Only one transaction
+	// of a relay and its replacements can be mined, so only the last one sent counts until the relay is settled.
+	Replaces *ethcmn.Hash `json:"replaces,omitempty"`
+}
+
+// SpendBudget limits the ETH spent on relay transactions within a rolling period, keeping a ledger of the gas spent
+// by each relay.
+type SpendBudget interface {
+	// Allow returns true if spending the given amount (in wei) keeps the spend within the budget.
+	Allow(amount *big.Int) bool
+
+	// Record adds a relay transaction, sent with the given gas limit and price, to the ledger.
+	Record(txHash ethcmn.Hash, kind string, nonce uint64, gasLimit uint64, gasPrice *big.Int) error
+
+	// RecordReplacement adds a transaction that replaces or cancels a recorded relay transaction, sent with the given
+	// gas limit and effective gas price, to the ledger.
+	RecordReplacement(txHash, replacementHash ethcmn.Hash, gasLimit uint64, gasPrice *big.Int) error
+
+	// Settle updates the costs of a recorded relay transaction and its replacements once one of them, minedHash, used
+	// the given gas. Transactions that were not mined use no gas.
+	Settle(txHash, minedHash ethcmn.Hash, gasUsed uint64) error
+
+	// Spent returns the amount (in wei) spent within the current period.
+	Spent() *big.Int
+
+	// Limit returns the maximum amount (in wei) that can be spent within a period.
+	Limit() *big.Int
+}
+
+type spendBudget struct {
+	logger     zerolog.Logger
+	limit      *big.Int
+	period     time.Duration
+	ledgerPath string
+	now        func() time.Time
+
+	mtx     sync.Mutex
+	entries []*LedgerEntry
+
+	// ledgerLines is the number of lines of the ledger file. Settled entries are appended again, so the file is
+	// compacted once it has too many lines for the entries within the period.
+	ledgerLines int
+}
+
+// NewSpendBudget returns a SpendBudget of limit wei per period. If a ledger path is given, the ledger is stored in
+// that file (one JSON entry per line) so the spend survives restarts. Entries that left the period are pruned from
+// the file when it's loaded and whenever it's compacted.
+func NewSpendBudget(
+	logger zerolog.Logger,
+	limit *big.Int,
+	period time.Duration,
+	ledgerPath string,
+) (SpendBudget, error) {
+	if limit == nil || limit.Sign() <= 0 {
+		return nil, errors.New("spend budget must be greater than zero")
+	}
+
+	if period <= 0 {
+		return nil, errors.New("spend budget period must be greater than zero")
+	}
+
+	b := &spendBudget{
+		logger:     logger.With().Str("module", "spend_budget").Logger(),
+		limit:      limit,
+		period:     period,
+		ledgerPath: ledgerPath,
+		now:        time.Now,
+	}
+
+	if ledgerPath != "" {
+		if err := b.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+func (b *spendBudget) Allow(amount *big.Int) bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	total := new(big.Int).Add(b.spent(), amount)
+	return total.Cmp(b.limit) <= 0
+}
+
+func (b *spendBudget) Record(
+	txHash ethcmn.Hash,
+	kind string,
+	nonce uint64,
+	gasLimit uint64,
+	gasPrice *big.Int,
+) error {
+	entry := &LedgerEntry{
+		Time:     b.now(),
+		TxHash:   txHash,
+		Kind:     kind,
+		Nonce:    nonce,
+		GasLimit: gasLimit,
+		GasPrice: gasPrice,
+		Cost:     new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit)),
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.entries = append(b.entries, entry)
+	return b.persist(entry)
+}
+
+func (b *spendBudget) RecordReplacement(
+	txHash ethcmn.Hash,
+	replacementHash ethcmn.Hash,
+	gasLimit uint64,
+	gasPrice *big.Int,
+) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	relayEntries := b.relayEntries(txHash)
+	if len(relayEntries) == 0 {
+		return errors.Errorf("tx %s not found in the spend ledger", txHash.Hex())
+	}
+
+	// The replaced transactions can't be mined along with the replacement, so they no longer count until the relay
+	// is settled.
+	for _, entry := range relayEntries {
+		if entry.Settled || entry.Cost.Sign() == 0 {
+			continue
+		}
+
+		entry.Cost = big.NewInt(0)
+		if err := b.persist(entry); err != nil {
+			return err
+		}
+	}
+
+	relayEntry := relayEntries[0]
+	entry := &LedgerEntry{
+		Time:     b.now(),
+		TxHash:   replacementHash,
+		Kind:     relayEntry.Kind,
+		Nonce:    relayEntry.Nonce,
+		GasLimit: gasLimit,
+		GasPrice: gasPrice,
+		Cost:     new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit)),
+		Replaces: &txHash,
+	}
+
+	b.entries = append(b.entries, entry)
+	return b.persist(entry)
+}
+
+func (b *spendBudget) Settle(txHash, minedHash ethcmn.Hash, gasUsed uint64) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	relayEntries := b.relayEntries(txHash)
+	if len(relayEntries) == 0 {
+		return errors.Errorf("tx %s not found in the spend ledger", txHash.Hex())
+	}
+
+	for _, entry := range relayEntries {
+		entry.GasUsed = 0
+		if entry.TxHash == minedHash {
+			entry.GasUsed = gasUsed
+		}
+
+		entry.Cost = new(big.Int).Mul(entry.GasPrice, new(big.Int).SetUint64(entry.GasUsed))
+		entry.Settled = true
+
+		b.logger.Debug().
+			Str("tx_hash", entry.TxHash.Hex()).
+			Str("kind", entry.Kind).
+			Uint64("gas_used", entry.GasUsed).
+			Str("cost_wei", entry.Cost.String()).
+			Msg("settled relay cost")
+
+		if err := b.persist(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// relayEntries returns the entry of a relay transaction, followed by the entries of its replacements, or nil if the
+// relay is not in the ledger. Must be called with the lock held.
+func (b *spendBudget) relayEntries(txHash ethcmn.Hash) []*LedgerEntry {
+	var (
+		relayEntry   *LedgerEntry
+		replacements []*LedgerEntry
+	)
+
+	for _, entry := range b.entries {
+		switch {
+		case entry.Replaces == nil && entry.TxHash == txHash:
+			relayEntry = entry
+
+		case entry.Replaces != nil && *entry.Replaces == txHash:
+			replacements = append(replacements, entry)
+		}
+	}
+
+	if relayEntry == nil {
+		return nil
+	}
+
+	return append([]*LedgerEntry{relayEntry}, replacements...)
+}
+
+func (b *spendBudget) Spent() *big.Int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return b.spent()
+}
+
+func (b *spendBudget) Limit() *big.Int {
+	return new(big.Int).Set(b.limit)
+}
+
+// spent returns the sum of the costs of the entries within the current period, pruning the older ones. Must be
+// called with the lock held.
+func (b *spendBudget) spent() *big.Int {
+	periodStart := b.now().Add(-b.period)
+
+	total := big.NewInt(0)
+	entries := b.entries[:0]
+	for _, entry := range b.entries {
+		if entry.Time.Before(periodStart) {
+			continue
+		}
+
+		entries = append(entries, entry)
+		total.Add(total, entry.Cost)
+	}
+
+	b.entries = entries
+	return total
+}
+
+// persist appends an entry to the ledger file. Updated entries are appended again; the last one of each tx wins when
+// the ledger is loaded. The file is compacted once it has at least twice as many lines as there are entries within
+// the period (and at least ledgerCompactMinLines). Must be called with the lock held.
+func (b *spendBudget) persist(entry *LedgerEntry) error {
+	if b.ledgerPath == "" {
+		return nil
+	}
+
+	if err := b.appendEntry(entry); err != nil {
+		return err
+	}
+
+	if b.ledgerLines >= ledgerCompactMinLines {
+		b.spent() // prunes the entries that left the period
+		if staleLines := b.ledgerLines - len(b.entries); staleLines >= len(b.entries) {
+			return b.compact()
+		}
+	}
+
+	return nil
+}
+
+// appendEntry appends an entry to the ledger file.
+func (b *spendBudget) appendEntry(entry *LedgerEntry) error {
+	f, err := os.OpenFile(b.ledgerPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to open spend ledger")
+	}
+	defer f.Close()
+
+	bz, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(bz, '\n')); err != nil {
+		return errors.Wrap(err, "failed to write spend ledger")
+	}
+
+	b.ledgerLines++
+	return nil
+}
+
+// compact rewrites the ledger file with the last version of the entries within the period. The entries are written
+// to a temporary file that replaces the ledger, so a crash never leaves a partial ledger. Must be called with the
+// lock held.
+func (b *spendBudget) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(b.ledgerPath), filepath.Base(b.ledgerPath)+".tmp*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create compacted spend ledger")
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck // it fails once the file is renamed
+
+	w := bufio.NewWriter(tmp)
+	for _, entry := range b.entries {
+		bz, err := json.Marshal(entry)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+
+		if _, err := w.Write(append(bz, '\n')); err != nil {
+			tmp.Close()
+			return errors.Wrap(err, "failed to write compacted spend ledger")
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "failed to write compacted spend ledger")
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to write compacted spend ledger")
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+		return errors.Wrap(err, "failed to set compacted spend ledger permissions")
+	}
+
+	if err := os.Rename(tmp.Name(), b.ledgerPath); err != nil {
+		return errors.Wrap(err, "failed to replace spend ledger")
+	}
+
+	b.logger.Debug().Int("old_lines", b.ledgerLines).Int("entries", len(b.entries)).Msg("compacted spend ledger")
+
+	b.ledgerLines = len(b.entries)
+	return nil
+}
+
+// load reads the entries of the ledger file within the current period.
+func (b *spendBudget) load() error {
+	f, err := os.Open(b.ledgerPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to open spend ledger")
+	}
+	defer f.Close()
+
+	byHash := map[ethcmn.Hash]*LedgerEntry{}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry LedgerEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return errors.Wrap(err, "failed to decode spend ledger entry")
+		}
+
+		if existing, ok := byHash[entry.TxHash]; ok {
+			*existing = entry
+			continue
+		}
+
+		byHash[entry.TxHash] = &entry
+		b.entries = append(b.entries, &entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "failed to read spend ledger")
+	}
+
+	spent := b.spent()
+	if err := b.compact(); err != nil {
+		return err
+	}
+
+	b.logger.Info().
+		Str("spent_wei", spent.String()).
+		Str("limit_wei", b.limit.String()).
+		Msg("loaded spend ledger")
+
+	return nil
+}
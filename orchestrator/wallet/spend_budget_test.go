@@ -0,0 +1,199 @@
+package wallet
+
+import (
+	"bufio"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpendBudget(t *testing.T) {
+	firstTx := ethcmn.HexToHash("0x01")
+	secondTx := ethcmn.HexToHash("0x02")
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	b, err := NewSpendBudget(zerolog.Nop(), big.NewInt(1000), 24*time.Hour, "")
+	assert.Nil(t, err)
+
+	budget := b.(*spendBudget)
+	budget.now = func() time.Time { return now }
+
+	assert.True(t, budget.Allow(big.NewInt(1000)))
+	assert.False(t, budget.Allow(big.NewInt(1001)))
+
+	assert.Nil(t, budget.Record(firstTx, "batch", 1, 50, big.NewInt(10)))
+	assert.Equal(t, big.NewInt(500), budget.Spent())
+	assert.False(t, budget.Allow(big.NewInt(501)))
+
+	assert.Nil(t, budget.Settle(firstTx, firstTx, 30))
+	assert.Equal(t, big.NewInt(300), budget.Spent())
+	assert.Error(t, budget.Settle(secondTx, secondTx, 30))
+
+	now = now.Add(12 * time.Hour)
+	assert.Nil(t, budget.Record(secondTx, "valset", 2, 20, big.NewInt(10)))
+	assert.Equal(t, big.NewInt(500), budget.Spent())
+
+	// The first tx leaves the rolling period.
+	now = now.Add(13 * time.Hour)
+	assert.Equal(t, big.NewInt(200), budget.Spent())
+}
+
+func TestSpendBudgetLedger(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.jsonl")
+	firstTx := ethcmn.HexToHash("0x01")
+	secondTx := ethcmn.HexToHash("0x02")
+
+	budget, err := NewSpendBudget(zerolog.Nop(), big.NewInt(1000), 24*time.Hour, ledgerPath)
+	assert.Nil(t, err)
+
+	assert.Nil(t, budget.Record(firstTx, "batch", 1, 50, big.NewInt(10)))
+	assert.Nil(t, budget.Record(secondTx, "valset", 2, 20, big.NewInt(10)))
+	assert.Nil(t, budget.Settle(firstTx, firstTx, 30))
+
+	// The settled cost is loaded after a restart.
+	reloaded, err := NewSpendBudget(zerolog.Nop(), big.NewInt(1000), 24*time.Hour, ledgerPath)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(500), reloaded.Spent())
+
+	entries := reloaded.(*spendBudget).entries
+	assert.Len(t, entries, 2)
+	assert.True(t, entries[0].Settled)
+	assert.Equal(t, uint64(30), entries[0].GasUsed)
+	assert.Equal(t, "valset", entries[1].Kind)
+}
+
+func TestSpendBudgetReplacements(t *testing.T) {
+	relayTx := ethcmn.HexToHash("0x01")
+	replacementTx := ethcmn.HexToHash("0x02")
+	cancelTx := ethcmn.HexToHash("0x03")
+
+	newBudget := func(t *testing.T) SpendBudget {
+		budget, err := NewSpendBudget(zerolog.Nop(), big.NewInt(10000), 24*time.Hour, "")
+		assert.Nil(t, err)
+
+		assert.Nil(t, budget.Record(relayTx, "batch", 1, 50, big.NewInt(10)))
+		assert.Nil(t, budget.RecordReplacement(relayTx, replacementTx, 50, big.NewInt(12)))
+		assert.Nil(t, budget.RecordReplacement(relayTx, cancelTx, 21, big.NewInt(15)))
+
+		return budget
+	}
+
+	t.Run("pending", func(t *testing.T) {
+		budget := newBudget(t)
+
+		// Only the last tx sent for the relay counts.
+		assert.Equal(t, big.NewInt(315), budget.Spent())
+
+		entries := budget.(*spendBudget).entries
+		assert.Len(t, entries, 3)
+		assert.Equal(t, "batch", entries[2].Kind)
+		assert.Equal(t, uint64(1), entries[2].Nonce)
+		assert.Equal(t, &relayTx, entries[2].Replaces)
+	})
+
+	t.Run("replacement mined", func(t *testing.T) {
+		budget := newBudget(t)
+
+		assert.Nil(t, budget.Settle(relayTx, replacementTx, 40))
+		assert.Equal(t, big.NewInt(480), budget.Spent())
+	})
+
+	t.Run("original mined", func(t *testing.T) {
+		budget := newBudget(t)
+
+		assert.Nil(t, budget.Settle(relayTx, relayTx, 40))
+		assert.Equal(t, big.NewInt(400), budget.Spent())
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		budget := newBudget(t)
+
+		assert.Nil(t, budget.Settle(relayTx, cancelTx, 0))
+		assert.Equal(t, big.NewInt(0), budget.Spent())
+	})
+
+	t.Run("unknown relay", func(t *testing.T) {
+		budget := newBudget(t)
+
+		assert.Error(t, budget.RecordReplacement(replacementTx, cancelTx, 21, big.NewInt(15)))
+	})
+}
+
+func TestSpendBudgetLedgerReplacements(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.jsonl")
+	relayTx := ethcmn.HexToHash("0x01")
+	replacementTx := ethcmn.HexToHash("0x02")
+
+	budget, err := NewSpendBudget(zerolog.Nop(), big.NewInt(1000), 24*time.Hour, ledgerPath)
+	assert.Nil(t, err)
+
+	assert.Nil(t, budget.Record(relayTx, "batch", 1, 50, big.NewInt(10)))
+	assert.Nil(t, budget.RecordReplacement(relayTx, replacementTx, 50, big.NewInt(12)))
+
+	// The relay can be settled after a restart.
+	reloaded, err := NewSpendBudget(zerolog.Nop(), big.NewInt(1000), 24*time.Hour, ledgerPath)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(600), reloaded.Spent())
+
+	assert.Nil(t, reloaded.Settle(relayTx, replacementTx, 30))
+	assert.Equal(t, big.NewInt(360), reloaded.Spent())
+}
+
+func TestSpendBudgetLedgerCompaction(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.jsonl")
+	now := time.Now().Add(-25 * time.Hour)
+
+	b, err := NewSpendBudget(zerolog.Nop(), big.NewInt(1000000), 24*time.Hour, ledgerPath)
+	assert.Nil(t, err)
+
+	budget := b.(*spendBudget)
+	budget.now = func() time.Time { return now }
+
+	for i := int64(1); i <= ledgerCompactMinLines; i++ {
+		txHash := ethcmn.BigToHash(big.NewInt(i))
+		assert.Nil(t, budget.Record(txHash, "batch", uint64(i), 50, big.NewInt(10)))
+		assert.Nil(t, budget.Settle(txHash, txHash, 30))
+	}
+
+	// Every settled entry was written twice, so the ledger was compacted.
+	assert.Less(t, countLines(t, ledgerPath), 2*ledgerCompactMinLines)
+
+	// The entries that left the period are pruned from the ledger when it's loaded.
+	now = time.Now()
+	lastTx := ethcmn.HexToHash("0xff")
+	assert.Nil(t, budget.Record(lastTx, "valset", 1, 20, big.NewInt(10)))
+
+	reloaded, err := NewSpendBudget(zerolog.Nop(), big.NewInt(1000000), 24*time.Hour, ledgerPath)
+	assert.Nil(t, err)
+	assert.Len(t, reloaded.(*spendBudget).entries, 1)
+	assert.Equal(t, 1, countLines(t, ledgerPath))
+}
+
+func countLines(t *testing.T, path string) int {
+	f, err := os.Open(path)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	var lines int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines++
+	}
+
+	return lines
+}
+
+func TestParseBudgetPeriod(t *testing.T) {
+	period, err := ParseBudgetPeriod(BudgetPeriodWeek)
+	assert.Nil(t, err)
+	assert.Equal(t, 7*24*time.Hour, period)
+
+	_, err = ParseBudgetPeriod("month")
+	assert.Error(t, err)
+}